@@ -118,12 +118,30 @@ func (dp *Handler) Add(txn *badger.Txn, chainID uint32, utxoID []byte, biValue *
 	return nil
 }
 
+// AddSpent will store a deposit that the state trie already records as spent,
+// so that the deposit may be served to nodes syncing the state from this one
+func (dp *Handler) AddSpent(txn *badger.Txn, utxoID []byte, utxo *objs.TXOut) error {
+	utxoID = utils.CopySlice(utxoID)
+	utxoID = utils.ForceSliceToLength(utxoID, constants.HashLen)
+	if err := db.SetUTXO(txn, dp.makeKey(utxoID), utxo); err != nil {
+		utils.DebugTrace(dp.logger, err)
+		return err
+	}
+	return nil
+}
+
 // Remove will delete all references to a deposit from the Handler
 func (dp *Handler) Remove(txn *badger.Txn, utxoID []byte) error {
+	utxoID = utils.CopySlice(utxoID)
+	utxoID = utils.ForceSliceToLength(utxoID, constants.HashLen)
 	err := dp.valueIndex.Drop(txn, utxoID)
 	if err != nil {
 		utils.DebugTrace(dp.logger, err)
 	}
+	if err := utils.DeleteValue(txn, dp.makeKey(utxoID)); err != nil {
+		utils.DebugTrace(dp.logger, err)
+		return err
+	}
 	return nil
 }
 
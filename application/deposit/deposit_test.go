@@ -533,6 +533,19 @@ func TestDepositRemove(t *testing.T) {
 		if err != nil {
 			t.Fatal()
 		}
+		_, missing, _, err := hndlr.Get(txn, [][]byte{utxoID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(missing) != 1 {
+			t.Fatal("removed deposit still present")
+		}
+
+		// A removed deposit may be added again
+		err = hndlr.Add(txn, testingChainID, utxoID, two, testingOwner())
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		return nil
 	})
@@ -0,0 +1,194 @@
+package application_test
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	"github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const fastSyncChainID = uint32(42)
+
+type fastSyncNode struct {
+	database *db.Database
+	app      *application.Application
+	dph      *deposit.Handler
+}
+
+func newFastSyncNode(t *testing.T) *fastSyncNode {
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	stateDb, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	txnDb, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	database := &db.Database{}
+	assert.Nil(t, database.Init(stateDb))
+	dph := &deposit.Handler{}
+	assert.Nil(t, dph.Init())
+	app := &application.Application{}
+	assert.Nil(t, app.Init(database, txnDb, dph))
+	return &fastSyncNode{database: database, app: app, dph: dph}
+}
+
+// spendDeposits returns transactions moving num new deposits into value stores
+func spendDeposits(t *testing.T, node *fastSyncNode, num int) []interfaces.Transaction {
+	signer := &crypto.Secp256k1Signer{}
+	assert.Nil(t, signer.SetPrivk(crypto.Hasher([]byte("secret"))))
+	pubk, err := signer.Pubkey()
+	assert.Nil(t, err)
+	account := crypto.GetAccount(pubk)
+	owner := &objs.ValueStoreOwner{SVA: objs.ValueStoreSVA, CurveSpec: constants.CurveSecp256k1, Account: account}
+
+	txs := []interfaces.Transaction{}
+	err = node.database.Update(func(txn *badger.Txn) error {
+		for i := 0; i < num; i++ {
+			depositID := utils.ForceSliceToLength([]byte(strconv.Itoa(i+1)), constants.HashLen)
+			if err := node.dph.Add(txn, fastSyncChainID, depositID, big.NewInt(1), &objs.Owner{CurveSpec: constants.CurveSecp256k1, Account: account}); err != nil {
+				return err
+			}
+			deps, _, _, err := node.dph.Get(txn, [][]byte{depositID})
+			if err != nil {
+				return err
+			}
+			dep, err := deps[0].ValueStore()
+			if err != nil {
+				return err
+			}
+			txIn, err := dep.MakeTxIn()
+			if err != nil {
+				return err
+			}
+			out := &objs.TXOut{}
+			err = out.NewValueStore(&objs.ValueStore{
+				VSPreImage: &objs.VSPreImage{TXOutIdx: 0, Value: uint256.One(), ChainID: fastSyncChainID, Owner: owner},
+				TxHash:     make([]byte, constants.HashLen),
+			})
+			if err != nil {
+				return err
+			}
+			tx := &objs.Tx{Vin: []*objs.TXIn{txIn}, Vout: []*objs.TXOut{out}}
+			if err := tx.SetTxHash(); err != nil {
+				return err
+			}
+			if err := dep.Sign(tx.Vin[0], signer); err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	return txs
+}
+
+// fastSync copies the state trie at height from src to dst the way the
+// snapshot manager of consensus does
+func fastSync(t *testing.T, src *fastSyncNode, dst *fastSyncNode, height uint32, root []byte) error {
+	srcTxn := src.database.DB().NewTransaction(false)
+	defer srcTxn.Discard()
+
+	return dst.database.Update(func(txn *badger.Txn) error {
+		if err := dst.app.BeginSnapShotSync(txn); err != nil {
+			return err
+		}
+		pending := map[string]int{string(root): 0}
+		for len(pending) > 0 {
+			for key, layer := range pending {
+				delete(pending, key)
+				batch, err := src.app.GetSnapShotNode(srcTxn, height, []byte(key))
+				if err != nil {
+					return err
+				}
+				children, newLayer, leaves, err := dst.app.StoreSnapShotNode(txn, batch, []byte(key), layer)
+				if err != nil {
+					return err
+				}
+				for _, child := range children {
+					pending[string(child)] = newLayer
+				}
+				for _, leaf := range leaves {
+					data, err := src.app.GetSnapShotStateData(srcTxn, leaf.Key)
+					if err != nil {
+						return err
+					}
+					if err := dst.app.StoreSnapShotStateData(txn, leaf.Key, leaf.Value, data); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return dst.app.FinalizeSnapShotRoot(txn, root, height)
+	})
+}
+
+func stateRoot(t *testing.T, node *fastSyncNode, height uint32) []byte {
+	txn := node.database.DB().NewTransaction(true)
+	defer txn.Discard()
+	root, err := node.app.ApplyState(txn, fastSyncChainID, height, nil)
+	assert.Nil(t, err)
+	return root
+}
+
+func TestFastSyncSpentDeposits(t *testing.T) {
+	src := newFastSyncNode(t)
+	txs := spendDeposits(t, src, 20)
+	var root []byte
+	err := src.database.Update(func(txn *badger.Txn) error {
+		var err error
+		root, err = src.app.ApplyState(txn, fastSyncChainID, 2, txs)
+		return err
+	})
+	assert.Nil(t, err)
+
+	// The spent deposits are in the state trie under their consuming TXIn
+	dst := newFastSyncNode(t)
+	assert.Nil(t, fastSync(t, src, dst, 2, root))
+	assert.Equal(t, root, stateRoot(t, dst, 3))
+
+	// They are kept as spent, so they are neither spendable nor lost to
+	// nodes syncing from this one
+	err = dst.database.View(func(txn *badger.Txn) error {
+		depositID := utils.ForceSliceToLength([]byte("1"), constants.HashLen)
+		ok, err := dst.app.UTXOContains(txn, depositID)
+		if err != nil {
+			return err
+		}
+		assert.False(t, ok)
+		found, _, spent, err := dst.dph.Get(txn, [][]byte{depositID})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 0, len(found))
+		assert.Equal(t, 1, len(spent))
+		return nil
+	})
+	assert.Nil(t, err)
+
+	next := newFastSyncNode(t)
+	assert.Nil(t, fastSync(t, dst, next, 2, root))
+	assert.Equal(t, root, stateRoot(t, next, 3))
+
+	// A spent deposit that doesn't match its place in the trie is rejected
+	err = next.database.Update(func(txn *badger.Txn) error {
+		depositID := utils.ForceSliceToLength([]byte("1"), constants.HashLen)
+		data, err := next.app.GetSnapShotStateData(txn, depositID)
+		if err != nil {
+			return err
+		}
+		return next.app.StoreSnapShotStateData(txn, depositID, crypto.Hasher(depositID), data)
+	})
+	assert.NotNil(t, err)
+}
@@ -359,7 +359,19 @@ func (tm *txHandler) GetSnapShotNode(txn *badger.Txn, height uint32, key []byte)
 }
 
 func (tm *txHandler) StoreSnapShotStateData(txn *badger.Txn, key []byte, value []byte, data []byte) error {
-	return tm.uHdlr.StoreSnapShotStateData(txn, key, value, data)
+	if err := tm.uHdlr.StoreSnapShotStateData(txn, key, value, data); err != nil {
+		utils.DebugTrace(tm.logger, err)
+		return err
+	}
+	utxo := &objs.TXOut{}
+	if err := utxo.UnmarshalBinary(data); err != nil {
+		utils.DebugTrace(tm.logger, err)
+		return err
+	}
+	if !utxo.IsDeposit() {
+		return nil
+	}
+	return tm.dHdlr.AddSpent(txn, key, utxo)
 }
 
 func (tm *txHandler) FinalizeSync(txn *badger.Txn) error {
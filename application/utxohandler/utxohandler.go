@@ -835,6 +835,24 @@ func (ut *UTXOHandler) StoreSnapShotStateData(txn *badger.Txn, utxoID []byte, pr
 		}
 		return errorz.ErrInvalid{}.New(fmt.Sprintf("utxoID does not match calcUtxoID; utxoID: %x; calcUtxoID: %x calcTxHash: %x TxOutIdx: %v", utxoID, calcUtxoID, calcTxHash, utxoIdxOut))
 	}
+	if utxo.IsDeposit() {
+		// a deposit is only in the state trie once it has been spent, and the
+		// trie then holds the prehash of the consuming TXIn
+		txIn, err := utxo.MakeTxIn()
+		if err != nil {
+			utils.DebugTrace(ut.logger, err)
+			return err
+		}
+		calcPreHash, err := txIn.PreHash()
+		if err != nil {
+			utils.DebugTrace(ut.logger, err)
+			return err
+		}
+		if !bytes.Equal(calcPreHash, preHash) {
+			return errorz.ErrInvalid{}.New(fmt.Sprintf("preHash does not match spent deposit; preHash: %x; calcPreHash: %x; utxoID: %x", preHash, calcPreHash, utxoID))
+		}
+		return nil
+	}
 	calcPreHash, err := utxo.PreHash()
 	if err != nil {
 		utils.DebugTrace(ut.logger, err)
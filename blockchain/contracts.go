@@ -40,7 +40,7 @@ func (c *ContractDetails) LookupContracts(ctx context.Context, registryAddress c
 	logger := eth.logger
 
 	// Load the registry first
-	registry, err := bindings.NewRegistry(registryAddress, eth.client)
+	registry, err := bindings.NewRegistry(registryAddress, eth.transactor)
 	if err != nil {
 		return err
 	}
@@ -61,44 +61,44 @@ func (c *ContractDetails) LookupContracts(ctx context.Context, registryAddress c
 	c.depositAddress, err = lookup("deposit/v1")
 	logAndEat(logger, err)
 
-	c.deposit, err = bindings.NewDeposit(c.depositAddress, eth.client)
+	c.deposit, err = bindings.NewDeposit(c.depositAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	c.ethdkgAddress, err = lookup("ethdkg/v1")
 	logAndEat(logger, err)
 
-	c.ethdkg, err = bindings.NewETHDKG(c.ethdkgAddress, eth.client)
+	c.ethdkg, err = bindings.NewETHDKG(c.ethdkgAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	c.stakingTokenAddress, err = lookup("stakingToken/v1")
 	logAndEat(logger, err)
 
-	c.stakingToken, err = bindings.NewToken(c.stakingTokenAddress, eth.client)
+	c.stakingToken, err = bindings.NewToken(c.stakingTokenAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	c.utilityTokenAddress, err = lookup("utilityToken/v1")
 	logAndEat(logger, err)
 
-	c.utilityToken, err = bindings.NewToken(c.utilityTokenAddress, eth.client)
+	c.utilityToken, err = bindings.NewToken(c.utilityTokenAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	c.validatorsAddress, err = lookup("validators/v1")
 	logAndEat(logger, err)
 
 	// These all call the ValidatorsDiamond contract but we need various interfaces to keep API
-	c.validators, err = bindings.NewValidators(c.validatorsAddress, eth.client)
+	c.validators, err = bindings.NewValidators(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
-	c.participants, err = bindings.NewParticipants(c.validatorsAddress, eth.client)
+	c.participants, err = bindings.NewParticipants(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
-	c.snapshots, err = bindings.NewSnapshots(c.validatorsAddress, eth.client)
+	c.snapshots, err = bindings.NewSnapshots(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	stakingAddress, err := lookup("staking/v1")
 	logAndEat(logger, err)
 
-	c.staking, err = bindings.NewStaking(stakingAddress, eth.client)
+	c.staking, err = bindings.NewStaking(stakingAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	return nil
@@ -121,7 +121,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	facetConfigGroup := 222
 
 	var txn *types.Transaction
-	c.registryAddress, txn, c.registry, err = bindings.DeployRegistry(txnOpts, eth.client)
+	c.registryAddress, txn, c.registry, err = bindings.DeployRegistry(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy registry...")
 		return nil, common.Address{}, err
@@ -129,7 +129,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 	logger.Infof("* registryAddress = \"0x%0.40x\"", c.registryAddress)
 
-	c.stakingTokenAddress, txn, c.stakingToken, err = bindings.DeployToken(txnOpts, eth.client, StringToBytes32("STK"), StringToBytes32("MadNet Staking"))
+	c.stakingTokenAddress, txn, c.stakingToken, err = bindings.DeployToken(txnOpts, eth.transactor, StringToBytes32("STK"), StringToBytes32("MadNet Staking"))
 	if err != nil {
 		logger.Errorf("Failed to deploy stakingToken...")
 		return nil, common.Address{}, err
@@ -137,7 +137,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 	logger.Infof("  stakingTokenAddress = \"0x%0.40x\"", c.stakingTokenAddress)
 
-	c.cryptoAddress, txn, c.crypto, err = bindings.DeployCrypto(txnOpts, eth.client)
+	c.cryptoAddress, txn, c.crypto, err = bindings.DeployCrypto(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy crypto...")
 		return nil, common.Address{}, err
@@ -145,7 +145,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 	logger.Infof("        cryptoAddress = \"0x%0.40x\"", c.cryptoAddress)
 
-	c.utilityTokenAddress, txn, c.utilityToken, err = bindings.DeployToken(txnOpts, eth.client, StringToBytes32("UTL"), StringToBytes32("MadNet Utility"))
+	c.utilityTokenAddress, txn, c.utilityToken, err = bindings.DeployToken(txnOpts, eth.transactor, StringToBytes32("UTL"), StringToBytes32("MadNet Utility"))
 	if err != nil {
 		logger.Errorf("Failed to deploy utilityToken...")
 		return nil, common.Address{}, err
@@ -153,7 +153,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 	logger.Infof("  utilityTokenAddress = \"0x%0.40x\"", c.utilityTokenAddress)
 
-	c.depositAddress, txn, c.deposit, err = bindings.DeployDeposit(txnOpts, eth.client, c.registryAddress)
+	c.depositAddress, txn, c.deposit, err = bindings.DeployDeposit(txnOpts, eth.transactor, c.registryAddress)
 	if err != nil {
 		logger.Errorf("Failed to deploy deposit...")
 		return nil, common.Address{}, err
@@ -162,7 +162,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof("  depositAddress = \"0x%0.40x\"", c.depositAddress)
 
 	// Deploy ValidatorsDiamond
-	c.validatorsAddress, txn, _, err = bindings.DeployValidatorsDiamond(txnOpts, eth.client) // Deploy the core diamond
+	c.validatorsAddress, txn, _, err = bindings.DeployValidatorsDiamond(txnOpts, eth.transactor) // Deploy the core diamond
 	if err != nil {
 		logger.Errorf("Failed to deploy validators diamond...")
 		return nil, common.Address{}, err
@@ -170,21 +170,21 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 
 	// Deploy validators facets
-	participantsFacet, txn, _, err := bindings.DeployParticipantsFacet(txnOpts, eth.client)
+	participantsFacet, txn, _, err := bindings.DeployParticipantsFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Error("Failed to deploy participants facet...")
 		return nil, common.Address{}, err
 	}
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 
-	snapshotsFacet, txn, _, err := bindings.DeploySnapshotsFacet(txnOpts, eth.client)
+	snapshotsFacet, txn, _, err := bindings.DeploySnapshotsFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Error("Failed to deploy snapshots facet...")
 		return nil, common.Address{}, err
 	}
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 
-	stakingFacet, txn, _, err := bindings.DeployStakingFacet(txnOpts, eth.client)
+	stakingFacet, txn, _, err := bindings.DeployStakingFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Error("Failed to deploy staking facet...")
 		return nil, common.Address{}, err
@@ -192,26 +192,26 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, deployGroup, txn)
 
 	// Bind diamond to interfaces
-	c.validators, err = bindings.NewValidators(c.validatorsAddress, eth.client)
+	c.validators, err = bindings.NewValidators(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
-	c.staking, err = bindings.NewStaking(c.validatorsAddress, eth.client)
+	c.staking, err = bindings.NewStaking(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
-	c.participants, err = bindings.NewParticipants(c.validatorsAddress, eth.client)
+	c.participants, err = bindings.NewParticipants(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
-	c.snapshots, err = bindings.NewSnapshots(c.validatorsAddress, eth.client)
+	c.snapshots, err = bindings.NewSnapshots(c.validatorsAddress, eth.transactor)
 	logAndEat(logger, err)
 
-	c.validators, err = bindings.NewValidators(c.validatorsAddress, eth.client) // Validators is just an interface
+	c.validators, err = bindings.NewValidators(c.validatorsAddress, eth.transactor) // Validators is just an interface
 	if err != nil {
 		logger.Errorf("Failed to deploy validators...")
 		return nil, common.Address{}, err
 	}
 	logger.Infof("  validatorsAddress = \"0x%0.40x\"", c.validatorsAddress)
 
-	validatorsUpdate, err := bindings.NewDiamondUpdateFacet(c.validatorsAddress, eth.client)
+	validatorsUpdate, err := bindings.NewDiamondUpdateFacet(c.validatorsAddress, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to bind validators update  ..")
 		return nil, common.Address{}, err
@@ -278,9 +278,10 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, facetConfigGroup, vu.Add("confirmValidators()", participantsFacet))
 	q.QueueGroupTransaction(ctx, facetConfigGroup, vu.Add("validatorMaxCount()", participantsFacet))
 	q.QueueGroupTransaction(ctx, facetConfigGroup, vu.Add("validatorCount()", participantsFacet))
+	q.QueueGroupTransaction(ctx, facetConfigGroup, vu.Add("getValidators()", participantsFacet))
 	q.QueueGroupTransaction(ctx, facetConfigGroup, vu.Add("setValidatorMaxCount(uint8)", participantsFacet))
 
-	c.ethdkgAddress, txn, _, err = bindings.DeployEthDKGDiamond(txnOpts, eth.client)
+	c.ethdkgAddress, txn, _, err = bindings.DeployEthDKGDiamond(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGDiamond...")
 		return nil, common.Address{}, err
@@ -288,11 +289,11 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, facetConfigGroup, txn)
 	logger.Infof(" Gas = %0.10v EthDKGDiamond = \"0x%0.40x\"", txn.Gas(), c.EthdkgAddress())
 
-	c.ethdkg, err = bindings.NewETHDKG(c.ethdkgAddress, eth.client)
+	c.ethdkg, err = bindings.NewETHDKG(c.ethdkgAddress, eth.transactor)
 	logAndEat(logger, err)
 
 	var ethdkgCompletionAddress common.Address
-	ethdkgCompletionAddress, txn, _, err = bindings.DeployEthDKGCompletionFacet(txnOpts, eth.client)
+	ethdkgCompletionAddress, txn, _, err = bindings.DeployEthDKGCompletionFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGCompletionFacet...")
 		return nil, common.Address{}, err
@@ -301,7 +302,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof(" Gas = %0.10v EthDKGCompletionFacet = \"0x%0.40x\"", txn.Gas(), ethdkgCompletionAddress)
 
 	var ethdkgGroupAccusationAddress common.Address
-	ethdkgGroupAccusationAddress, txn, _, err = bindings.DeployEthDKGGroupAccusationFacet(txnOpts, eth.client)
+	ethdkgGroupAccusationAddress, txn, _, err = bindings.DeployEthDKGGroupAccusationFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGGroupAccusationFacet...")
 		return nil, common.Address{}, err
@@ -310,7 +311,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof(" Gas = %0.10v EthDKGGroupAccusationFacet = \"0x%0.40x\"", txn.Gas(), ethdkgGroupAccusationAddress)
 
 	var ethdkgInitializeAddress common.Address
-	ethdkgInitializeAddress, txn, _, err = bindings.DeployEthDKGInitializeFacet(txnOpts, eth.client)
+	ethdkgInitializeAddress, txn, _, err = bindings.DeployEthDKGInitializeFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGInitializeFacet...")
 		return nil, common.Address{}, err
@@ -319,7 +320,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof(" Gas = %0.10v EthDKGInitializeFacet = \"0x%0.40x\"", txn.Gas(), ethdkgInitializeAddress)
 
 	var ethdkgSubmitMPKAddress common.Address
-	ethdkgSubmitMPKAddress, txn, _, err = bindings.DeployEthDKGSubmitMPKFacet(txnOpts, eth.client)
+	ethdkgSubmitMPKAddress, txn, _, err = bindings.DeployEthDKGSubmitMPKFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGSubmitMPKFacet...")
 		return nil, common.Address{}, err
@@ -328,7 +329,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof(" Gas = %0.10v EthDKGSubmitMPKFacet = \"0x%0.40x\"", txn.Gas(), ethdkgSubmitMPKAddress)
 
 	var ethdkgSubmitDisputeAddress common.Address
-	ethdkgSubmitDisputeAddress, txn, _, err = bindings.DeployEthDKGSubmitDisputeFacet(txnOpts, eth.client)
+	ethdkgSubmitDisputeAddress, txn, _, err = bindings.DeployEthDKGSubmitDisputeFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGSubmitDisputeFacet...")
 		return nil, common.Address{}, err
@@ -337,7 +338,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof(" Gas = %0.10v EthDKGSubmitDisputeFacet = \"0x%0.40x\"", txn.Gas(), ethdkgSubmitDisputeAddress)
 
 	var ethdkgMiscAddress common.Address
-	ethdkgMiscAddress, txn, _, err = bindings.DeployEthDKGMiscFacet(txnOpts, eth.client)
+	ethdkgMiscAddress, txn, _, err = bindings.DeployEthDKGMiscFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGMiscFacet...")
 		return nil, common.Address{}, err
@@ -346,7 +347,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	logger.Infof(" Gas = %0.10v EthDKGMiscFacet = \"0x%0.40x\"", txn.Gas(), ethdkgMiscAddress)
 
 	var ethdkgInfoFacetAddress common.Address
-	ethdkgInfoFacetAddress, txn, _, err = bindings.DeployEthDKGInformationFacet(txnOpts, eth.client)
+	ethdkgInfoFacetAddress, txn, _, err = bindings.DeployEthDKGInformationFacet(txnOpts, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to deploy EthDKGInformationFacet...")
 		return nil, common.Address{}, err
@@ -354,7 +355,7 @@ func (c *ContractDetails) DeployContracts(ctx context.Context, account accounts.
 	q.QueueGroupTransaction(ctx, facetConfigGroup, txn)
 	logger.Infof(" Gas = %0.10v EthDKGInformationFacet = \"0x%0.40x\"", txn.Gas(), ethdkgInfoFacetAddress)
 
-	ethdkgUpdate, err := bindings.NewDiamondUpdateFacet(c.ethdkgAddress, eth.client)
+	ethdkgUpdate, err := bindings.NewDiamondUpdateFacet(c.ethdkgAddress, eth.transactor)
 	if err != nil {
 		logger.Errorf("Failed to bind ethdkg update  ..")
 		return nil, common.Address{}, err
@@ -24,6 +24,12 @@ func NewCompletionTask(state *objects.DkgState) *CompletionTask {
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *CompletionTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 func (t *CompletionTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
 	t.State.Lock()
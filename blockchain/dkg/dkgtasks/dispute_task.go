@@ -23,6 +23,12 @@ func NewDisputeTask(state *objects.DkgState) *DisputeTask {
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *DisputeTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 // This is not exported and does not lock so can only be called from within task. Return value indicates whether task has been initialized.
 func (t *DisputeTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 	t.State.Lock()
@@ -0,0 +1,17 @@
+package dkgtasks
+
+import "github.com/MadBase/MadNet/blockchain/tasks"
+
+// The tasks of every phase are registered so schedules holding them can be
+// persisted with the monitor state
+func init() {
+	tasks.RegisterTask(&PlaceHolder{})
+	tasks.RegisterTask(&RegisterTask{})
+	tasks.RegisterTask(&ShareDistributionTask{})
+	tasks.RegisterTask(&DisputeTask{})
+	tasks.RegisterTask(&KeyshareSubmissionTask{})
+	tasks.RegisterTask(&MPKSubmissionTask{})
+	tasks.RegisterTask(&GPKSubmissionTask{})
+	tasks.RegisterTask(&GPKJDisputeTask{})
+	tasks.RegisterTask(&CompletionTask{})
+}
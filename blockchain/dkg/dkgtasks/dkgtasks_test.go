@@ -108,15 +108,23 @@ func connectSimulatorEndpoint(t *testing.T, accountAddresses []string) interface
 }
 
 func connectRemoteEndpoint(t *testing.T, accountAddresses []string) interfaces.Ethereum {
+	passcodes, err := blockchain.PasscodeFile("assets_test/passcodes.txt")
+	assert.Nil(t, err)
+
 	eth, err := blockchain.NewEthereumEndpoint(
-		"http://192.168.86.29:8545",
-		"keystore_test",
-		"assets_test/passcodes.txt",
+		[]string{"http://192.168.86.29:8545"},
+		0, // Any chain is fine
+		blockchain.NewKeystoreSigner("keystore_test", passcodes),
 		accountAddresses[0],
 		3*time.Second, // This is the timeout for blocking actions
 		30,            // Let's do lots of retries
 		1*time.Second, // This is the retry delay
-		2)             // For testing finality is 2 blocks
+		2,             // For testing finality is 2 blocks
+		0,             // No minimum peer count
+		1,             // A single endpoint is trusted
+		0,             // Any head block age is fine
+		nil,           // No gas price limit
+		0)             // Transactions are never replaced
 	assert.Nil(t, err)
 
 	return eth
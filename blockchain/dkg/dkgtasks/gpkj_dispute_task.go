@@ -26,6 +26,12 @@ func NewGPKJDisputeTask(state *objects.DkgState) *GPKJDisputeTask {
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *GPKJDisputeTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 func (t *GPKJDisputeTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
 	t.State.Lock()
@@ -26,6 +26,12 @@ func NewGPKSubmissionTask(state *objects.DkgState) *GPKSubmissionTask {
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *GPKSubmissionTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 func (t *GPKSubmissionTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
 	t.State.Lock()
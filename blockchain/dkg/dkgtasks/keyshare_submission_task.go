@@ -25,6 +25,12 @@ func NewKeyshareSubmissionTask(state *objects.DkgState) *KeyshareSubmissionTask
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *KeyshareSubmissionTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 // This is not exported and does not lock so can only be called from within task. Return value indicates whether task has been initialized.
 func (t *KeyshareSubmissionTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
@@ -26,6 +26,12 @@ func NewMPKSubmissionTask(state *objects.DkgState) *MPKSubmissionTask {
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *MPKSubmissionTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 func (t *MPKSubmissionTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
 	t.State.Lock()
@@ -26,6 +26,12 @@ func NewRegisterTask(state *objects.DkgState) *RegisterTask {
 	}
 }
 
+// SetDkgState replaces the state of the round, which is decoded separately
+// for each task
+func (t *RegisterTask) SetDkgState(state *objects.DkgState) {
+	t.State = state
+}
+
 // This is not exported and does not lock so can only be called from within task. Return value indicates whether task has been initialized.
 func (t *RegisterTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
@@ -0,0 +1,638 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// Endpoint specific errors
+var (
+	ErrNoEndpoints  = errors.New("at least 1 ethereum endpoint required")
+	ErrNoQuorum     = errors.New("not enough ethereum endpoints agree on the logs")
+	ErrWrongChain   = errors.New("ethereum endpoint is on another chain than configured")
+	ErrNotConnected = errors.New("ethereum endpoint is not connected")
+)
+
+// endpoint is the connection to one Ethereum node. The connection is made
+// once, when the node first answers, and isn't changed afterwards.
+type endpoint struct {
+	sync.Mutex
+	url       string
+	rpcClient *rpc.Client
+	client    *ethclient.Client
+	closed    bool
+	healthy   bool
+}
+
+// connect dials the node unless the endpoint is connected already
+func (e *endpoint) connect(ctx context.Context) error {
+	e.Lock()
+	defer e.Unlock()
+	if e.closed {
+		return ErrNotConnected
+	}
+	if e.rpcClient != nil {
+		return nil
+	}
+	rpcClient, err := rpc.DialContext(ctx, e.url)
+	if err != nil {
+		return err
+	}
+	e.rpcClient = rpcClient
+	e.client = ethclient.NewClient(rpcClient)
+	return nil
+}
+
+// connected tells if calls can be made to the node
+func (e *endpoint) connected() bool {
+	e.Lock()
+	defer e.Unlock()
+	return e.rpcClient != nil && !e.closed
+}
+
+func (e *endpoint) close() {
+	e.Lock()
+	defer e.Unlock()
+	e.closed = true
+	if e.rpcClient != nil {
+		e.rpcClient.Close()
+	}
+}
+
+// endpointPool is a GethClient backed by several Ethereum nodes. Calls go to
+// the first healthy node in the configured order and fail over to the next
+// one when it stops responding.
+type endpointPool struct {
+	sync.RWMutex
+	logger     *logrus.Logger
+	endpoints  []*endpoint
+	current    int
+	chainID    *big.Int
+	timeout    time.Duration
+	minPeers   uint64
+	quorum     int
+	maxHeadAge time.Duration
+	closeChan  chan struct{}
+	closeOnce  sync.Once
+}
+
+// newEndpointPool connects to the endpoints, which must all be on chainID or,
+// if it's nil, on the chain of the first endpoint that answers. Endpoints that
+// can't be reached are retried by the health checks, as long as at least
+// quorum endpoints could be connected to.
+func newEndpointPool(logger *logrus.Logger, urls []string, chainID *big.Int, timeout time.Duration, minPeers int, quorum int, maxHeadAge time.Duration) (*endpointPool, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if quorum > len(urls) {
+		return nil, fmt.Errorf("quorum of %v is more than the %v ethereum endpoints", quorum, len(urls))
+	}
+
+	p := &endpointPool{
+		logger:     logger,
+		timeout:    timeout,
+		minPeers:   uint64(minPeers),
+		quorum:     quorum,
+		maxHeadAge: maxHeadAge,
+		closeChan:  make(chan struct{}),
+	}
+
+	connected := 0
+	for _, url := range urls {
+		e := &endpoint{url: url}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := e.connect(ctx)
+		cancel()
+		if err != nil {
+			logger.Errorf("Could not connect to ethereum endpoint %v: %v", url, err)
+		} else {
+			connected++
+		}
+		p.endpoints = append(p.endpoints, e)
+	}
+	required := quorum
+	if required < 1 {
+		required = 1
+	}
+	if connected < required {
+		p.close()
+		return nil, fmt.Errorf("connected to %v ethereum endpoints but %v are required", connected, required)
+	}
+
+	// Every endpoint must be on the chain of the first one that answers
+	var err error
+	for _, e := range p.endpoints {
+		if !e.connected() {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		p.chainID, err = e.client.ChainID(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+		logger.Warnf("Could not get chain ID from ethereum endpoint %v: %v", e.url, err)
+	}
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	if chainID != nil && chainID.Cmp(p.chainID) != 0 {
+		logger.Errorf("Ethereum endpoint is on chain %v but chain %v is configured", p.chainID, chainID)
+		p.close()
+		return nil, ErrWrongChain
+	}
+
+	p.checkHealth()
+
+	return p, nil
+}
+
+// healthLoop checks the endpoints until the pool is closed
+func (p *endpointPool) healthLoop() {
+	ticker := time.NewTicker(constants.EthereumHealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.closeChan:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+func (p *endpointPool) close() {
+	p.closeOnce.Do(func() {
+		close(p.closeChan)
+		for _, e := range p.endpoints {
+			e.close()
+		}
+	})
+}
+
+// checkHealth checks every endpoint and makes the first healthy one active
+func (p *endpointPool) checkHealth() {
+	healthy := make([]bool, len(p.endpoints))
+	for i, e := range p.endpoints {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		err := p.endpointHealth(ctx, e)
+		cancel()
+		if err != nil {
+			p.logger.Warnf("Ethereum endpoint %v is unhealthy: %v", e.url, err)
+		}
+		healthy[i] = err == nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	for i, e := range p.endpoints {
+		e.healthy = healthy[i]
+	}
+	for i, e := range p.endpoints {
+		if e.healthy {
+			if i != p.current {
+				p.logger.Infof("Switching to ethereum endpoint %v", e.url)
+				p.current = i
+			}
+			return
+		}
+	}
+	p.logger.Errorf("No healthy ethereum endpoint. Staying with %v.", p.endpoints[p.current].url)
+}
+
+// endpointHealth connects to an endpoint if needed and checks its chain, sync
+// progress, peers and head
+func (p *endpointPool) endpointHealth(ctx context.Context, e *endpoint) error {
+	if err := e.connect(ctx); err != nil {
+		return err
+	}
+
+	chainID, err := e.client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if chainID.Cmp(p.chainID) != 0 {
+		return fmt.Errorf("chain ID is %v instead of %v", chainID, p.chainID)
+	}
+
+	progress, err := e.client.SyncProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		return fmt.Errorf("syncing at block %v of %v", progress.CurrentBlock, progress.HighestBlock)
+	}
+
+	peers, err := p.peerCount(ctx, e)
+	if err != nil {
+		return err
+	}
+	if peers < p.minPeers {
+		return fmt.Errorf("has %v peers but %v are required", peers, p.minPeers)
+	}
+
+	header, err := e.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if p.maxHeadAge > 0 {
+		age := time.Since(time.Unix(int64(header.Time), 0))
+		if age > p.maxHeadAge {
+			return fmt.Errorf("head block %v is %v old", header.Number, age.Round(time.Second))
+		}
+	}
+
+	return nil
+}
+
+func (p *endpointPool) peerCount(ctx context.Context, e *endpoint) (uint64, error) {
+	var peerCountString string
+	if err := e.rpcClient.CallContext(ctx, &peerCountString, "net_peerCount"); err != nil {
+		return 0, err
+	}
+
+	var peerCount uint64
+	_, err := fmt.Sscanf(peerCountString, "0x%x", &peerCount)
+	if err != nil {
+		return 0, err
+	}
+	return peerCount, nil
+}
+
+// active returns the endpoint calls currently go to
+func (p *endpointPool) active() *endpoint {
+	p.RLock()
+	defer p.RUnlock()
+	return p.endpoints[p.current]
+}
+
+// failover marks an endpoint unhealthy and moves on to the next healthy one,
+// or simply the next one if none is healthy
+func (p *endpointPool) failover(e *endpoint, err error) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.logger.Warnf("Ethereum endpoint %v failed: %v", e.url, err)
+	e.healthy = false
+	if p.endpoints[p.current] != e {
+		return
+	}
+
+	next := (p.current + 1) % len(p.endpoints)
+	for i := range p.endpoints {
+		idx := (p.current + 1 + i) % len(p.endpoints)
+		if p.endpoints[idx].healthy {
+			next = idx
+			break
+		}
+	}
+	p.current = next
+	p.logger.Infof("Switching to ethereum endpoint %v", p.endpoints[next].url)
+}
+
+// endpointFailed tells if err means the endpoint could not answer, as opposed
+// to an answer that is an error
+func endpointFailed(ctx context.Context, err error) bool {
+	if err == nil || err == ethereum.NotFound {
+		return false
+	}
+	if _, ok := err.(rpc.Error); ok {
+		return false
+	}
+	return ctx.Err() != context.Canceled
+}
+
+// do runs fn against the active endpoint and fails over until an endpoint
+// answers or every endpoint was tried
+func (p *endpointPool) do(ctx context.Context, fn func(*endpoint) error) error {
+	var err error
+	for range p.endpoints {
+		e := p.active()
+		err = ErrNotConnected
+		if e.connected() {
+			err = fn(e)
+		}
+		if !endpointFailed(ctx, err) {
+			return err
+		}
+		p.failover(e, err)
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// url returns the url of the active endpoint
+func (p *endpointPool) url() string {
+	return p.active().url
+}
+
+// PeerCount returns the number of peers of the active endpoint
+func (p *endpointPool) PeerCount(ctx context.Context) (uint64, error) {
+	var count uint64
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		count, err = p.peerCount(ctx, e)
+		return
+	})
+	return count, err
+}
+
+// SyncProgress returns the sync progress of the active endpoint
+func (p *endpointPool) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	var progress *ethereum.SyncProgress
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		progress, err = e.client.SyncProgress(ctx)
+		return
+	})
+	return progress, err
+}
+
+// CallContext makes a raw JSON-RPC call to the active endpoint
+func (p *endpointPool) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return p.do(ctx, func(e *endpoint) error {
+		return e.rpcClient.CallContext(ctx, result, method, args...)
+	})
+}
+
+// QuorumFilterLogs returns the logs matching query once enough endpoints
+// returned exactly the same logs. Endpoints that disagree are marked unhealthy.
+func (p *endpointPool) QuorumFilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if p.quorum <= 1 {
+		return p.FilterLogs(ctx, query)
+	}
+
+	// Ask the healthy endpoints first
+	p.RLock()
+	order := make([]*endpoint, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if e.healthy {
+			order = append(order, e)
+		}
+	}
+	for _, e := range p.endpoints {
+		if !e.healthy {
+			order = append(order, e)
+		}
+	}
+	p.RUnlock()
+
+	answers := make(map[*endpoint]common.Hash)
+	votes := make(map[common.Hash]int)
+	for _, e := range order {
+		if !e.connected() {
+			continue
+		}
+		logs, err := e.client.FilterLogs(ctx, query)
+		if err != nil {
+			if endpointFailed(ctx, err) {
+				p.failover(e, err)
+			}
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		raw, err := json.Marshal(logs)
+		if err != nil {
+			return nil, err
+		}
+		hash := crypto.Keccak256Hash(raw)
+		answers[e] = hash
+		votes[hash]++
+		if votes[hash] < p.quorum {
+			continue
+		}
+		for other, otherHash := range answers {
+			if otherHash != hash {
+				p.failover(other, errors.New("returned logs that disagree with the quorum"))
+			}
+		}
+		return logs, nil
+	}
+
+	return nil, ErrNoQuorum
+}
+
+// BlockByHash implements GethClient
+func (p *endpointPool) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	var block *types.Block
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		block, err = e.client.BlockByHash(ctx, hash)
+		return
+	})
+	return block, err
+}
+
+// BlockByNumber implements GethClient
+func (p *endpointPool) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	var block *types.Block
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		block, err = e.client.BlockByNumber(ctx, number)
+		return
+	})
+	return block, err
+}
+
+// HeaderByHash implements GethClient
+func (p *endpointPool) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	var header *types.Header
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		header, err = e.client.HeaderByHash(ctx, hash)
+		return
+	})
+	return header, err
+}
+
+// HeaderByNumber implements GethClient
+func (p *endpointPool) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var header *types.Header
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		header, err = e.client.HeaderByNumber(ctx, number)
+		return
+	})
+	return header, err
+}
+
+// TransactionCount implements GethClient
+func (p *endpointPool) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	var count uint
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		count, err = e.client.TransactionCount(ctx, blockHash)
+		return
+	})
+	return count, err
+}
+
+// TransactionInBlock implements GethClient
+func (p *endpointPool) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
+	var txn *types.Transaction
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		txn, err = e.client.TransactionInBlock(ctx, blockHash, index)
+		return
+	})
+	return txn, err
+}
+
+// SubscribeNewHead implements GethClient. Subscriptions stay on the endpoint
+// that was active when they were made.
+func (p *endpointPool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	e := p.active()
+	if !e.connected() {
+		return nil, ErrNotConnected
+	}
+	return e.client.SubscribeNewHead(ctx, ch)
+}
+
+// TransactionByHash implements GethClient
+func (p *endpointPool) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	var txn *types.Transaction
+	var isPending bool
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		txn, isPending, err = e.client.TransactionByHash(ctx, txHash)
+		return
+	})
+	return txn, isPending, err
+}
+
+// TransactionReceipt implements GethClient
+func (p *endpointPool) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var rcpt *types.Receipt
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		rcpt, err = e.client.TransactionReceipt(ctx, txHash)
+		return
+	})
+	return rcpt, err
+}
+
+// BalanceAt implements GethClient
+func (p *endpointPool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var balance *big.Int
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		balance, err = e.client.BalanceAt(ctx, account, blockNumber)
+		return
+	})
+	return balance, err
+}
+
+// StorageAt implements GethClient
+func (p *endpointPool) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	var storage []byte
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		storage, err = e.client.StorageAt(ctx, account, key, blockNumber)
+		return
+	})
+	return storage, err
+}
+
+// CodeAt implements GethClient
+func (p *endpointPool) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	var code []byte
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		code, err = e.client.CodeAt(ctx, account, blockNumber)
+		return
+	})
+	return code, err
+}
+
+// NonceAt implements GethClient
+func (p *endpointPool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	var nonce uint64
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		nonce, err = e.client.NonceAt(ctx, account, blockNumber)
+		return
+	})
+	return nonce, err
+}
+
+// CallContract implements GethClient
+func (p *endpointPool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		result, err = e.client.CallContract(ctx, call, blockNumber)
+		return
+	})
+	return result, err
+}
+
+// PendingCodeAt implements GethClient
+func (p *endpointPool) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	var code []byte
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		code, err = e.client.PendingCodeAt(ctx, account)
+		return
+	})
+	return code, err
+}
+
+// PendingNonceAt implements GethClient
+func (p *endpointPool) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	var nonce uint64
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		nonce, err = e.client.PendingNonceAt(ctx, account)
+		return
+	})
+	return nonce, err
+}
+
+// SuggestGasPrice implements GethClient
+func (p *endpointPool) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var price *big.Int
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		price, err = e.client.SuggestGasPrice(ctx)
+		return
+	})
+	return price, err
+}
+
+// EstimateGas implements GethClient
+func (p *endpointPool) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	var gas uint64
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		gas, err = e.client.EstimateGas(ctx, call)
+		return
+	})
+	return gas, err
+}
+
+// SendTransaction implements GethClient
+func (p *endpointPool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return p.do(ctx, func(e *endpoint) error {
+		return e.client.SendTransaction(ctx, tx)
+	})
+}
+
+// FilterLogs implements GethClient
+func (p *endpointPool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := p.do(ctx, func(e *endpoint) (err error) {
+		logs, err = e.client.FilterLogs(ctx, query)
+		return
+	})
+	return logs, err
+}
+
+// SubscribeFilterLogs implements GethClient. Subscriptions stay on the
+// endpoint that was active when they were made.
+func (p *endpointPool) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	e := p.active()
+	if !e.connected() {
+		return nil, ErrNotConnected
+	}
+	return e.client.SubscribeFilterLogs(ctx, query, ch)
+}
@@ -0,0 +1,291 @@
+package blockchain_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// simEndpoint serves a simulated backend over HTTP with the calls an
+// Ethereum endpoint needs
+type simEndpoint struct {
+	sync.Mutex
+	sim    *backends.SimulatedBackend
+	server *httptest.Server
+	peers  uint
+	forged []types.Log
+}
+
+type simEthAPI struct{ e *simEndpoint }
+
+func (api *simEthAPI) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(1337)
+}
+
+func (api *simEthAPI) Syncing() bool {
+	return false
+}
+
+func (api *simEthAPI) Coinbase() common.Address {
+	return common.Address{}
+}
+
+func (api *simEthAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	var height *big.Int
+	if number >= 0 {
+		height = big.NewInt(number.Int64())
+	}
+	header, err := api.e.sim.HeaderByNumber(ctx, height)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	block := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	}
+	block["transactions"] = []interface{}{}
+	block["uncles"] = []interface{}{}
+	return block, nil
+}
+
+type simFilterArgs struct {
+	FromBlock *hexutil.Big     `json:"fromBlock"`
+	ToBlock   *hexutil.Big     `json:"toBlock"`
+	Addresses []common.Address `json:"address"`
+}
+
+func (api *simEthAPI) GetLogs(ctx context.Context, args simFilterArgs) ([]types.Log, error) {
+	logs, err := api.e.sim.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: (*big.Int)(args.FromBlock),
+		ToBlock:   (*big.Int)(args.ToBlock),
+		Addresses: args.Addresses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	api.e.Lock()
+	defer api.e.Unlock()
+	return append(logs, api.e.forged...), nil
+}
+
+type simNetAPI struct{ e *simEndpoint }
+
+func (api *simNetAPI) PeerCount() hexutil.Uint {
+	api.e.Lock()
+	defer api.e.Unlock()
+	return hexutil.Uint(api.e.peers)
+}
+
+func newSimEndpoint(t *testing.T, peers uint) *simEndpoint {
+	genAlloc := make(core.GenesisAlloc)
+	for _, address := range accountAddresses {
+		genAlloc[common.HexToAddress(address)] = core.GenesisAccount{Balance: big.NewInt(9223372036854775807)}
+	}
+	e := &simEndpoint{
+		sim:   backends.NewSimulatedBackend(genAlloc, 10000000000000000),
+		peers: peers,
+	}
+	e.sim.Commit()
+
+	srv := rpc.NewServer()
+	assert.Nil(t, srv.RegisterName("eth", &simEthAPI{e}))
+	assert.Nil(t, srv.RegisterName("net", &simNetAPI{e}))
+	e.server = httptest.NewServer(srv)
+	t.Cleanup(func() {
+		e.server.Close()
+		srv.Stop()
+		e.sim.Close()
+	})
+	return e
+}
+
+func (e *simEndpoint) forge(log types.Log) {
+	e.Lock()
+	defer e.Unlock()
+	e.forged = append(e.forged, log)
+}
+
+func testSigner(t *testing.T) blockchain.Signer {
+	passcodes, err := blockchain.PasscodeFile("../assets/test/passcodes.txt")
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return blockchain.NewKeystoreSigner("../assets/test/keys", passcodes)
+}
+
+func connectEndpoints(t *testing.T, quorum int, maxHeadAge time.Duration, eps ...*simEndpoint) *blockchain.EthereumDetails {
+	urls := []string{}
+	for _, e := range eps {
+		urls = append(urls, e.server.URL)
+	}
+	eth, err := blockchain.NewEthereumEndpoint(
+		urls,
+		0,
+		testSigner(t),
+		accountAddresses[0],
+		time.Second,
+		1,
+		time.Second,
+		0,
+		1,
+		quorum,
+		maxHeadAge,
+		nil,
+		0)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { eth.Close() })
+	return eth
+}
+
+func TestEndpointFailover(t *testing.T) {
+	ctx := context.Background()
+	eps := []*simEndpoint{
+		newSimEndpoint(t, 0),
+		newSimEndpoint(t, 1),
+		newSimEndpoint(t, 1),
+		newSimEndpoint(t, 1),
+	}
+	eth := connectEndpoints(t, 2, 0, eps...)
+
+	// The endpoint without peers is skipped
+	assert.Equal(t, eps[1].server.URL, eth.GetEndpoint())
+	assert.Equal(t, int64(1337), eth.ChainID().Int64())
+	height, err := eth.GetCurrentHeight(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	logs, err := eth.GetEvents(ctx, 0, 1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(logs))
+
+	// Forged logs are outvoted and their endpoint is dropped
+	eps[1].forge(types.Log{Address: common.HexToAddress("0x1"), Topics: []common.Hash{}, BlockNumber: 1, Data: []byte{1}})
+	logs, err = eth.GetEvents(ctx, 0, 1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(logs))
+	assert.Equal(t, eps[2].server.URL, eth.GetEndpoint())
+
+	// A stalled endpoint is replaced
+	eps[2].server.Close()
+	height, err = eth.GetCurrentHeight(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), height)
+	assert.Equal(t, eps[3].server.URL, eth.GetEndpoint())
+
+	// Without a quorum no logs are returned
+	eps[0].forge(types.Log{Address: common.HexToAddress("0x2"), Topics: []common.Hash{}, BlockNumber: 1, Data: []byte{2}})
+	_, err = eth.GetEvents(ctx, 0, 1, nil)
+	assert.Equal(t, blockchain.ErrNoQuorum, err)
+}
+
+func TestEndpointHeadAge(t *testing.T) {
+	stale := newSimEndpoint(t, 1)
+	fresh := newSimEndpoint(t, 1)
+	// Blocks from the future are not accepted so bring the head close to now
+	assert.Nil(t, fresh.sim.AdjustTime(time.Duration(time.Now().Unix()-60)*time.Second))
+	fresh.sim.Commit()
+
+	eth := connectEndpoints(t, 1, time.Minute, stale, fresh)
+	assert.Equal(t, fresh.server.URL, eth.GetEndpoint())
+
+	eth = connectEndpoints(t, 1, 0, stale, fresh)
+	assert.Equal(t, stale.server.URL, eth.GetEndpoint())
+
+	// A zero head age, the default of the flag, isn't checked so an endpoint
+	// with a head of a few seconds ago is healthy
+	noPeers := newSimEndpoint(t, 0)
+	recent := newSimEndpoint(t, 1)
+	assert.Nil(t, recent.sim.AdjustTime(time.Duration(time.Now().Unix()-25)*time.Second))
+	recent.sim.Commit()
+	eth = connectEndpoints(t, 1, 0, noPeers, recent)
+	assert.Equal(t, recent.server.URL, eth.GetEndpoint())
+}
+
+func TestEndpointChainID(t *testing.T) {
+	e := newSimEndpoint(t, 1)
+	for _, test := range []struct {
+		chainID uint64
+		err     error
+	}{{1337, nil}, {1, blockchain.ErrWrongChain}} {
+		eth, err := blockchain.NewEthereumEndpoint(
+			[]string{e.server.URL},
+			test.chainID,
+			testSigner(t),
+			accountAddresses[0],
+			time.Second,
+			1,
+			time.Second,
+			0,
+			1,
+			1,
+			0,
+			nil,
+			0)
+		assert.Equal(t, test.err, err)
+		if err == nil {
+			assert.Equal(t, int64(1337), eth.ChainID().Int64())
+			eth.Close()
+		}
+	}
+}
+
+func TestEndpointUnreachable(t *testing.T) {
+	e := newSimEndpoint(t, 1)
+	dead := "ws://127.0.0.1:1"
+	for _, test := range []struct {
+		urls   []string
+		quorum int
+		ok     bool
+	}{
+		{[]string{dead, e.server.URL}, 1, true},
+		{[]string{dead, e.server.URL}, 2, false},
+		{[]string{dead}, 0, false},
+	} {
+		eth, err := blockchain.NewEthereumEndpoint(
+			test.urls,
+			0,
+			testSigner(t),
+			accountAddresses[0],
+			time.Second,
+			1,
+			time.Second,
+			0,
+			1,
+			test.quorum,
+			0,
+			nil,
+			0)
+		if !test.ok {
+			assert.NotNil(t, err)
+			continue
+		}
+		if assert.Nil(t, err) {
+			// The unreachable endpoint is skipped until it answers
+			assert.Equal(t, e.server.URL, eth.GetEndpoint())
+			height, err := eth.GetCurrentHeight(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(1), height)
+			eth.Close()
+		}
+	}
+}
@@ -1,17 +1,13 @@
 package blockchain
 
 import (
-	"bufio"
 	"context"
 	"errors"
-	"fmt"
-	"io/ioutil"
 	"math/big"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/MadBase/bridge/bindings"
 	"github.com/ethereum/go-ethereum"
@@ -22,8 +18,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,25 +30,27 @@ var (
 
 type EthereumDetails struct {
 	logger         *logrus.Logger
-	endpoint       string
-	keystore       *keystore.KeyStore
+	endpoint       func() string
+	signer         Signer
 	finalityDelay  uint64
 	accounts       map[common.Address]accounts.Account
 	coinbase       common.Address
 	defaultAccount accounts.Account
-	keys           map[common.Address]*keystore.Key
-	passcodes      map[common.Address]string
 	timeout        time.Duration
 	retryCount     int
 	retryDelay     time.Duration
 	contracts      interfaces.Contracts
 	client         interfaces.GethClient
+	transactor     interfaces.GethClient
 	close          func() error
 	commit         func()
 	chainID        *big.Int
 	syncing        func(ctx context.Context) (*ethereum.SyncProgress, error)
 	peerCount      func(ctx context.Context) (uint64, error)
+	filterLogs     func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
 	queue          interfaces.TxnQueue
+	fees           *FeeEstimator
+	nonces         *NonceManager
 	selectors      interfaces.SelectorMap
 }
 
@@ -85,8 +81,6 @@ func NewEthereumSimulator(
 	eth := &EthereumDetails{
 		logger:        logger,
 		accounts:      make(map[common.Address]accounts.Account),
-		keys:          make(map[common.Address]*keystore.Key),
-		passcodes:     make(map[common.Address]string),
 		retryCount:    retryCount,
 		retryDelay:    retryDelay,
 		timeout:       timeout,
@@ -94,10 +88,14 @@ func NewEthereumSimulator(
 		selectors:     newKnownSelectors()}
 	eth.contracts = &ContractDetails{eth: eth}
 
-	eth.LoadAccounts(pathKeystore)
-	err := eth.LoadPasscodes(pathPasscodes)
+	passcodes, err := PasscodeFile(pathPasscodes)
 	if err != nil {
-		logger.Errorf("Error in NewEthereumSimulator at eth.LoadPasscodes: %v", err)
+		logger.Errorf("Error in NewEthereumSimulator at PasscodeFile: %v", err)
+		return nil, err
+	}
+	eth.signer = NewKeystoreSigner(pathKeystore, passcodes)
+	if err := eth.loadAccounts(context.Background()); err != nil {
+		logger.Errorf("Error in NewEthereumSimulator at eth.loadAccounts: %v", err)
 		return nil, err
 	}
 
@@ -110,16 +108,22 @@ func NewEthereumSimulator(
 	gasLimit := uint64(10000000000000000)
 	sim := backends.NewSimulatedBackend(genAlloc, gasLimit)
 	eth.client = sim
-	eth.queue = NewTxnQueue(sim, eth.selectors)
+	eth.chainID = big.NewInt(1337)
+	eth.fees = NewFeeEstimator(sim, nil, nil)
+	// Transactions are in the simulator as soon as they're sent, so any it
+	// doesn't know of were dropped, e.g. by rolling back
+	eth.nonces = NewNonceManager(sim, 0)
+	eth.transactor = &nonceTransactor{GethClient: sim, nonces: eth.nonces, signer: types.NewEIP155Signer(eth.chainID), ordered: true}
+	eth.queue = NewTxnQueue(sim, eth.selectors, eth.chainID, eth.fees, eth.SignTransaction, 0, eth.nonces)
 	eth.queue.StartLoop()
 
-	eth.chainID = big.NewInt(1337)
 	eth.peerCount = func(context.Context) (uint64, error) {
 		return 0, nil
 	}
 	eth.syncing = func(ctx context.Context) (*ethereum.SyncProgress, error) {
 		return nil, nil
 	}
+	eth.filterLogs = sim.FilterLogs
 
 	eth.close = func() error {
 		return sim.Close()
@@ -132,25 +136,35 @@ func NewEthereumSimulator(
 	return eth, nil
 }
 
-// NewEthereumEndpoint creates a new Ethereum abstraction
+// NewEthereumEndpoint creates a new Ethereum abstraction. Transactions are
+// signed by signer, which also lists the accounts available. Calls fail over
+// between the endpoints, which must be on chain chainID, zero to accept the
+// chain of the first one answering, and are checked for at least minPeers
+// peers and a head block no older than maxHeadAge. Events are only accepted once quorum
+// endpoints returned the same logs. Transactions offer at most maxGasPrice wei
+// per gas, zero for no limit, and are replaced with a higher gas price when
+// they aren't mined within replaceAfter blocks, zero to never replace.
 func NewEthereumEndpoint(
-	endpoint string,
-	pathKeystore string,
-	pathPasscodes string,
+	endpoints []string,
+	chainID uint64,
+	signer Signer,
 	defaultAccount string,
 	timeout time.Duration,
 	retryCount int,
 	retryDelay time.Duration,
-	finalityDelay int) (*EthereumDetails, error) {
+	finalityDelay int,
+	minPeers int,
+	quorum int,
+	maxHeadAge time.Duration,
+	maxGasPrice *big.Int,
+	replaceAfter uint64) (*EthereumDetails, error) {
 
 	logger := logging.GetLogger("ethereum")
 
 	eth := &EthereumDetails{
-		endpoint:      endpoint,
 		logger:        logger,
 		accounts:      make(map[common.Address]accounts.Account),
-		keys:          make(map[common.Address]*keystore.Key),
-		passcodes:     make(map[common.Address]string),
+		signer:        signer,
 		finalityDelay: uint64(finalityDelay),
 		timeout:       timeout,
 		retryCount:    retryCount,
@@ -159,11 +173,12 @@ func NewEthereumEndpoint(
 
 	eth.contracts = &ContractDetails{eth: eth}
 
-	// Load accounts + passcodes
-	eth.LoadAccounts(pathKeystore)
-	err := eth.LoadPasscodes(pathPasscodes)
+	// Load accounts
+	loadCtx, loadCancel := context.WithTimeout(context.Background(), timeout)
+	defer loadCancel()
+	err := eth.loadAccounts(loadCtx)
 	if err != nil {
-		logger.Errorf("Error in NewEthereumEndpoint at eth.LoadPasscodes: %v", err)
+		logger.Errorf("Error in NewEthereumEndpoint at eth.loadAccounts: %v", err)
 		return nil, err
 	}
 
@@ -176,37 +191,44 @@ func NewEthereumEndpoint(
 	}
 	eth.SetDefaultAccount(acct)
 
-	// Low level rpc client
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	rpcClient, rpcErr := rpc.DialContext(ctx, endpoint)
-	if rpcErr != nil {
-		logger.Errorf("Error in NewEthereumEndpoint at rpc.DialContext: %v", err)
-		return nil, rpcErr
-	}
-	ethClient := ethclient.NewClient(rpcClient)
-	eth.client = ethClient
-	eth.queue = NewTxnQueue(ethClient, eth.selectors)
-	eth.chainID, err = ethClient.ChainID(ctx)
+	// Low level rpc clients
+	var expectedChainID *big.Int
+	if chainID != 0 {
+		expectedChainID = new(big.Int).SetUint64(chainID)
+	}
+	pool, err := newEndpointPool(logger, endpoints, expectedChainID, timeout, minPeers, quorum, maxHeadAge)
 	if err != nil {
-		logger.Errorf("Error in NewEthereumEndpoint at ethClient.ChainID: %v", err)
+		logger.Errorf("Error in NewEthereumEndpoint at newEndpointPool: %v", err)
 		return nil, err
 	}
+	go pool.healthLoop()
 
-	eth.peerCount = func(ctx context.Context) (uint64, error) {
-		return eth.getPeerCount(ctx, rpcClient)
-	}
-	eth.syncing = ethClient.SyncProgress
+	eth.client = pool
+	eth.chainID = pool.chainID
+	eth.fees = NewFeeEstimator(pool, pool.CallContext, maxGasPrice)
+	eth.nonces = NewNonceManager(pool, constants.EthereumNonceDropTimeout)
+	eth.transactor = &nonceTransactor{GethClient: pool, nonces: eth.nonces, signer: types.NewEIP155Signer(eth.chainID)}
+	eth.queue = NewTxnQueue(pool, eth.selectors, eth.chainID, eth.fees, eth.SignTransaction, replaceAfter, eth.nonces)
+	eth.queue.StartLoop()
+	eth.endpoint = pool.url
+	eth.peerCount = pool.PeerCount
+	eth.syncing = pool.SyncProgress
+	eth.filterLogs = pool.QuorumFilterLogs
 
 	// Find coinbase
-	if e := rpcClient.CallContext(ctx, &eth.coinbase, "eth_coinbase"); e != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if e := pool.CallContext(ctx, &eth.coinbase, "eth_coinbase"); e != nil {
 		logger.Warnf("Failed to determine coinbase: %v", e)
 	} else {
 		logger.Infof("Coinbase: %v", eth.coinbase.Hex())
 	}
 
 	logger.Debug("Completed initialization")
-	eth.close = func() error { return nil }
+	eth.close = func() error {
+		pool.close()
+		return nil
+	}
 	eth.commit = func() {}
 
 	return eth, nil
@@ -355,21 +377,14 @@ func (eth *EthereumDetails) Queue() interfaces.TxnQueue {
 	return eth.queue
 }
 
-func (eth *EthereumDetails) getPeerCount(ctx context.Context, rpcClient *rpc.Client) (uint64, error) {
-	// Let's see how many peers our endpoint has
-	var peerCountString string
-	if err := rpcClient.CallContext(ctx, &peerCountString, "net_peerCount"); err != nil {
-		eth.logger.Warnf("could not get peerCount: %v", err)
-		return 0, err
-	}
+// Nonces returns the nonce manager used for transactions of all accounts
+func (eth *EthereumDetails) Nonces() *NonceManager {
+	return eth.nonces
+}
 
-	var peerCount uint64
-	_, err := fmt.Sscanf(peerCountString, "0x%x", &peerCount)
-	if err != nil {
-		eth.logger.Warnf("could not parse peerCount: %v", err)
-		return 0, err
-	}
-	return peerCount, nil
+// SetNonceStore persists the nonces of transactions in flight across restarts
+func (eth *EthereumDetails) SetNonceStore(store NonceStore) {
+	eth.nonces.SetStore(store)
 }
 
 //IsEthereumAccessible checks against endpoint to confirm server responds
@@ -390,87 +405,25 @@ func (eth *EthereumDetails) ChainID() *big.Int {
 	return eth.chainID
 }
 
-//LoadAccounts Scans the directory specified and loads all the accounts found
-func (eth *EthereumDetails) LoadAccounts(directoryPath string) {
-	logger := eth.logger
-
-	logger.Infof("LoadAccounts(\"%v\")...", directoryPath)
-	ks := keystore.NewKeyStore(directoryPath, keystore.StandardScryptN, keystore.StandardScryptP)
-	accts := make(map[common.Address]accounts.Account, 10)
-
-	for _, wallet := range ks.Wallets() {
-		for _, account := range wallet.Accounts() {
-			logger.Infof("... found account %v", account.Address.Hex())
-			accts[account.Address] = account
-		}
-	}
-
-	eth.accounts = accts
-	eth.keystore = ks
-}
-
-// LoadPasscodes loads the specified passcode file
-func (eth *EthereumDetails) LoadPasscodes(filePath string) error {
-	logger := eth.logger
-
-	logger.Infof("LoadPasscodes(\"%v\")...", filePath)
-	passcodes := make(map[common.Address]string)
-
-	file, err := os.Open(filePath)
+// loadAccounts finds the accounts the signer can sign for
+func (eth *EthereumDetails) loadAccounts(ctx context.Context) error {
+	accts, err := eth.signer.Accounts(ctx)
 	if err != nil {
-		logger.Errorf("Failed to open passcode file \"%v\": %s", filePath, err)
 		return err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(line, "#") {
-			components := strings.Split(line, "=")
-			if len(components) == 2 {
-				address := strings.TrimSpace(components[0])
-				passcode := strings.TrimSpace(components[1])
 
-				passcodes[common.HexToAddress(address)] = passcode
-			}
-		}
+	eth.accounts = make(map[common.Address]accounts.Account, len(accts))
+	for _, account := range accts {
+		eth.logger.Infof("... found account %v", account.Address.Hex())
+		eth.accounts[account.Address] = account
 	}
 
-	eth.passcodes = passcodes
-
 	return nil
 }
 
-// UnlockAccount unlocks the previously loaded account using the previously loaded passcode
+// UnlockAccount prepares the signer to sign for the previously loaded account
 func (eth *EthereumDetails) UnlockAccount(acct accounts.Account) error {
-
-	passcode, passcodeFound := eth.passcodes[acct.Address]
-	if !passcodeFound {
-		return ErrPasscodeNotFound
-	}
-
-	err := eth.keystore.Unlock(acct, passcode)
-	if err != nil {
-		return err
-	}
-
-	// Open the account key file
-	keyJSON, err := ioutil.ReadFile(acct.URL.Path)
-	if err != nil {
-		return err
-	}
-
-	// Get the private key
-	key, err := keystore.DecryptKey(keyJSON, passcode)
-	if err != nil {
-		return err
-	}
-
-	eth.keys[acct.Address] = key
-
-	return nil
+	return eth.signer.Unlock(acct)
 }
 
 // GetGethClient returns an amalgamated geth client interface
@@ -488,11 +441,10 @@ func (eth *EthereumDetails) GetAccount(addr common.Address) (accounts.Account, e
 	return acct, nil
 }
 
+// GetAccountKeys returns the keys of an unlocked account, unless the signer
+// holds them elsewhere
 func (eth *EthereumDetails) GetAccountKeys(addr common.Address) (*keystore.Key, error) {
-	if key, ok := eth.keys[addr]; ok {
-		return key, nil
-	}
-	return nil, ErrKeysNotFound
+	return eth.signer.Key(addr)
 }
 
 // SetDefaultAccount designates the account to be used by default
@@ -521,8 +473,12 @@ func (eth *EthereumDetails) GetBalance(addr common.Address) (*big.Int, error) {
 	return balance, nil
 }
 
+// GetEndpoint returns the url of the Ethereum endpoint in use
 func (eth *EthereumDetails) GetEndpoint() string {
-	return eth.endpoint
+	if eth.endpoint == nil {
+		return ""
+	}
+	return eth.endpoint()
 }
 
 func (eth *EthereumDetails) GetTimeoutContext() (context.Context, context.CancelFunc) {
@@ -558,7 +514,7 @@ func (eth *EthereumDetails) GetEvents(ctx context.Context, firstBlock uint64, la
 		ToBlock:   new(big.Int).SetUint64(lastBlock),
 		Addresses: addresses}
 
-	logs, err := eth.client.FilterLogs(ctx, query)
+	logs, err := eth.filterLogs(ctx, query)
 	if err != nil {
 		logger.Errorf("Could not filter logs: %v", err)
 		return nil, err
@@ -586,19 +542,47 @@ func (eth *EthereumDetails) Timeout() time.Duration {
 	return eth.timeout
 }
 
+// GetTransactionOpts returns options for transactions of account signed by
+// the signer
 func (eth *EthereumDetails) GetTransactionOpts(ctx context.Context, account accounts.Account) (*bind.TransactOpts, error) {
-	opts, err := bind.NewKeyStoreTransactorWithChainID(eth.keystore, account, eth.chainID)
-	if err != nil {
+	if _, err := eth.GetAccount(account.Address); err != nil {
 		eth.logger.Errorf("could not create transactor for %v: %v", account.Address.Hex(), err)
-	} else {
-		opts.Context = ctx
-		opts.Nonce = nil
-		opts.Value = big.NewInt(0)
-		opts.GasLimit = uint64(0)
+		return nil, err
+	}
+
+	opts := &bind.TransactOpts{
+		From:     account.Address,
+		Context:  ctx,
+		Value:    big.NewInt(0),
+		GasLimit: uint64(0)}
+
+	var err error
+	opts.GasPrice, err = eth.fees.GasPrice(ctx)
+	if err != nil {
+		eth.logger.Warnf("could not estimate gas price, leaving it to the endpoint: %v", err)
 		opts.GasPrice = nil
 	}
 
-	return opts, err
+	sign := func(from common.Address, txn *types.Transaction) (*types.Transaction, error) {
+		if from != account.Address {
+			return nil, bind.ErrNotAuthorized
+		}
+		return eth.signer.SignTx(opts.Context, account, txn, eth.chainID)
+	}
+
+	// Nonces are assigned when signing so the options can be reused for
+	// several transactions, unless the caller picked one. The contract
+	// bindings send through eth.transactor, which reports on the nonce.
+	opts.Signer = func(from common.Address, txn *types.Transaction) (*types.Transaction, error) {
+		if opts.Nonce != nil {
+			return sign(from, txn)
+		}
+		return eth.nonces.Reserve(opts.Context, from, func(nonce uint64) (*types.Transaction, error) {
+			return sign(from, withNonce(txn, nonce, txn.GasPrice()))
+		})
+	}
+
+	return opts, nil
 }
 
 func (eth *EthereumDetails) GetCallOpts(ctx context.Context, account accounts.Account) *bind.CallOpts { // TODO provide and use context
@@ -612,38 +596,45 @@ func (eth *EthereumDetails) GetCallOpts(ctx context.Context, account accounts.Ac
 // TransferEther transfer's ether from one account to another, assumes from is unlocked
 func (eth *EthereumDetails) TransferEther(from common.Address, to common.Address, wei *big.Int) (*types.Transaction, error) {
 
-	nonce, err := eth.client.PendingNonceAt(context.Background(), from)
-	if err != nil {
-		return nil, err
-	}
+	ctx, cancel := eth.GetTimeoutContext()
+	defer cancel()
 
-	gasPrice, err := eth.client.SuggestGasPrice(context.Background())
+	gasPrice, err := eth.fees.GasPrice(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var data []byte
 	gasLimit := uint64(21000)
-	tx := types.NewTransaction(nonce, to, wei, gasLimit, gasPrice, data)
-
-	eth.logger.Debugf("TransferEther => chainID:%v from:%v nonce:%v, to:%v, wei:%v, gasLimit:%v, gasPrice:%v",
-		eth.chainID, from.Hex(), nonce, to.Hex(), wei, gasLimit, gasPrice)
 
-	signer := types.NewEIP155Signer(eth.chainID)
-
-	signedTx, err := types.SignTx(tx, signer, eth.keys[from].PrivateKey)
+	signedTx, err := eth.nonces.Reserve(ctx, from, func(nonce uint64) (*types.Transaction, error) {
+		return eth.SignTransaction(from, types.NewTransaction(nonce, to, wei, gasLimit, gasPrice, data))
+	})
+	if err == nil {
+		// The signer may have changed the gas price
+		eth.logger.Debugf("TransferEther => chainID:%v from:%v nonce:%v, to:%v, wei:%v, gasLimit:%v, gasPrice:%v",
+			eth.chainID, from.Hex(), signedTx.Nonce(), to.Hex(), wei, gasLimit, signedTx.GasPrice())
+		err = eth.transactor.SendTransaction(ctx, signedTx)
+	}
 	if err != nil {
 		eth.logger.Error(err)
 		return nil, err
 	}
-	ctx, cancel := eth.GetTimeoutContext()
-	defer cancel()
-	err = eth.client.SendTransaction(ctx, signedTx)
+
+	return signedTx, nil
+}
+
+// SignTransaction has the signer sign a transaction of an unlocked account
+func (eth *EthereumDetails) SignTransaction(from common.Address, txn *types.Transaction) (*types.Transaction, error) {
+	acct, err := eth.GetAccount(from)
 	if err != nil {
 		return nil, err
 	}
 
-	return signedTx, nil
+	ctx, cancel := eth.GetTimeoutContext()
+	defer cancel()
+
+	return eth.signer.SignTx(ctx, acct, txn, eth.chainID)
 }
 
 // GetCurrentHeight gets the height of the endpoints chain
@@ -694,8 +685,9 @@ func (eth *EthereumDetails) Clone(defaultAccount accounts.Account) *EthereumDeta
 }
 
 // StringToBytes32 is useful for convert a Go string into a bytes32 useful calling Solidity
+// Strings shorter than 32 bytes are padded with zeros, longer ones are truncated
 func StringToBytes32(str string) (b [32]byte) {
-	copy(b[:], []byte(str)[0:32])
+	copy(b[:], str)
 	return
 }
 
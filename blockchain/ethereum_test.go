@@ -192,3 +192,17 @@ func TestCreateSelector(t *testing.T) {
 
 	assert.Equal(t, "ca5a0fae", fmt.Sprintf("%x", selector))
 }
+
+func TestStringToBytes32(t *testing.T) {
+	// Short strings are padded with zeros
+	b := blockchain.StringToBytes32("STK")
+	assert.Equal(t, []byte("STK"), b[:3])
+	assert.Equal(t, make([]byte, 29), b[3:])
+
+	assert.Equal(t, [32]byte{}, blockchain.StringToBytes32(""))
+
+	// Long strings are truncated
+	long := "0123456789abcdef0123456789abcdefXYZ"
+	b = blockchain.StringToBytes32(long)
+	assert.Equal(t, []byte(long[:32]), b[:])
+}
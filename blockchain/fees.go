@@ -0,0 +1,139 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// feeHistoryBlocks is the number of blocks the priority fee is taken from
+	feeHistoryBlocks = 10
+
+	// defaultPriorityFee is offered when the endpoint has no fee history
+	defaultPriorityFee = params.GWei
+)
+
+// RPCCaller makes a raw JSON-RPC call to an Ethereum endpoint
+type RPCCaller func(ctx context.Context, result interface{}, method string, args ...interface{}) error
+
+// FeeEstimator prices transactions.
+//
+// On chains with EIP-1559 a legacy transaction pays its whole gas price, so
+// the price offered covers twice the base fee of the latest block, which
+// leaves room for the base fee to rise for several blocks, plus the median
+// priority fee paid in recent blocks. Otherwise the price suggested by the
+// endpoint is used. The price never exceeds the cap.
+type FeeEstimator struct {
+	client interfaces.GethClient
+	call   RPCCaller
+	maxFee *big.Int
+}
+
+// NewFeeEstimator returns a fee estimator using client. Without call the
+// estimator can not detect EIP-1559 and a nil or zero maxFee is no cap.
+func NewFeeEstimator(client interfaces.GethClient, call RPCCaller, maxFee *big.Int) *FeeEstimator {
+	if maxFee != nil && maxFee.Sign() <= 0 {
+		maxFee = nil
+	}
+	return &FeeEstimator{client: client, call: call, maxFee: maxFee}
+}
+
+// GasPrice returns the gas price to offer for a new transaction
+func (f *FeeEstimator) GasPrice(ctx context.Context) (*big.Int, error) {
+	price, err := f.dynamicPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if price == nil {
+		price, err = f.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f.capped(price), nil
+}
+
+// Bump returns the gas price to replace a transaction offering price with. It
+// is at least 12.5% more, which is above what nodes require to accept a
+// replacement, and no more than the cap. A price that can't be raised is
+// returned unchanged.
+func (f *FeeEstimator) Bump(ctx context.Context, price *big.Int) (*big.Int, error) {
+	bumped := new(big.Int).Rsh(price, 3)
+	bumped.Add(bumped, price)
+	bumped.Add(bumped, big.NewInt(1))
+
+	current, err := f.GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current.Cmp(bumped) > 0 {
+		bumped = current
+	}
+
+	bumped = f.capped(bumped)
+	if bumped.Cmp(price) < 0 {
+		return new(big.Int).Set(price), nil
+	}
+	return bumped, nil
+}
+
+func (f *FeeEstimator) capped(price *big.Int) *big.Int {
+	if f.maxFee != nil && price.Cmp(f.maxFee) > 0 {
+		return new(big.Int).Set(f.maxFee)
+	}
+	return price
+}
+
+// dynamicPrice returns the price derived from the base fee or nil if the
+// chain doesn't have one
+func (f *FeeEstimator) dynamicPrice(ctx context.Context) (*big.Int, error) {
+	if f.call == nil {
+		return nil, nil
+	}
+
+	var head struct {
+		BaseFee *hexutil.Big `json:"baseFeePerGas"`
+	}
+	if err := f.call(ctx, &head, "eth_getBlockByNumber", "latest", false); err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, nil
+	}
+
+	price := new(big.Int).Lsh(head.BaseFee.ToInt(), 1)
+	return price.Add(price, f.priorityFee(ctx)), nil
+}
+
+// priorityFee returns the median of the median priority fees paid in the
+// last blocks, asking the endpoint for a suggestion if there is no history
+func (f *FeeEstimator) priorityFee(ctx context.Context) *big.Int {
+	var history struct {
+		Reward [][]*hexutil.Big `json:"reward"`
+	}
+	err := f.call(ctx, &history, "eth_feeHistory", hexutil.Uint64(feeHistoryBlocks), "latest", []float64{50})
+	if err == nil {
+		tips := []*big.Int{}
+		for _, reward := range history.Reward {
+			if len(reward) > 0 && reward[0] != nil {
+				tips = append(tips, reward[0].ToInt())
+			}
+		}
+		if len(tips) > 0 {
+			sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+			return tips[len(tips)/2]
+		}
+	}
+
+	var tip hexutil.Big
+	if err := f.call(ctx, &tip, "eth_maxPriorityFeePerGas"); err == nil {
+		return tip.ToInt()
+	}
+
+	return big.NewInt(defaultPriorityFee)
+}
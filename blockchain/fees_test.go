@@ -0,0 +1,83 @@
+package blockchain_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRPC answers raw calls with canned JSON
+func fakeRPC(answers map[string]string) blockchain.RPCCaller {
+	return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+		answer, present := answers[method]
+		if !present {
+			return errors.New("method not found")
+		}
+		return json.Unmarshal([]byte(answer), result)
+	}
+}
+
+func TestFeeEstimator(t *testing.T) {
+	ctx := context.Background()
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{}, 10000000)
+	defer sim.Close()
+
+	gwei := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei))
+	}
+
+	// Before EIP-1559 the endpoint suggests the price
+	fees := blockchain.NewFeeEstimator(sim, nil, nil)
+	price, err := fees.GasPrice(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), price.Int64())
+
+	fees = blockchain.NewFeeEstimator(sim, fakeRPC(map[string]string{
+		"eth_getBlockByNumber": `{"number":"0x10"}`,
+	}), nil)
+	price, err = fees.GasPrice(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), price.Int64())
+
+	// Twice the base fee plus the median priority fee
+	fees = blockchain.NewFeeEstimator(sim, fakeRPC(map[string]string{
+		"eth_getBlockByNumber": `{"number":"0x10","baseFeePerGas":"0x2540be400"}`,
+		"eth_feeHistory":       `{"reward":[["0x3b9aca00"],["0xb2d05e00"],["0x77359400"]]}`,
+	}), nil)
+	price, err = fees.GasPrice(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, gwei(22), price)
+
+	// Without history the endpoint suggests the priority fee
+	fees = blockchain.NewFeeEstimator(sim, fakeRPC(map[string]string{
+		"eth_getBlockByNumber":     `{"number":"0x10","baseFeePerGas":"0x2540be400"}`,
+		"eth_maxPriorityFeePerGas": `"0xb2d05e00"`,
+	}), gwei(21))
+	price, err = fees.GasPrice(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, gwei(21), price, "price over the cap")
+
+	// Replacements pay at least 12.5% more but never more than the cap
+	price, err = fees.Bump(ctx, gwei(16))
+	assert.Nil(t, err)
+	assert.Equal(t, gwei(21), price)
+	price, err = fees.Bump(ctx, gwei(8))
+	assert.Nil(t, err)
+	assert.Equal(t, gwei(21), price)
+
+	fees = blockchain.NewFeeEstimator(sim, nil, gwei(21))
+	price, err = fees.Bump(ctx, gwei(16))
+	assert.Nil(t, err)
+	assert.Equal(t, new(big.Int).Add(gwei(18), big.NewInt(1)), price)
+	price, err = fees.Bump(ctx, gwei(21))
+	assert.Nil(t, err)
+	assert.Equal(t, gwei(21), price, "price at the cap")
+}
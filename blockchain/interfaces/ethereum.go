@@ -29,9 +29,6 @@ type Ethereum interface {
 	GetCallOpts(context.Context, accounts.Account) *bind.CallOpts
 	GetTransactionOpts(context.Context, accounts.Account) (*bind.TransactOpts, error)
 
-	LoadAccounts(string)
-	LoadPasscodes(string) error
-
 	UnlockAccount(accounts.Account) error
 
 	TransferEther(common.Address, common.Address, *big.Int) (*types.Transaction, error)
@@ -162,10 +159,12 @@ type TaskHandler interface {
 // Schedule simple interface to a block based schedule
 type Schedule interface {
 	Schedule(start uint64, end uint64, thing Task) (uuid.UUID, error)
+	Reschedule(taskId uuid.UUID, start uint64) (uuid.UUID, error)
 	Purge()
 	PurgePrior(now uint64)
 	Find(now uint64) (uuid.UUID, error)
 	Retrieve(taskId uuid.UUID) (Task, error)
+	Range(taskId uuid.UUID) (uint64, uint64, error)
 	Length() int
 	Remove(taskId uuid.UUID) error
 	Status(logger *logrus.Entry)
@@ -9,15 +9,25 @@ import (
 	"github.com/MadBase/MadNet/logging"
 	"github.com/MadBase/MadNet/utils"
 	"github.com/dgraph-io/badger/v2"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
 )
 
 var stateKey = []byte("monitorStateKey")
 
+var nonceKey = []byte("monitorNonceKey")
+
+var taskRunKey = []byte("monitorTaskRunKey")
+
 // Database describes required functionality for monitor persistence
 type Database interface {
 	FindState() (*objects.MonitorState, error)
 	UpdateState(state *objects.MonitorState) error
+	FindNonces(account common.Address) (*objects.AccountNonces, error)
+	UpdateNonces(account common.Address, nonces *objects.AccountNonces) error
+	FindTaskRuns() ([]*objects.TaskRun, error)
+	UpdateTaskRun(run *objects.TaskRun) error
+	DeleteTaskRun(key string) error
 }
 
 type monitorDB struct {
@@ -81,6 +91,11 @@ func (mon *monitorDB) FindState() (*objects.MonitorState, error) {
 		return nil, err
 	}
 
+	// Each scheduled task was decoded with its own copy of the round
+	if schedule, ok := state.Schedule.(*SequentialSchedule); ok && state.EthDKG != nil {
+		schedule.ShareDkgState(state.EthDKG)
+	}
+
 	return state, nil
 }
 
@@ -100,3 +115,113 @@ func (mon *monitorDB) UpdateState(state *objects.MonitorState) error {
 
 	return mon.database.Update(fn)
 }
+
+// FindNonces loads the nonces of account, which are empty if none were stored
+func (mon *monitorDB) FindNonces(account common.Address) (*objects.AccountNonces, error) {
+
+	nonces := &objects.AccountNonces{InFlight: make(map[uint64]common.Hash)}
+
+	fn := func(txn *badger.Txn) error {
+		data, err := utils.GetValue(txn, append(utils.CopySlice(nonceKey), account.Bytes()...))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		buf := bytes.NewBuffer(data)
+		dec := gob.NewDecoder(buf)
+		return dec.Decode(nonces)
+	}
+
+	err := mon.database.View(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonces.InFlight == nil {
+		nonces.InFlight = make(map[uint64]common.Hash)
+	}
+
+	return nonces, nil
+}
+
+func (mon *monitorDB) UpdateNonces(account common.Address, nonces *objects.AccountNonces) error {
+
+	buf := &bytes.Buffer{}
+
+	enc := gob.NewEncoder(buf)
+	err := enc.Encode(nonces)
+	if err != nil {
+		return err
+	}
+
+	fn := func(txn *badger.Txn) error {
+		return utils.SetValue(txn, append(utils.CopySlice(nonceKey), account.Bytes()...), buf.Bytes())
+	}
+
+	return mon.database.Update(fn)
+}
+
+// FindTaskRuns loads the history of all task runs
+func (mon *monitorDB) FindTaskRuns() ([]*objects.TaskRun, error) {
+
+	runs := []*objects.TaskRun{}
+
+	fn := func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = taskRunKey
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+
+		for iter.Rewind(); iter.Valid(); iter.Next() {
+			data, err := iter.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			run := &objects.TaskRun{}
+			buf := bytes.NewBuffer(data)
+			dec := gob.NewDecoder(buf)
+			if err := dec.Decode(run); err != nil {
+				return err
+			}
+			runs = append(runs, run)
+		}
+		return nil
+	}
+
+	err := mon.database.View(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (mon *monitorDB) UpdateTaskRun(run *objects.TaskRun) error {
+
+	buf := &bytes.Buffer{}
+
+	enc := gob.NewEncoder(buf)
+	err := enc.Encode(run)
+	if err != nil {
+		return err
+	}
+
+	fn := func(txn *badger.Txn) error {
+		return utils.SetValue(txn, append(utils.CopySlice(taskRunKey), []byte(run.Key)...), buf.Bytes())
+	}
+
+	return mon.database.Update(fn)
+}
+
+func (mon *monitorDB) DeleteTaskRun(key string) error {
+
+	fn := func(txn *badger.Txn) error {
+		return utils.DeleteValue(txn, append(utils.CopySlice(taskRunKey), []byte(key)...))
+	}
+
+	return mon.database.Update(fn)
+}
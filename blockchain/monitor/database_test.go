@@ -1,7 +1,74 @@
 package monitor_test
 
-import "testing"
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/MadBase/MadNet/blockchain/dkg/dkgtasks"
+	"github.com/MadBase/MadNet/blockchain/monitor"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestDBFind(t *testing.T) {
 
 }
+
+func TestDBStateRestart(t *testing.T) {
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	acct := accounts.Account{Address: common.HexToAddress("0x546F99F244b7B58B855330AE0E2BC1b30b41302F")}
+	dkgState := objects.NewDkgState(acct)
+	dkgState.Index = 3
+	dkgState.RegistrationStart = 10
+	dkgState.GroupPublicKey = [4]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4)}
+	dkgState.SecretValue = big.NewInt(42)
+	dkgState.TransportPrivateKey = big.NewInt(43)
+
+	schedule := monitor.NewSequentialSchedule()
+	distribute, err := schedule.Schedule(21, 30, dkgtasks.NewShareDistributionTask(dkgState))
+	assert.Nil(t, err)
+
+	state := &objects.MonitorState{
+		HighestBlockProcessed: 25,
+		EthDKG:                dkgState,
+		Schedule:              schedule,
+	}
+
+	database := monitor.NewDatabase(ctx, "", true)
+	assert.Nil(t, database.UpdateState(state))
+
+	// What is public about the round survives a restart, its secrets don't
+	restarted, err := database.FindState()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(25), restarted.HighestBlockProcessed)
+	assert.Equal(t, 3, restarted.EthDKG.Index)
+	assert.Equal(t, int64(4), restarted.EthDKG.GroupPublicKey[3].Int64())
+	assert.Nil(t, restarted.EthDKG.SecretValue)
+	assert.Nil(t, restarted.EthDKG.TransportPrivateKey)
+	assert.Equal(t, 1, restarted.Schedule.Length())
+
+	// So the unfinished round is dropped with its tasks
+	assert.True(t, monitor.DropUnfinishedDkg(restarted))
+	assert.Equal(t, acct.Address, restarted.EthDKG.Account.Address)
+	assert.Equal(t, 0, restarted.EthDKG.Index)
+	assert.Equal(t, 0, restarted.Schedule.Length())
+	assert.False(t, monitor.DropUnfinishedDkg(restarted))
+
+	// A completed round is kept
+	dkgState.Complete = true
+	assert.Nil(t, database.UpdateState(state))
+	restarted, err = database.FindState()
+	assert.Nil(t, err)
+	assert.False(t, monitor.DropUnfinishedDkg(restarted))
+	assert.Equal(t, 3, restarted.EthDKG.Index)
+
+	// Its scheduled tasks share the state of the round again
+	task, err := restarted.Schedule.Retrieve(distribute)
+	assert.Nil(t, err)
+	assert.Same(t, restarted.EthDKG, task.(*dkgtasks.ShareDistributionTask).State)
+}
@@ -0,0 +1,227 @@
+package monitor_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	aobjs "github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/blockchain/monitor"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	minterfaces "github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func openInMemory(t *testing.T) *badger.DB {
+	rawDB, err := badger.Open(badger.DefaultOptions("").WithInMemory(true))
+	assert.Nil(t, err)
+	t.Cleanup(func() { rawDB.Close() })
+	return rawDB
+}
+
+// newApplication returns an application backed by in-memory databases
+func newApplication(t *testing.T) (*db.Database, *deposit.Handler, *application.Application) {
+	conDB := &db.Database{}
+	assert.Nil(t, conDB.Init(openInMemory(t)))
+	dph := &deposit.Handler{}
+	assert.Nil(t, dph.Init())
+	app := &application.Application{}
+	assert.Nil(t, app.Init(conDB, openInMemory(t), dph))
+	return conDB, dph, app
+}
+
+// processEvents feeds every log emitted by the deposit contract through the
+// event map
+func processEvents(t *testing.T, eth interfaces.Ethereum, em *objects.EventMap, state *objects.MonitorState) int {
+	ctx := context.TODO()
+	height, err := eth.GetCurrentHeight(ctx)
+	assert.Nil(t, err)
+
+	logs, err := eth.GetEvents(ctx, 1, height, []common.Address{eth.Contracts().DepositAddress()})
+	assert.Nil(t, err)
+
+	logger := logging.GetLogger("monitor").WithField("Test", t.Name())
+	processed := 0
+	for _, log := range logs {
+		info, present := em.Lookup(log.Topics[0].String())
+		if !present || info.Processor == nil {
+			continue
+		}
+		assert.Nil(t, info.Processor(eth, logger.WithField("Event", info.Name), state, log))
+		processed++
+	}
+	return processed
+}
+
+func TestSetupEventMap(t *testing.T) {
+	em := objects.NewEventMap()
+	assert.Nil(t, monitor.SetupEventMap(em, nil, nil, nil))
+
+	for name, id := range map[string]string{
+		"DepositReceived": "0x5b063c6569a91e8133fc6cd71d31a4ca5c65c652fd53ae093f46107754f08541",
+		"ValidatorMember": "0x113b129fac2dde341b9fbbec2bb79a95b9945b0e80fda711fc8ae5c7b0ea83b0",
+		"ValidatorSet":    "0x1c85ff1efe0a905f8feca811e617102cb7ec896aded693eb96366c8ef22bb09f",
+		"SnapshotTaken":   "0x6d438b6b835d16cdae6efdc0259fdfba17e6aa32dae81863a2467866f85f724a",
+	} {
+		info, present := em.Lookup(id)
+		if assert.Truef(t, present, "%v not registered", name) {
+			assert.Equal(t, name, info.Name)
+			assert.NotNilf(t, info.Processor, "%v has no processor", name)
+		}
+	}
+}
+
+func TestDepositReceived(t *testing.T) {
+	eth := setupEthereum(t)
+	c := eth.Contracts()
+	ctx := context.TODO()
+	acct := eth.GetDefaultAccount()
+
+	conDB, dph, app := newApplication(t)
+
+	em := objects.NewEventMap()
+	assert.Nil(t, monitor.SetupEventMap(em, conDB, nil, dph))
+
+	// Deposit utility tokens
+	txnOpts, err := eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+
+	txn, err := c.UtilityToken().Approve(txnOpts, c.DepositAddress(), big.NewInt(10000))
+	assert.Nil(t, err)
+	eth.Commit()
+	rcpt, err := eth.Queue().QueueAndWait(ctx, txn)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), rcpt.Status)
+
+	txn, err = c.Deposit().Deposit(txnOpts, big.NewInt(1000))
+	assert.Nil(t, err)
+	eth.Commit()
+	rcpt, err = eth.Queue().QueueAndWait(ctx, txn)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), rcpt.Status)
+
+	state := &objects.MonitorState{}
+	assert.Equal(t, 1, processEvents(t, eth, em, state))
+
+	// The deposit is a ValueStore owned by the depositor
+	chainID := uint32(eth.ChainID().Uint64())
+	account := acct.Address.Bytes()
+
+	var depositVS *aobjs.ValueStore
+	err = conDB.View(func(txn *badger.Txn) error {
+		utxoIDs, value, err := app.GetValueForOwner(txn, constants.CurveSecp256k1, account, uint256.One())
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(utxoIDs)) {
+			return err
+		}
+		v, err := value.ToUint32()
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(1000), v)
+
+		found, missing, spent, err := dph.Get(txn, utxoIDs)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(missing))
+		assert.Equal(t, 0, len(spent))
+		if !assert.Equal(t, 1, len(found)) {
+			return nil
+		}
+		depositVS, err = found[0].ValueStore()
+		return err
+	})
+	assert.Nil(t, err)
+	if !assert.NotNil(t, depositVS) {
+		return
+	}
+	assert.True(t, depositVS.IsDeposit())
+
+	// Spend the deposit through the application
+	keys, err := eth.GetAccountKeys(acct.Address)
+	assert.Nil(t, err)
+	signer := &crypto.Secp256k1Signer{}
+	assert.Nil(t, signer.SetPrivk(ethcrypto.FromECDSA(keys.PrivateKey)))
+
+	tx := spendValueStore(t, signer, chainID, depositVS)
+	err = conDB.Update(func(txn *badger.Txn) error {
+		if err := app.PendingTxAdd(txn, chainID, 1, []minterfaces.Transaction{tx}); err != nil {
+			return err
+		}
+		txs, stateHash, err := app.GetValidProposal(txn, chainID, 1, constants.MaxBytes)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 1, len(txs))
+		valid, err := app.IsValid(txn, chainID, 1, stateHash, txs)
+		if err != nil {
+			return err
+		}
+		assert.True(t, valid)
+		_, err = app.ApplyState(txn, chainID, 1, txs)
+		return err
+	})
+	assert.Nil(t, err)
+
+	// The deposit is spent and the new ValueStore is in the UTXO set
+	utxoIDs, err := tx.GeneratedUTXOID()
+	assert.Nil(t, err)
+	depositID, err := depositVS.UTXOID()
+	assert.Nil(t, err)
+	err = conDB.View(func(txn *badger.Txn) error {
+		ok, err := app.UTXOContains(txn, utxoIDs[0])
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		_, _, spent, err := dph.Get(txn, [][]byte{depositID})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(spent))
+		return nil
+	})
+	assert.Nil(t, err)
+
+	// The spent deposit can not be added again
+	owner := &aobjs.Owner{}
+	assert.Nil(t, owner.New(account, constants.CurveSecp256k1))
+	assert.NotNil(t, conDB.Update(func(txn *badger.Txn) error {
+		return dph.Add(txn, chainID, depositVS.TxHash, big.NewInt(1000), owner)
+	}))
+}
+
+// spendValueStore returns a transaction that moves the value of vs to a new
+// ValueStore of the same owner
+func spendValueStore(t *testing.T, s aobjs.Signer, chainID uint32, vs *aobjs.ValueStore) *aobjs.Tx {
+	txIn, err := vs.MakeTxIn()
+	assert.Nil(t, err)
+	value, err := vs.Value()
+	assert.Nil(t, err)
+	pubkey, err := s.Pubkey()
+	assert.Nil(t, err)
+
+	newValueStore := &aobjs.ValueStore{
+		VSPreImage: &aobjs.VSPreImage{
+			ChainID:  chainID,
+			Value:    value,
+			Owner:    &aobjs.ValueStoreOwner{SVA: aobjs.ValueStoreSVA, CurveSpec: constants.CurveSecp256k1, Account: crypto.GetAccount(pubkey)},
+			TXOutIdx: 0,
+		},
+		TxHash: make([]byte, constants.HashLen),
+	}
+	newUTXO := &aobjs.TXOut{}
+	assert.Nil(t, newUTXO.NewValueStore(newValueStore))
+
+	tx := &aobjs.Tx{
+		Vin:  []*aobjs.TXIn{txIn},
+		Vout: []*aobjs.TXOut{newUTXO},
+	}
+	assert.Nil(t, tx.SetTxHash())
+	assert.Nil(t, vs.Sign(tx.Vin[0], s))
+	return tx
+}
@@ -3,10 +3,13 @@ package monitor
 import (
 	"context"
 	"math/big"
-	"strings"
 
+	"github.com/MadBase/MadNet/application/deposit"
 	aobjs "github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/blockchain/interfaces"
 	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/consensus/admin"
+	"github.com/MadBase/MadNet/consensus/db"
 	"github.com/MadBase/MadNet/consensus/objs"
 	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/crypto/bn256"
@@ -15,172 +18,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// ProcessRegistrationOpen when we see ETHDKG has initialized we need to start
-func (svcs *Services) ProcessRegistrationOpen(state *objects.MonitorState, log types.Log) error {
-
-	eth := svcs.eth
-	c := eth.Contracts()
-	logger := svcs.logger
-
-	event, err := c.Ethdkg().ParseRegistrationOpen(log)
-	if err != nil {
-		return err
-	}
-
-	// if ETHDKGInProgress(state.EthDKG, log.BlockNumber) {
-	// 	logger.Warnf("Received RegistrationOpen event while ETHDKG in progress. Aborting old round.")
-	// 	AbortETHDKG(state.EthDKG)
-	// }
-
-	// if event.RegistrationEnds.Uint64() > state.HighestBlockFinalized {
-
-	// 	private, public, err := dkg.GenerateKeys()
-	// 	if err != nil {
-	// 		return err
-	// 	}
-
-	logger.Infof(strings.Repeat("-", 80))
-	logger.Infof("Registration Open from block %v to %v", event.DkgStarts, event.RegistrationEnds)
-	logger.Infof(strings.Repeat("-", 80))
-
-	// 	// Record the schedule
-	// 	schedule := &EthDKGSchedule{}
-	// 	schedule.RegistrationStart = log.BlockNumber
-	// 	schedule.RegistrationEnd = event.RegistrationEnds.Uint64()
-
-	// 	schedule.ShareDistributionStart = schedule.RegistrationEnd + 1
-	// 	schedule.ShareDistributionEnd = event.ShareDistributionEnds.Uint64()
-
-	// 	schedule.DisputeStart = schedule.ShareDistributionEnd + 1
-	// 	schedule.DisputeEnd = event.DisputeEnds.Uint64()
-
-	// 	schedule.KeyShareSubmissionStart = schedule.DisputeEnd + 1
-	// 	schedule.KeyShareSubmissionEnd = event.KeyShareSubmissionEnds.Uint64()
-
-	// 	schedule.MPKSubmissionStart = schedule.KeyShareSubmissionEnd + 1
-	// 	schedule.MPKSubmissionEnd = event.MpkSubmissionEnds.Uint64()
-
-	// 	schedule.GPKJSubmissionStart = schedule.MPKSubmissionEnd + 1
-	// 	schedule.GPKJSubmissionEnd = event.GpkjSubmissionEnds.Uint64()
-
-	// 	schedule.GPKJGroupAccusationStart = schedule.GPKJSubmissionEnd + 1
-	// 	schedule.GPKJGroupAccusationEnd = event.GpkjDisputeEnds.Uint64()
-
-	// 	schedule.CompleteStart = schedule.GPKJGroupAccusationEnd + 1
-	// 	schedule.CompleteEnd = event.DkgComplete.Uint64()
-
-	// 	// TODO associate names with these also to help with debugging/logging
-	// 	ib := make(map[uint64]func(*State, uint64) error)
-	// 	ib[schedule.ShareDistributionStart] = svcs.DoDistributeShares      // Do ShareDistribution
-	// 	ib[schedule.DisputeStart] = svcs.DoSubmitDispute                   // Do Disputes
-	// 	ib[schedule.KeyShareSubmissionStart] = svcs.DoSubmitKeyShare       // Do KeyShareSubmission
-	// 	ib[schedule.MPKSubmissionStart] = svcs.DoSubmitMasterPublicKey     // Do MPKSubmission
-	// 	ib[schedule.GPKJSubmissionStart] = svcs.DoSubmitGPKj               // Do GPKJSubmission
-	// 	ib[schedule.GPKJGroupAccusationStart] = svcs.DoGroupAccusationGPKj // Do GPKJDisputes
-	// 	ib[schedule.CompleteStart] = svcs.DoSuccessfulCompletion           // Do SuccessfulCompletion
-
-	// 	logger.Infof("Adding block processors for %v", ib)
-	// 	state.interestingBlocks = ib
-
-	// 	acct := eth.GetDefaultAccount()
-
-	// 	state.EthDKG = NewEthDKGState()
-	// 	state.EthDKG.Address = acct.Address
-	// 	state.EthDKG.Schedule = schedule
-	// 	state.EthDKG.TransportPrivateKey = private
-	// 	state.EthDKG.TransportPublicKey = public
-
-	// 	taskLogger := logging.GetLogger("rt")
-	// 	taskLogger.Infof("Stubbed out")
-
-	// 	// TODO build a proper RegisterTask with the correct EthDKGState struct
-	// 	// task := dkgtasks.NewRegisterTask(
-	// 	// 	eth.GetDefaultAccount(),
-	// 	// 	state.EthDKG.TransportPublicKey,
-	// 	// 	state.EthDKG.Schedule.RegistrationEnd)
-
-	// 	// task := dkgtasks.NewRegisterTask(state.EthDKG)
-
-	// 	// state.EthDKG.RegistrationTH = svcs.taskMan.NewTaskHandler(taskLogger, eth, task)
-
-	// 	// state.EthDKG.RegistrationTH.Start()
-
-	// } else {
-	// 	logger.Infof("Not participating in DKG... registration ends at height %v but height %v is finalized.",
-	// 		event.RegistrationEnds, state.HighestBlockFinalized)
-	// 	// state.ethdkg = EthDKGState{} // TODO I need to cancel any TaskHandlers too
-	// }
-
-	return nil
-}
-
-// ProcessShareDistribution accumulates everyones shares ETHDKG
-func (svcs *Services) ProcessShareDistribution(state *objects.MonitorState, log types.Log) error {
-	logger := svcs.logger
-
-	logger.Info(strings.Repeat("-", 60))
-	logger.Info("ProcessShareDistribution()")
-	logger.Info(strings.Repeat("-", 60))
-
-	// if !ETHDKGInProgress(state.EthDKG, log.BlockNumber) {
-	// 	logger.Warn("Ignoring share distribution since we are not participating this round...")
-	// 	return ErrCanNotContinue
-	// }
-
-	// eth := svcs.eth
-	// c := eth.Contracts()
-	// ethdkg := state.EthDKG
-
-	// event, err := c.Ethdkg.ParseShareDistribution(log)
-	// if err != nil {
-	// 	return err
-	// }
-
-	// ethdkg.Commitments[event.Issuer] = event.Commitments
-	// ethdkg.EncryptedShares[event.Issuer] = event.EncryptedShares
-
-	return nil
-}
-
-// ProcessKeyShareSubmission ETHDKG
-func (svcs *Services) ProcessKeyShareSubmission(state *objects.MonitorState, log types.Log) error {
-
-	logger := svcs.logger
-
-	logger.Info(strings.Repeat("-", 60))
-	logger.Info("ProcessKeyShareSubmission()")
-	logger.Info(strings.Repeat("-", 60))
-
-	// if !ETHDKGInProgress(state.EthDKG, log.BlockNumber) {
-	// 	logger.Warn("Ignoring key share submission since we are not participating this round...")
-	// 	return ErrCanNotContinue
-	// }
-
-	// eth := svcs.eth
-	// c := eth.Contracts()
-	// event, err := c.Ethdkg.ParseKeyShareSubmission(log)
-	// if err != nil {
-	// 	return err
-	// }
-
-	// addr := event.Issuer
-	// keyshareG1 := event.KeyShareG1
-	// keyshareG1Proof := event.KeyShareG1CorrectnessProof
-	// keyshareG2 := event.KeyShareG2
-
-	// logger.Infof("keyshareG1:%v keyshareG2:%v", dkgtasks.FormatBigIntSlice(keyshareG1[:]), dkgtasks.FormatBigIntSlice(keyshareG2[:]))
-
-	// state.EthDKG.KeyShareG1s[addr] = keyshareG1
-	// state.EthDKG.KeyShareG1CorrectnessProofs[addr] = keyshareG1Proof
-	// state.EthDKG.KeyShareG2s[addr] = keyshareG2
-
-	return nil
-}
-
 // ProcessValidatorSet handles receiving validatorSet changes
-func (svcs *Services) ProcessValidatorSet(state *objects.MonitorState, log types.Log) error {
+func ProcessValidatorSet(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log,
+	adminHandler *admin.Handlers) error {
 
-	eth := svcs.eth
 	c := eth.Contracts()
 
 	updatedState := state
@@ -192,6 +33,10 @@ func (svcs *Services) ProcessValidatorSet(state *objects.MonitorState, log types
 
 	epoch := uint32(event.Epoch.Int64())
 
+	if state.ValidatorSets == nil {
+		state.ValidatorSets = make(map[uint32]objects.ValidatorSet)
+	}
+
 	vs := state.ValidatorSets[epoch]
 	vs.NotBeforeMadNetHeight = event.MadHeight
 	vs.ValidatorCount = event.ValidatorCount
@@ -202,7 +47,7 @@ func (svcs *Services) ProcessValidatorSet(state *objects.MonitorState, log types
 
 	updatedState.ValidatorSets[epoch] = vs
 
-	err = svcs.checkValidatorSet(updatedState, epoch)
+	err = checkValidatorSet(logger, updatedState, epoch, adminHandler)
 	if err != nil {
 		return err
 	}
@@ -211,9 +56,9 @@ func (svcs *Services) ProcessValidatorSet(state *objects.MonitorState, log types
 }
 
 // ProcessValidatorMember handles receiving keys for a specific validator
-func (svcs *Services) ProcessValidatorMember(state *objects.MonitorState, log types.Log) error {
+func ProcessValidatorMember(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log,
+	adminHandler *admin.Handlers) error {
 
-	eth := svcs.eth
 	c := eth.Contracts()
 
 	event, err := c.Ethdkg().ParseValidatorMember(log)
@@ -230,7 +75,10 @@ func (svcs *Services) ProcessValidatorMember(state *objects.MonitorState, log ty
 		Index:     index,
 		SharedKey: [4]big.Int{*event.Share0, *event.Share1, *event.Share2, *event.Share3},
 	}
-	if len(state.Validators) < int(index+1) {
+	if state.Validators == nil {
+		state.Validators = make(map[uint32][]objects.Validator)
+	}
+	if len(state.Validators[epoch]) < int(index+1) {
 		newValList := make([]objects.Validator, int(index+1))
 		copy(newValList, state.Validators[epoch])
 		state.Validators[epoch] = newValList
@@ -241,11 +89,11 @@ func (svcs *Services) ProcessValidatorMember(state *objects.MonitorState, log ty
 		&v.SharedKey[2], &v.SharedKey[3]}
 	groupShare, err := bn256.MarshalG2Big(ptrGroupShare)
 	if err != nil {
-		svcs.logger.Errorf("Failed to marshal groupShare: %v", err)
+		logger.Errorf("Failed to marshal groupShare: %v", err)
 		return err
 	}
-	svcs.logger.Debugf("Validator member %v %x", v.Index, groupShare)
-	err = svcs.checkValidatorSet(state, epoch)
+	logger.Debugf("Validator member %v %x", v.Index, groupShare)
+	err = checkValidatorSet(logger, state, epoch, adminHandler)
 	if err != nil {
 		return err
 	}
@@ -253,8 +101,7 @@ func (svcs *Services) ProcessValidatorMember(state *objects.MonitorState, log ty
 	return nil
 }
 
-func (svcs *Services) checkValidatorSet(state *objects.MonitorState, epoch uint32) error {
-	logger := svcs.logger
+func checkValidatorSet(logger *logrus.Entry, state *objects.MonitorState, epoch uint32, adminHandler *admin.Handlers) error {
 
 	// Make sure we've received a validator set event
 	validatorSet, present := state.ValidatorSets[epoch]
@@ -308,7 +155,7 @@ func (svcs *Services) checkValidatorSet(state *objects.MonitorState, epoch uint3
 			logger.Infof("ValidatorMember[%v]: {GroupShare: 0x%x, VAddr: %x}", validator.Index, groupShare, v.VAddr)
 		}
 		logger.Infof("ValidatorSet: {GroupKey: 0x%x, NotBefore: %v, Validators: %v }", vs.GroupKey, vs.NotBefore, vs.Validators)
-		err = svcs.ah.AddValidatorSet(vs)
+		err = adminHandler.AddValidatorSet(vs)
 		if err != nil {
 			logger.Errorf("Unable to add validator set: %v", err) // TODO handle -- MUST retry or consensus shuts down
 		}
@@ -317,25 +164,24 @@ func (svcs *Services) checkValidatorSet(state *objects.MonitorState, epoch uint3
 }
 
 // ProcessDepositReceived handles logic around receiving a deposit event
-func (svcs *Services) ProcessDepositReceived(state *objects.MonitorState, log types.Log) error {
+func ProcessDepositReceived(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log,
+	cdb *db.Database, depositHandler *deposit.Handler) error {
 
-	eth := svcs.eth
 	c := eth.Contracts()
-	logger := svcs.logger
 
 	event, err := c.Deposit().ParseDepositReceived(log)
 	if err != nil {
 		return err
 	}
 
-	bigChainID := svcs.eth.ChainID()
+	bigChainID := eth.ChainID()
 	//TODO check to make sure chainID fits into a uint32
 	chainID := uint32(bigChainID.Uint64())
 
 	logger.Infof("deposit depositID:%x ethereum:0x%x amount:%d",
 		event.DepositID, event.Depositor, event.Amount)
 
-	return svcs.consensusDb.Update(func(txn *badger.Txn) error {
+	return cdb.Update(func(txn *badger.Txn) error {
 		depositNonce := event.DepositID.Bytes()
 		account := event.Depositor.Bytes()
 		owner := &aobjs.Owner{}
@@ -344,16 +190,15 @@ func (svcs *Services) ProcessDepositReceived(state *objects.MonitorState, log ty
 			logger.Debugf("Error in Services.ProcessDepositReceived at owner.New: %v", err)
 			return err
 		}
-		return svcs.dph.Add(txn, chainID, depositNonce, event.Amount, owner)
+		return depositHandler.Add(txn, chainID, depositNonce, event.Amount, owner)
 	})
 }
 
 // ProcessSnapshotTaken handles receiving snapshots
-func (svcs *Services) ProcessSnapshotTaken(state *objects.MonitorState, log types.Log) error {
+func ProcessSnapshotTaken(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log,
+	adminHandler *admin.Handlers) error {
 
-	eth := svcs.eth
 	c := eth.Contracts()
-	logger := svcs.logger
 
 	event, err := c.Validators().ParseSnapshotTaken(log)
 	if err != nil {
@@ -392,7 +237,7 @@ func (svcs *Services) ProcessSnapshotTaken(state *objects.MonitorState, log type
 	header.SigGroup = rawSignature
 
 	// send the reconstituted header to a handler
-	err = svcs.ah.AddSnapshot(header, ethDkgStarted) // TODO must happen or things stuff
+	err = adminHandler.AddSnapshot(header, ethDkgStarted) // TODO must happen or things stuff
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"errors"
+
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/pborman/uuid"
+)
+
+// Manual intervention errors
+var (
+	ErrRewindForward = errors.New("can only rewind to a block before the highest processed block")
+	ErrNoSchedule    = errors.New("monitor state has no schedule")
+	ErrNoDkgRound    = errors.New("monitor state has no ETHDKG round")
+)
+
+// The functions below change persisted monitor state while the node is
+// stopped, so operators don't have to delete the monitor database
+
+// Rewind makes the monitor process the events after block again. The
+// effects of events already processed are not undone. It also resumes a
+// monitor stopped by a reorg it couldn't roll back, so what the reorg left
+// behind must be repaired first.
+func Rewind(state *objects.MonitorState, block uint64) error {
+	if block >= state.HighestBlockProcessed {
+		return ErrRewindForward
+	}
+
+	state.HighestBlockProcessed = block
+	state.InSync = false
+	state.RepairNeeded = ""
+
+	// Blocks past the rewind are recorded again when they're processed
+	for height := range state.ProcessedBlocks {
+		if height > block {
+			delete(state.ProcessedBlocks, height)
+		}
+	}
+
+	return nil
+}
+
+// CancelTask removes a scheduled task
+func CancelTask(state *objects.MonitorState, taskId uuid.UUID) error {
+	if state.Schedule == nil {
+		return ErrNoSchedule
+	}
+	return state.Schedule.Remove(taskId)
+}
+
+// RerunTask schedules a task again under a new id for what is left of its
+// range after the highest processed block
+func RerunTask(state *objects.MonitorState, taskId uuid.UUID) (uuid.UUID, error) {
+	if state.Schedule == nil {
+		return nil, ErrNoSchedule
+	}
+	return state.Schedule.Reschedule(taskId, state.HighestBlockProcessed+1)
+}
+
+// ClearDkg abandons the current round of ETHDKG and its scheduled tasks, so
+// the next round starts over
+func ClearDkg(state *objects.MonitorState) error {
+	if state.EthDKG == nil {
+		return ErrNoDkgRound
+	}
+
+	state.EthDKG = objects.NewDkgState(state.EthDKG.Account)
+	if state.Schedule != nil {
+		state.Schedule.Purge()
+	}
+
+	return nil
+}
+
+// DropUnfinishedDkg clears a round of ETHDKG that was in progress when state
+// was persisted, since its secrets weren't and the round can't be finished
+func DropUnfinishedDkg(state *objects.MonitorState) bool {
+	if state.EthDKG == nil || !state.EthDKG.InProgress() {
+		return false
+	}
+
+	return ClearDkg(state) == nil
+}
@@ -0,0 +1,89 @@
+package monitor_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/MadBase/MadNet/blockchain/dkg/dkgtasks"
+	"github.com/MadBase/MadNet/blockchain/monitor"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntervention(t *testing.T) {
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	acct := accounts.Account{Address: common.HexToAddress("0x546F99F244b7B58B855330AE0E2BC1b30b41302F")}
+	dkgState := objects.NewDkgState(acct)
+	dkgState.Index = 3
+	dkgState.SecretValue = big.NewInt(42)
+
+	schedule := monitor.NewSequentialSchedule()
+	register, err := schedule.Schedule(10, 20, dkgtasks.NewRegisterTask(dkgState))
+	assert.Nil(t, err)
+	distribute, err := schedule.Schedule(21, 30, dkgtasks.NewShareDistributionTask(dkgState))
+	assert.Nil(t, err)
+
+	state := &objects.MonitorState{
+		HighestBlockProcessed: 25,
+		InSync:                true,
+		EthDKG:                dkgState,
+		Schedule:              schedule,
+		ProcessedBlocks: map[uint64]*objects.ProcessedBlock{
+			15: {Hash: common.HexToHash("0x15")},
+			25: {Hash: common.HexToHash("0x25")},
+		},
+	}
+
+	// The schedule and round survive being persisted
+	database := monitor.NewDatabase(ctx, "", true)
+	assert.Nil(t, database.UpdateState(state))
+	state, err = database.FindState()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, state.EthDKG.Index)
+	assert.Nil(t, state.EthDKG.SecretValue)
+	assert.Equal(t, 2, state.Schedule.Length())
+	task, err := state.Schedule.Retrieve(register)
+	assert.Nil(t, err)
+	assert.IsType(t, &dkgtasks.RegisterTask{}, task)
+
+	// Tasks can only run again while their range lasts
+	_, err = monitor.RerunTask(state, register)
+	assert.Equal(t, monitor.ErrScheduleExpired, err)
+	rerun, err := monitor.RerunTask(state, distribute)
+	assert.Nil(t, err)
+	_, err = state.Schedule.Retrieve(distribute)
+	assert.Equal(t, monitor.ErrNotScheduled, err)
+	id, err := state.Schedule.Find(26)
+	assert.Nil(t, err)
+	assert.Equal(t, rerun, id)
+	_, err = state.Schedule.Find(25)
+	assert.Equal(t, monitor.ErrNothingScheduled, err)
+
+	assert.Nil(t, monitor.CancelTask(state, register))
+	assert.Equal(t, monitor.ErrNotScheduled, monitor.CancelTask(state, register))
+	assert.Equal(t, 1, state.Schedule.Length())
+
+	// Rewinding forgets the blocks that are processed again and resumes a
+	// monitor waiting for repair
+	state.RepairNeeded = "reorg"
+	assert.Equal(t, monitor.ErrRewindForward, monitor.Rewind(state, 25))
+	assert.Nil(t, monitor.Rewind(state, 20))
+	assert.Equal(t, uint64(20), state.HighestBlockProcessed)
+	assert.False(t, state.InSync)
+	assert.Equal(t, "", state.RepairNeeded)
+	assert.Equal(t, 1, len(state.ProcessedBlocks))
+	assert.NotNil(t, state.ProcessedBlocks[15])
+
+	assert.Nil(t, monitor.ClearDkg(state))
+	assert.Equal(t, 0, state.Schedule.Length())
+	assert.Equal(t, acct.Address, state.EthDKG.Account.Address)
+	assert.Equal(t, 0, state.EthDKG.Index)
+
+	state.EthDKG = nil
+	assert.Equal(t, monitor.ErrNoDkgRound, monitor.ClearDkg(state))
+}
@@ -80,6 +80,9 @@ func (mon *monitor) StartEventLoop() (chan<- bool, error) {
 		}
 		logger.Info("Setting initial state to defaults...")
 	}
+	if DropUnfinishedDkg(initialState) {
+		logger.Warn("Dropped the unfinished round of ETHDKG, its secrets aren't persisted")
+	}
 	initialState.InSync = false
 	logger.Info("Current state:")
 	logger.Infof("...Highest block finalized: %v", initialState.HighestBlockFinalized)
@@ -129,17 +132,12 @@ func (mon *monitor) eventLoopTick(state *objects.MonitorState, tick time.Time, s
 	case responseValue := <-resp.Response():
 		switch value := responseValue.(type) {
 		case *objects.MonitorState:
-			diff := originalState.Diff(state)
-			if len(diff) > 0 {
-				select {
-				case mon.statusMsg <- fmt.Sprintf("State \xce\x94 %v", diff):
-				default:
-				}
-				mon.database.UpdateState(value)
-			}
+			mon.persistState(originalState, value)
 			return nil
 		case error:
 			logger.Warnf("SvcWatchEthereum() : %v", value)
+			// Keep what changed before the failure, such as a request for repair
+			mon.persistState(originalState, state)
 		default:
 			logger.Errorf("SvcWatchEthereum() invalid return type: %v", value)
 		}
@@ -149,3 +147,18 @@ func (mon *monitor) eventLoopTick(state *objects.MonitorState, tick time.Time, s
 
 	return nil
 }
+
+// persistState saves state if it changed since original
+func (mon *monitor) persistState(original *objects.MonitorState, state *objects.MonitorState) {
+	diff := original.Diff(state)
+	if len(diff) == 0 {
+		return
+	}
+	select {
+	case mon.statusMsg <- fmt.Sprintf("State \xce\x94 %v", diff):
+	default:
+	}
+	if err := mon.database.UpdateState(state); err != nil {
+		mon.logger.Errorf("Could not persist state: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrRepairNeeded is returned while the monitor waits for an operator to
+// repair what a reorg left behind
+var ErrRepairNeeded = errors.New("manual repair needed")
+
+// processedBlock returns the record of the processed block at height
+func processedBlock(state *objects.MonitorState, height uint64) *objects.ProcessedBlock {
+	if state.ProcessedBlocks == nil {
+		state.ProcessedBlocks = make(map[uint64]*objects.ProcessedBlock)
+	}
+	block, present := state.ProcessedBlocks[height]
+	if !present {
+		block = &objects.ProcessedBlock{}
+		state.ProcessedBlocks[height] = block
+	}
+	return block
+}
+
+// recordLog remembers a processed log so its effects can be undone
+func recordLog(state *objects.MonitorState, log types.Log) {
+	block := processedBlock(state, log.BlockNumber)
+	block.Hash = log.BlockHash
+	block.Logs = append(block.Logs, log)
+}
+
+// oldestBlock returns the lowest height that can still be rolled back
+func oldestBlock(state *objects.MonitorState) uint64 {
+	if state.HighestBlockProcessed < constants.MonitorReorgDepth {
+		return 0
+	}
+	return state.HighestBlockProcessed - constants.MonitorReorgDepth
+}
+
+// pruneBlocks forgets the processed blocks that are too deep to roll back
+func pruneBlocks(state *objects.MonitorState) {
+	oldest := oldestBlock(state)
+	for height := range state.ProcessedBlocks {
+		if height < oldest {
+			delete(state.ProcessedBlocks, height)
+		}
+	}
+}
+
+// blockHash returns the hash of the canonical block at height or the zero
+// hash if the chain is not that long
+func (svcs *Services) blockHash(ctx context.Context, height uint64) (common.Hash, error) {
+	header, err := svcs.eth.GetGethClient().HeaderByNumber(ctx, new(big.Int).SetUint64(height))
+	if err == ethereum.NotFound {
+		return common.Hash{}, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if header == nil {
+		return common.Hash{}, nil
+	}
+	return header.Hash(), nil
+}
+
+// CheckReorg compares the processed blocks with the canonical chain. If they
+// diverged the effects of the blocks that left the chain are rolled back and
+// processing resumes after the highest block both agree on. If that isn't
+// possible the monitor stops until an operator repairs the node.
+func (svcs *Services) CheckReorg(ctx context.Context, state *objects.MonitorState) error {
+	logger := svcs.logger
+
+	if len(state.ProcessedBlocks) == 0 {
+		return nil
+	}
+
+	heights := make([]uint64, 0, len(state.ProcessedBlocks))
+	for height := range state.ProcessedBlocks {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+
+	// Find the highest processed block that is still canonical. Every block
+	// with processed events is recorded, so if none is left the whole window
+	// is processed again.
+	found := false
+	ancestor := oldestBlock(state)
+	for i, height := range heights {
+		hash, err := svcs.blockHash(ctx, height)
+		if err != nil {
+			return err
+		}
+		if hash == state.ProcessedBlocks[height].Hash {
+			if i == 0 {
+				return nil
+			}
+			ancestor = height
+			found = true
+			break
+		}
+	}
+	if !found {
+		logger.Warnf("No processed block is canonical. Ethereum reorgs deeper than %v blocks can not be rolled back.", constants.MonitorReorgDepth)
+	}
+
+	logger.Warnf("Ethereum reorg detected. Rolling back processed blocks %v to %v.", heights[0], ancestor+1)
+
+	// Nothing is rolled back if any event can't be, so the operator repairs
+	// the node from where the reorg was detected
+	for _, height := range heights {
+		if height <= ancestor {
+			break
+		}
+		for _, log := range state.ProcessedBlocks[height].Logs {
+			if name, ok := svcs.canUndo(log); !ok {
+				return svcs.needRepair(state, fmt.Sprintf("%v in block %v left the canonical chain and can not be rolled back, the chain forked after block %v", name, height, ancestor))
+			}
+		}
+	}
+
+	for _, height := range heights {
+		if height <= ancestor {
+			break
+		}
+		block := state.ProcessedBlocks[height]
+		for i := len(block.Logs) - 1; i >= 0; i-- {
+			if err := svcs.undoLog(state, block.Logs[i]); err != nil {
+				// Events up to the failed one keep their effects
+				block.Logs = block.Logs[:i+1]
+				state.HighestBlockProcessed = height
+				return svcs.needRepair(state, fmt.Sprintf("failed to roll back event in block %v: %v, the chain forked after block %v", height, err, ancestor))
+			}
+		}
+		delete(state.ProcessedBlocks, height)
+	}
+	state.HighestBlockProcessed = ancestor
+	state.InSync = false
+	svcs.ah.SetSynchronized(false)
+
+	return nil
+}
+
+// needRepair stops processing until an operator repairs the node and rewinds
+// the monitor state
+func (svcs *Services) needRepair(state *objects.MonitorState, reason string) error {
+	svcs.logger.Errorf("Monitor stopped, manual repair needed: %v", reason)
+	state.RepairNeeded = reason
+	state.InSync = false
+	svcs.ah.SetSynchronized(false)
+	return fmt.Errorf("%w: %v", ErrRepairNeeded, reason)
+}
+
+// canUndo tells if the effects of processing log can be rolled back and
+// returns the name of its event
+func (svcs *Services) canUndo(log types.Log) (string, bool) {
+	info, present := svcs.eventMap.Lookup(log.Topics[0].String())
+	if !present {
+		return "", true
+	}
+
+	switch info.Name {
+	case "DepositReceived", "RegistrationOpen", "ShareDistribution", "KeyShareSubmission":
+		return info.Name, true
+	}
+	return info.Name, false
+}
+
+// undoLog reverts the effects of processing log
+func (svcs *Services) undoLog(state *objects.MonitorState, log types.Log) error {
+	info, present := svcs.eventMap.Lookup(log.Topics[0].String())
+	if !present {
+		return nil
+	}
+
+	c := svcs.eth.Contracts()
+
+	switch info.Name {
+	case "DepositReceived":
+		event, err := c.Deposit().ParseDepositReceived(log)
+		if err != nil {
+			return err
+		}
+		return svcs.consensusDb.Update(func(txn *badger.Txn) error {
+			depositNonce := event.DepositID.Bytes()
+			_, _, spent, err := svcs.dph.Get(txn, [][]byte{depositNonce})
+			if err != nil {
+				return err
+			}
+			if len(spent) > 0 {
+				return fmt.Errorf("deposit %x is already spent", depositNonce)
+			}
+			svcs.logger.Infof("Removing deposit depositID:%x", depositNonce)
+			return svcs.dph.Remove(txn, depositNonce)
+		})
+	case "RegistrationOpen":
+		if state.Schedule != nil {
+			state.Schedule.Purge()
+		}
+		if state.EthDKG != nil {
+			state.EthDKG = objects.NewDkgState(state.EthDKG.Account)
+		}
+	case "ShareDistribution":
+		if state.EthDKG == nil {
+			return nil
+		}
+		event, err := c.Ethdkg().ParseShareDistribution(log)
+		if err != nil {
+			return err
+		}
+		delete(state.EthDKG.Commitments, event.Issuer)
+		delete(state.EthDKG.EncryptedShares, event.Issuer)
+	case "KeyShareSubmission":
+		if state.EthDKG == nil {
+			return nil
+		}
+		event, err := c.Ethdkg().ParseKeyShareSubmission(log)
+		if err != nil {
+			return err
+		}
+		delete(state.EthDKG.KeyShareG1s, event.Issuer)
+		delete(state.EthDKG.KeyShareG1CorrectnessProofs, event.Issuer)
+		delete(state.EthDKG.KeyShareG2s, event.Issuer)
+	default:
+		return fmt.Errorf("%v can not be rolled back", info.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package monitor_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/blockchain/monitor"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/consensus/admin"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/bridge/bindings"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// depositedValue returns the value of the deposits owned by the default account
+func depositedValue(t *testing.T, eth interfaces.Ethereum, conDB *db.Database, app *application.Application) uint32 {
+	all, err := new(uint256.Uint256).FromUint64(1 << 32)
+	assert.Nil(t, err)
+
+	var total uint32
+	err = conDB.View(func(txn *badger.Txn) error {
+		_, value, err := app.GetValueForOwner(txn, constants.CurveSecp256k1, eth.GetDefaultAccount().Address.Bytes(), all)
+		if err != nil {
+			return err
+		}
+		total, err = value.ToUint32()
+		return err
+	})
+	assert.Nil(t, err)
+	return total
+}
+
+// mine commits the pending block and checks every transaction succeeded
+func mine(t *testing.T, eth interfaces.Ethereum, txns ...*types.Transaction) {
+	eth.Commit()
+	for _, txn := range txns {
+		rcpt, err := eth.GetGethClient().TransactionReceipt(context.TODO(), txn.Hash())
+		if assert.Nil(t, err) {
+			assert.Equal(t, uint64(1), rcpt.Status)
+		}
+	}
+}
+
+func TestReorg(t *testing.T) {
+	eth := setupEthereum(t)
+	c := eth.Contracts()
+	ctx := context.TODO()
+	acct := eth.GetDefaultAccount()
+	sim := eth.GetGethClient().(*backends.SimulatedBackend)
+
+	conDB, dph, app := newApplication(t)
+
+	keys, err := eth.GetAccountKeys(acct.Address)
+	assert.Nil(t, err)
+	ah := &admin.Handlers{}
+	assert.Nil(t, ah.Init(uint32(eth.ChainID().Uint64()), conDB, nil, app, ethcrypto.FromECDSAPub(&keys.PrivateKey.PublicKey)))
+
+	svcs := monitor.NewServices(eth, conDB, dph, ah, 100)
+
+	txnOpts, err := eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+	txn, err := c.UtilityToken().Approve(txnOpts, c.DepositAddress(), big.NewInt(10000))
+	assert.Nil(t, err)
+	mine(t, eth, txn)
+
+	height, err := eth.GetCurrentHeight(ctx)
+	assert.Nil(t, err)
+	state := &objects.MonitorState{
+		HighestBlockProcessed: height - 1,
+		EthDKG:                objects.NewDkgState(acct),
+		Schedule:              monitor.NewSequentialSchedule(),
+	}
+	assert.Nil(t, svcs.WatchEthereum(state))
+	assert.Equal(t, height, state.HighestBlockProcessed)
+
+	// Deposit and open registration in a block that will be forked away
+	txnOpts, err = eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+	deposit, err := c.Deposit().Deposit(txnOpts, big.NewInt(1000))
+	assert.Nil(t, err)
+	txnOpts, err = eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+	initialize, err := c.Ethdkg().InitializeState(txnOpts)
+	assert.Nil(t, err)
+	mine(t, eth, deposit, initialize)
+
+	assert.Nil(t, svcs.WatchEthereum(state))
+	assert.Equal(t, height+1, state.HighestBlockProcessed)
+	assert.Equal(t, uint32(1000), depositedValue(t, eth, conDB, app))
+	assert.NotEqual(t, 0, state.Schedule.Length())
+
+	// Fork the chain from the last common block
+	sim.Blockchain().SetHead(height)
+	sim.Rollback()
+
+	mine(t, eth)
+	txnOpts, err = eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+	deposit, err = c.Deposit().Deposit(txnOpts, big.NewInt(500))
+	assert.Nil(t, err)
+	mine(t, eth, deposit)
+
+	// The forked block is rolled back before the new chain is processed
+	assert.Nil(t, svcs.CheckReorg(ctx, state))
+	assert.False(t, state.InSync)
+	assert.Equal(t, height, state.HighestBlockProcessed)
+	assert.Equal(t, 0, state.Schedule.Length())
+	assert.Equal(t, uint32(0), depositedValue(t, eth, conDB, app))
+
+	assert.Nil(t, svcs.WatchEthereum(state))
+	assert.True(t, state.InSync)
+	assert.Equal(t, height+2, state.HighestBlockProcessed)
+	assert.Equal(t, uint32(500), depositedValue(t, eth, conDB, app))
+	assert.Equal(t, 0, state.Schedule.Length())
+
+	// A chain that is still canonical is left alone
+	mine(t, eth)
+	assert.Nil(t, svcs.CheckReorg(ctx, state))
+	assert.Nil(t, svcs.WatchEthereum(state))
+	assert.Equal(t, height+3, state.HighestBlockProcessed)
+	assert.Equal(t, uint32(500), depositedValue(t, eth, conDB, app))
+}
+
+func TestReorgNeedsRepair(t *testing.T) {
+	eth := setupEthereum(t)
+	c := eth.Contracts()
+	ctx := context.TODO()
+	acct := eth.GetDefaultAccount()
+	sim := eth.GetGethClient().(*backends.SimulatedBackend)
+
+	conDB, dph, app := newApplication(t)
+
+	keys, err := eth.GetAccountKeys(acct.Address)
+	assert.Nil(t, err)
+	ah := &admin.Handlers{}
+	assert.Nil(t, ah.Init(uint32(eth.ChainID().Uint64()), conDB, nil, app, ethcrypto.FromECDSAPub(&keys.PrivateKey.PublicKey)))
+
+	svcs := monitor.NewServices(eth, conDB, dph, ah, 100)
+
+	ethdkgABI, err := abi.JSON(strings.NewReader(bindings.ETHDKGABI))
+	assert.Nil(t, err)
+
+	txnOpts, err := eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+	txn, err := c.UtilityToken().Approve(txnOpts, c.DepositAddress(), big.NewInt(10000))
+	assert.Nil(t, err)
+	mine(t, eth, txn)
+
+	height, err := eth.GetCurrentHeight(ctx)
+	assert.Nil(t, err)
+	state := &objects.MonitorState{
+		HighestBlockProcessed: height - 1,
+		EthDKG:                objects.NewDkgState(acct),
+		Schedule:              monitor.NewSequentialSchedule(),
+	}
+	assert.Nil(t, svcs.WatchEthereum(state))
+
+	txnOpts, err = eth.GetTransactionOpts(ctx, acct)
+	assert.Nil(t, err)
+	deposit, err := c.Deposit().Deposit(txnOpts, big.NewInt(1000))
+	assert.Nil(t, err)
+	mine(t, eth, deposit)
+
+	assert.Nil(t, svcs.WatchEthereum(state))
+	assert.Equal(t, height+1, state.HighestBlockProcessed)
+	assert.Equal(t, uint32(1000), depositedValue(t, eth, conDB, app))
+
+	// Finishing a round of ETHDKG takes too long here, so the block is given
+	// the ValidatorSet event the round ends with as if it had been processed
+	block := state.ProcessedBlocks[height+1]
+	if !assert.NotNil(t, block) {
+		return
+	}
+	block.Logs = append(block.Logs, types.Log{
+		Topics:      []common.Hash{ethdkgABI.Events["ValidatorSet"].ID},
+		BlockNumber: height + 1,
+		BlockHash:   block.Hash,
+	})
+
+	// Fork the chain from the last common block
+	sim.Blockchain().SetHead(height)
+	sim.Rollback()
+	mine(t, eth)
+	mine(t, eth)
+
+	// Nothing is rolled back and processing stops until the node is repaired
+	err = svcs.CheckReorg(ctx, state)
+	assert.True(t, errors.Is(err, monitor.ErrRepairNeeded))
+	assert.NotEqual(t, "", state.RepairNeeded)
+	assert.False(t, state.InSync)
+	assert.Equal(t, height+1, state.HighestBlockProcessed)
+	assert.Equal(t, 2, len(state.ProcessedBlocks[height+1].Logs))
+	assert.Equal(t, uint32(1000), depositedValue(t, eth, conDB, app))
+
+	err = svcs.WatchEthereum(state)
+	assert.True(t, errors.Is(err, monitor.ErrRepairNeeded))
+	assert.Equal(t, height+1, state.HighestBlockProcessed)
+
+	// Rewinding to the fork resumes processing on the new chain
+	assert.Nil(t, monitor.Rewind(state, height))
+	assert.Equal(t, "", state.RepairNeeded)
+	assert.Nil(t, svcs.WatchEthereum(state))
+	assert.True(t, state.InSync)
+	assert.Equal(t, height+2, state.HighestBlockProcessed)
+}
@@ -1,10 +1,12 @@
 package monitor
 
 import (
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 
 	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/blockchain/objects"
 	"github.com/MadBase/MadNet/blockchain/tasks"
 	"github.com/pborman/uuid"
 	"github.com/sirupsen/logrus"
@@ -14,8 +16,14 @@ var (
 	ErrOverlappingSchedule = errors.New("overlapping schedule range")
 	ErrNothingScheduled    = errors.New("nothing schedule for time")
 	ErrNotScheduled        = errors.New("scheduled task not found")
+	ErrScheduleExpired     = errors.New("scheduled task range already ended")
 )
 
+// The schedule is persisted with the monitor state
+func init() {
+	gob.Register(&SequentialSchedule{})
+}
+
 type Block struct {
 	Start uint64
 	End   uint64
@@ -63,6 +71,21 @@ func (b *Block) UnmarshalJSON(raw []byte) error {
 	return nil
 }
 
+// GobEncode encodes the block like MarshalJSON so the task keeps its type
+func (b *Block) GobEncode() ([]byte, error) {
+	return b.MarshalJSON()
+}
+
+// GobDecode decodes a block encoded by GobEncode
+func (b *Block) GobDecode(raw []byte) error {
+	return b.UnmarshalJSON(raw)
+}
+
+// dkgTask is a task of a round of ETHDKG, all of which share its state
+type dkgTask interface {
+	SetDkgState(state *objects.DkgState)
+}
+
 type SequentialSchedule struct {
 	Ranges map[string]*Block
 }
@@ -86,6 +109,32 @@ func (s *SequentialSchedule) Schedule(start uint64, end uint64, thing interfaces
 	return id, nil
 }
 
+// Reschedule moves a task to a new id so it's run again, no earlier than
+// start and until the end of its range
+func (s *SequentialSchedule) Reschedule(taskId uuid.UUID, start uint64) (uuid.UUID, error) {
+	id := taskId.String()
+
+	block, present := s.Ranges[id]
+	if !present {
+		return nil, ErrNotScheduled
+	}
+	if start < block.Start {
+		start = block.Start
+	}
+	if start > block.End {
+		return nil, ErrScheduleExpired
+	}
+
+	delete(s.Ranges, id)
+	newId, err := s.Schedule(start, block.End, block.Task)
+	if err != nil {
+		s.Ranges[id] = block
+		return nil, err
+	}
+
+	return newId, nil
+}
+
 func (s *SequentialSchedule) Purge() {
 	for taskID := range s.Ranges {
 		delete(s.Ranges, taskID)
@@ -119,6 +168,16 @@ func (s *SequentialSchedule) Retrieve(taskId uuid.UUID) (interfaces.Task, error)
 	return block.Task, nil
 }
 
+// Range returns the first and last block of a scheduled task
+func (s *SequentialSchedule) Range(taskId uuid.UUID) (uint64, uint64, error) {
+	block, present := s.Ranges[taskId.String()]
+	if !present {
+		return 0, 0, ErrNotScheduled
+	}
+
+	return block.Start, block.End, nil
+}
+
 func (s *SequentialSchedule) Length() int {
 	return len(s.Ranges)
 }
@@ -136,6 +195,15 @@ func (s *SequentialSchedule) Remove(taskId uuid.UUID) error {
 	return nil
 }
 
+// ShareDkgState points the scheduled tasks of ETHDKG at the state of the round
+func (s *SequentialSchedule) ShareDkgState(state *objects.DkgState) {
+	for _, block := range s.Ranges {
+		if task, ok := block.Task.(dkgTask); ok {
+			task.SetDkgState(state)
+		}
+	}
+}
+
 func (s *SequentialSchedule) Status(logger *logrus.Entry) {
 	for id, block := range s.Ranges {
 		str, err := block.MarshalJSON()
@@ -2,7 +2,9 @@ package monitor
 
 import (
 	"context"
-	"time"
+	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/MadBase/MadNet/application/deposit"
 	"github.com/MadBase/MadNet/blockchain/dkg/dkgevents"
@@ -14,17 +16,14 @@ import (
 	"github.com/MadBase/MadNet/consensus/db"
 	"github.com/MadBase/MadNet/consensus/objs"
 	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/MadBase/bridge/bindings"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/sirupsen/logrus"
 )
 
-//
-type eventProcessor struct {
-	name      string
-	processor func(*objects.MonitorState, types.Log) error
-}
-
 // Services just a bundle of requirements common for monitoring functionality
 type Services struct {
 	logger            *logrus.Logger
@@ -35,7 +34,6 @@ type Services struct {
 	contractAddresses []common.Address
 	batchSize         int
 	eventMap          *objects.EventMap
-	events            map[string]*eventProcessor
 	taskMan           tasks.Manager
 }
 
@@ -58,64 +56,11 @@ func NewServices(eth interfaces.Ethereum, db *db.Database, dph *deposit.Handler,
 		dph:               dph,
 		eth:               eth,
 		eventMap:          objects.NewEventMap(),
-		events:            make(map[string]*eventProcessor),
 		logger:            serviceLogger,
 		taskMan:           tasks.NewManager()}
 
 	// Register handlers for known events, if this failed we really can't continue
-	if err := SetupEventMap(svcs.eventMap); err != nil {
-		panic(err)
-	}
-
-	// Below are the RegisterEvent()'s with nil fn's to improve logging by correlating a name with the topic
-	if err := svcs.RegisterEvent("0x3529eeacda732ca25cee203cc6382b6d0688ee079ec8e53fd2dcbf259bdd3fa1", "DepositReceived-Obsolete", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x6bae01a1b82866e1dfe8d98c42383fc58df9b4adeb47d7ac24ee4b53d409da6c", "DepositReceived-Obsolete", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925", "DSTokenApproval", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0xce241d7ca1f669fee44b6fc00b8eba2df3bb514eed0f6f668f8f89096e81ed94", "LogSetOwner", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x0f6798a560793a54c3bcfe86a93cde1e73087d944c0ea20544137d4121396885", "Mint", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", "Transfer", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x8c25e214c5693ebaf8008875bacedeb9e0aafd393864a314ed1801b2a4e13dd9", "ValidatorJoined", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x319bbadb03b94aedc69babb34a28675536a9cb30f4bbde343e1d0018c44ebd94", "ValidatorLeft", nil); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x1de2f07b0a1c69916a8b25b889051644192307ea08444a2e11f8654d1db3ab0c", "LockedStake", nil); err != nil {
-		panic(err)
-	}
-
-	// Real event processors are below
-	if err := svcs.RegisterEvent("0x5b063c6569a91e8133fc6cd71d31a4ca5c65c652fd53ae093f46107754f08541", "DepositReceived", svcs.ProcessDepositReceived); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x113b129fac2dde341b9fbbec2bb79a95b9945b0e80fda711fc8ae5c7b0ea83b0", "ValidatorMember", svcs.ProcessValidatorMember); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x1c85ff1efe0a905f8feca811e617102cb7ec896aded693eb96366c8ef22bb09f", "ValidatorSet", svcs.ProcessValidatorSet); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x6d438b6b835d16cdae6efdc0259fdfba17e6aa32dae81863a2467866f85f724a", "SnapshotTaken", svcs.ProcessSnapshotTaken); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0xa84d294194d6169652a99150fd2ef10e18b0d2caa10beeea237bbddcc6e22b10", "ShareDistribution", svcs.ProcessShareDistribution); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0xb0ee36c3780de716eb6c83687f433ae2558a6923e090fd238b657fb6c896badc", "KeyShareSubmission", svcs.ProcessKeyShareSubmission); err != nil {
-		panic(err)
-	}
-	if err := svcs.RegisterEvent("0x9c6f8368fe7e77e8cb9438744581403bcb3f53298e517f04c1b8475487402e97", "RegistrationOpen", svcs.ProcessRegistrationOpen); err != nil {
+	if err := SetupEventMap(svcs.eventMap, db, ah, dph); err != nil {
 		panic(err)
 	}
 
@@ -124,58 +69,73 @@ func NewServices(eth interfaces.Ethereum, db *db.Database, dph *deposit.Handler,
 	return svcs
 }
 
-// SetupEventMap populates map with known log topics
-func SetupEventMap(em *objects.EventMap) error {
-
-	// if err := em.RegisterLocked("0x3529eeacda732ca25cee203cc6382b6d0688ee079ec8e53fd2dcbf259bdd3fa1", "DepositReceived-Obsolete", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x6bae01a1b82866e1dfe8d98c42383fc58df9b4adeb47d7ac24ee4b53d409da6c", "DepositReceived-Obsolete", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925", "DSTokenApproval", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0xce241d7ca1f669fee44b6fc00b8eba2df3bb514eed0f6f668f8f89096e81ed94", "LogSetOwner", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x0f6798a560793a54c3bcfe86a93cde1e73087d944c0ea20544137d4121396885", "Mint", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", "Transfer", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x8c25e214c5693ebaf8008875bacedeb9e0aafd393864a314ed1801b2a4e13dd9", "ValidatorJoined", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x319bbadb03b94aedc69babb34a28675536a9cb30f4bbde343e1d0018c44ebd94", "ValidatorLeft", nil); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x1de2f07b0a1c69916a8b25b889051644192307ea08444a2e11f8654d1db3ab0c", "LockedStake", nil); err != nil {
-	// 	return err
-	// }
-
-	// Real event processors are below
-	// if err := em.RegisterLocked("0x5b063c6569a91e8133fc6cd71d31a4ca5c65c652fd53ae093f46107754f08541", "DepositReceived", svcs.ProcessDepositReceived); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x113b129fac2dde341b9fbbec2bb79a95b9945b0e80fda711fc8ae5c7b0ea83b0", "ValidatorMember", svcs.ProcessValidatorMember); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x1c85ff1efe0a905f8feca811e617102cb7ec896aded693eb96366c8ef22bb09f", "ValidatorSet", svcs.ProcessValidatorSet); err != nil {
-	// 	return err
-	// }
-	// if err := em.RegisterLocked("0x6d438b6b835d16cdae6efdc0259fdfba17e6aa32dae81863a2467866f85f724a", "SnapshotTaken", svcs.ProcessSnapshotTaken); err != nil {
-	// 	return err
-	// }
-	if err := em.RegisterLocked("0xa84d294194d6169652a99150fd2ef10e18b0d2caa10beeea237bbddcc6e22b10", "ShareDistribution", dkgevents.ProcessShareDistribution); err != nil {
-		return err
+// eventID returns the topic of the named event in a contract ABI
+func eventID(contractABI string, name string) (string, error) {
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return "", err
 	}
-	if err := em.RegisterLocked("0xb0ee36c3780de716eb6c83687f433ae2558a6923e090fd238b657fb6c896badc", "KeyShareSubmission", dkgevents.ProcessKeyShareSubmission); err != nil {
-		return err
+	event, ok := parsed.Events[name]
+	if !ok {
+		return "", fmt.Errorf("event %v not found in contract ABI", name)
 	}
-	if err := em.RegisterLocked("0x9c6f8368fe7e77e8cb9438744581403bcb3f53298e517f04c1b8475487402e97", "RegistrationOpen", dkgevents.ProcessOpenRegistration); err != nil {
-		return err
+	return event.ID.String(), nil
+}
+
+// dkgProcessor only runs fn when state is tracking a round of ETHDKG
+func dkgProcessor(fn objects.EventProcessor) objects.EventProcessor {
+	return func(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log) error {
+		if state.EthDKG == nil || state.Schedule == nil {
+			logger.Debug("Not participating in ETHDKG")
+			return nil
+		}
+		return fn(eth, logger, state, log)
+	}
+}
+
+// SetupEventMap populates map with known log topics
+func SetupEventMap(em *objects.EventMap, cdb *db.Database, adminHandler *admin.Handlers, depositHandler *deposit.Handler) error {
+
+	events := []struct {
+		contractABI string
+		name        string
+		fn          objects.EventProcessor
+	}{
+		// Registered without a processor to correlate a name with the topic when logging
+		{bindings.TokenABI, "Approval", nil},
+		{bindings.TokenABI, "LogSetOwner", nil},
+		{bindings.TokenABI, "Mint", nil},
+		{bindings.TokenABI, "Transfer", nil},
+		{bindings.ValidatorsABI, "ValidatorJoined", nil},
+		{bindings.ValidatorsABI, "ValidatorLeft", nil},
+		{bindings.ValidatorsABI, "LockedStake", nil},
+
+		// Real event processors are below
+		{bindings.DepositABI, "DepositReceived", func(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log) error {
+			return ProcessDepositReceived(eth, logger, state, log, cdb, depositHandler)
+		}},
+		{bindings.ETHDKGABI, "ValidatorMember", func(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log) error {
+			return ProcessValidatorMember(eth, logger, state, log, adminHandler)
+		}},
+		{bindings.ETHDKGABI, "ValidatorSet", func(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log) error {
+			return ProcessValidatorSet(eth, logger, state, log, adminHandler)
+		}},
+		{bindings.ValidatorsABI, "SnapshotTaken", func(eth interfaces.Ethereum, logger *logrus.Entry, state *objects.MonitorState, log types.Log) error {
+			return ProcessSnapshotTaken(eth, logger, state, log, adminHandler)
+		}},
+		{bindings.ETHDKGABI, "ShareDistribution", dkgProcessor(dkgevents.ProcessShareDistribution)},
+		{bindings.ETHDKGABI, "KeyShareSubmission", dkgProcessor(dkgevents.ProcessKeyShareSubmission)},
+		{bindings.ETHDKGABI, "RegistrationOpen", dkgProcessor(dkgevents.ProcessOpenRegistration)},
+	}
+
+	for _, event := range events {
+		id, err := eventID(event.contractABI, event.name)
+		if err != nil {
+			return err
+		}
+		if err := em.RegisterLocked(id, event.name, event.fn); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -186,6 +146,13 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 	logger := svcs.logger
 	eth := svcs.eth
 
+	// Nothing is processed until an operator repairs what a reorg left behind
+	if state.RepairNeeded != "" {
+		state.InSync = false
+		svcs.ah.SetSynchronized(false)
+		return fmt.Errorf("%w: %v", ErrRepairNeeded, state.RepairNeeded)
+	}
+
 	ctx, cancelFunc := eth.GetTimeoutContext()
 	defer cancelFunc()
 
@@ -217,6 +184,12 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 		return err
 	}
 
+	// Undo what was processed from blocks that are no longer canonical
+	err = svcs.CheckReorg(ctx, state)
+	if err != nil {
+		return err
+	}
+
 	// Decide what events to look for
 	firstBlock := state.HighestBlockProcessed + 1
 	lastBlock := state.HighestBlockProcessed + uint64(svcs.batchSize) // Be optimistic
@@ -243,6 +216,12 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 
 		logsByBlock := make(map[uint64][]types.Log)
 
+		// Remember the last block so a reorg can be detected later
+		lastHash, err := svcs.blockHash(ctx, lastBlock)
+		if err != nil {
+			return err
+		}
+
 		// Grab all the events in range
 		logs, err := svcs.eth.GetEvents(ctx, firstBlock, lastBlock, svcs.contractAddresses)
 		if err != nil {
@@ -252,6 +231,10 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 		// Find the blocks with events
 		for _, log := range logs {
 			bn := log.BlockNumber
+			if bn == lastBlock && log.BlockHash != lastHash {
+				logger.Warnf("Chain changed while fetching events for block %v.", bn)
+				return nil
+			}
 			if la, ok := logsByBlock[bn]; ok {
 				logsByBlock[bn] = append(la, log)
 			} else {
@@ -265,19 +248,22 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 			// If current block has any events, we process all of them
 			if logs, present := logsByBlock[block]; present {
 				for _, log := range logs {
-					eventSelector := log.Topics[0].String()
-
-					ep, ok := svcs.events[eventSelector]
-					if ok {
-						logger.Debugf("... block:%v event:%v name:%v", block, eventSelector, ep.name)
-						if ep.processor != nil {
-							err := ep.processor(state, log)
+					eventID := log.Topics[0].String()
+					logEntry := logger.WithField("EventID", eventID)
+
+					info, present := svcs.eventMap.Lookup(eventID)
+					if present {
+						logEntry = logEntry.WithField("Event", info.Name)
+						logEntry.Debugf("... block:%v", block)
+						if info.Processor != nil {
+							recordLog(state, log)
+							err := info.Processor(eth, logEntry, state, log)
 							if err != nil {
-								logger.Errorf("Event handler for %v failed: %v", eventSelector, err)
+								logEntry.Errorf("Event handler failed: %v", err)
 							}
 						}
 					} else {
-						logger.Debugf("... block:%v event:%v", block, eventSelector)
+						logEntry.Debugf("... block:%v", block)
 					}
 				}
 			}
@@ -300,6 +286,9 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 			state.HighestBlockProcessed = lastBlock
 		}
 
+		processedBlock(state, lastBlock).Hash = lastHash
+		pruneBlocks(state)
+
 		if lastBlock < finalizedHeight {
 			state.InSync = false
 			svcs.ah.SetSynchronized(false)
@@ -310,13 +299,10 @@ func (svcs *Services) WatchEthereum(state *objects.MonitorState) error {
 
 	}
 
-	return nil
-}
-
-// RegisterEvent registers a handler for when an interesting event shows up
-func (svcs *Services) RegisterEvent(selector string, name string, fn func(*objects.MonitorState, types.Log) error) error {
+	if state.InSync {
+		svcs.startScheduledTask(state)
+	}
 
-	svcs.events[selector] = &eventProcessor{processor: fn, name: name}
 	return nil
 }
 
@@ -368,12 +354,10 @@ func (svcs *Services) UpdateProgress(ctx context.Context, state *objects.Monitor
 	return nil
 }
 
-// PersistSnapshot records the given block header on Ethereum and increments epoch
-// TODO Returning an error kills the main loop, retry forever instead
+// PersistSnapshot records the given block header on Ethereum in the background,
+// the task manager retries it and keeps a log of failed attempts
 func (svcs *Services) PersistSnapshot(blockHeader *objs.BlockHeader) error {
 
-	eth := svcs.eth
-	c := eth.Contracts()
 	logger := svcs.logger
 
 	// pull out the block claims
@@ -387,38 +371,68 @@ func (svcs *Services) PersistSnapshot(blockHeader *objs.BlockHeader) error {
 	// pull out the sig
 	rawSigGroup := blockHeader.SigGroup
 
-	// Do the mechanics
-	txnOpts, err := svcs.eth.GetTransactionOpts(context.Background(), svcs.eth.GetDefaultAccount())
+	// the block hash decides which validator submits first
+	blockHash, err := blockHeader.BlockHash()
 	if err != nil {
-		logger.Errorf("Could not create transaction for snapshot: %v", err)
-		return nil //CAN NOT RETURN ERROR OR SUBSCRIPTION IS LOST!
+		logger.Errorf("Could not compute hash of BlockHeader: %v", err)
+		return nil
+	}
+
+	epoch := big.NewInt(int64(utils.Epoch(bclaims.Height)))
+	firstBlock := uint64(config.Configuration.Ethereum.StartingBlock)
+	task := tasks.NewSnapshotTask(svcs.eth.GetDefaultAccount(), epoch, blockHash, rawBclaims, rawSigGroup, firstBlock)
+
+	// Waiting for its turn can take longer than an attempt may, so the task
+	// limits its own calls instead
+	policy := tasks.DefaultRetryPolicy(svcs.eth)
+	policy.Timeout = 0
+
+	taskLogger := logger.WithField("Epoch", epoch)
+	if !svcs.taskMan.Run(taskLogger, svcs.eth, fmt.Sprintf("snapshot-%v", epoch), task, policy) {
+		taskLogger.Info("Snapshot task already ran")
+	}
+
+	return nil
+}
+
+// startScheduledTask runs the task scheduled for the highest processed block,
+// its id keeps it from running more than once
+func (svcs *Services) startScheduledTask(state *objects.MonitorState) {
+	if state.Schedule == nil {
+		return
 	}
 
-	txn, err := c.Validators().Snapshot(txnOpts, rawSigGroup, rawBclaims)
+	taskID, err := state.Schedule.Find(state.HighestBlockProcessed)
 	if err != nil {
-		logger.Errorf("Failed to take snapshot: %v", err)
-		return nil //CAN NOT RETURN ERROR OR SUBSCRIPTION IS LOST!
+		return
 	}
 
-	toCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	logger := svcs.logger.WithField("TaskID", taskID.String())
 
-	rcpt, err := eth.Queue().QueueAndWait(toCtx, txn)
+	task, err := state.Schedule.Retrieve(taskID)
 	if err != nil {
-		logger.Errorf("Failed to retrieve snapshot receipt: %v", err)
-		return nil //CAN NOT RETURN ERROR OR SUBSCRIPTION IS LOST!
+		logger.Errorf("Could not retrieve scheduled task: %v", err)
+		return
+	}
+	_, end, err := state.Schedule.Range(taskID)
+	if err != nil {
+		logger.Errorf("Could not find range of scheduled task: %v", err)
+		return
 	}
 
-	if rcpt == nil {
-		logger.Warnf("No receipt from snapshot")
-	} else {
-		if rcpt.Status != uint64(1) {
-			logger.Errorf("Snapshot receipt shows failure.")
-			return nil //CAN NOT RETURN ERROR OR SUBSCRIPTION IS LOST!
-		}
+	// Tasks are retried until their range ends
+	policy := tasks.DefaultRetryPolicy(svcs.eth)
+	policy.MaxAttempts = 0
+	policy.DeadlineBlock = end
+
+	if svcs.taskMan.Run(logger, svcs.eth, taskID.String(), task, policy) {
+		logger.Infof("Started scheduled task until block %v", end)
 	}
+}
 
-	return nil
+// Tasks returns the manager running snapshot and scheduled tasks
+func (svcs *Services) Tasks() tasks.Manager {
+	return svcs.taskMan
 }
 
 // SetBN256PrivateKey informs the admin bus of the BN256 private key
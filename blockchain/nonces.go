@@ -0,0 +1,342 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+// NonceStore persists the nonces of accounts across restarts
+type NonceStore interface {
+	FindNonces(account common.Address) (*objects.AccountNonces, error)
+	UpdateNonces(account common.Address, nonces *objects.AccountNonces) error
+}
+
+// accountNonces are the nonces of one account, the lock is held while a
+// transaction is assigned a nonce and signed
+type accountNonces struct {
+	sync.Mutex
+	nonces     *objects.AccountNonces
+	sentAt     map[uint64]time.Time // When each transaction in flight was sent by this process
+	reservedAt map[uint64]time.Time // When each nonce whose transaction wasn't sent yet was reserved
+}
+
+// NonceManager hands out the nonces of transactions so several can be built
+// for the same account at once without relying on the endpoint's pending
+// nonce. A nonce in flight whose transaction is unknown to the endpoint for
+// dropAfter is handed out again, as are nonces skipped because their
+// transaction was never sent.
+type NonceManager struct {
+	sync.Mutex
+	client    interfaces.GethClient
+	store     NonceStore
+	dropAfter time.Duration
+	accounts  map[common.Address]*accountNonces
+	logger    *logrus.Entry
+}
+
+// NewNonceManager creates a nonce manager that keeps state in memory until a
+// store is set
+func NewNonceManager(client interfaces.GethClient, dropAfter time.Duration) *NonceManager {
+	return &NonceManager{
+		client:    client,
+		dropAfter: dropAfter,
+		accounts:  make(map[common.Address]*accountNonces),
+		logger:    logging.GetLogger("ethereum").WithField("Component", "nonces")}
+}
+
+// SetStore persists nonces in store, loading them again on next use
+func (m *NonceManager) SetStore(store NonceStore) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.store = store
+	m.accounts = make(map[common.Address]*accountNonces)
+}
+
+// Assign hands out a nonce of from to build a transaction with and records the
+// transaction built as in flight. No other nonce of from is handed out until
+// build returns, so it may also send the transaction.
+func (m *NonceManager) Assign(ctx context.Context, from common.Address, build func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	return m.assign(ctx, from, build, true)
+}
+
+// Reserve hands out a nonce like Assign for a transaction that is sent after
+// build returns. The nonce isn't handed out again until Sent or SendFailed
+// reports on the transaction, or until EthereumNonceDropTimeout passed
+// without a report.
+func (m *NonceManager) Reserve(ctx context.Context, from common.Address, build func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	return m.assign(ctx, from, build, false)
+}
+
+func (m *NonceManager) assign(ctx context.Context, from common.Address, build func(nonce uint64) (*types.Transaction, error), sent bool) (*types.Transaction, error) {
+	acct := m.account(from)
+	acct.Lock()
+	defer acct.Unlock()
+
+	pending, err := m.reconcile(ctx, from, acct)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill gaps before using a new nonce
+	nonce := acct.nonces.Next
+	for n := pending; n < acct.nonces.Next; n++ {
+		if _, present := acct.nonces.InFlight[n]; !present {
+			nonce = n
+			break
+		}
+	}
+
+	txn, err := build(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	acct.nonces.InFlight[nonce] = txn.Hash()
+	if sent {
+		acct.sentAt[nonce] = time.Now()
+	} else {
+		acct.reservedAt[nonce] = time.Now()
+	}
+	if nonce >= acct.nonces.Next {
+		acct.nonces.Next = nonce + 1
+	}
+	m.save(from, acct)
+
+	return txn, nil
+}
+
+// Sent records the latest transaction sent with a nonce, such as one
+// replacing a stuck transaction
+func (m *NonceManager) Sent(from common.Address, nonce uint64, txn common.Hash) {
+	acct := m.account(from)
+	acct.Lock()
+	defer acct.Unlock()
+
+	acct.nonces.InFlight[nonce] = txn
+	acct.sentAt[nonce] = time.Now()
+	delete(acct.reservedAt, nonce)
+	if nonce >= acct.nonces.Next {
+		acct.nonces.Next = nonce + 1
+	}
+	m.save(from, acct)
+}
+
+// Release makes a nonce available again because its transaction wasn't sent
+func (m *NonceManager) Release(from common.Address, nonce uint64) {
+	acct := m.account(from)
+	acct.Lock()
+	defer acct.Unlock()
+
+	delete(acct.nonces.InFlight, nonce)
+	delete(acct.sentAt, nonce)
+	delete(acct.reservedAt, nonce)
+	m.save(from, acct)
+}
+
+// SendFailed makes a reserved nonce available again because its transaction
+// couldn't be sent, unless the nonce was handed out again meanwhile
+func (m *NonceManager) SendFailed(from common.Address, nonce uint64, txn common.Hash) {
+	acct := m.account(from)
+	acct.Lock()
+	defer acct.Unlock()
+
+	if acct.nonces.InFlight[nonce] != txn {
+		return
+	}
+	delete(acct.nonces.InFlight, nonce)
+	delete(acct.sentAt, nonce)
+	delete(acct.reservedAt, nonce)
+	m.save(from, acct)
+}
+
+// Mined records that the transaction with a nonce was mined
+func (m *NonceManager) Mined(from common.Address, nonce uint64) {
+	m.Release(from, nonce)
+}
+
+// InFlight returns the nonces of from whose transactions weren't seen mined
+func (m *NonceManager) InFlight(from common.Address) map[uint64]common.Hash {
+	acct := m.account(from)
+	acct.Lock()
+	defer acct.Unlock()
+
+	inFlight := make(map[uint64]common.Hash, len(acct.nonces.InFlight))
+	for nonce, txn := range acct.nonces.InFlight {
+		inFlight[nonce] = txn
+	}
+	return inFlight
+}
+
+func (m *NonceManager) account(from common.Address) *accountNonces {
+	m.Lock()
+	defer m.Unlock()
+
+	acct, present := m.accounts[from]
+	if present {
+		return acct
+	}
+
+	acct = &accountNonces{sentAt: make(map[uint64]time.Time), reservedAt: make(map[uint64]time.Time)}
+	if m.store != nil {
+		nonces, err := m.store.FindNonces(from)
+		if err != nil {
+			m.logger.Warnf("could not load nonces of %v: %v", from.Hex(), err)
+		} else {
+			acct.nonces = nonces
+		}
+	}
+	if acct.nonces == nil {
+		acct.nonces = &objects.AccountNonces{InFlight: make(map[uint64]common.Hash)}
+	}
+	m.accounts[from] = acct
+
+	return acct
+}
+
+// reconcile forgets the transactions that were mined or dropped and returns
+// the endpoint's pending nonce, which is where gaps start
+func (m *NonceManager) reconcile(ctx context.Context, from common.Address, acct *accountNonces) (uint64, error) {
+	confirmed, err := m.client.NonceAt(ctx, from, nil)
+	if err != nil {
+		return 0, err
+	}
+	pending, err := m.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := false
+	for nonce, txn := range acct.nonces.InFlight {
+		if nonce < confirmed {
+			delete(acct.nonces.InFlight, nonce)
+			delete(acct.sentAt, nonce)
+			delete(acct.reservedAt, nonce)
+			changed = true
+			continue
+		}
+
+		// A reserved nonce is only reused once nothing reported on its
+		// transaction for long
+		if reservedAt, present := acct.reservedAt[nonce]; present {
+			if time.Since(reservedAt) < constants.EthereumNonceDropTimeout {
+				continue
+			}
+			m.logger.WithField("Account", from.Hex()).
+				WithField("Nonce", nonce).
+				Warn("Transaction was never sent, reusing its nonce")
+			delete(acct.nonces.InFlight, nonce)
+			delete(acct.reservedAt, nonce)
+			changed = true
+			continue
+		}
+
+		// Transactions above a gap are queued by the endpoint, so only
+		// those at or past its pending nonce can have been dropped
+		if nonce < pending || time.Since(acct.sentAt[nonce]) < m.dropAfter {
+			continue
+		}
+		_, _, err := m.client.TransactionByHash(ctx, txn)
+		if err == ethereum.NotFound {
+			m.logger.WithField("Account", from.Hex()).
+				WithField("Nonce", nonce).
+				WithField("Transaction", txn.Hex()).
+				Warn("Transaction was dropped, reusing its nonce")
+			delete(acct.nonces.InFlight, nonce)
+			delete(acct.sentAt, nonce)
+			changed = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	// Transactions sent by others with the same account
+	if acct.nonces.Next < pending {
+		acct.nonces.Next = pending
+		changed = true
+	}
+
+	if changed {
+		m.save(from, acct)
+	}
+
+	return pending, nil
+}
+
+func (m *NonceManager) save(from common.Address, acct *accountNonces) {
+	m.Lock()
+	store := m.store
+	m.Unlock()
+
+	if store == nil {
+		return
+	}
+	if err := store.UpdateNonces(from, acct.nonces); err != nil {
+		m.logger.Warnf("could not save nonces of %v: %v", from.Hex(), err)
+	}
+}
+
+// withNonce returns an unsigned copy of txn with another nonce and gas price
+func withNonce(txn *types.Transaction, nonce uint64, gasPrice *big.Int) *types.Transaction {
+	if txn.To() == nil {
+		return types.NewContractCreation(nonce, txn.Value(), txn.Gas(), gasPrice, txn.Data())
+	}
+	return types.NewTransaction(nonce, *txn.To(), txn.Value(), txn.Gas(), gasPrice, txn.Data())
+}
+
+// nonceTransactor sends the transactions of contract bindings, whose nonces
+// were reserved when they were signed, and reports to the nonce manager
+// whether they were sent
+type nonceTransactor struct {
+	interfaces.GethClient
+	nonces  *NonceManager
+	signer  types.Signer
+	ordered bool // The simulator only takes transactions in nonce order
+}
+
+func (t *nonceTransactor) SendTransaction(ctx context.Context, txn *types.Transaction) error {
+	from, err := types.Sender(t.signer, txn)
+	if err != nil {
+		return t.GethClient.SendTransaction(ctx, txn)
+	}
+
+	if t.ordered {
+		err = t.waitTurn(ctx, from, txn.Nonce())
+	}
+	if err == nil {
+		err = t.GethClient.SendTransaction(ctx, txn)
+	}
+	if err != nil {
+		t.nonces.SendFailed(from, txn.Nonce(), txn.Hash())
+		return err
+	}
+	t.nonces.Sent(from, txn.Nonce(), txn.Hash())
+
+	return nil
+}
+
+// waitTurn waits for the transactions with lower nonces to be sent
+func (t *nonceTransactor) waitTurn(ctx context.Context, from common.Address, nonce uint64) error {
+	for {
+		pending, err := t.GethClient.PendingNonceAt(ctx, from)
+		if err != nil || pending >= nonce {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package blockchain_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryNonceStore struct {
+	sync.Mutex
+	nonces map[common.Address]objects.AccountNonces
+}
+
+func (s *memoryNonceStore) FindNonces(account common.Address) (*objects.AccountNonces, error) {
+	s.Lock()
+	defer s.Unlock()
+	nonces := &objects.AccountNonces{InFlight: make(map[uint64]common.Hash)}
+	if stored, present := s.nonces[account]; present {
+		nonces.Next = stored.Next
+		for nonce, txn := range stored.InFlight {
+			nonces.InFlight[nonce] = txn
+		}
+	}
+	return nonces, nil
+}
+
+func (s *memoryNonceStore) UpdateNonces(account common.Address, nonces *objects.AccountNonces) error {
+	s.Lock()
+	defer s.Unlock()
+	stored := objects.AccountNonces{Next: nonces.Next, InFlight: make(map[uint64]common.Hash)}
+	for nonce, txn := range nonces.InFlight {
+		stored.InFlight[nonce] = txn
+	}
+	s.nonces[account] = stored
+	return nil
+}
+
+func TestNonceManager(t *testing.T) {
+	eth, err := blockchain.NewEthereumSimulator(
+		"../assets/test/keys",
+		"../assets/test/passcodes.txt",
+		6,
+		1*time.Second,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		accountAddresses...)
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	acct := eth.GetDefaultAccount()
+	assert.Nil(t, eth.UnlockAccount(acct))
+	sim := eth.GetGethClient().(*backends.SimulatedBackend)
+	ctx := context.Background()
+	to := common.HexToAddress(accountAddresses[1])
+
+	build := func(send bool) func(uint64) (*types.Transaction, error) {
+		return func(nonce uint64) (*types.Transaction, error) {
+			txn, err := eth.SignTransaction(acct.Address, types.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(1), nil))
+			if err != nil || !send {
+				return txn, err
+			}
+			return txn, sim.SendTransaction(ctx, txn)
+		}
+	}
+
+	// A transaction that never reached the endpoint frees its nonce once
+	// it's considered dropped
+	nonces := blockchain.NewNonceManager(sim, 0)
+	lost, err := nonces.Assign(ctx, acct.Address, build(false))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), lost.Nonce())
+	txn, err := nonces.Assign(ctx, acct.Address, build(true))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), txn.Nonce())
+
+	// Nonces in flight survive a restart
+	store := &memoryNonceStore{nonces: make(map[common.Address]objects.AccountNonces)}
+	nonces = blockchain.NewNonceManager(sim, time.Hour)
+	nonces.SetStore(store)
+	txn, err = nonces.Assign(ctx, acct.Address, build(true))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), txn.Nonce())
+
+	nonces = blockchain.NewNonceManager(sim, time.Hour)
+	nonces.SetStore(store)
+	assert.Equal(t, map[uint64]common.Hash{1: txn.Hash()}, nonces.InFlight(acct.Address))
+
+	// Mined transactions are forgotten
+	eth.Commit()
+	txn, err = nonces.Assign(ctx, acct.Address, build(true))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), txn.Nonce())
+	assert.Equal(t, map[uint64]common.Hash{2: txn.Hash()}, nonces.InFlight(acct.Address))
+}
+
+func TestConcurrentTransactions(t *testing.T) {
+	eth, err := blockchain.NewEthereumSimulator(
+		"../assets/test/keys",
+		"../assets/test/passcodes.txt",
+		6,
+		1*time.Second,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		accountAddresses...)
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	acct := eth.GetDefaultAccount()
+	assert.Nil(t, eth.UnlockAccount(acct))
+	to := common.HexToAddress(accountAddresses[1])
+
+	n := 10
+	txns := make(chan *types.Transaction, n)
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < n; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txn, err := eth.TransferEther(acct.Address, to, big.NewInt(1))
+			if assert.Nil(t, err) {
+				txns <- txn
+			}
+		}()
+	}
+	wg.Wait()
+	close(txns)
+	eth.Commit()
+
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cf()
+
+	seen := make(map[uint64]*types.Transaction)
+	for txn := range txns {
+		assert.Nil(t, seen[txn.Nonce()])
+		seen[txn.Nonce()] = txn
+		eth.Queue().QueueTransaction(ctx, txn)
+	}
+	assert.Equal(t, n, len(seen))
+
+	for _, txn := range seen {
+		rcpt, err := eth.Queue().WaitTransaction(ctx, txn)
+		if assert.Nil(t, err) {
+			assert.Equal(t, uint64(1), rcpt.Status)
+		}
+	}
+
+	// The queue reports the mined transactions
+	assert.Equal(t, 0, len(eth.Nonces().InFlight(acct.Address)))
+}
+
+func TestConcurrentContractTransactions(t *testing.T) {
+	eth, err := blockchain.NewEthereumSimulator(
+		"../assets/test/keys",
+		"../assets/test/passcodes.txt",
+		6,
+		1*time.Second,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		accountAddresses...)
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	acct := eth.GetDefaultAccount()
+	assert.Nil(t, eth.UnlockAccount(acct))
+	to := common.HexToAddress(accountAddresses[1])
+	c := eth.Contracts()
+	_, _, err = c.DeployContracts(context.Background(), acct)
+	assert.Nil(t, err)
+	eth.Commit()
+
+	// The options are shared by all the calls, each gets its own nonce
+	txnOpts, err := eth.GetTransactionOpts(context.Background(), acct)
+	assert.Nil(t, err)
+
+	n := 10
+	txns := make(chan *types.Transaction, n)
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < n; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txn, err := c.StakingToken().Transfer(txnOpts, to, big.NewInt(1))
+			if assert.Nil(t, err) {
+				txns <- txn
+			}
+		}()
+	}
+	wg.Wait()
+	close(txns)
+	eth.Commit()
+
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cf()
+
+	seen := make(map[uint64]*types.Transaction)
+	for txn := range txns {
+		assert.Nil(t, seen[txn.Nonce()])
+		seen[txn.Nonce()] = txn
+		eth.Queue().QueueTransaction(ctx, txn)
+	}
+	assert.Equal(t, n, len(seen))
+
+	for _, txn := range seen {
+		rcpt, err := eth.Queue().WaitTransaction(ctx, txn)
+		if assert.Nil(t, err) {
+			assert.Equal(t, uint64(1), rcpt.Status)
+		}
+	}
+	assert.Equal(t, 0, len(eth.Nonces().InFlight(acct.Address)))
+
+	// A transaction that couldn't be sent gives its nonce back right away.
+	// It can't be sent while one with a lower nonce is held back.
+	held, err := eth.Nonces().Reserve(ctx, acct.Address, func(nonce uint64) (*types.Transaction, error) {
+		return eth.SignTransaction(acct.Address, types.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(1), nil))
+	})
+	assert.Nil(t, err)
+
+	shortCtx, shortCf := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer shortCf()
+	failedOpts, err := eth.GetTransactionOpts(shortCtx, acct)
+	assert.Nil(t, err)
+	_, err = c.StakingToken().Transfer(failedOpts, to, big.NewInt(1))
+	assert.NotNil(t, err)
+	assert.Equal(t, map[uint64]common.Hash{held.Nonce(): held.Hash()}, eth.Nonces().InFlight(acct.Address))
+
+	eth.Nonces().SendFailed(acct.Address, held.Nonce(), held.Hash())
+	txn, err := c.StakingToken().Transfer(txnOpts, to, big.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, held.Nonce(), txn.Nonce())
+	assert.Equal(t, map[uint64]common.Hash{txn.Nonce(): txn.Hash()}, eth.Nonces().InFlight(acct.Address))
+}
@@ -90,6 +90,74 @@ func NewDkgState(account accounts.Account) *DkgState {
 	}
 }
 
+// dkgStateData is DkgState without its methods, so it can be marshalled
+// without recursing into them
+type dkgStateData DkgState
+
+// dkgStateJSON replaces the account of the state, whose URL can't be
+// unmarshalled when it's empty, and shadows the secrets of the local
+// validator so they're neither shown nor persisted
+type dkgStateJSON struct {
+	*dkgStateData
+	Account       common.Address
+	AccountScheme string
+	AccountPath   string
+
+	GroupPrivateKey     *big.Int   `json:",omitempty"`
+	PrivateCoefficients []*big.Int `json:",omitempty"`
+	SecretValue         *big.Int   `json:",omitempty"`
+	TransportPrivateKey *big.Int   `json:",omitempty"`
+}
+
+// MarshalJSON encodes the state without its lock or secrets
+func (state *DkgState) MarshalJSON() ([]byte, error) {
+	state.RLock()
+	defer state.RUnlock()
+
+	return json.Marshal(&dkgStateJSON{
+		dkgStateData:  (*dkgStateData)(state),
+		Account:       state.Account.Address,
+		AccountScheme: state.Account.URL.Scheme,
+		AccountPath:   state.Account.URL.Path})
+}
+
+// UnmarshalJSON decodes state encoded by MarshalJSON, which leaves the
+// secrets unset
+func (state *DkgState) UnmarshalJSON(raw []byte) error {
+	state.Lock()
+	defer state.Unlock()
+
+	data := &dkgStateJSON{dkgStateData: (*dkgStateData)(state)}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return err
+	}
+	state.Account = accounts.Account{
+		Address: data.Account,
+		URL:     accounts.URL{Scheme: data.AccountScheme, Path: data.AccountPath}}
+
+	return nil
+}
+
+// GobEncode encodes the state as JSON because gob rejects the lock. The
+// secrets aren't persisted, so a round in progress can't be resumed.
+func (state *DkgState) GobEncode() ([]byte, error) {
+	return state.MarshalJSON()
+}
+
+// GobDecode decodes state encoded by GobEncode
+func (state *DkgState) GobDecode(raw []byte) error {
+	return state.UnmarshalJSON(raw)
+}
+
+// InProgress is true from the opening of registration until the round
+// completes
+func (state *DkgState) InProgress() bool {
+	state.RLock()
+	defer state.RUnlock()
+
+	return state.RegistrationStart > 0 && !state.Complete
+}
+
 func (state *DkgState) PopulateSchedule(event *bindings.ETHDKGRegistrationOpen) {
 
 	state.RegistrationStart = event.DkgStarts.Uint64()
@@ -8,6 +8,7 @@ import (
 
 	"github.com/MadBase/MadNet/blockchain/interfaces"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // MonitorState contains info required to monitor Ethereum
@@ -28,9 +29,26 @@ type MonitorState struct {
 	Validators             map[uint32][]Validator
 	Schedule               interfaces.Schedule
 	EthDKG                 *DkgState
+	ProcessedBlocks        map[uint64]*ProcessedBlock
+	RepairNeeded           string // Why processing stopped until an operator repairs the node
 	// interestingBlocks      map[uint64]func(*MonitorState, uint64) error
 }
 
+// ProcessedBlock records the hash of a processed Ethereum block and the logs
+// that were acted on, so their effects can be undone if the block leaves the
+// canonical chain
+type ProcessedBlock struct {
+	Hash common.Hash
+	Logs []types.Log
+}
+
+// AccountNonces records the nonces an account used for transactions that
+// weren't seen mined yet
+type AccountNonces struct {
+	Next     uint64                 // Lowest nonce never handed out
+	InFlight map[uint64]common.Hash // Latest transaction sent with each nonce
+}
+
 // EthDKGPhase is used to indicate what phase we are currently in
 type EthDKGPhase int
 
@@ -154,6 +172,7 @@ func (s *MonitorState) Clone() *MonitorState {
 	ns.HighestEpochSeen = s.HighestEpochSeen
 	ns.InSync = s.InSync
 	ns.EthereumInSync = s.EthereumInSync
+	ns.RepairNeeded = s.RepairNeeded
 
 	return ns
 }
@@ -198,5 +217,9 @@ func (s *MonitorState) Diff(o *MonitorState) string {
 		d = append(d, fmt.Sprintf("CommunicationFailures: %v -> %v", s.CommunicationFailures, o.CommunicationFailures))
 	}
 
+	if s.RepairNeeded != o.RepairNeeded {
+		d = append(d, fmt.Sprintf("RepairNeeded: %q -> %q", s.RepairNeeded, o.RepairNeeded))
+	}
+
 	return strings.Join(d, ", ")
 }
@@ -5,11 +5,14 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,6 +57,41 @@ func TestBidirectionalJson(t *testing.T) {
 	assertStateMatch(t, ms2)
 }
 
+func TestDkgStateSecrets(t *testing.T) {
+
+	ms := createState()
+	ms.EthDKG = objects.NewDkgState(accounts.Account{Address: common.HexToAddress("0x546F99F244b7B58B855330AE0E2BC1b30b41302F")})
+	ms.EthDKG.Index = 3
+	ms.EthDKG.GroupPrivateKey = big.NewInt(11)
+	ms.EthDKG.PrivateCoefficients = []*big.Int{big.NewInt(12), big.NewInt(13)}
+	ms.EthDKG.SecretValue = big.NewInt(12)
+	ms.EthDKG.TransportPrivateKey = big.NewInt(14)
+
+	// Shown state leaves out the secrets
+	raw, err := json.Marshal(ms)
+	assert.Nil(t, err)
+	for _, name := range []string{"GroupPrivateKey", "PrivateCoefficients", "SecretValue", "TransportPrivateKey"} {
+		assert.NotContains(t, string(raw), name)
+	}
+
+	// And so does persisted state
+	buf := &bytes.Buffer{}
+	assert.Nil(t, gob.NewEncoder(buf).Encode(ms))
+	ms2 := &objects.MonitorState{}
+	assert.Nil(t, gob.NewDecoder(buf).Decode(ms2))
+
+	assertStateMatch(t, ms2)
+	assert.Equal(t, 3, ms2.EthDKG.Index)
+	assert.Equal(t, ms.EthDKG.Account.Address, ms2.EthDKG.Account.Address)
+	assert.Nil(t, ms2.EthDKG.GroupPrivateKey)
+	assert.Nil(t, ms2.EthDKG.PrivateCoefficients)
+	assert.Nil(t, ms2.EthDKG.SecretValue)
+	assert.Nil(t, ms2.EthDKG.TransportPrivateKey)
+
+	// The state being encoded keeps its secrets
+	assert.Equal(t, int64(12), ms.EthDKG.SecretValue.Int64())
+}
+
 func createState() *objects.MonitorState {
 
 	// task := &dumbTask{}
@@ -0,0 +1,73 @@
+package objects
+
+import (
+	"fmt"
+	"time"
+)
+
+// TaskStatus is where a task run is in its life
+type TaskStatus int
+
+// These are the valid statuses of a task run
+const (
+	TaskPending TaskStatus = iota
+	TaskRunning
+	TaskSucceeded
+	TaskDeadLetter
+)
+
+func (s TaskStatus) String() string {
+	return [...]string{
+		"Pending",
+		"Running",
+		"Succeeded",
+		"DeadLetter",
+	}[s]
+}
+
+// Finished is true once a task run won't be attempted again
+func (s TaskStatus) Finished() bool {
+	return s == TaskSucceeded || s == TaskDeadLetter
+}
+
+// MarshalText shows the status by name
+func (s TaskStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a status shown by MarshalText
+func (s *TaskStatus) UnmarshalText(raw []byte) error {
+	for status := TaskPending; status <= TaskDeadLetter; status++ {
+		if status.String() == string(raw) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown task status %q", raw)
+}
+
+// TaskAttempt records one attempt at running a task
+type TaskAttempt struct {
+	Started time.Time
+	Block   uint64 // Ethereum height when the attempt started, 0 if unknown
+	Err     string // Empty if the attempt succeeded
+}
+
+// TaskRun is the history of running a task under an idempotency key
+type TaskRun struct {
+	Key      string
+	Name     string // Type of the task
+	Status   TaskStatus
+	Reason   string    // Why the run was dead lettered
+	Deadline uint64    // Last Ethereum block an attempt may start in, 0 if none
+	Finished time.Time // When the run succeeded or was dead lettered
+	Attempts []TaskAttempt
+}
+
+// Clone returns a copy of the run
+func (r *TaskRun) Clone() *TaskRun {
+	run := *r
+	run.Attempts = make([]TaskAttempt, len(r.Attempts))
+	copy(run.Attempts, r.Attempts)
+	return &run
+}
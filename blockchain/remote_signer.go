@@ -0,0 +1,205 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Remote signer errors
+var (
+	ErrRemoteKey           = errors.New("key is held by the remote signer")
+	ErrRemoteAccount       = errors.New("remote signer does not sign for account")
+	ErrRemoteTransaction   = errors.New("remote signer returned a different transaction")
+	ErrRemoteGasPrice      = errors.New("remote signer raised the gas price above the cap")
+	ErrUnknownRemoteAPI    = errors.New("unknown remote signer api")
+	ErrRemoteEmptyResponse = errors.New("remote signer returned no transaction")
+)
+
+// RemoteSignerAPI is the JSON-RPC dialect spoken by a remote signer
+type RemoteSignerAPI int
+
+// These are the supported remote signers
+const (
+	ClefAPI       RemoteSignerAPI = iota // account_list and account_signTransaction
+	Web3SignerAPI                        // eth_accounts and eth_signTransaction
+)
+
+// ParseRemoteSignerAPI finds the api of a remote signer by name
+func ParseRemoteSignerAPI(name string) (RemoteSignerAPI, error) {
+	switch name {
+	case "clef":
+		return ClefAPI, nil
+	case "web3signer":
+		return Web3SignerAPI, nil
+	}
+	return 0, ErrUnknownRemoteAPI
+}
+
+// remoteTxArgs are the transaction fields sent to a remote signer
+type remoteTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     hexutil.Bytes   `json:"data"`
+	ChainID  *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+// RemoteSigner has transactions signed by a signer over JSON-RPC, such as clef
+// or web3signer, so keys are never on this node. Validators can't use it, they
+// sign consensus messages with the key of their Ethereum account.
+type RemoteSigner struct {
+	client      *rpc.Client
+	api         RemoteSignerAPI
+	timeout     time.Duration
+	maxGasPrice *big.Int
+}
+
+// NewRemoteSigner connects to the signer at url, calls time out after timeout.
+// The signer may not raise the gas price above maxGasPrice, a nil or zero
+// maxGasPrice is no cap.
+func NewRemoteSigner(url string, api RemoteSignerAPI, timeout time.Duration, maxGasPrice *big.Int) (*RemoteSigner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxGasPrice != nil && maxGasPrice.Sign() <= 0 {
+		maxGasPrice = nil
+	}
+
+	return &RemoteSigner{client: client, api: api, timeout: timeout, maxGasPrice: maxGasPrice}, nil
+}
+
+// Accounts lists the accounts the remote signer signs for
+func (s *RemoteSigner) Accounts(ctx context.Context) ([]accounts.Account, error) {
+	method := "account_list"
+	if s.api == Web3SignerAPI {
+		method = "eth_accounts"
+	}
+
+	addresses := []common.Address{}
+	if err := s.client.CallContext(ctx, &addresses, method); err != nil {
+		return nil, err
+	}
+
+	accts := make([]accounts.Account, len(addresses))
+	for idx, addr := range addresses {
+		accts[idx] = accounts.Account{Address: addr}
+	}
+	return accts, nil
+}
+
+// Unlock checks that the remote signer signs for acct, it's unlocked there
+func (s *RemoteSigner) Unlock(acct accounts.Account) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	accts, err := s.Accounts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range accts {
+		if a.Address == acct.Address {
+			return nil
+		}
+	}
+	return ErrRemoteAccount
+}
+
+// Key is never available, the remote signer holds it, so the account can't
+// sign consensus messages
+func (s *RemoteSigner) Key(addr common.Address) (*keystore.Key, error) {
+	return nil, ErrRemoteKey
+}
+
+// SignTx has the remote signer sign txn. The signed transaction must match
+// txn except for the gas price, which the signer may change up to the cap.
+func (s *RemoteSigner) SignTx(ctx context.Context, acct accounts.Account, txn *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := remoteTxArgs{
+		From:     acct.Address,
+		To:       txn.To(),
+		Gas:      hexutil.Uint64(txn.Gas()),
+		GasPrice: (*hexutil.Big)(txn.GasPrice()),
+		Value:    (*hexutil.Big)(txn.Value()),
+		Nonce:    hexutil.Uint64(txn.Nonce()),
+		Data:     txn.Data()}
+
+	// Web3signer signs for the chain it's configured with
+	method := "account_signTransaction"
+	if s.api == Web3SignerAPI {
+		method = "eth_signTransaction"
+	} else {
+		args.ChainID = (*hexutil.Big)(chainID)
+	}
+
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, method, args); err != nil {
+		return nil, err
+	}
+
+	// Clef returns the raw transaction with its decoded form, web3signer only
+	// the raw transaction
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err != nil {
+		response := struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}{}
+		if err := json.Unmarshal(result, &response); err != nil {
+			return nil, err
+		}
+		raw = response.Raw
+	}
+	if len(raw) == 0 {
+		return nil, ErrRemoteEmptyResponse
+	}
+
+	signed := &types.Transaction{}
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+
+	// A signer may change the gas price, nothing else
+	from, err := types.Sender(types.NewEIP155Signer(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover sender of signed transaction: %v", err)
+	}
+	if from != acct.Address || signed.Nonce() != txn.Nonce() || signed.Gas() != txn.Gas() ||
+		signed.Value().Cmp(txn.Value()) != 0 || !sameRecipient(signed.To(), txn.To()) ||
+		string(signed.Data()) != string(txn.Data()) {
+		return nil, ErrRemoteTransaction
+	}
+	if s.maxGasPrice != nil && signed.GasPrice().Cmp(s.maxGasPrice) > 0 {
+		return nil, ErrRemoteGasPrice
+	}
+
+	return signed, nil
+}
+
+// Close disconnects from the remote signer
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+func sameRecipient(a *common.Address, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,170 @@
+package blockchain
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/MadBase/MadNet/logging"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/peterh/liner"
+)
+
+// Signer signs the transactions of accounts, which may be done without their
+// keys being on this node
+type Signer interface {
+	// Accounts lists the accounts the signer can sign for
+	Accounts(ctx context.Context) ([]accounts.Account, error)
+	// Unlock prepares the signer to sign for acct
+	Unlock(acct accounts.Account) error
+	// Key returns the key of an unlocked account, if the signer holds it
+	Key(addr common.Address) (*keystore.Key, error)
+	// SignTx signs txn for acct on chain chainID
+	SignTx(ctx context.Context, acct accounts.Account, txn *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// PasscodeSource returns the passcode decrypting the keystore file of an account
+type PasscodeSource func(addr common.Address) (string, error)
+
+// PasscodeFile loads passcodes from a file of address=passcode lines
+func PasscodeFile(filePath string) (PasscodeSource, error) {
+	logger := logging.GetLogger("ethereum")
+
+	logger.Infof("LoadPasscodes(\"%v\")...", filePath)
+	passcodes := make(map[common.Address]string)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		logger.Errorf("Failed to open passcode file \"%v\": %s", filePath, err)
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") {
+			components := strings.Split(line, "=")
+			if len(components) == 2 {
+				address := strings.TrimSpace(components[0])
+				passcode := strings.TrimSpace(components[1])
+
+				passcodes[common.HexToAddress(address)] = passcode
+			}
+		}
+	}
+
+	return func(addr common.Address) (string, error) {
+		passcode, present := passcodes[addr]
+		if !present {
+			return "", ErrPasscodeNotFound
+		}
+		return passcode, nil
+	}, nil
+}
+
+// PasscodeEnv reads the passcode of every account from an environment variable
+func PasscodeEnv(name string) PasscodeSource {
+	return func(addr common.Address) (string, error) {
+		passcode, present := os.LookupEnv(name)
+		if !present {
+			return "", ErrPasscodeNotFound
+		}
+		return passcode, nil
+	}
+}
+
+// PasscodePrompt asks for passcodes on the terminal, or reads them from
+// standard input if it isn't one
+func PasscodePrompt() PasscodeSource {
+	return func(addr common.Address) (string, error) {
+		line := liner.NewLiner()
+		defer line.Close()
+
+		return line.PasswordPrompt(fmt.Sprintf("Passcode for %v: ", addr.Hex()))
+	}
+}
+
+// KeystoreSigner signs with keys decrypted from keystore files
+type KeystoreSigner struct {
+	mu       sync.RWMutex // Not embedded, Unlock unlocks accounts
+	keystore *keystore.KeyStore
+	passcode PasscodeSource
+	keys     map[common.Address]*keystore.Key
+}
+
+// NewKeystoreSigner creates a signer for the accounts in a keystore directory,
+// decrypted with passcodes from passcode
+func NewKeystoreSigner(directoryPath string, passcode PasscodeSource) *KeystoreSigner {
+	logging.GetLogger("ethereum").Infof("LoadAccounts(\"%v\")...", directoryPath)
+
+	return &KeystoreSigner{
+		keystore: keystore.NewKeyStore(directoryPath, keystore.StandardScryptN, keystore.StandardScryptP),
+		passcode: passcode,
+		keys:     make(map[common.Address]*keystore.Key)}
+}
+
+// Accounts lists the accounts found in the keystore directory
+func (s *KeystoreSigner) Accounts(ctx context.Context) ([]accounts.Account, error) {
+	accts := []accounts.Account{}
+	for _, wallet := range s.keystore.Wallets() {
+		accts = append(accts, wallet.Accounts()...)
+	}
+	return accts, nil
+}
+
+// Unlock decrypts the key of acct
+func (s *KeystoreSigner) Unlock(acct accounts.Account) error {
+
+	passcode, err := s.passcode(acct.Address)
+	if err != nil {
+		return err
+	}
+
+	// Open the account key file
+	keyJSON, err := ioutil.ReadFile(acct.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	// Get the private key
+	key, err := keystore.DecryptKey(keyJSON, passcode)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[acct.Address] = key
+
+	return nil
+}
+
+// Key returns the key of an unlocked account
+func (s *KeystoreSigner) Key(addr common.Address) (*keystore.Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if key, ok := s.keys[addr]; ok {
+		return key, nil
+	}
+	return nil, ErrKeysNotFound
+}
+
+// SignTx signs txn with the key of an unlocked account
+func (s *KeystoreSigner) SignTx(ctx context.Context, acct accounts.Account, txn *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key, err := s.Key(acct.Address)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(txn, types.NewEIP155Signer(chainID), key.PrivateKey)
+}
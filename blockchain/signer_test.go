@@ -0,0 +1,213 @@
+package blockchain_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// standInSigner answers like clef and web3signer for a single key
+type standInSigner struct {
+	key      *ecdsa.PrivateKey
+	chainID  *big.Int
+	tamper   bool
+	gas      uint64   // Replaces the gas asked for if not zero
+	gasPrice *big.Int // Replaces the gas price asked for if not nil
+}
+
+type standInTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     hexutil.Bytes   `json:"data"`
+}
+
+func (s *standInSigner) sign(args standInTxArgs) (hexutil.Bytes, error) {
+	nonce := uint64(args.Nonce)
+	if s.tamper {
+		nonce++
+	}
+	gas := uint64(args.Gas)
+	if s.gas != 0 {
+		gas = s.gas
+	}
+	gasPrice := (*big.Int)(args.GasPrice)
+	if s.gasPrice != nil {
+		gasPrice = s.gasPrice
+	}
+	txn := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       args.To,
+		Gas:      gas,
+		GasPrice: gasPrice,
+		Value:    (*big.Int)(args.Value),
+		Data:     args.Data})
+	signed, err := types.SignTx(txn, types.NewEIP155Signer(s.chainID), s.key)
+	if err != nil {
+		return nil, err
+	}
+	return signed.MarshalBinary()
+}
+
+type clefAPI struct{ s *standInSigner }
+
+func (api *clefAPI) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(api.s.key.PublicKey)}
+}
+
+func (api *clefAPI) SignTransaction(args standInTxArgs) (map[string]interface{}, error) {
+	raw, err := api.s.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": raw, "tx": args}, nil
+}
+
+type web3SignerAPI struct{ s *standInSigner }
+
+func (api *web3SignerAPI) Accounts() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(api.s.key.PublicKey)}
+}
+
+func (api *web3SignerAPI) SignTransaction(args standInTxArgs) (hexutil.Bytes, error) {
+	return api.s.sign(args)
+}
+
+func newStandInSigner(t *testing.T) (*standInSigner, string) {
+	key, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	s := &standInSigner{key: key, chainID: big.NewInt(1337)}
+
+	srv := rpc.NewServer()
+	assert.Nil(t, srv.RegisterName("account", &clefAPI{s}))
+	assert.Nil(t, srv.RegisterName("eth", &web3SignerAPI{s}))
+	server := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		server.Close()
+		srv.Stop()
+	})
+	return s, server.URL
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	addr := common.HexToAddress("0x546F99F244b7B58B855330AE0E2BC1b30b41302F")
+	chainID := big.NewInt(1337)
+	ctx := context.Background()
+
+	os.Setenv("TEST_KEYSTORE_SIGNER_PASSCODE", "abc123")
+	defer os.Unsetenv("TEST_KEYSTORE_SIGNER_PASSCODE")
+	signer := blockchain.NewKeystoreSigner("../assets/test/keys", blockchain.PasscodeEnv("TEST_KEYSTORE_SIGNER_PASSCODE"))
+
+	accts, err := signer.Accounts(ctx)
+	assert.Nil(t, err)
+	var acct accounts.Account
+	for _, a := range accts {
+		if a.Address == addr {
+			acct = a
+		}
+	}
+	assert.Equal(t, addr, acct.Address)
+
+	// Nothing is signed before the account is unlocked
+	txn := types.NewTransaction(0, addr, big.NewInt(1), 21000, big.NewInt(1), nil)
+	_, err = signer.SignTx(ctx, acct, txn, chainID)
+	assert.Equal(t, blockchain.ErrKeysNotFound, err)
+
+	assert.Nil(t, signer.Unlock(acct))
+	key, err := signer.Key(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, addr, key.Address)
+
+	signed, err := signer.SignTx(ctx, acct, txn, chainID)
+	assert.Nil(t, err)
+	from, err := types.Sender(types.NewEIP155Signer(chainID), signed)
+	assert.Nil(t, err)
+	assert.Equal(t, addr, from)
+
+	// A wrong passcode doesn't decrypt the key
+	os.Setenv("TEST_KEYSTORE_SIGNER_PASSCODE", "abc124")
+	assert.NotNil(t, blockchain.NewKeystoreSigner("../assets/test/keys", blockchain.PasscodeEnv("TEST_KEYSTORE_SIGNER_PASSCODE")).Unlock(acct))
+
+	os.Unsetenv("TEST_KEYSTORE_SIGNER_PASSCODE")
+	assert.Equal(t, blockchain.ErrPasscodeNotFound,
+		blockchain.NewKeystoreSigner("../assets/test/keys", blockchain.PasscodeEnv("TEST_KEYSTORE_SIGNER_PASSCODE")).Unlock(acct))
+}
+
+func TestRemoteSigner(t *testing.T) {
+	standIn, url := newStandInSigner(t)
+	addr := crypto.PubkeyToAddress(standIn.key.PublicKey)
+	chainID := big.NewInt(1337)
+	ctx := context.Background()
+
+	for _, api := range []blockchain.RemoteSignerAPI{blockchain.ClefAPI, blockchain.Web3SignerAPI} {
+		standIn.tamper = false
+
+		signer, err := blockchain.NewRemoteSigner(url, api, time.Second, big.NewInt(10))
+		assert.Nil(t, err)
+
+		accts, err := signer.Accounts(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(accts))
+		assert.Equal(t, addr, accts[0].Address)
+
+		acct := accounts.Account{Address: addr}
+		assert.Nil(t, signer.Unlock(acct))
+		assert.Equal(t, blockchain.ErrRemoteAccount, signer.Unlock(accounts.Account{Address: common.HexToAddress("0x01")}))
+
+		_, err = signer.Key(addr)
+		assert.Equal(t, blockchain.ErrRemoteKey, err)
+
+		to := common.HexToAddress("0x9AC1c9afBAec85278679fF75Ef109217f26b1417")
+		txn := types.NewTransaction(7, to, big.NewInt(11), 21000, big.NewInt(1), []byte{1, 2, 3})
+		signed, err := signer.SignTx(ctx, acct, txn, chainID)
+		assert.Nil(t, err)
+		assert.Equal(t, txn.Hash(), types.NewTx(&types.LegacyTx{
+			Nonce: signed.Nonce(), To: signed.To(), Gas: signed.Gas(), GasPrice: signed.GasPrice(),
+			Value: signed.Value(), Data: signed.Data()}).Hash())
+		from, err := types.Sender(types.NewEIP155Signer(chainID), signed)
+		assert.Nil(t, err)
+		assert.Equal(t, addr, from)
+
+		// A signer may change the gas price up to the cap
+		standIn.gasPrice = big.NewInt(10)
+		signed, err = signer.SignTx(ctx, acct, txn, chainID)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), signed.GasPrice().Int64())
+
+		standIn.gasPrice = big.NewInt(11)
+		_, err = signer.SignTx(ctx, acct, txn, chainID)
+		assert.Equal(t, blockchain.ErrRemoteGasPrice, err)
+		standIn.gasPrice = nil
+
+		// A signer must not sign anything but what it was asked to
+		standIn.gas = 30000
+		_, err = signer.SignTx(ctx, acct, txn, chainID)
+		assert.Equal(t, blockchain.ErrRemoteTransaction, err)
+		standIn.gas = 0
+
+		standIn.tamper = true
+		_, err = signer.SignTx(ctx, acct, txn, chainID)
+		assert.Equal(t, blockchain.ErrRemoteTransaction, err)
+
+		signer.Close()
+	}
+
+	_, err := blockchain.ParseRemoteSignerAPI("ledger")
+	assert.Equal(t, blockchain.ErrUnknownRemoteAPI, err)
+}
@@ -1,110 +1,323 @@
 package tasks
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/constants"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sirupsen/logrus"
 )
 
-// SnapshotTask pushes a snapshot to Ethereum
+// ErrMissingReceipt is returned if the snapshot was sent but no receipt came back
+var ErrMissingReceipt = errors.New("missing snapshot receipt")
+
+// SnapshotTask pushes a snapshot to Ethereum. Validators take turns of
+// SnapshotSlotBlocks Ethereum blocks, counted from the height where the
+// previous snapshot was stored, and only submit if no snapshot was taken when
+// their turn comes up.
 type SnapshotTask struct {
 	sync.Mutex
 	acct        accounts.Account
 	epoch       *big.Int
-	eth         interfaces.Ethereum
-	logger      *logrus.Logger
+	blockHash   []byte
 	rawBclaims  []byte
 	rawSigGroup []byte
+	slot        int
+	turns       int    // how many slots there are before they come around again
+	startHeight uint64 // Ethereum height the turns are counted from
+	firstBlock  uint64 // Ethereum height before which there are no snapshots
+	scanFrom    uint64 // Ethereum height SnapshotTaken events are looked for from
+	taken       bool
 }
 
-// NewSnapshotTask creates a new task
-func NewSnapshotTask(acct accounts.Account, logger *logrus.Logger, eth interfaces.Ethereum, epoch *big.Int, rawBclaims []byte, rawSigGroup []byte) *SnapshotTask {
+// NewSnapshotTask creates a new task, blockHash orders the validators. No
+// snapshot was taken before the Ethereum block firstBlock.
+func NewSnapshotTask(acct accounts.Account, epoch *big.Int, blockHash []byte, rawBclaims []byte, rawSigGroup []byte, firstBlock uint64) *SnapshotTask {
 	return &SnapshotTask{
 		acct:        acct,
 		epoch:       epoch,
-		eth:         eth,
-		logger:      logger,
+		blockHash:   blockHash,
 		rawBclaims:  rawBclaims,
 		rawSigGroup: rawSigGroup,
+		firstBlock:  firstBlock,
 	}
 }
 
-// DoWork is the first attempt at distributing shares via ethdkg
-func (t *SnapshotTask) DoWork(ctx context.Context) bool {
-	t.logger.Info("DoWork() ...")
-	return t.doTask(ctx)
+// SnapshotSlot is the turn of acct at submitting the snapshot of a block. Every
+// validator ranks the validator set by hashing each address with the block
+// hash, so they agree on the order without talking to each other. Accounts
+// outside the set go after every validator.
+func SnapshotSlot(blockHash []byte, validators []common.Address, acct common.Address) int {
+	ranked := make([]common.Address, len(validators))
+	copy(ranked, validators)
+
+	rank := func(addr common.Address) []byte {
+		return crypto.Keccak256(blockHash, addr.Bytes())
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return bytes.Compare(rank(ranked[i]), rank(ranked[j])) < 0
+	})
+
+	for idx, addr := range ranked {
+		if addr == acct {
+			return idx
+		}
+	}
+	return len(ranked)
 }
 
-// DoRetry is subsequent attempts at distributing shares via ethdkg
-func (t *SnapshotTask) DoRetry(ctx context.Context) bool {
-	t.logger.Info("DoRetry() ...")
-	return t.doTask(ctx)
+// SnapshotTurn tells if it's the turn of slot at the given Ethereum height.
+// Turns start at startHeight, last SnapshotSlotBlocks each and come around
+// again after every slot had one, so that validators that disagree on the
+// current height still agree on whose turn it is.
+func SnapshotTurn(startHeight uint64, height uint64, slot int, turns int) bool {
+	if height < startHeight || turns < 1 {
+		return false
+	}
+	turn := (height - startHeight) / constants.SnapshotSlotBlocks
+	return turn%uint64(turns) == uint64(slot)
 }
 
-func (t *SnapshotTask) doTask(ctx context.Context) bool {
+// Initialize finds our slot and when it comes up
+func (t *SnapshotTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	t.Lock()
+	defer t.Unlock()
+
+	taken, err := t.snapshotStored(ctx, eth)
+	if err != nil {
+		return fmt.Errorf("could not check snapshot of epoch %v: %v", t.epoch, err)
+	}
+	if taken {
+		t.taken = true
+		return nil
+	}
+
+	validators, err := eth.GetValidators(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get validators: %v", err)
+	}
+
+	// Every validator reads the same height off the previous snapshot
+	startHeight := uint64(0)
+	if t.epoch.Cmp(big.NewInt(1)) > 0 {
+		c := eth.Contracts()
+		callOpts := eth.GetCallOpts(ctx, t.acct)
+		height, err := c.Validators().GetHeightFromSnapshot(callOpts, new(big.Int).Sub(t.epoch, big.NewInt(1)))
+		if err != nil {
+			return fmt.Errorf("could not get height of previous snapshot: %v", err)
+		}
+		startHeight = uint64(height)
+	}
+
+	t.slot = SnapshotSlot(t.blockHash, validators, t.acct.Address)
+	t.turns = len(validators)
+	if t.slot >= t.turns {
+		t.turns = t.slot + 1
+	}
+	t.startHeight = startHeight
+
+	// The first epoch has no previous snapshot to count turns from, but there
+	// is no point looking for its event before the first block
+	t.scanFrom = startHeight
+	if t.scanFrom < t.firstBlock {
+		t.scanFrom = t.firstBlock
+	}
+
+	logger.Infof("slot %v of %v validators, turns counted from height %v", t.slot, len(validators), t.startHeight)
+
+	return nil
+}
+
+// DoWork is the first attempt at submitting the snapshot
+func (t *SnapshotTask) DoWork(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	logger.Info("DoWork() ...")
+	return t.doTask(ctx, logger, eth)
+}
+
+// DoRetry is subsequent attempts at submitting the snapshot
+func (t *SnapshotTask) DoRetry(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	logger.Info("DoRetry() ...")
+	return t.doTask(ctx, logger, eth)
+}
+
+func (t *SnapshotTask) doTask(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
 
 	t.Lock()
 	defer t.Unlock()
 
-	c := t.eth.Contracts()
+	// Validators in earlier slots get a chance to submit first
+	taken, err := t.waitForSlot(ctx, logger, eth)
+	if err != nil {
+		return err
+	}
+	if taken {
+		t.taken = true
+		return nil
+	}
+
+	c := eth.Contracts()
 
 	// Do the mechanics
-	txnOpts, err := t.eth.GetTransactionOpts(ctx, t.acct)
+	sendCtx, sendCancel := context.WithTimeout(ctx, eth.Timeout())
+	defer sendCancel()
+
+	txnOpts, err := eth.GetTransactionOpts(sendCtx, t.acct)
 	if err != nil {
-		t.logger.Errorf("Could not create transaction for snapshot: %v", err)
-		return false
+		return fmt.Errorf("could not create transaction for snapshot: %v", err)
 	}
 
 	txn, err := c.Validators().Snapshot(txnOpts, t.rawSigGroup, t.rawBclaims)
 	if err != nil {
-		t.logger.Errorf("Failed to take snapshot: %v", err)
-		return false
+		// The validator before us may have made it right at the end of its turn
+		if taken, _ := t.snapshotStored(sendCtx, eth); taken {
+			logger.Infof("snapshot of epoch %v was taken while submitting", t.epoch)
+			t.taken = true
+			return nil
+		}
+		return fmt.Errorf("failed to take snapshot: %v", err)
 	}
-	t.eth.Queue().QueueTransaction(ctx, txn)
 
-	receipt, err := t.eth.Queue().WaitTransaction(ctx, txn)
+	toCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	receipt, err := eth.Queue().QueueAndWait(toCtx, txn)
 	if err != nil {
-		t.logger.Errorf("Failed to retrieve snapshot receipt: %v", err)
-		return false
+		return fmt.Errorf("failed to retrieve snapshot receipt: %v", err)
 	}
 
 	if receipt == nil {
-		t.logger.Error("missing snapshot receipt")
-		return false
+		return ErrMissingReceipt
 	}
 
 	// Check receipt to confirm we were successful
 	if receipt.Status != uint64(1) {
-		t.logger.Errorf("snapshot status (%v) indicates failure: %v", receipt.Status, receipt.Logs)
-		return false
+		return fmt.Errorf("snapshot status (%v) indicates failure: %v", receipt.Status, receipt.Logs)
 	}
 
-	return true
+	return nil
 }
 
-// ShouldRetry checks if it makes sense to try again
-func (t *SnapshotTask) ShouldRetry(ctx context.Context) bool {
-	t.Lock()
-	defer t.Unlock()
+// waitForSlot waits until our slot comes up, watching for a SnapshotTaken
+// event of the epoch in the meantime. A snapshot transaction that is pending,
+// ours from an earlier attempt or one of a validator before us, is waited for
+// rather than submitted again. It returns true if the snapshot was taken.
+func (t *SnapshotTask) waitForSlot(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) (bool, error) {
+	if t.taken {
+		return true, nil
+	}
+
+	for {
+		taken, err := t.snapshotTaken(ctx, logger, eth)
+		if err != nil {
+			logger.Warnf("could not look for snapshot of epoch %v: %v", t.epoch, err)
+		} else if taken {
+			return true, nil
+		}
+
+		height, err := eth.GetCurrentHeight(ctx)
+		if err != nil {
+			logger.Warnf("could not get current height: %v", err)
+		} else if SnapshotTurn(t.startHeight, height, t.slot, t.turns) {
+			// Not every endpoint has a pending state, so submit if unsure
+			pending, err := t.snapshotPending(ctx, eth)
+			if err != nil {
+				logger.Warnf("could not look for pending snapshot of epoch %v: %v", t.epoch, err)
+			}
+			if !pending {
+				return false, nil
+			}
+			logger.Infof("snapshot of epoch %v is pending, waiting for it to be mined", t.epoch)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(eth.RetryDelay()):
+		}
+	}
+}
+
+// snapshotTaken looks for a SnapshotTaken event of the epoch since the turns
+// started
+func (t *SnapshotTask) snapshotTaken(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) (bool, error) {
+	c := eth.Contracts()
+
+	iter, err := c.Validators().FilterSnapshotTaken(&bind.FilterOpts{Start: t.scanFrom, Context: ctx}, []*big.Int{t.epoch}, nil)
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+
+	if iter.Next() {
+		logger.Infof("snapshot of epoch %v taken by %v at height %v", t.epoch, iter.Event.Validator.Hex(), iter.Event.Raw.BlockNumber)
+		return true, nil
+	}
+	return false, iter.Error()
+}
 
-	c := t.eth.Contracts()
+// snapshotStored checks if the contract has a snapshot of the epoch
+func (t *SnapshotTask) snapshotStored(ctx context.Context, eth interfaces.Ethereum) (bool, error) {
+	c := eth.Contracts()
 
-	callOpts := t.eth.GetCallOpts(ctx, t.acct)
+	callOpts := eth.GetCallOpts(ctx, t.acct)
 	height, err := c.Validators().GetHeightFromSnapshot(callOpts, t.epoch)
 	if err != nil {
+		return false, err
+	}
+	return height != 0, nil
+}
+
+// snapshotPending checks if the pending state has a snapshot of the epoch,
+// which means a snapshot transaction waits to be mined
+func (t *SnapshotTask) snapshotPending(ctx context.Context, eth interfaces.Ethereum) (bool, error) {
+	c := eth.Contracts()
+
+	callOpts := eth.GetCallOpts(ctx, t.acct)
+	callOpts.Pending = true
+	height, err := c.Validators().GetHeightFromSnapshot(callOpts, t.epoch)
+	if err != nil {
+		return false, err
+	}
+	return height != 0, nil
+}
+
+// ShouldRetry checks if the snapshot still has to be taken
+func (t *SnapshotTask) ShouldRetry(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	taken, err := t.snapshotStored(ctx, eth)
+	if err != nil {
+		// This probably means an endpoint issue, so we have to try again
+		logger.Warnf("could not check snapshot of epoch %v: %v", t.epoch, err)
 		return true
 	}
 
-	t.logger.Infof("height of epoch %v: %v", t.epoch, height)
+	logger.Infof("snapshot of epoch %v taken: %v", t.epoch, taken)
 
-	return false // TODO check to see if snapshot exists
+	// Someone else already took the snapshot
+	return !taken
 }
 
 // DoDone creates a log entry saying task is complete
-func (t *SnapshotTask) DoDone() {
-	t.logger.Infof("done")
+func (t *SnapshotTask) DoDone(logger *logrus.Entry) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.taken {
+		logger.Infof("done, snapshot was taken by another validator")
+		return
+	}
+	logger.Infof("done")
 }
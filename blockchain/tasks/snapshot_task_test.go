@@ -0,0 +1,212 @@
+package tasks_test
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/blockchain/tasks"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotSlot(t *testing.T) {
+	validators := []common.Address{
+		common.HexToAddress("0x546F99F244b7B58B855330AE0E2BC1b30b41302F"),
+		common.HexToAddress("0x9AC1c9afBAec85278679fF75Ef109217f26b1417"),
+		common.HexToAddress("0x26D3D8Ab74D62C26f1ACc220dA1646411c9880Ac"),
+		common.HexToAddress("0x615695C4a4D6a60830e5fca4901FbA099DF26271"),
+	}
+	reversed := []common.Address{validators[3], validators[2], validators[1], validators[0]}
+	blockHash := crypto.Keccak256([]byte("block"))
+
+	// Every validator gets its own slot, whatever order the set is in
+	slots := make(map[int]bool)
+	for _, validator := range validators {
+		slot := tasks.SnapshotSlot(blockHash, validators, validator)
+		assert.Equal(t, slot, tasks.SnapshotSlot(blockHash, reversed, validator))
+		assert.True(t, slot >= 0 && slot < len(validators))
+		slots[slot] = true
+	}
+	assert.Equal(t, len(validators), len(slots))
+
+	// The first slot moves around between blocks
+	first := make(map[common.Address]bool)
+	for i := 0; i < 32; i++ {
+		blockHash := crypto.Keccak256([]byte{byte(i)})
+		for _, validator := range validators {
+			if tasks.SnapshotSlot(blockHash, validators, validator) == 0 {
+				first[validator] = true
+			}
+		}
+	}
+	assert.True(t, len(first) > 1)
+
+	// Accounts outside the set go last
+	outsider := common.HexToAddress("0x01")
+	assert.Equal(t, len(validators), tasks.SnapshotSlot(blockHash, validators, outsider))
+}
+
+func TestSnapshotTurn(t *testing.T) {
+	// Validators seeing different heights agree on whose turn it is
+	for height := uint64(100); height < 100+3*constants.SnapshotSlotBlocks*4; height++ {
+		turns := 0
+		for slot := 0; slot < 3; slot++ {
+			if tasks.SnapshotTurn(100, height, slot, 3) {
+				turns++
+			}
+		}
+		assert.Equal(t, 1, turns)
+	}
+
+	assert.True(t, tasks.SnapshotTurn(100, 100, 0, 3))
+	assert.True(t, tasks.SnapshotTurn(100, 100+constants.SnapshotSlotBlocks, 1, 3))
+	assert.True(t, tasks.SnapshotTurn(100, 100+3*constants.SnapshotSlotBlocks, 0, 3))
+	assert.False(t, tasks.SnapshotTurn(100, 99, 0, 3))
+}
+
+const rawSnapshotHeader = "" +
+	"000000000000030008000000010004005900000002060000b500000002000000" +
+	"2a000000004000000d0000000201000019000000020100002500000002010000" +
+	"31000000020100007e06a605256de00205be97e3db46a7179d10baa270991a68" +
+	"82adff2b3ca99d37c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b" +
+	"7bfad8045d85a470000000000000000000000000000000000000000000000000" +
+	"00000000000000007682aa2f2a0cacceb6abbb88b081b76481dd2704ceb42194" +
+	"bb4d7aa8e41759110a1673b5fb0848a5fea6fb60aa3d013df90d1797f8b5511c" +
+	"242f1c4060cbf32512443fa842e474f906eb7aedbff7a2a20818b277ef9e9fed" +
+	"bae4d4012cdd476021b1d4a7f125e9199e945f602942928ccebfe5f76822bce2" +
+	"c25b05da413cf9431097b5fc8ed39f381362375f1de1680cdd0525c59a76959b" +
+	"b91deac7590ecdd12686f605b19f284323f20d30a2b1aa5333f7471acc3787a1" +
+	"c9b24fed41717ba612f6f612c92fdee07fd6636ed067a0262971ace406b1242a" +
+	"7c41397d34b642ed"
+
+// setupValidators deploys the contracts and registers every account but the
+// default one as a validator
+func setupValidators(t *testing.T, eth interfaces.Ethereum, addresses []string) []accounts.Account {
+	ctx := context.Background()
+	c := eth.Contracts()
+
+	owner := eth.GetDefaultAccount()
+	assert.Nil(t, eth.UnlockAccount(owner))
+	_, _, err := c.DeployContracts(ctx, owner)
+	assert.Nil(t, err)
+
+	txnOpts, err := eth.GetTransactionOpts(ctx, owner)
+	assert.Nil(t, err)
+
+	validators := []accounts.Account{}
+	for idx := 1; idx < len(addresses); idx++ {
+		acct, err := eth.GetAccount(common.HexToAddress(addresses[idx]))
+		assert.Nil(t, err)
+		assert.Nil(t, eth.UnlockAccount(acct))
+		validators = append(validators, acct)
+
+		txn, err := c.StakingToken().Transfer(txnOpts, acct.Address, big.NewInt(10_000_000))
+		assert.Nil(t, err)
+		eth.Queue().QueueGroupTransaction(ctx, 1, txn)
+
+		o, err := eth.GetTransactionOpts(ctx, acct)
+		assert.Nil(t, err)
+
+		txn, err = c.StakingToken().Approve(o, c.ValidatorsAddress(), big.NewInt(10_000_000))
+		assert.Nil(t, err)
+		eth.Queue().QueueGroupTransaction(ctx, 1, txn)
+
+		txn, err = c.Staking().LockStake(o, big.NewInt(1_000_000))
+		assert.Nil(t, err)
+		eth.Queue().QueueGroupTransaction(ctx, 1, txn)
+
+		madID := [2]*big.Int{big.NewInt(int64(idx)), big.NewInt(int64(idx * 2))}
+		txn, err = c.Validators().AddValidator(o, acct.Address, madID)
+		assert.Nil(t, err)
+		eth.Queue().QueueGroupTransaction(ctx, 1, txn)
+	}
+
+	rcpts, err := eth.Queue().WaitGroupTransactions(ctx, 1)
+	assert.Nil(t, err)
+	for _, rcpt := range rcpts {
+		assert.Equal(t, uint64(1), rcpt.Status)
+	}
+
+	return validators
+}
+
+func TestSnapshotTaskTakesTurns(t *testing.T) {
+	addresses := []string{
+		"0x546F99F244b7B58B855330AE0E2BC1b30b41302F", "0x9AC1c9afBAec85278679fF75Ef109217f26b1417",
+		"0x26D3D8Ab74D62C26f1ACc220dA1646411c9880Ac", "0x615695C4a4D6a60830e5fca4901FbA099DF26271"}
+
+	eth, err := blockchain.NewEthereumSimulator(
+		"../../assets/test/keys",
+		"../../assets/test/passcodes.txt",
+		1,
+		10*time.Millisecond,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		addresses...)
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	go func() {
+		for ctx.Err() == nil {
+			time.Sleep(20 * time.Millisecond)
+			eth.Commit()
+		}
+	}()
+
+	validators := setupValidators(t, eth, addresses)
+
+	rawBlockHeader, err := hex.DecodeString(rawSnapshotHeader)
+	assert.Nil(t, err)
+	blockHeader := &objs.BlockHeader{}
+	assert.Nil(t, blockHeader.UnmarshalBinary(rawBlockHeader))
+	rawBclaims, err := blockHeader.BClaims.MarshalBinary()
+	assert.Nil(t, err)
+	blockHash, err := blockHeader.BlockHash()
+	assert.Nil(t, err)
+
+	// Every validator commits the block and tries to submit its snapshot
+	epoch := big.NewInt(1)
+	wg := sync.WaitGroup{}
+	for _, acct := range validators {
+		task := tasks.NewSnapshotTask(acct, epoch, blockHash, rawBclaims, blockHeader.SigGroup, 0)
+		logger := logging.GetLogger("monitor").WithField("Validator", acct.Address.Hex())
+		assert.Nil(t, task.Initialize(ctx, logger, eth))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, task.DoWork(ctx, logger, eth))
+			assert.False(t, task.ShouldRetry(ctx, logger, eth))
+		}()
+	}
+	wg.Wait()
+
+	// Exactly one of them took the snapshot, the others saw it and skipped theirs
+	iter, err := eth.Contracts().Validators().FilterSnapshotTaken(&bind.FilterOpts{Context: ctx}, nil, nil)
+	assert.Nil(t, err)
+	defer iter.Close()
+
+	submitters := []common.Address{}
+	for iter.Next() {
+		assert.Equal(t, epoch, iter.Event.Epoch)
+		submitters = append(submitters, iter.Event.Validator)
+	}
+	assert.Nil(t, iter.Error())
+	assert.Equal(t, 1, len(submitters))
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/MadBase/MadNet/blockchain/interfaces"
 	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/pborman/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -170,21 +175,76 @@ type TaskWrapper struct {
 type Manager interface {
 	// NewTaskHandler(logger *logrus.Logger, eth interfaces.Ethereum, t interfaces.Task) interfaces.TaskHandler
 	StartTask(logger *logrus.Entry, eth interfaces.Ethereum, t interfaces.Task) interfaces.TaskHandler
+	Run(logger *logrus.Entry, eth interfaces.Ethereum, key string, t interfaces.Task, policy RetryPolicy) bool
+	FindRun(key string) (*objects.TaskRun, bool)
+	Runs() []*objects.TaskRun
+	SetStore(store RunStore) error
 	WaitForTasks()
 }
 
+// RunStore persists the history of task runs across restarts
+type RunStore interface {
+	FindTaskRuns() ([]*objects.TaskRun, error)
+	UpdateTaskRun(run *objects.TaskRun) error
+	DeleteTaskRun(key string) error
+}
+
+// RetryPolicy decides how often and for how long a task is attempted
+type RetryPolicy struct {
+	MaxAttempts   int           // Attempts before giving up, 0 for no limit
+	Backoff       time.Duration // Delay after the first failed attempt
+	MaxBackoff    time.Duration // Backoff doubles after each failed attempt while it stays within this
+	DeadlineBlock uint64        // No attempt starts after this Ethereum block, 0 for no deadline
+	Timeout       time.Duration // Longest an attempt may take, 0 for no limit
+}
+
 // ManagerDetails contains information required for implmentation of task Manager
 type ManagerDetails struct {
-	wg sync.WaitGroup
+	sync.Mutex
+	wg      sync.WaitGroup
+	store   RunStore
+	runs    map[string]*objects.TaskRun
+	running map[string]bool
+	logger  *logrus.Entry
+}
+
+// taskHandler lets the caller of StartTask follow and cancel a run
+type taskHandler struct {
+	sync.Mutex
+	cancel     context.CancelFunc
+	complete   bool
+	successful bool
 }
 
 // ========================================================
 // Manager implementation
 // ========================================================
 
-// NewManager creates a new Manager
+// NewManager creates a new Manager that keeps the history of runs in memory
+// until a store is set
 func NewManager() Manager {
-	return &ManagerDetails{wg: sync.WaitGroup{}}
+	return &ManagerDetails{
+		runs:    make(map[string]*objects.TaskRun),
+		running: make(map[string]bool),
+		logger:  logging.GetLogger("monitor").WithField("Component", "tasks")}
+}
+
+// DefaultRetryPolicy retries as often as the settings of eth allow
+func DefaultRetryPolicy(eth interfaces.Ethereum) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: eth.RetryCount() + 1,
+		Backoff:     eth.RetryDelay(),
+		MaxBackoff:  constants.TaskMaxBackoff,
+		Timeout:     eth.Timeout()}
+}
+
+// delay is how long to wait after the given number of failed attempts
+func (p RetryPolicy) delay(failed int) time.Duration {
+	delay := p.Backoff
+	for n := 1; n < failed && delay*2 <= p.MaxBackoff; n++ {
+		delay *= 2
+	}
+	return delay
 }
 
 // NewTaskHandler creates a new task handler, where each phase can take upto 'timeout'
@@ -205,66 +265,297 @@ func NewManager() Manager {
 // 		wg:     &md.wg}
 // }
 
+// StartTask runs a task once under a new key with the default retry policy
 func (md *ManagerDetails) StartTask(logger *logrus.Entry, eth interfaces.Ethereum, task interfaces.Task) interfaces.TaskHandler {
+	key := fmt.Sprintf("%v-%v", taskName(task), uuid.NewRandom())
+	return md.start(logger, eth, key, task, DefaultRetryPolicy(eth))
+}
+
+// Run starts running task in the background unless a run with the same key
+// is already going or finished. A run interrupted by a restart continues
+// where it left off. Returns whether the task was started.
+func (md *ManagerDetails) Run(logger *logrus.Entry, eth interfaces.Ethereum, key string, task interfaces.Task, policy RetryPolicy) bool {
+	return md.start(logger, eth, key, task, policy) != nil
+}
+
+// start runs task under key, it returns nil if the task wasn't started
+func (md *ManagerDetails) start(logger *logrus.Entry, eth interfaces.Ethereum, key string, task interfaces.Task, policy RetryPolicy) *taskHandler {
+	md.Lock()
+	run, present := md.runs[key]
+	if md.running[key] || (present && run.Status.Finished()) {
+		md.Unlock()
+		return nil
+	}
+	if !present {
+		run = &objects.TaskRun{Key: key, Name: taskName(task)}
+		md.runs[key] = run
+	}
+	run.Status = objects.TaskRunning
+	run.Deadline = policy.DeadlineBlock
+	md.running[key] = true
 	md.wg.Add(1)
+	md.Unlock()
+
+	logger = logger.WithField("TaskKey", key)
+	logger.Debugf("Task policy is %+v", policy)
+	md.save(key)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := &taskHandler{cancel: cancel}
 
 	go func() {
-		defer task.DoDone(logger)
 		defer md.wg.Done()
+		defer task.DoDone(logger)
+		defer func() {
+			md.Lock()
+			defer md.Unlock()
+			delete(md.running, key)
+		}()
+		defer md.prune()
+		defer cancel()
 
-		retryCount := eth.RetryCount()
-		retryDelay := eth.RetryDelay()
-		timeout := eth.Timeout()
-		logger.Debugf("Task timeout is %v, retryCount is %v and retryDelay is %v", timeout, retryCount, retryDelay)
+		successful := md.attempt(ctx, logger, eth, key, task, policy)
 
-		// Setup
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		handler.Lock()
+		defer handler.Unlock()
+		handler.complete = true
+		handler.successful = successful
+	}()
+
+	return handler
+}
 
-		var count int
-		var err error
+// attempt runs task until it succeeds, the policy gives up on it or ctx is
+// canceled. Returns whether the task succeeded.
+func (md *ManagerDetails) attempt(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum, key string, task interfaces.Task, policy RetryPolicy) bool {
+	md.Lock()
+	count := len(md.runs[key].Attempts)
+	md.Unlock()
 
-		err = task.Initialize(ctx, logger, eth)
-		logger.Debugf("Initialize ... error %v", err)
-		for err != nil && count < retryCount {
-			if err == objects.ErrCanNotContinue {
-				logger.Error("can not continue:", err)
-				return
-			}
-			time.Sleep(retryDelay)
-			err = task.Initialize(ctx, logger, eth)
-			count++
-		}
+	initialized := false
+	for {
+		height, err := eth.GetCurrentHeight(ctx)
 		if err != nil {
-			logger.Errorf("Failed to initialize task: %v", err)
-			return
+			logger.Warnf("Could not check current height: %v", err)
+			height = 0
+		} else if policy.DeadlineBlock > 0 && height > policy.DeadlineBlock {
+			md.deadLetter(logger, key, fmt.Sprintf("deadline block %v passed", policy.DeadlineBlock))
+			return false
 		}
 
-		count = 0
-		err = task.DoWork(ctx, logger, eth)
-		for err != nil && count < retryCount && task.ShouldRetry(ctx, logger, eth) {
-			if err == objects.ErrCanNotContinue {
-				logger.Error("can not continue", err)
-				return
+		// Task state isn't persisted, so after a restart it's set up again
+		attempt := objects.TaskAttempt{Started: time.Now(), Block: height}
+		attemptCtx, cancel := policy.attemptContext(ctx)
+		if !initialized {
+			err = task.Initialize(attemptCtx, logger, eth)
+			initialized = err == nil
+		}
+		if initialized {
+			if count == 0 {
+				err = task.DoWork(attemptCtx, logger, eth)
+			} else {
+				err = task.DoRetry(attemptCtx, logger, eth)
 			}
-			time.Sleep(retryDelay)
-			err = task.DoRetry(ctx, logger, eth)
-			count++
 		}
+		count++
+
 		if err != nil {
-			logger.Errorf("Failed to execute task: %v", err)
-			return
+			attempt.Err = err.Error()
 		}
-	}()
+		md.update(key, func(run *objects.TaskRun) {
+			run.Attempts = append(run.Attempts, attempt)
+			if err == nil {
+				run.Status = objects.TaskSucceeded
+				run.Finished = time.Now()
+			}
+		})
+
+		if err == nil {
+			cancel()
+			logger.Infof("Task succeeded after %v attempts", count)
+			return true
+		}
+		logger.Warnf("Attempt %v failed: %v", count, err)
+
+		var reason string
+		switch {
+		case ctx.Err() != nil:
+			reason = "task was canceled"
+		case err == objects.ErrCanNotContinue:
+			reason = err.Error()
+		case policy.MaxAttempts > 0 && count >= policy.MaxAttempts:
+			reason = fmt.Sprintf("gave up after %v attempts", count)
+		case initialized && !task.ShouldRetry(attemptCtx, logger, eth):
+			reason = "task can not be retried"
+		}
+		cancel()
+		if reason != "" {
+			md.deadLetter(logger, key, reason)
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			md.deadLetter(logger, key, "task was canceled")
+			return false
+		case <-time.After(policy.delay(count)):
+		}
+	}
+}
+
+// attemptContext limits an attempt to the timeout of the policy
+func (p RetryPolicy) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.Timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.Timeout)
+}
+
+func (md *ManagerDetails) deadLetter(logger *logrus.Entry, key string, reason string) {
+	logger.Errorf("Task failed: %v", reason)
+	md.update(key, func(run *objects.TaskRun) {
+		run.Status = objects.TaskDeadLetter
+		run.Reason = reason
+		run.Finished = time.Now()
+	})
+}
+
+func (md *ManagerDetails) update(key string, fn func(*objects.TaskRun)) {
+	md.Lock()
+	fn(md.runs[key])
+	md.Unlock()
+
+	md.save(key)
+}
+
+func (md *ManagerDetails) save(key string) {
+	md.Lock()
+	store := md.store
+	run := md.runs[key].Clone()
+	md.Unlock()
+
+	if store == nil {
+		return
+	}
+	if err := store.UpdateTaskRun(run); err != nil {
+		md.logger.Warnf("could not save task run %v: %v", key, err)
+	}
+}
+
+// prune forgets the oldest finished runs beyond the retention limit
+func (md *ManagerDetails) prune() {
+	md.Lock()
+	finished := []*objects.TaskRun{}
+	for _, run := range md.runs {
+		if run.Status.Finished() {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) <= constants.TaskRunRetention {
+		md.Unlock()
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].Finished.Before(finished[j].Finished)
+	})
+	finished = finished[:len(finished)-constants.TaskRunRetention]
+	for _, run := range finished {
+		delete(md.runs, run.Key)
+	}
+	store := md.store
+	md.Unlock()
+
+	if store == nil {
+		return
+	}
+	for _, run := range finished {
+		if err := store.DeleteTaskRun(run.Key); err != nil {
+			md.logger.Warnf("could not delete task run %v: %v", run.Key, err)
+		}
+	}
+}
+
+// FindRun returns a copy of the run with key
+func (md *ManagerDetails) FindRun(key string) (*objects.TaskRun, bool) {
+	md.Lock()
+	defer md.Unlock()
+
+	run, present := md.runs[key]
+	if !present {
+		return nil, false
+	}
+	return run.Clone(), true
+}
+
+// Runs returns copies of all known runs, including those of earlier processes
+// sharing the store
+func (md *ManagerDetails) Runs() []*objects.TaskRun {
+	md.Lock()
+	defer md.Unlock()
+
+	runs := make([]*objects.TaskRun, 0, len(md.runs))
+	for _, run := range md.runs {
+		runs = append(runs, run.Clone())
+	}
+	return runs
+}
+
+// SetStore persists runs in store, loading the runs already stored
+func (md *ManagerDetails) SetStore(store RunStore) error {
+	stored, err := store.FindTaskRuns()
+	if err != nil {
+		return err
+	}
+
+	md.Lock()
+	md.store = store
+	for _, run := range stored {
+		if !md.running[run.Key] {
+			md.runs[run.Key] = run
+		}
+	}
+	md.Unlock()
+
+	md.prune()
 
 	return nil
 }
 
+// Cancel stops the run, it's dead lettered unless it already finished
+func (th *taskHandler) Cancel() {
+	th.cancel()
+}
+
+// Start does nothing, the run was started by the manager
+func (th *taskHandler) Start() {}
+
+// Complete is true once the run finished
+func (th *taskHandler) Complete() bool {
+	th.Lock()
+	defer th.Unlock()
+	return th.complete
+}
+
+// Successful is true if the run finished because the task succeeded
+func (th *taskHandler) Successful() bool {
+	th.Lock()
+	defer th.Unlock()
+	return th.successful
+}
+
 // WaitForTasks blocks until all tasks associated withis Manager have completed
 func (md *ManagerDetails) WaitForTasks() {
 	md.wg.Wait()
 }
 
+func taskName(task interfaces.Task) string {
+	tipe := reflect.TypeOf(task)
+	if tipe.Kind() == reflect.Ptr {
+		tipe = tipe.Elem()
+	}
+	return tipe.String()
+}
+
 // ========================================================
 // Custom Marshal/Unmarshal for tasks
 // ========================================================
@@ -1,9 +1,22 @@
 package tasks_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/blockchain/tasks"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,3 +28,247 @@ func TestFoo(t *testing.T) {
 
 	t.Logf("Raw data:%v", string(raw))
 }
+
+var errFlaky = errors.New("flaky")
+
+// flakyTask fails until it was attempted often enough
+type flakyTask struct {
+	sync.Mutex
+	failures int
+	initErr  error
+	retry    bool
+	work     int
+	done     bool
+}
+
+func (ft *flakyTask) Initialize(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	return ft.initErr
+}
+
+func (ft *flakyTask) DoWork(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	return ft.doTask()
+}
+
+func (ft *flakyTask) DoRetry(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	return ft.doTask()
+}
+
+func (ft *flakyTask) doTask() error {
+	ft.Lock()
+	defer ft.Unlock()
+	ft.work++
+	if ft.work <= ft.failures {
+		return errFlaky
+	}
+	return nil
+}
+
+func (ft *flakyTask) attempts() int {
+	ft.Lock()
+	defer ft.Unlock()
+	return ft.work
+}
+
+func (ft *flakyTask) ShouldRetry(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) bool {
+	return ft.retry
+}
+
+func (ft *flakyTask) DoDone(logger *logrus.Entry) {
+	ft.Lock()
+	defer ft.Unlock()
+	ft.done = true
+}
+
+type memoryRunStore struct {
+	sync.Mutex
+	runs map[string]*objects.TaskRun
+}
+
+func (s *memoryRunStore) FindTaskRuns() ([]*objects.TaskRun, error) {
+	s.Lock()
+	defer s.Unlock()
+	runs := []*objects.TaskRun{}
+	for _, run := range s.runs {
+		runs = append(runs, run.Clone())
+	}
+	return runs, nil
+}
+
+func (s *memoryRunStore) UpdateTaskRun(run *objects.TaskRun) error {
+	s.Lock()
+	defer s.Unlock()
+	s.runs[run.Key] = run.Clone()
+	return nil
+}
+
+func (s *memoryRunStore) DeleteTaskRun(key string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.runs, key)
+	return nil
+}
+
+// slowTask takes until its context is done
+type slowTask struct {
+	flakyTask
+}
+
+func (st *slowTask) DoWork(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	return st.doTask(ctx)
+}
+
+func (st *slowTask) DoRetry(ctx context.Context, logger *logrus.Entry, eth interfaces.Ethereum) error {
+	return st.doTask(ctx)
+}
+
+func (st *slowTask) doTask(ctx context.Context) error {
+	st.Lock()
+	st.work++
+	st.Unlock()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunner(t *testing.T) {
+	eth, err := blockchain.NewEthereumSimulator(
+		"../../assets/test/keys",
+		"../../assets/test/passcodes.txt",
+		1,
+		time.Millisecond,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		"0x546F99F244b7B58B855330AE0E2BC1b30b41302F")
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	logger := logging.GetLogger("monitor").WithField("Test", t.Name())
+	store := &memoryRunStore{runs: make(map[string]*objects.TaskRun)}
+	manager := tasks.NewManager()
+	assert.Nil(t, manager.SetStore(store))
+
+	policy := tasks.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+	// Failed attempts are logged until the task succeeds
+	flaky := &flakyTask{failures: 2, retry: true}
+	assert.True(t, manager.Run(logger, eth, "flaky", flaky, policy))
+	assert.False(t, manager.Run(logger, eth, "flaky", flaky, policy))
+
+	// Attempts give up when the policy says so
+	hopeless := &flakyTask{failures: 10, retry: true}
+	assert.True(t, manager.Run(logger, eth, "hopeless", hopeless, policy))
+	stuck := &flakyTask{failures: 10, retry: false}
+	assert.True(t, manager.Run(logger, eth, "stuck", stuck, policy))
+	broken := &flakyTask{initErr: objects.ErrCanNotContinue, retry: true}
+	assert.True(t, manager.Run(logger, eth, "broken", broken, policy))
+
+	manager.WaitForTasks()
+
+	run, present := manager.FindRun("flaky")
+	assert.True(t, present)
+	assert.Equal(t, objects.TaskSucceeded, run.Status)
+	assert.Equal(t, "tasks_test.flakyTask", run.Name)
+	assert.Equal(t, 3, len(run.Attempts))
+	assert.Equal(t, errFlaky.Error(), run.Attempts[0].Err)
+	assert.Equal(t, "", run.Attempts[2].Err)
+	assert.True(t, flaky.done)
+
+	run, _ = manager.FindRun("hopeless")
+	assert.Equal(t, objects.TaskDeadLetter, run.Status)
+	assert.Equal(t, "gave up after 3 attempts", run.Reason)
+	assert.Equal(t, 3, hopeless.work)
+
+	run, _ = manager.FindRun("stuck")
+	assert.Equal(t, objects.TaskDeadLetter, run.Status)
+	assert.Equal(t, 1, len(run.Attempts))
+
+	run, _ = manager.FindRun("broken")
+	assert.Equal(t, objects.TaskDeadLetter, run.Status)
+	assert.Equal(t, objects.ErrCanNotContinue.Error(), run.Attempts[0].Err)
+	assert.Equal(t, 0, broken.work)
+
+	// Nothing is attempted past the deadline
+	eth.Commit()
+	eth.Commit()
+	late := &flakyTask{retry: true}
+	policy.DeadlineBlock = 1
+	assert.True(t, manager.Run(logger, eth, "late", late, policy))
+	manager.WaitForTasks()
+	run, _ = manager.FindRun("late")
+	assert.Equal(t, objects.TaskDeadLetter, run.Status)
+	assert.Equal(t, "deadline block 1 passed", run.Reason)
+	assert.Equal(t, 0, len(run.Attempts))
+
+	// Finished runs are remembered across restarts
+	manager = tasks.NewManager()
+	assert.Nil(t, manager.SetStore(store))
+	assert.Equal(t, 5, len(manager.Runs()))
+	assert.False(t, manager.Run(logger, eth, "hopeless", &flakyTask{retry: true}, policy))
+	run, _ = manager.FindRun("hopeless")
+	assert.Equal(t, 3, len(run.Attempts))
+}
+
+func TestRunnerLimits(t *testing.T) {
+	eth, err := blockchain.NewEthereumSimulator(
+		"../../assets/test/keys",
+		"../../assets/test/passcodes.txt",
+		1,
+		time.Hour,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		"0x546F99F244b7B58B855330AE0E2BC1b30b41302F")
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	logger := logging.GetLogger("monitor").WithField("Test", t.Name())
+	store := &memoryRunStore{runs: make(map[string]*objects.TaskRun)}
+	manager := tasks.NewManager()
+	assert.Nil(t, manager.SetStore(store))
+
+	// Tasks started without a key can be followed through their handler
+	flaky := &flakyTask{retry: true}
+	handler := manager.StartTask(logger, eth, flaky)
+	manager.WaitForTasks()
+	assert.True(t, handler.Complete())
+	assert.True(t, handler.Successful())
+	assert.Equal(t, 1, len(manager.Runs()))
+
+	// Each attempt is limited by the timeout of the policy
+	policy := tasks.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond, Timeout: 10 * time.Millisecond}
+	slow := &slowTask{flakyTask{retry: true}}
+	assert.True(t, manager.Run(logger, eth, "slow", slow, policy))
+	manager.WaitForTasks()
+	run, _ := manager.FindRun("slow")
+	assert.Equal(t, objects.TaskDeadLetter, run.Status)
+	assert.Equal(t, 2, len(run.Attempts))
+	assert.Equal(t, context.DeadlineExceeded.Error(), run.Attempts[0].Err)
+
+	// Canceling doesn't wait for the hour of backoff to pass
+	hopeless := &flakyTask{failures: 10, retry: true}
+	handler = manager.StartTask(logger, eth, hopeless)
+	for hopeless.attempts() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	handler.Cancel()
+	manager.WaitForTasks()
+	assert.True(t, handler.Complete())
+	assert.False(t, handler.Successful())
+	assert.Equal(t, 1, hopeless.attempts())
+
+	// Only the latest finished runs are remembered
+	for i := 0; i < constants.TaskRunRetention+2; i++ {
+		assert.Nil(t, store.UpdateTaskRun(&objects.TaskRun{
+			Key:      fmt.Sprintf("old-%v", i),
+			Status:   objects.TaskSucceeded,
+			Finished: time.Unix(int64(i), 0)}))
+	}
+	manager = tasks.NewManager()
+	assert.Nil(t, manager.SetStore(store))
+	assert.Equal(t, constants.TaskRunRetention, len(manager.Runs()))
+	assert.Equal(t, constants.TaskRunRetention, len(store.runs))
+	_, present := manager.FindRun("old-0")
+	assert.False(t, present)
+	_, present = manager.FindRun("slow")
+	assert.True(t, present)
+}
@@ -31,31 +31,52 @@ type Request struct {
 
 //
 type Response struct {
-	message string
-	err     error
-	rcpt    *types.Receipt
-	rcpts   []*types.Receipt
+	message  string
+	err      error
+	rcpt     *types.Receipt
+	rcpts    []*types.Receipt
+	profiles map[interfaces.FuncSelector]TransactionProfile
 }
 
 // TransactionProfile
 type TransactionProfile struct {
-	AverageGas   uint64
-	MinimumGas   uint64
-	MaximumGas   uint64
-	TotalCount   uint64
-	TotalGas     uint64
-	TotalSuccess uint64
+	AverageGas        uint64
+	MinimumGas        uint64
+	MaximumGas        uint64
+	AverageGasPrice   uint64 // Gas prices are in wei and only count the version that was mined
+	MinimumGasPrice   uint64
+	MaximumGasPrice   uint64
+	TotalCount        uint64
+	TotalGas          uint64
+	TotalSuccess      uint64
+	TotalReplacements uint64
+}
+
+// TxnSigner signs a transaction for an account
+type TxnSigner func(from common.Address, txn *types.Transaction) (*types.Transaction, error)
+
+// pendingTxn is a transaction whose receipt we're looking for and the versions
+// with higher gas prices that replaced it
+type pendingTxn struct {
+	versions []*types.Transaction // Every version sent, the latest one last
+	sentAt   uint64               // Block height when the latest version was first seen unmined
 }
 
 // Behind is the struct used while monitoring Ethereum transactions
 type Behind struct {
 	sync.Mutex
 	waitingTxns    []common.Hash                                  // Just a list of transactions whose receipts we're looking for
+	pendingTxns    map[common.Hash]*pendingTxn                    // The versions sent of each waiting transaction
 	readyTxns      map[common.Hash]*types.Receipt                 // All the transaction -> receipt pairs we know of
 	selectors      map[common.Hash]interfaces.FuncSelector        // Maps a transaction to it's function selector
 	groups         map[int][]common.Hash                          // A group is just an ID and a list of transactions
 	aggregates     map[interfaces.FuncSelector]TransactionProfile //
 	client         interfaces.GethClient                          // An interface with the Geth functionality we need
+	chainSigner    types.Signer                                   // Recovers the senders of transactions on our chain
+	fees           *FeeEstimator                                  // Prices the replacements of stuck transactions
+	signer         TxnSigner                                      // Signs the replacements, nil to never replace
+	replaceAfter   uint64                                         // Blocks a transaction may stay unmined before it's replaced
+	nonces         *NonceManager                                  // Told about replacements and mined transactions, may be nil
 	knownSelectors interfaces.SelectorMap                         //
 	logger         *logrus.Entry                                  //
 	reqch          <-chan *Request                                //
@@ -111,16 +132,41 @@ func (b *Behind) collectReceipts() {
 		return
 	}
 
+	var height uint64
+	if b.signer != nil && b.replaceAfter > 0 {
+		header, err := b.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			b.logger.Errorf("error getting height: %v", err)
+		} else if header != nil {
+			height = header.Number.Uint64()
+		}
+	}
+
 	// loop over transactions in need of receipts while building new list
 	remainingTxns := make([]common.Hash, 0, n)
 	for _, txn := range b.waitingTxns {
-		rcpt, err := b.client.TransactionReceipt(ctx, txn)
+		pending, present := b.pendingTxns[txn]
+		if !present {
+			// Queued more than once and already collected
+			continue
+		}
+		rcpt, mined, err := b.receipt(ctx, pending)
 		if err == geth.NotFound || (err == nil && rcpt == nil) {
 			b.logger.Debugf("receipt not found: %v", txn.Hex())
+			if height > 0 {
+				b.replace(ctx, txn, pending, height)
+			}
 		} else if err != nil {
 			b.logger.Errorf("error getting receipt: %v", txn)
 		} else if rcpt != nil {
 			b.readyTxns[txn] = rcpt
+			delete(b.pendingTxns, txn)
+
+			if b.nonces != nil {
+				if from, err := types.Sender(b.chainSigner, mined); err == nil {
+					b.nonces.Mined(from, mined.Nonce())
+				}
+			}
 
 			var profile TransactionProfile
 			var selector [4]byte
@@ -142,6 +188,15 @@ func (b *Behind) collectReceipts() {
 			if profile.MinimumGas == 0 || profile.MinimumGas > rcpt.GasUsed {
 				profile.MinimumGas = rcpt.GasUsed
 			}
+			if gasPrice := mined.GasPrice().Uint64(); mined.GasPrice().IsUint64() {
+				profile.AverageGasPrice = (profile.AverageGasPrice*profile.TotalCount + gasPrice) / (profile.TotalCount + 1)
+				if profile.MaximumGasPrice < gasPrice {
+					profile.MaximumGasPrice = gasPrice
+				}
+				if profile.MinimumGasPrice == 0 || profile.MinimumGasPrice > gasPrice {
+					profile.MinimumGasPrice = gasPrice
+				}
+			}
 			profile.TotalCount++
 			profile.TotalGas += rcpt.GasUsed
 			if rcpt.Status == uint64(1) {
@@ -152,14 +207,15 @@ func (b *Behind) collectReceipts() {
 			logEntry := b.logger.WithField("Transaction", rcpt.TxHash.Hex()).
 				WithField("Function", sig).
 				WithField("Selector", fmt.Sprintf("%x", selector)).
-				WithField("Successful", rcpt.Status == 1)
+				WithField("Successful", rcpt.Status == 1).
+				WithField("GasPrice", mined.GasPrice()).
+				WithField("Replacements", len(pending.versions)-1)
 
 			// This is hideous but useful when troubleshooting with simulator
 			if b.logger.Logger.IsLevelEnabled(logrus.DebugLevel) {
 				fullTxn, _, err := b.client.TransactionByHash(ctx, txn)
 				if err == nil {
-					signer := types.NewEIP155Signer(big.NewInt(1337))
-					msg, err := fullTxn.AsMessage(signer)
+					msg, err := fullTxn.AsMessage(b.chainSigner)
 					if err == nil {
 						logEntry = logEntry.WithField("From", msg.From().Hash().Hex())
 					}
@@ -178,6 +234,86 @@ func (b *Behind) collectReceipts() {
 	b.waitingTxns = remainingTxns
 }
 
+// receipt looks for the receipt of any version of a transaction, newest first
+func (b *Behind) receipt(ctx context.Context, pending *pendingTxn) (*types.Receipt, *types.Transaction, error) {
+	for idx := len(pending.versions) - 1; idx >= 0; idx-- {
+		version := pending.versions[idx]
+		rcpt, err := b.client.TransactionReceipt(ctx, version.Hash())
+		if err == geth.NotFound || (err == nil && rcpt == nil) {
+			continue
+		}
+		return rcpt, version, err
+	}
+	return nil, nil, geth.NotFound
+}
+
+// replace sends a transaction again with a higher gas price once it stayed
+// unmined for replaceAfter blocks
+func (b *Behind) replace(ctx context.Context, txn common.Hash, pending *pendingTxn, height uint64) {
+	if pending.sentAt == 0 {
+		pending.sentAt = height
+	}
+	if height < pending.sentAt+b.replaceAfter {
+		return
+	}
+
+	latest := pending.versions[len(pending.versions)-1]
+	logEntry := b.logger.WithField("Transaction", txn.Hex()).
+		WithField("Nonce", latest.Nonce()).
+		WithField("Blocks", height-pending.sentAt)
+
+	gasPrice, err := b.fees.Bump(ctx, latest.GasPrice())
+	if err != nil {
+		logEntry.Errorf("could not price replacement: %v", err)
+		return
+	}
+	if gasPrice.Cmp(latest.GasPrice()) <= 0 {
+		logEntry.Warnf("transaction not mined but gas price %v is at the cap", latest.GasPrice())
+		pending.sentAt = height
+		return
+	}
+
+	from, err := types.Sender(b.chainSigner, latest)
+	if err != nil {
+		logEntry.Errorf("could not recover sender: %v", err)
+		return
+	}
+
+	replacement, err := b.signer(from, withNonce(latest, latest.Nonce(), gasPrice))
+	if err != nil {
+		logEntry.Errorf("could not sign replacement: %v", err)
+		return
+	}
+	// A remote signer may have changed the price, only what it signed counts
+	if replacement.GasPrice().Cmp(latest.GasPrice()) <= 0 {
+		logEntry.Warnf("signer did not raise the gas price of the replacement above %v", latest.GasPrice())
+		pending.sentAt = height
+		return
+	}
+	err = b.client.SendTransaction(ctx, replacement)
+	if err != nil {
+		// Most likely a version was mined in the meantime
+		logEntry.Warnf("could not send replacement: %v", err)
+		pending.sentAt = height
+		return
+	}
+
+	pending.versions = append(pending.versions, replacement)
+	pending.sentAt = height
+	if b.nonces != nil {
+		b.nonces.Sent(from, replacement.Nonce(), replacement.Hash())
+	}
+
+	selector := b.selectors[txn]
+	profile := b.aggregates[selector]
+	profile.TotalReplacements++
+	b.aggregates[selector] = profile
+
+	logEntry.WithField("Replacement", replacement.Hash().Hex()).
+		WithField("GasPrice", replacement.GasPrice()).
+		Info("Replaced stuck transaction")
+}
+
 func (b *Behind) process(req *Request, handler func(req *Request) *Response) {
 
 	b.logger.Debug("processing request...")
@@ -209,6 +345,9 @@ func (b *Behind) queue(req *Request) *Response {
 
 	b.selectors[txnHash] = selector
 	b.waitingTxns = append(b.waitingTxns, txnHash)
+	if _, present := b.pendingTxns[txnHash]; !present {
+		b.pendingTxns[txnHash] = &pendingTxn{versions: []*types.Transaction{req.txn}}
+	}
 
 	if _, present := b.groups[req.group]; !present {
 		b.groups[req.group] = make([]common.Hash, 0, 10)
@@ -217,8 +356,7 @@ func (b *Behind) queue(req *Request) *Response {
 
 	// This is hideous but useful when troubleshooting with simulator
 	if b.logger.Logger.IsLevelEnabled(logrus.DebugLevel) {
-		signer := types.NewEIP155Signer(big.NewInt(1337))
-		msg, err := req.txn.AsMessage(signer)
+		msg, err := req.txn.AsMessage(b.chainSigner)
 		if err == nil {
 			logEntry = logEntry.WithField("From", msg.From().Hash().Hex())
 		}
@@ -237,15 +375,17 @@ func (b *Behind) status(req *Request) *Response {
 		WithField("Pending", len(b.waitingTxns)).
 		Info("Transaction counts")
 
+	profiles := make(map[interfaces.FuncSelector]TransactionProfile, len(b.aggregates))
 	for selector, profile := range b.aggregates {
 		sig := b.knownSelectors.Signature(selector)
 		b.logger.WithField("Selector", fmt.Sprintf("%x", selector)).
 			WithField("Function", sig).
 			WithField("Profile", fmt.Sprintf("%+v", profile)).
 			Info("Status")
+		profiles[selector] = profile
 	}
 
-	return &Response{message: "status check"}
+	return &Response{message: "status check", profiles: profiles}
 }
 
 func (b *Behind) wait(req *Request) *Response {
@@ -315,14 +455,25 @@ type TxnQueueDetail struct {
 	reqch   chan<- *Request
 }
 
-func NewTxnQueue(client interfaces.GethClient, sm interfaces.SelectorMap) *TxnQueueDetail {
+// NewTxnQueue creates a queue that collects the receipts of transactions sent
+// on the chain with chainID. A transaction that isn't mined within
+// replaceAfter blocks is sent again with a higher gas price signed by signer,
+// unless signer is nil or replaceAfter is zero. Replaced and mined
+// transactions are recorded with nonces, if any.
+func NewTxnQueue(client interfaces.GethClient, sm interfaces.SelectorMap, chainID *big.Int, fees *FeeEstimator, signer TxnSigner, replaceAfter uint64, nonces *NonceManager) *TxnQueueDetail {
 	reqch := make(chan *Request, 10)
 
 	b := &Behind{
 		reqch:          reqch,
 		client:         client,
+		chainSigner:    types.NewEIP155Signer(chainID),
+		fees:           fees,
+		signer:         signer,
+		replaceAfter:   replaceAfter,
+		nonces:         nonces,
 		logger:         logging.GetLogger("ethereum").WithField("Component", "behind"),
 		waitingTxns:    make([]common.Hash, 0, 20),
+		pendingTxns:    make(map[common.Hash]*pendingTxn),
 		readyTxns:      make(map[common.Hash]*types.Receipt),
 		selectors:      make(map[common.Hash]interfaces.FuncSelector),
 		aggregates:     make(map[interfaces.FuncSelector]TransactionProfile),
@@ -387,6 +538,17 @@ func (f *TxnQueueDetail) WaitGroupTransactions(ctx context.Context, grp int) ([]
 	return resp.rcpts, nil
 }
 
+// Status logs the transaction counts and returns the profile of every
+// function called so far
+func (f *TxnQueueDetail) Status(ctx context.Context) (map[interfaces.FuncSelector]TransactionProfile, error) {
+	req := &Request{name: "status", respch: make(chan *Response)}
+	resp := f.requestWait(ctx, req)
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	return resp.profiles, nil
+}
+
 func (f *TxnQueueDetail) Close() {
 	f.logger.Debug("closing request channel...")
 	close(f.reqch)
@@ -3,10 +3,14 @@ package blockchain_test
 import (
 	"context"
 	"math/big"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/MadBase/MadNet/blockchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -56,3 +60,84 @@ func TestFoo(t *testing.T) {
 	}
 
 }
+
+// droppingClient loses the first transactions sent to it, like an endpoint
+// whose mempool evicted them
+type droppingClient struct {
+	*backends.SimulatedBackend
+	drop int32
+}
+
+func (c *droppingClient) SendTransaction(ctx context.Context, txn *types.Transaction) error {
+	if atomic.AddInt32(&c.drop, -1) >= 0 {
+		return nil
+	}
+	return c.SimulatedBackend.SendTransaction(ctx, txn)
+}
+
+func TestReplaceStuckTransaction(t *testing.T) {
+	eth, err := blockchain.NewEthereumSimulator(
+		"../assets/test/keys",
+		"../assets/test/passcodes.txt",
+		6,
+		1*time.Second,
+		5*time.Second,
+		0,
+		big.NewInt(9223372036854775807),
+		accountAddresses...)
+	assert.Nil(t, err)
+	defer eth.Close()
+
+	acct := eth.GetDefaultAccount()
+	assert.Nil(t, eth.UnlockAccount(acct))
+
+	sim := eth.GetGethClient().(*backends.SimulatedBackend)
+	client := &droppingClient{SimulatedBackend: sim, drop: 1}
+	fees := blockchain.NewFeeEstimator(client, nil, big.NewInt(10))
+	queue := blockchain.NewTxnQueue(client, eth.KnownSelectors(), eth.ChainID(), fees, eth.SignTransaction, 2, nil)
+	queue.StartLoop()
+	defer queue.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(200 * time.Millisecond):
+				eth.Commit()
+			}
+		}
+	}()
+
+	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cf()
+
+	nonce, err := sim.PendingNonceAt(ctx, acct.Address)
+	assert.Nil(t, err)
+	txn := types.NewTransaction(nonce, common.HexToAddress(accountAddresses[1]), big.NewInt(1), 21000, big.NewInt(1), nil)
+	txn, err = eth.SignTransaction(acct.Address, txn)
+	assert.Nil(t, err)
+	assert.Nil(t, client.SendTransaction(ctx, txn))
+
+	// The lost transaction is replaced by one with a higher gas price
+	rcpt, err := queue.QueueAndWait(ctx, txn)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint64(1), rcpt.Status)
+	assert.NotEqual(t, txn.Hash(), rcpt.TxHash)
+
+	mined, _, err := sim.TransactionByHash(ctx, rcpt.TxHash)
+	assert.Nil(t, err)
+	assert.Equal(t, nonce, mined.Nonce())
+	assert.Equal(t, int64(2), mined.GasPrice().Int64())
+
+	profiles, err := queue.Status(ctx)
+	assert.Nil(t, err)
+	profile := profiles[blockchain.ExtractSelector(nil)]
+	assert.Equal(t, uint64(1), profile.TotalReplacements)
+	assert.Equal(t, uint64(1), profile.TotalCount)
+	assert.Equal(t, uint64(2), profile.MaximumGasPrice)
+}
@@ -7,13 +7,14 @@ import (
 	"time"
 
 	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/interfaces"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/MadBase/MadNet/peering"
 	pb "github.com/MadBase/MadNet/proto"
 	"github.com/MadBase/MadNet/transport"
 	"github.com/MadBase/MadNet/types"
-	lru "github.com/hashicorp/golang-lru"
+	"github.com/MadBase/MadNet/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc/peer"
@@ -53,20 +54,39 @@ func bootNode(cmd *cobra.Command, args []string) {
 	if err != nil {
 		panic(err)
 	}
+	// Only peers on the allowlist may connect
+	allowlist, err := transport.NewAllowlist(config.Configuration.Transport.AllowedIdentities(), config.Configuration.Transport.AllowedHosts())
+	if err != nil {
+		logger.Panic(err)
+	}
 	// Establish P2P listener
-	xport, err := transport.NewP2PTransport(logger, cid, privateKeyHex, int(p2pPort), host)
+	xport, err := transport.NewP2PTransport(logger, cid, privateKeyHex, int(p2pPort), host, allowlist)
 	if err != nil {
 		logger.Panic(err)
 	}
 	defer xport.Close()
 
+	// Open the record database, in memory if no path is configured
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	dbPath := config.Configuration.BootNode.DatabasePath
+	db, err := utils.OpenBadger(closeChan, dbPath, dbPath == "")
+	if err != nil {
+		logger.Panic(err)
+	}
+	defer db.Close()
+
 	// Register a boot node server
 	cacheSize := config.Configuration.BootNode.CacheSize
-	cache, err := lru.New(cacheSize)
+	if cacheSize <= 0 {
+		cacheSize = constants.AddrBookMaxSize
+	}
+	records, err := newRecordStore(logger, db, cacheSize)
 	if err != nil {
-		panic(err)
+		logger.Panic(err)
 	}
-	srvr := &Server{nodes: cache, log: logger}
+	srvr := &Server{records: records, log: logger}
+	go probeLoop(closeChan, records, xport)
 	handler := peering.NewBootNodeServerHandler(logger, xport.NodeAddr(), srvr)
 	defer handler.Close()
 
@@ -79,12 +99,12 @@ func bootNode(cmd *cobra.Command, args []string) {
 
 // Server implements the bootnode protocol
 type Server struct {
-	log   *logrus.Logger
-	nodes *lru.Cache
+	log     *logrus.Logger
+	records *recordStore
 }
 
-// KnownNodes returns a set of recently seen peers when the bootnode is connected to
-//goland:noinspection GoUnusedParameter
+// KnownNodes returns the listed nodes on the chain of the caller. Callers
+// that present a valid signed record are listed in turn.
 func (bn *Server) KnownNodes(ctx context.Context, r *pb.BootNodeRequest) (*pb.BootNodeResponse, error) {
 	// get the identity of the caller
 	p, ok := peer.FromContext(ctx)
@@ -95,43 +115,20 @@ func (bn *Server) KnownNodes(ctx context.Context, r *pb.BootNodeRequest) (*pb.Bo
 	caller := p.Addr.(interfaces.NodeAddr)
 	callerAddr := caller.P2PAddr()
 	callerIdent := caller.Identity()
-	// defer the addition of the caller to the cache
-	defer bn.nodes.ContainsOrAdd(callerIdent, callerAddr)
-	// get the list of known identities from the cache
-	identList := bn.nodes.Keys()
-	bn.log.Debugf("Serving bootnode request to %s with %d known nodes", callerAddr, len(identList))
-	// create a var to store output in
-	var returnList []string
-	nmap := make(map[string]bool)
-	// add the caller to the known map
-	nmap[callerIdent] = true
-	// for each known identity
-	for i := 0; i < len(identList); i++ {
-		// convet to a string
-		identif := identList[i]
-		ident, ok := identif.(string)
-		if !ok {
-			// should never happen
-			bn.log.Fatal("Bootnode ident type cast failed - must shut down")
-			continue
+	returnList := bn.records.known(caller.ChainID(), callerIdent)
+	bn.log.Debugf("Serving bootnode request to %s with %d known nodes", callerAddr, len(returnList))
+	if len(r.Record) > 0 {
+		record := &transport.NodeRecord{}
+		err := record.UnmarshalBinary(r.Record)
+		if err == nil {
+			err = record.Verify(callerIdent, caller.ChainID(), time.Now())
 		}
-		// if the ident is already being tracked, continue
-		// this ensures the caller is not added to the list
-		if nmap[ident] {
-			continue
+		if err == nil {
+			err = bn.records.add(record, caller.Host())
 		}
-		addrif, ok := bn.nodes.Get(ident)
-		if !ok {
-			// should never happen
-			bn.log.Fatal("Bootnode addr type cast failed - must shut down")
-			continue
+		if err != nil {
+			bn.log.Debugf("Rejected node record of %s: %v", callerAddr, err)
 		}
-		// convert the addr to a string
-		addr := addrif.(string)
-		// append to the return list
-		returnList = append(returnList, addr)
-		// set the ident in the known map
-		nmap[ident] = true
 	}
 	resp := &pb.BootNodeResponse{
 		Peers: returnList,
@@ -140,6 +137,18 @@ func (bn *Server) KnownNodes(ctx context.Context, r *pb.BootNodeRequest) (*pb.Bo
 	return resp, nil
 }
 
+// probeLoop periodically drops the listed nodes that are no longer reachable
+func probeLoop(closeChan <-chan struct{}, records *recordStore, xport interfaces.P2PTransport) {
+	for {
+		select {
+		case <-closeChan:
+			return
+		case <-time.After(constants.BootNodeProbeInterval):
+			records.probe(xport)
+		}
+	}
+}
+
 func forceCleanup(conn interfaces.P2PConn) {
 	select {
 	case <-time.After(time.Second * 10):
@@ -0,0 +1,271 @@
+package bootnode
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/constants/dbprefix"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/types"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// recordEntry is a verified node record and the addresses it is served as
+type recordEntry struct {
+	record   *transport.NodeRecord
+	addrs    []interfaces.NodeAddr
+	failures int
+}
+
+var errInvalidEntry = errors.New("invalid record entry")
+
+// marshalEntry encodes a record with the host the node connected from, which
+// is not part of the signed record
+func marshalEntry(host string, rb []byte) []byte {
+	out := make([]byte, 2, 2+len(host)+len(rb))
+	binary.BigEndian.PutUint16(out, uint16(len(host)))
+	out = append(out, host...)
+	return append(out, rb...)
+}
+
+func unmarshalEntry(v []byte) (string, []byte, error) {
+	if len(v) < 2 {
+		return "", nil, errInvalidEntry
+	}
+	n := int(binary.BigEndian.Uint16(v))
+	if len(v) < 2+n {
+		return "", nil, errInvalidEntry
+	}
+	return string(v[2 : 2+n]), v[2+n:], nil
+}
+
+// resolveAddrs returns the addresses of a record as they are served
+func resolveAddrs(r *transport.NodeRecord, host string) ([]interfaces.NodeAddr, error) {
+	addrs, err := r.NodeAddrs()
+	if err != nil {
+		return nil, err
+	}
+	for i := range addrs {
+		addrs[i] = transport.ResolveHost(addrs[i], host)
+	}
+	return addrs, nil
+}
+
+// recordStore holds the verified records of the nodes the bootnode lists.
+// Records are persisted so a restarted bootnode keeps serving the network.
+// At most maxSize records are kept; the records closest to expiry are
+// evicted first.
+type recordStore struct {
+	sync.Mutex
+	logger   *logrus.Logger
+	database *badger.DB
+	maxSize  int
+	records  map[string]*recordEntry
+}
+
+func newRecordStore(logger *logrus.Logger, database *badger.DB, maxSize int) (*recordStore, error) {
+	rs := &recordStore{
+		logger:   logger,
+		database: database,
+		maxSize:  maxSize,
+		records:  make(map[string]*recordEntry),
+	}
+	if err := rs.load(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (rs *recordStore) makeKey(identity string) []byte {
+	key := dbprefix.PrefixBootNodeRecord()
+	key = append(key, []byte(identity)...)
+	return key
+}
+
+// load reads the persisted records and drops the ones that no longer verify
+func (rs *recordStore) load() error {
+	now := time.Now()
+	stale := [][]byte{}
+	err := rs.database.View(func(txn *badger.Txn) error {
+		prefix := dbprefix.PrefixBootNodeRecord()
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			itm := iter.Item()
+			key := itm.KeyCopy(nil)
+			value, err := itm.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			host, rb, err := unmarshalEntry(value)
+			if err != nil {
+				utils.DebugTrace(rs.logger, err)
+				stale = append(stale, key)
+				continue
+			}
+			r := &transport.NodeRecord{}
+			if err := r.UnmarshalBinary(rb); err != nil {
+				utils.DebugTrace(rs.logger, err)
+				stale = append(stale, key)
+				continue
+			}
+			if err := r.Verify(string(key[len(prefix):]), r.ChainID, now); err != nil {
+				stale = append(stale, key)
+				continue
+			}
+			addrs, err := resolveAddrs(r, host)
+			if err != nil {
+				stale = append(stale, key)
+				continue
+			}
+			rs.records[r.Identity] = &recordEntry{record: r, addrs: addrs}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return rs.database.Update(func(txn *badger.Txn) error {
+		for _, key := range stale {
+			if err := utils.DeleteValue(txn, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// add stores a verified record. Unspecified hosts in the record are resolved
+// to host, the address the node connected from.
+func (rs *recordStore) add(r *transport.NodeRecord, host string) error {
+	addrs, err := resolveAddrs(r, host)
+	if err != nil {
+		return err
+	}
+	rb, err := r.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	rs.Lock()
+	defer rs.Unlock()
+	if old, ok := rs.records[r.Identity]; ok && old.record.Expiry.After(r.Expiry) {
+		return nil
+	}
+	err = rs.database.Update(func(txn *badger.Txn) error {
+		return utils.SetValue(txn, rs.makeKey(r.Identity), marshalEntry(host, rb))
+	})
+	if err != nil {
+		return err
+	}
+	rs.records[r.Identity] = &recordEntry{record: r, addrs: addrs}
+	rs.evict()
+	return nil
+}
+
+// evict drops the records closest to expiry until the store fits maxSize.
+// The caller must hold the lock.
+func (rs *recordStore) evict() {
+	for len(rs.records) > rs.maxSize {
+		var oldest string
+		for ident, e := range rs.records {
+			if oldest == "" || e.record.Expiry.Before(rs.records[oldest].record.Expiry) {
+				oldest = ident
+			}
+		}
+		rs.remove(oldest)
+	}
+}
+
+// remove drops a record. The caller must hold the lock.
+func (rs *recordStore) remove(identity string) {
+	delete(rs.records, identity)
+	err := rs.database.Update(func(txn *badger.Txn) error {
+		return utils.DeleteValue(txn, rs.makeKey(identity))
+	})
+	if err != nil {
+		utils.DebugTrace(rs.logger, err)
+	}
+}
+
+// known returns the addresses of the listed nodes on chainID except the
+// node with identity exclude
+func (rs *recordStore) known(chainID types.ChainIdentifier, exclude string) []string {
+	rs.Lock()
+	defer rs.Unlock()
+	now := time.Now()
+	out := []string{}
+	for ident, e := range rs.records {
+		if ident == exclude || e.record.ChainID != chainID || !now.Before(e.record.Expiry) {
+			continue
+		}
+		for _, addr := range e.addrs {
+			out = append(out, addr.P2PAddr())
+		}
+	}
+	return out
+}
+
+// probe dials every listed node and drops the nodes that expired or failed
+// constants.BootNodeProbeFailures probes in a row. A probe only runs the
+// brontide handshake, which proves the node is alive and holds the key of
+// its record.
+func (rs *recordStore) probe(xport interfaces.P2PTransport) {
+	now := time.Now()
+	targets := make(map[string]interfaces.NodeAddr)
+	func() {
+		rs.Lock()
+		defer rs.Unlock()
+		for ident, e := range rs.records {
+			if !now.Before(e.record.Expiry) || len(e.addrs) == 0 {
+				rs.remove(ident)
+				continue
+			}
+			targets[ident] = e.addrs[0]
+		}
+	}()
+	sem := make(chan struct{}, constants.BootNodeProbeWorkers)
+	wg := sync.WaitGroup{}
+	for ident, addr := range targets {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(ident string, addr interfaces.NodeAddr) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			conn, err := xport.Dial(addr, types.Bootnode)
+			if err == nil {
+				if err := conn.Close(); err != nil {
+					utils.DebugTrace(rs.logger, err)
+				}
+			}
+			rs.probed(ident, err)
+		}(ident, addr)
+	}
+	wg.Wait()
+}
+
+// probed records the result of a probe
+func (rs *recordStore) probed(identity string, err error) {
+	rs.Lock()
+	defer rs.Unlock()
+	e, ok := rs.records[identity]
+	if !ok {
+		return
+	}
+	if err == nil {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures >= constants.BootNodeProbeFailures {
+		rs.logger.Debugf("Dropping unreachable node %s: %v", identity, err)
+		rs.remove(identity)
+	}
+}
@@ -0,0 +1,138 @@
+package bootnode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/types"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeRecord signs a record of a new node listening on all interfaces
+func makeRecord(t *testing.T, chainID types.ChainIdentifier, ttl time.Duration) *transport.NodeRecord {
+	privk, err := transport.NewTransportPrivateKey()
+	assert.Nil(t, err)
+	r, err := transport.NewNodeRecord(privk, chainID, nil, ttl)
+	assert.Nil(t, err)
+	addr, err := transport.NewNodeAddr(fmt.Sprintf("%08x|%s@0.0.0.0:4242", uint32(chainID), r.Identity))
+	assert.Nil(t, err)
+	r, err = transport.NewNodeRecord(privk, chainID, []interfaces.NodeAddr{addr}, ttl)
+	assert.Nil(t, err)
+	return r
+}
+
+func p2pAddr(r *transport.NodeRecord, host string) string {
+	return fmt.Sprintf("%08x|%s@%s:4242", uint32(r.ChainID), r.Identity, host)
+}
+
+func setupStore(t *testing.T, maxSize int) (*recordStore, *badger.DB) {
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+	database, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	rs, err := newRecordStore(logging.GetLogger("bootnode"), database, maxSize)
+	assert.Nil(t, err)
+	return rs, database
+}
+
+func TestRecordStoreLoad(t *testing.T) {
+	rs, database := setupStore(t, 8)
+	r1 := makeRecord(t, 7, time.Minute)
+	r2 := makeRecord(t, 7, time.Minute)
+	assert.Nil(t, rs.add(r1, "10.0.0.1"))
+	assert.Nil(t, rs.add(r2, "10.0.0.2"))
+
+	// A record that no longer verifies is dropped on load
+	forged := makeRecord(t, 7, time.Minute)
+	forged.ChainID = 8
+	fb, err := forged.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Nil(t, database.Update(func(txn *badger.Txn) error {
+		return utils.SetValue(txn, rs.makeKey(forged.Identity), marshalEntry("10.0.0.3", fb))
+	}))
+
+	// A restarted bootnode serves the records it had
+	restarted, err := newRecordStore(logging.GetLogger("bootnode"), database, 8)
+	assert.Nil(t, err)
+	known := restarted.known(7, "")
+	sort.Strings(known)
+	expected := []string{p2pAddr(r1, "10.0.0.1"), p2pAddr(r2, "10.0.0.2")}
+	sort.Strings(expected)
+	assert.Equal(t, expected, known)
+
+	err = database.View(func(txn *badger.Txn) error {
+		_, err := utils.GetValue(txn, rs.makeKey(forged.Identity))
+		return err
+	})
+	assert.Equal(t, badger.ErrKeyNotFound, err)
+}
+
+func TestRecordStoreEvict(t *testing.T) {
+	rs, database := setupStore(t, 2)
+	soon := makeRecord(t, 7, time.Minute)
+	later := makeRecord(t, 7, 2*time.Minute)
+	latest := makeRecord(t, 7, 3*time.Minute)
+	assert.Nil(t, rs.add(later, "10.0.0.1"))
+	assert.Nil(t, rs.add(soon, "10.0.0.2"))
+	assert.Nil(t, rs.add(latest, "10.0.0.3"))
+
+	// The record closest to expiry makes room
+	assert.Equal(t, 2, len(rs.records))
+	_, ok := rs.records[soon.Identity]
+	assert.False(t, ok)
+
+	restarted, err := newRecordStore(logging.GetLogger("bootnode"), database, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(restarted.records))
+	_, ok = restarted.records[soon.Identity]
+	assert.False(t, ok)
+}
+
+func TestRecordStoreKnown(t *testing.T) {
+	rs, _ := setupStore(t, 8)
+	r1 := makeRecord(t, 7, time.Minute)
+	r2 := makeRecord(t, 7, time.Minute)
+	other := makeRecord(t, 8, time.Minute)
+	assert.Nil(t, rs.add(r1, "10.0.0.1"))
+	assert.Nil(t, rs.add(r2, "10.0.0.2"))
+	assert.Nil(t, rs.add(other, "10.0.0.3"))
+
+	// Only nodes of the chain are listed, and never the asking node
+	assert.Equal(t, []string{p2pAddr(r2, "10.0.0.2")}, rs.known(7, r1.Identity))
+	assert.Equal(t, []string{p2pAddr(other, "10.0.0.3")}, rs.known(8, ""))
+	assert.Equal(t, []string{}, rs.known(9, ""))
+}
+
+func TestRecordStoreProbed(t *testing.T) {
+	rs, database := setupStore(t, 8)
+	r := makeRecord(t, 7, time.Minute)
+	assert.Nil(t, rs.add(r, "10.0.0.1"))
+
+	errDial := errors.New("dial failed")
+
+	// A node that answers again starts over
+	for i := 0; i < constants.BootNodeProbeFailures-1; i++ {
+		rs.probed(r.Identity, errDial)
+	}
+	rs.probed(r.Identity, nil)
+	for i := 0; i < constants.BootNodeProbeFailures-1; i++ {
+		rs.probed(r.Identity, errDial)
+	}
+	assert.Equal(t, 1, len(rs.known(7, "")))
+
+	rs.probed(r.Identity, errDial)
+	assert.Equal(t, 0, len(rs.known(7, "")))
+
+	restarted, err := newRecordStore(logging.GetLogger("bootnode"), database, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(restarted.known(7, "")))
+}
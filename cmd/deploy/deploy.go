@@ -8,6 +8,7 @@ import (
 
 	"github.com/MadBase/MadNet/blockchain"
 	"github.com/MadBase/MadNet/blockchain/interfaces"
+	"github.com/MadBase/MadNet/cmd/utils"
 	"github.com/MadBase/MadNet/config"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/MadBase/bridge/bindings"
@@ -30,15 +31,25 @@ func deployNode(cmd *cobra.Command, args []string) {
 	logger := logging.GetLogger("deploy")
 	logger.Info("Deploying contracts...")
 
+	signer, err := utils.NewSigner()
+	if err != nil {
+		logger.Fatalf("Could not create signer: %v", err)
+	}
+
 	eth, err := blockchain.NewEthereumEndpoint(
-		config.Configuration.Ethereum.Endpoint,
-		config.Configuration.Ethereum.Keystore,
-		config.Configuration.Ethereum.Passcodes,
+		config.Configuration.Ethereum.Endpoints(),
+		uint64(config.Configuration.Ethereum.ChainID),
+		signer,
 		config.Configuration.Ethereum.DefaultAccount,
 		config.Configuration.Ethereum.Timeout,
 		config.Configuration.Ethereum.RetryCount,
 		config.Configuration.Ethereum.RetryDelay,
-		config.Configuration.Ethereum.FinalityDelay)
+		config.Configuration.Ethereum.FinalityDelay,
+		config.Configuration.Ethereum.EndpointMinimumPeers,
+		config.Configuration.Ethereum.EndpointQuorum,
+		config.Configuration.Ethereum.EndpointMaxHeadAge,
+		config.Configuration.Ethereum.MaxGasPriceWei(),
+		uint64(config.Configuration.Ethereum.TxnReplaceAfter))
 	if err != nil {
 		logger.Errorf("Could not connect to Ethereum: %v", err)
 	}
@@ -10,8 +10,11 @@ import (
 
 	"github.com/MadBase/MadNet/cmd/bootnode"
 	"github.com/MadBase/MadNet/cmd/deploy"
+	"github.com/MadBase/MadNet/cmd/monitor"
+	"github.com/MadBase/MadNet/cmd/snapshot"
 	"github.com/MadBase/MadNet/cmd/utils"
 	"github.com/MadBase/MadNet/cmd/validator"
+	"github.com/MadBase/MadNet/cmd/verify"
 	"github.com/MadBase/MadNet/config"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/sirupsen/logrus"
@@ -27,6 +30,13 @@ type option struct {
 	value interface{}
 }
 
+// durationOption is the value of a duration option whose flag doesn't
+// default to a second
+type durationOption struct {
+	value        *time.Duration
+	defaultValue time.Duration
+}
+
 // Runner wraps a cobra command's Run() and sets up loggers first
 // It assumes 'logging' flag uses the format "pkg1=debug,pkg2=error"
 func runner(commandRun func(*cobra.Command, []string)) func(*cobra.Command, []string) {
@@ -86,33 +96,55 @@ func main() {
 			{"chain.transactionDBInMemory", "", "", &config.Configuration.Chain.TransactionDbInMemory},
 			{"chain.monitorDB", "", "", &config.Configuration.Chain.MonitorDbPath},
 			{"chain.monitorDBInMemory", "", "", &config.Configuration.Chain.MonitorDbInMemory},
-			{"ethereum.endpoint", "", "", &config.Configuration.Ethereum.Endpoint},
+			{"ethereum.chainId", "", "Chain ID the Ethereum endpoints must be on, zero to accept any", &config.Configuration.Ethereum.ChainID},
+			{"ethereum.endpoint", "", "Comma separated urls of the Ethereum endpoints in order of preference", &config.Configuration.Ethereum.Endpoint},
 			{"ethereum.endpointPeers", "", "Minimum peers required", &config.Configuration.Ethereum.EndpointMinimumPeers},
+			{"ethereum.endpointQuorum", "", "Number of endpoints that must return the same events", &config.Configuration.Ethereum.EndpointQuorum},
+			{"ethereum.endpointMaxHeadAge", "", "Age of the head block after which an endpoint is unhealthy, zero to not check", durationOption{&config.Configuration.Ethereum.EndpointMaxHeadAge, 0}},
 			{"ethereum.keystore", "", "", &config.Configuration.Ethereum.Keystore},
 			{"ethereum.timeout", "", "", &config.Configuration.Ethereum.Timeout},
 			{"ethereum.testEther", "", "", &config.Configuration.Ethereum.TestEther},
 			{"ethereum.deployAccount", "", "", &config.Configuration.Ethereum.DeployAccount},
 			{"ethereum.defaultAccount", "", "", &config.Configuration.Ethereum.DefaultAccount},
+			{"ethereum.maxGasPrice", "", "Highest gas price in gwei offered for a transaction, zero for no limit", &config.Configuration.Ethereum.MaxGasPrice},
+			{"ethereum.txnReplaceAfter", "", "Blocks to wait for a transaction to be mined before sending it again with a higher gas price, zero to never resend", &config.Configuration.Ethereum.TxnReplaceAfter},
 			{"ethereum.finalityDelay", "", "Number blocks before we consider a block final", &config.Configuration.Ethereum.FinalityDelay},
 			{"ethereum.retryCount", "", "Number of times to retry an Ethereum operation", &config.Configuration.Ethereum.RetryCount},
 			{"ethereum.retryDelay", "", "Delay between retry attempts", &config.Configuration.Ethereum.RetryDelay},
-			{"ethereum.passcodes", "", "Passcodes for keystore", &config.Configuration.Ethereum.Passcodes},
+			{"ethereum.passcodes", "", "Passcodes for keystore, if empty they're read from MADNET_ETHEREUM_PASSCODE or asked for", &config.Configuration.Ethereum.Passcodes},
+			{"ethereum.signer", "", "Signer of transactions, one of keystore, clef or web3signer. Validators need keystore, they sign consensus messages with the same key", &config.Configuration.Ethereum.Signer},
+			{"ethereum.signerEndpoint", "", "Url of the clef or web3signer remote signer", &config.Configuration.Ethereum.SignerEndpoint},
 			{"ethereum.startingBlock", "", "The first block we care about", &config.Configuration.Ethereum.StartingBlock},
 			{"ethereum.registryAddress", "", "", &config.Configuration.Ethereum.RegistryAddress},
 			{"monitor.batchSize", "", "", &config.Configuration.Monitor.BatchSize},
+			{"monitor.confirm", "", "Write the changes of monitor commands to the monitor db instead of only showing them", &config.Configuration.Monitor.Confirm},
 			{"monitor.interval", "", "", &config.Configuration.Monitor.Interval},
 			{"transport.peerLimitMin", "", "", &config.Configuration.Transport.PeerLimitMin},
 			{"transport.peerLimitMax", "", "", &config.Configuration.Transport.PeerLimitMax},
 			{"transport.privateKey", "", "", &config.Configuration.Transport.PrivateKey},
 			{"transport.originLimit", "", "", &config.Configuration.Transport.OriginLimit},
-			{"transport.whitelist", "", "", &config.Configuration.Transport.Whitelist},
+			{"transport.whitelist", "", "Comma separated transport identities of the only peers allowed to connect; reloaded on SIGHUP", &config.Configuration.Transport.Whitelist},
+			{"transport.whitelistHosts", "", "Comma separated hosts, IP addresses or CIDR ranges of the only peers used from discovery; reloaded on SIGHUP", &config.Configuration.Transport.WhitelistHosts},
 			{"transport.bootnodeAddresses", "", "", &config.Configuration.Transport.BootNodeAddresses},
 			{"transport.p2pListeningAddress", "", "", &config.Configuration.Transport.P2PListeningAddress},
 			{"transport.discoveryListeningAddress", "", "", &config.Configuration.Transport.DiscoveryListeningAddress},
 			{"transport.localStateListeningAddress", "", "", &config.Configuration.Transport.LocalStateListeningAddress},
 			{"transport.timeout", "", "", &config.Configuration.Transport.Timeout},
 			{"transport.firewallMode", "", "", &config.Configuration.Transport.FirewallMode},
-			{"transport.firewallHost", "", "", &config.Configuration.Transport.FirewallHost}},
+			{"transport.firewallHost", "", "", &config.Configuration.Transport.FirewallHost},
+			{"transport.sentryAddresses", "", "Comma separated addresses of the sentry nodes; a validator with sentries only peers with them", &config.Configuration.Transport.SentryAddresses},
+			{"transport.privatePeers", "", "Comma separated identities of peers whose addresses are never shared", &config.Configuration.Transport.PrivatePeers},
+			{"transport.banThreshold", "", "Score below which a peer is banned", &config.Configuration.Transport.BanThreshold},
+			{"transport.banDuration", "", "How long a misbehaving peer stays banned, the default duration if zero", durationOption{&config.Configuration.Transport.BanDuration, 0}},
+			{"transport.gossipRateLimit", "", "Gossip messages per second accepted from a peer", &config.Configuration.Transport.GossipRateLimit},
+			{"transport.gossipRateBurst", "", "Gossip messages a peer may send at once", &config.Configuration.Transport.GossipRateBurst},
+			{"transport.requestRateLimit", "", "Requests per second accepted from a peer", &config.Configuration.Transport.RequestRateLimit},
+			{"transport.requestRateBurst", "", "Requests a peer may send at once", &config.Configuration.Transport.RequestRateBurst},
+			{"transport.snapShotRateLimit", "", "Snapshot requests per second accepted from a peer", &config.Configuration.Transport.SnapShotRateLimit},
+			{"transport.snapShotRateBurst", "", "Snapshot requests a peer may send at once", &config.Configuration.Transport.SnapShotRateBurst},
+			{"transport.bandwidthLimit", "", "Bytes per second sent or received over all connections, zero for no limit", &config.Configuration.Transport.BandwidthLimit},
+			{"transport.peerBandwidthLimit", "", "Bytes per second sent or received over the connections to one peer, zero for no limit", &config.Configuration.Transport.PeerBandwidthLimit},
+			{"transport.compression", "", "Compress P2P payloads sent to peers that support it", &config.Configuration.Transport.Compression}},
 
 		&utils.Command: {
 			{"utils.status", "", "", &config.Configuration.Utils.Status}},
@@ -127,7 +159,8 @@ func main() {
 
 		&bootnode.Command: {
 			{"bootnode.listeningAddress", "", "", &config.Configuration.BootNode.ListeningAddress},
-			{"bootnode.cacheSize", "", "", &config.Configuration.BootNode.CacheSize}},
+			{"bootnode.cacheSize", "", "Maximum number of nodes listed", &config.Configuration.BootNode.CacheSize},
+			{"bootnode.databasePath", "", "Directory the node records are persisted in, in memory if empty", &config.Configuration.BootNode.DatabasePath}},
 
 		&validator.Command: {
 			{"validator.rewardAccount", "", "", &config.Configuration.Validator.RewardAccount},
@@ -136,6 +169,21 @@ func main() {
 		&deploy.Command: {
 			{"deploy.migrations", "", "", &config.Configuration.Deploy.Migrations},
 			{"deploy.testMigrations", "", "", &config.Configuration.Deploy.TestMigrations}},
+
+		&verify.Command: {},
+
+		&snapshot.Command: {},
+		&snapshot.ExportCommand: {
+			{"snapshot.height", "", "Height of the snapshot to export, the most recent if zero", &config.Configuration.Snapshot.Height}},
+		&snapshot.ImportCommand: {},
+
+		&monitor.Command:           {},
+		&monitor.ShowCommand:       {},
+		&monitor.RewindCommand:     {},
+		&monitor.CancelTaskCommand: {},
+		&monitor.RerunTaskCommand:  {},
+		&monitor.ClearDkgCommand:   {},
+		&monitor.TasksCommand:      {},
 	}
 
 	// Establish command hierarchy
@@ -143,6 +191,17 @@ func main() {
 		&bootnode.Command:            &rootCommand,
 		&validator.Command:           &rootCommand,
 		&deploy.Command:              &rootCommand,
+		&verify.Command:              &rootCommand,
+		&snapshot.Command:            &rootCommand,
+		&snapshot.ExportCommand:      &snapshot.Command,
+		&snapshot.ImportCommand:      &snapshot.Command,
+		&monitor.Command:             &rootCommand,
+		&monitor.ShowCommand:         &monitor.Command,
+		&monitor.RewindCommand:       &monitor.Command,
+		&monitor.CancelTaskCommand:   &monitor.Command,
+		&monitor.RerunTaskCommand:    &monitor.Command,
+		&monitor.ClearDkgCommand:     &monitor.Command,
+		&monitor.TasksCommand:        &monitor.Command,
 		&utils.Command:               &rootCommand,
 		&utils.ApproveTokensCommand:  &utils.Command,
 		&utils.EthdkgCommand:         &utils.Command,
@@ -169,13 +228,18 @@ func main() {
 		var defaultStringArray []string
 		for _, o := range options[c] {
 
+			durDefault := 1 * time.Second
+			if d, ok := o.value.(durationOption); ok {
+				o.value, durDefault = d.value, d.defaultValue
+			}
+
 			typeOfPtr := reflect.TypeOf(o.value)
 			if typeOfPtr.Kind() != reflect.Ptr {
 				logger.Fatalf("Option value for %v should be supplied as a pointer.", o.name)
 			} else {
 				// These cascading type asserts don't work in a switch statement
 				if durPtr, ok := o.value.(*time.Duration); ok {
-					cFlags.DurationVarP(durPtr, o.name, o.short, 1*time.Second, o.usage)
+					cFlags.DurationVarP(durPtr, o.name, o.short, durDefault, o.usage)
 				} else if strPtr, ok := o.value.(*string); ok {
 					cFlags.StringVarP(strPtr, o.name, o.short, "", o.usage)
 				} else if strArrayPtr, ok := o.value.(*[]string); ok {
@@ -18,12 +18,21 @@ type {{$rpc.Service}}{{$rpc.Name}}Handler interface {
 	Handle{{$rpc.Service}}{{$rpc.Name}}(context.Context, *{{$rpc.RequestType}}) (*{{$rpc.ReturnsType}}, error)
 }
 {{end}}{{end}}
+// RateLimiter is consulted by the inboundRPCDispatch before every call is
+// dispatched to a handler. method is the name of the call as <Service><Name>.
+// A non nil error rejects the call and is returned to the caller.
+type RateLimiter interface {
+	Allow(ctx context.Context, method string) error
+}
+
 // inboundRPCDispatch allows handlers to be registered for all RPC methods
 // using the Register<Service><Name> methods.
 // After registration, the inboundRPCDispatch struct will dispatch calls
 // to an rpc method via the methods named as <Service><Name>(...)
 type inboundRPCDispatch struct {
-	sync.Mutex{{range $service := $Services}}{{range $rpc := $service.RPC}}
+	sync.Mutex
+	// limiter is the optional rate limiter for all RPC methods
+	limiter RateLimiter{{range $service := $Services}}{{range $rpc := $service.RPC}}
   //	handler{{$rpc.Service}}{{$rpc.Name}} is the registered handler for the
 	//  {{$rpc.Name}} RPC method of service {{$rpc.Service}}
 	handler{{$rpc.Service}}{{$rpc.Name}} {{$rpc.Service}}{{$rpc.Name}}Handler
@@ -32,6 +41,25 @@ type inboundRPCDispatch struct {
 	// method has been registered.
 	waitChan{{$rpc.Service}}{{$rpc.Name}} chan struct{}{{end}}{{end}}
 }
+
+// SetRateLimiter will register the object 'l' as the rate limiter for all
+// RPC methods
+func (d *inboundRPCDispatch) SetRateLimiter(l RateLimiter) {
+	d.Lock()
+	defer d.Unlock()
+	d.limiter = l
+}
+
+// allow returns the error of the rate limiter if one is registered
+func (d *inboundRPCDispatch) allow(ctx context.Context, method string) error {
+	d.Lock()
+	l := d.limiter
+	d.Unlock()
+	if l == nil {
+		return nil
+	}
+	return l.Allow(ctx, method)
+}
 {{range $service := $Services}}{{range $rpc := $service.RPC}}
 // Register{{$rpc.Service}}{{$rpc.Name}} will register the object 't' as the service
 // handler for the RPC method {{$rpc.Name}} from service {{$rpc.Service}}
@@ -51,6 +79,10 @@ func (d *inboundRPCDispatch) Register{{$rpc.Service}}{{$rpc.Name}}(t {{$rpc.Serv
 // {{$rpc.Service}}{{$rpc.Name}} will invoke the handler for the RPC method
 // {{$rpc.Name}} from service {{$rpc.Service}}
 func (d *inboundRPCDispatch) {{$rpc.Service}}{{$rpc.Name}}(ctx context.Context, r *{{$rpc.RequestType}}) (*{{$rpc.ReturnsType}}, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "{{$rpc.Service}}{{$rpc.Name}}"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -99,9 +131,19 @@ package {{.Package}}
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
+
+type testRateLimiter struct {
+	method string
+}
+
+func (tl *testRateLimiter) Allow(ctx context.Context, method string) error {
+	tl.method = method
+	return errors.New("rate limited")
+}
 {{range $service := $Services}}{{range $rpc := $service.RPC}}
 type test{{$rpc.Service}}{{$rpc.Name}}Handler struct{}
 
@@ -170,5 +212,28 @@ func Test{{$rpc.Service}}{{$rpc.Name}}Cancel(t *testing.T) {
 	cancelErr := <-errChan
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
+
+func Test{{$rpc.Service}}{{$rpc.Name}}RateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &test{{$rpc.Service}}{{$rpc.Name}}Handler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.Register{{$rpc.Service}}{{$rpc.Name}}(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := Generated{{$rpc.Service}}Server{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.{{$rpc.Name}}(context.Background(), &{{$rpc.RequestType}}{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "{{$rpc.Service}}{{$rpc.Name}}", l.method, "the rate limiter must be called with the method name")
+}
 {{end}}{{end}}
 `
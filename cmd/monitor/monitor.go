@@ -0,0 +1,214 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/MadBase/MadNet/blockchain/monitor"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/pborman/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Command is the parent of the commands inspecting and changing the monitor state
+var Command = cobra.Command{
+	Use:   "monitor",
+	Short: "Inspects and repairs the persisted monitor state",
+	Long:  "monitor reads the Ethereum monitor state from the monitor db. The node must not be running. Commands changing the state only show the change unless monitor.confirm is set."}
+
+// ShowCommand is the cobra.Command for dumping the monitor state
+var ShowCommand = cobra.Command{
+	Use:   "show",
+	Short: "Prints the monitor state as JSON",
+	Long:  "show prints the persisted monitor state, including the ETHDKG round, validator sets and scheduled tasks, as JSON.",
+	Args:  cobra.NoArgs,
+	Run:   showState}
+
+// RewindCommand is the cobra.Command for processing Ethereum blocks again
+var RewindCommand = cobra.Command{
+	Use:   "rewind <block>",
+	Short: "Processes the Ethereum events after a block again",
+	Long:  "rewind lowers the highest processed block so the events after it are processed again when the node starts. Effects of events already processed are not undone. It also resumes a monitor stopped by an Ethereum reorg it couldn't roll back, rewind to the block the chain forked after once the node is repaired.",
+	Args:  cobra.ExactArgs(1),
+	Run:   rewind}
+
+// CancelTaskCommand is the cobra.Command for removing a scheduled task
+var CancelTaskCommand = cobra.Command{
+	Use:   "cancel <task id>",
+	Short: "Removes a scheduled task",
+	Long:  "cancel removes the task with the id shown by monitor show from the schedule.",
+	Args:  cobra.ExactArgs(1),
+	Run:   cancelTask}
+
+// RerunTaskCommand is the cobra.Command for running a scheduled task again
+var RerunTaskCommand = cobra.Command{
+	Use:   "rerun <task id>",
+	Short: "Schedules a task to run again",
+	Long:  "rerun schedules the task with the id shown by monitor show again under a new id, for what is left of its block range after the highest processed block.",
+	Args:  cobra.ExactArgs(1),
+	Run:   rerunTask}
+
+// ClearDkgCommand is the cobra.Command for abandoning a failed round of ETHDKG
+var ClearDkgCommand = cobra.Command{
+	Use:   "clear-dkg",
+	Short: "Abandons the current ETHDKG round",
+	Long:  "clear-dkg drops what is known of the current ETHDKG round and its scheduled tasks so the node takes part in the next round from scratch.",
+	Args:  cobra.NoArgs,
+	Run:   clearDkg}
+
+// TasksCommand is the cobra.Command for showing the history of task runs
+var TasksCommand = cobra.Command{
+	Use:   "tasks",
+	Short: "Prints the attempts of snapshot and ETHDKG tasks as JSON",
+	Long:  "tasks prints every persisted task run with its status, attempts and the errors they failed with. A running node serves the same over the GetTaskRuns local RPC.",
+	Args:  cobra.NoArgs,
+	Run:   showTasks}
+
+func showState(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("monitor")
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	_, state := loadState(ctx, logger)
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		logger.Fatalf("Could not encode monitor state: %v", err)
+	}
+	fmt.Println(string(raw))
+}
+
+func showTasks(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("monitor")
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	runs, err := openDatabase(ctx, logger).FindTaskRuns()
+	if err != nil {
+		logger.Fatalf("Could not find task runs: %v", err)
+	}
+	raw, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		logger.Fatalf("Could not encode task runs: %v", err)
+	}
+	fmt.Println(string(raw))
+}
+
+func rewind(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("monitor")
+
+	block, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		logger.Fatalf("Invalid block %q: %v", args[0], err)
+	}
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	database, state := loadState(ctx, logger)
+	highest := state.HighestBlockProcessed
+	if err := monitor.Rewind(state, block); err != nil {
+		logger.Fatalf("Could not rewind from block %d to %d: %v", highest, block, err)
+	}
+	logger.Infof("Rewinding highest block processed from %d to %d", highest, block)
+	saveState(logger, database, state)
+}
+
+func cancelTask(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("monitor")
+
+	taskID := parseTaskID(logger, args[0])
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	database, state := loadState(ctx, logger)
+	if err := monitor.CancelTask(state, taskID); err != nil {
+		logger.Fatalf("Could not cancel task %v: %v", taskID, err)
+	}
+	logger.Infof("Cancelling task %v", taskID)
+	saveState(logger, database, state)
+}
+
+func rerunTask(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("monitor")
+
+	taskID := parseTaskID(logger, args[0])
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	database, state := loadState(ctx, logger)
+	newID, err := monitor.RerunTask(state, taskID)
+	if err != nil {
+		logger.Fatalf("Could not rerun task %v: %v", taskID, err)
+	}
+	logger.Infof("Rescheduling task %v as %v", taskID, newID)
+	saveState(logger, database, state)
+}
+
+func clearDkg(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("monitor")
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	database, state := loadState(ctx, logger)
+	if err := monitor.ClearDkg(state); err != nil {
+		logger.Fatalf("Could not clear ETHDKG round: %v", err)
+	}
+	logger.Info("Clearing ETHDKG round and its scheduled tasks")
+	saveState(logger, database, state)
+}
+
+func parseTaskID(logger *logrus.Logger, arg string) uuid.UUID {
+	taskID := uuid.Parse(arg)
+	if taskID == nil {
+		logger.Fatalf("Invalid task id %q", arg)
+	}
+	return taskID
+}
+
+// openDatabase opens the monitor db, which fails while the node is running
+func openDatabase(ctx context.Context, logger *logrus.Logger) monitor.Database {
+	chain := config.Configuration.Chain
+	if chain.MonitorDbInMemory {
+		logger.Fatal("The monitor db is in memory, there is no persisted state")
+	}
+
+	rawMonDb, err := utils.OpenBadger(ctx.Done(), chain.MonitorDbPath, false)
+	if err != nil {
+		logger.Fatalf("Could not open monitor db, make sure the node is stopped: %v", err)
+	}
+	return monitor.NewDatabaseFromExisting(rawMonDb)
+}
+
+// loadState reads the state from the monitor db
+func loadState(ctx context.Context, logger *logrus.Logger) (monitor.Database, *objects.MonitorState) {
+	database := openDatabase(ctx, logger)
+
+	state, err := database.FindState()
+	if err != nil {
+		logger.Fatalf("Could not find monitor state: %v", err)
+	}
+	return database, state
+}
+
+// saveState writes the state if the change was confirmed
+func saveState(logger *logrus.Logger, database monitor.Database, state *objects.MonitorState) {
+	if !config.Configuration.Monitor.Confirm {
+		logger.Warn("Change not written, run again with --monitor.confirm to apply it")
+		return
+	}
+	if err := database.UpdateState(state); err != nil {
+		logger.Fatalf("Could not write monitor state: %v", err)
+	}
+	logger.Info("Monitor state written")
+}
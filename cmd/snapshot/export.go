@@ -0,0 +1,252 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	trie "github.com/MadBase/MadNet/badgerTrie"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type pendingNode struct {
+	root  []byte
+	layer int
+}
+
+// exporter walks the state and header tries of a snapshot and writes every
+// node and leaf to a snapshot file.
+type exporter struct {
+	logger *logrus.Logger
+
+	srcDB  *db.Database
+	srcApp *application.Application
+
+	// the walked nodes are stored into a scratch database so that the child
+	// keys and leaves of every node are found exactly as fast sync finds them
+	scratchDB  *db.Database
+	scratchApp *application.Application
+
+	counts fileCounts
+}
+
+func newExporter(logger *logrus.Logger, closeChan <-chan struct{}, stateDb *badger.DB) (*exporter, error) {
+	srcDB := &db.Database{}
+	if err := srcDB.Init(stateDb); err != nil {
+		return nil, err
+	}
+	srcApp, err := newApplication(closeChan, srcDB)
+	if err != nil {
+		return nil, err
+	}
+	scratchStateDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		return nil, err
+	}
+	scratchDB := &db.Database{}
+	if err := scratchDB.Init(scratchStateDb); err != nil {
+		return nil, err
+	}
+	scratchApp, err := newApplication(closeChan, scratchDB)
+	if err != nil {
+		return nil, err
+	}
+	return &exporter{
+		logger:     logger,
+		srcDB:      srcDB,
+		srcApp:     srcApp,
+		scratchDB:  scratchDB,
+		scratchApp: scratchApp,
+	}, nil
+}
+
+// newApplication builds an application on top of database with an in
+// memory transaction database
+func newApplication(closeChan <-chan struct{}, database *db.Database) (*application.Application, error) {
+	txnDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		return nil, err
+	}
+	dph := &deposit.Handler{}
+	if err := dph.Init(); err != nil {
+		return nil, err
+	}
+	app := &application.Application{}
+	if err := app.Init(database, txnDb, dph); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// snapshotHeader returns the snapshot block header at height, or the most
+// recent snapshot if height is zero.
+func (e *exporter) snapshotHeader(height uint32) (*objs.BlockHeader, error) {
+	var bh *objs.BlockHeader
+	err := e.srcDB.View(func(txn *badger.Txn) error {
+		var err error
+		if height == 0 {
+			bh, err = e.srcDB.GetLastSnapshot(txn)
+		} else {
+			bh, err = e.srcDB.GetSnapshotBlockHeader(txn, height)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bh, nil
+}
+
+// export writes the snapshot at bh to w
+func (e *exporter) export(w io.Writer, bh *objs.BlockHeader) error {
+	fw, err := newFileWriter(w)
+	if err != nil {
+		return err
+	}
+	bhBytes, err := bh.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := fw.write(recordSnapshotHeader, bhBytes); err != nil {
+		return err
+	}
+	if err := e.exportState(fw, bh); err != nil {
+		return err
+	}
+	if err := e.exportHeaders(fw, bh); err != nil {
+		return err
+	}
+	if err := fw.write(recordEnd, e.counts.MarshalBinary()); err != nil {
+		return err
+	}
+	return fw.flush()
+}
+
+func (e *exporter) exportState(fw *fileWriter, bh *objs.BlockHeader) error {
+	height := bh.BClaims.Height
+	if bytes.Equal(bh.BClaims.StateRoot, make([]byte, constants.HashLen)) {
+		return nil
+	}
+	queue := []pendingNode{{root: bh.BClaims.StateRoot, layer: 0}}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		var batch []byte
+		err := e.srcDB.View(func(txn *badger.Txn) error {
+			var err error
+			batch, err = e.srcApp.GetSnapShotNode(txn, height, next.root)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("state node %x: %v", next.root, err)
+		}
+		var leaves []trie.LeafNode
+		err = e.scratchDB.Update(func(txn *badger.Txn) error {
+			children, layer, lvs, err := e.scratchApp.StoreSnapShotNode(txn, batch, next.root, next.layer)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				queue = append(queue, pendingNode{root: child, layer: layer})
+			}
+			leaves = lvs
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("state node %x: %v", next.root, err)
+		}
+		if err := fw.write(recordStateNode, marshalNode(next.layer, next.root, batch)); err != nil {
+			return err
+		}
+		e.counts.StateNodes++
+		for _, lf := range leaves {
+			var data []byte
+			err := e.srcDB.View(func(txn *badger.Txn) error {
+				var err error
+				data, err = e.srcApp.GetSnapShotStateData(txn, utils.CopySlice(lf.Key))
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("state leaf %x: %v", lf.Key, err)
+			}
+			if err := fw.write(recordStateLeaf, marshalLeaf(lf.Key, lf.Value, data)); err != nil {
+				return err
+			}
+			e.counts.StateLeaves++
+		}
+		if e.counts.StateNodes%10000 == 0 {
+			e.logger.Infof("Exported %d state nodes and %d state leaves", e.counts.StateNodes, e.counts.StateLeaves)
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportHeaders(fw *fileWriter, bh *objs.BlockHeader) error {
+	if bytes.Equal(bh.BClaims.HeaderRoot, make([]byte, constants.HashLen)) {
+		return nil
+	}
+	queue := []pendingNode{{root: bh.BClaims.HeaderRoot, layer: 0}}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		var batch []byte
+		err := e.srcDB.View(func(txn *badger.Txn) error {
+			var err error
+			batch, err = e.srcDB.GetSnapShotHdrNode(txn, next.root)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("header node %x: %v", next.root, err)
+		}
+		var leaves []trie.LeafNode
+		err = e.scratchDB.Update(func(txn *badger.Txn) error {
+			children, layer, lvs, err := e.scratchDB.SetSnapShotHdrNode(txn, batch, next.root, next.layer)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				queue = append(queue, pendingNode{root: child, layer: layer})
+			}
+			leaves = lvs
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("header node %x: %v", next.root, err)
+		}
+		if err := fw.write(recordHdrNode, marshalNode(next.layer, next.root, batch)); err != nil {
+			return err
+		}
+		e.counts.HdrNodes++
+		for _, lf := range leaves {
+			bhHeight, err := utils.UnmarshalUint32(lf.Key[0:4])
+			if err != nil {
+				return err
+			}
+			var hdr *objs.BlockHeader
+			err = e.srcDB.View(func(txn *badger.Txn) error {
+				var err error
+				hdr, err = e.srcDB.GetCommittedBlockHeader(txn, bhHeight)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("block header at height %d: %v", bhHeight, err)
+			}
+			hdrBytes, err := hdr.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if err := fw.write(recordBlockHeader, hdrBytes); err != nil {
+				return err
+			}
+			e.counts.BlockHeaders++
+		}
+	}
+	return nil
+}
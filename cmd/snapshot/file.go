@@ -0,0 +1,190 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/utils"
+)
+
+// A snapshot file starts with fileMagic and the format version followed by
+// a sequence of records. A record is a one byte record type, the four byte
+// big endian length of the payload and the payload.
+//
+// The snapshot block header is always the first record and recordEnd is
+// always the last. Every node is written before its children and the state
+// leaves and block headers referenced by a node follow that node, so the
+// file can be verified against the roots of the snapshot block header in a
+// single pass.
+const (
+	fileMagic   = "MNSNAP"
+	fileVersion = uint32(1)
+
+	// maxRecordSize bounds the payload of a single record
+	maxRecordSize = 64 * 1024 * 1024
+)
+
+type recordType byte
+
+const (
+	// recordSnapshotHeader holds the marshalled snapshot block header
+	recordSnapshotHeader recordType = iota + 1
+	// recordStateNode holds layer | root | batch of a state trie node
+	recordStateNode
+	// recordStateLeaf holds utxoID | preHash | utxo of a state trie leaf
+	recordStateLeaf
+	// recordHdrNode holds layer | root | batch of a header trie node
+	recordHdrNode
+	// recordBlockHeader holds a marshalled block header referenced by a
+	// header trie leaf
+	recordBlockHeader
+	// recordEnd holds the number of state nodes, state leaves, header
+	// nodes and block headers written
+	recordEnd
+)
+
+// fileCounts is the payload of recordEnd
+type fileCounts struct {
+	StateNodes   uint32
+	StateLeaves  uint32
+	HdrNodes     uint32
+	BlockHeaders uint32
+}
+
+func (fc *fileCounts) MarshalBinary() []byte {
+	out := []byte{}
+	out = append(out, utils.MarshalUint32(fc.StateNodes)...)
+	out = append(out, utils.MarshalUint32(fc.StateLeaves)...)
+	out = append(out, utils.MarshalUint32(fc.HdrNodes)...)
+	out = append(out, utils.MarshalUint32(fc.BlockHeaders)...)
+	return out
+}
+
+func (fc *fileCounts) UnmarshalBinary(v []byte) error {
+	if len(v) != 16 {
+		return errors.New("invalid end record")
+	}
+	fc.StateNodes, _ = utils.UnmarshalUint32(v[0:4])
+	fc.StateLeaves, _ = utils.UnmarshalUint32(v[4:8])
+	fc.HdrNodes, _ = utils.UnmarshalUint32(v[8:12])
+	fc.BlockHeaders, _ = utils.UnmarshalUint32(v[12:16])
+	return nil
+}
+
+// marshalNode encodes the payload of a state or header trie node record
+func marshalNode(layer int, root []byte, batch []byte) []byte {
+	out := utils.MarshalUint32(uint32(layer))
+	out = append(out, root...)
+	out = append(out, batch...)
+	return out
+}
+
+func unmarshalNode(v []byte) (int, []byte, []byte, error) {
+	if len(v) < 4+constants.HashLen {
+		return 0, nil, nil, errors.New("invalid node record")
+	}
+	layer, _ := utils.UnmarshalUint32(v[0:4])
+	root := utils.CopySlice(v[4 : 4+constants.HashLen])
+	batch := utils.CopySlice(v[4+constants.HashLen:])
+	return int(layer), root, batch, nil
+}
+
+// marshalLeaf encodes the payload of a state leaf record
+func marshalLeaf(key []byte, value []byte, data []byte) []byte {
+	out := []byte{}
+	out = append(out, key...)
+	out = append(out, value...)
+	out = append(out, data...)
+	return out
+}
+
+func unmarshalLeaf(v []byte) ([]byte, []byte, []byte, error) {
+	if len(v) < 2*constants.HashLen {
+		return nil, nil, nil, errors.New("invalid leaf record")
+	}
+	key := utils.CopySlice(v[:constants.HashLen])
+	value := utils.CopySlice(v[constants.HashLen : 2*constants.HashLen])
+	data := utils.CopySlice(v[2*constants.HashLen:])
+	return key, value, data, nil
+}
+
+type fileWriter struct {
+	w *bufio.Writer
+}
+
+func newFileWriter(w io.Writer) (*fileWriter, error) {
+	fw := &fileWriter{w: bufio.NewWriter(w)}
+	if _, err := fw.w.WriteString(fileMagic); err != nil {
+		return nil, err
+	}
+	if _, err := fw.w.Write(utils.MarshalUint32(fileVersion)); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+func (fw *fileWriter) write(rt recordType, payload []byte) error {
+	if len(payload) > maxRecordSize {
+		return fmt.Errorf("record of %d bytes is too large", len(payload))
+	}
+	if err := fw.w.WriteByte(byte(rt)); err != nil {
+		return err
+	}
+	if _, err := fw.w.Write(utils.MarshalUint32(uint32(len(payload)))); err != nil {
+		return err
+	}
+	_, err := fw.w.Write(payload)
+	return err
+}
+
+func (fw *fileWriter) flush() error {
+	return fw.w.Flush()
+}
+
+type fileReader struct {
+	r *bufio.Reader
+}
+
+func newFileReader(r io.Reader) (*fileReader, error) {
+	fr := &fileReader{r: bufio.NewReader(r)}
+	hdr := make([]byte, len(fileMagic)+4)
+	if _, err := io.ReadFull(fr.r, hdr); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hdr[:len(fileMagic)], []byte(fileMagic)) {
+		return nil, errors.New("not a snapshot file")
+	}
+	version, _ := utils.UnmarshalUint32(hdr[len(fileMagic):])
+	if version != fileVersion {
+		return nil, fmt.Errorf("unsupported snapshot file version %d", version)
+	}
+	return fr, nil
+}
+
+// next returns the next record. A file that ends without a recordEnd
+// returns io.ErrUnexpectedEOF.
+func (fr *fileReader) next() (recordType, []byte, error) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(fr.r, hdr); err != nil {
+		if err == io.EOF {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	size, _ := utils.UnmarshalUint32(hdr[1:])
+	if size > maxRecordSize {
+		return 0, nil, fmt.Errorf("record of %d bytes is too large", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(fr.r, payload); err != nil {
+		if err == io.EOF {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return recordType(hdr[0]), payload, nil
+}
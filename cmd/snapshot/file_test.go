@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	root := crypto.Hasher([]byte("root"))
+	key := crypto.Hasher([]byte("key"))
+	value := crypto.Hasher([]byte("value"))
+	counts := &fileCounts{StateNodes: 1, StateLeaves: 2, HdrNodes: 3, BlockHeaders: 4}
+
+	buf := &bytes.Buffer{}
+	fw, err := newFileWriter(buf)
+	assert.Nil(t, err)
+	assert.Nil(t, fw.write(recordSnapshotHeader, []byte("header")))
+	assert.Nil(t, fw.write(recordStateNode, marshalNode(3, root, []byte("batch"))))
+	assert.Nil(t, fw.write(recordStateLeaf, marshalLeaf(key, value, []byte("data"))))
+	assert.Nil(t, fw.write(recordEnd, counts.MarshalBinary()))
+	assert.Nil(t, fw.flush())
+
+	fr, err := newFileReader(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+
+	rt, payload, err := fr.next()
+	assert.Nil(t, err)
+	assert.Equal(t, recordSnapshotHeader, rt)
+	assert.Equal(t, []byte("header"), payload)
+
+	rt, payload, err = fr.next()
+	assert.Nil(t, err)
+	assert.Equal(t, recordStateNode, rt)
+	layer, gotRoot, batch, err := unmarshalNode(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, layer)
+	assert.Equal(t, root, gotRoot)
+	assert.Equal(t, []byte("batch"), batch)
+
+	rt, payload, err = fr.next()
+	assert.Nil(t, err)
+	assert.Equal(t, recordStateLeaf, rt)
+	gotKey, gotValue, data, err := unmarshalLeaf(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, key, gotKey)
+	assert.Equal(t, value, gotValue)
+	assert.Equal(t, []byte("data"), data)
+
+	rt, payload, err = fr.next()
+	assert.Nil(t, err)
+	assert.Equal(t, recordEnd, rt)
+	gotCounts := &fileCounts{}
+	assert.Nil(t, gotCounts.UnmarshalBinary(payload))
+	assert.Equal(t, *counts, *gotCounts)
+
+	// A file without a further record is cut short
+	_, _, err = fr.next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// Records cut in the middle are too
+	fr, err = newFileReader(bytes.NewReader(buf.Bytes()[:len(buf.Bytes())-3]))
+	assert.Nil(t, err)
+	for err == nil {
+		_, _, err = fr.next()
+	}
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestFileRejects(t *testing.T) {
+	_, err := newFileReader(bytes.NewReader([]byte("NOTSNAP\x00\x00\x00\x01")))
+	assert.NotNil(t, err)
+
+	// A version this node does not know
+	_, err = newFileReader(bytes.NewReader([]byte(fileMagic + "\x00\x00\x00\x02")))
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, (&fileCounts{}).UnmarshalBinary(make([]byte, 15)))
+	_, _, _, err = unmarshalNode(make([]byte, 4+constants.HashLen-1))
+	assert.NotNil(t, err)
+	_, _, _, err = unmarshalLeaf(make([]byte, 2*constants.HashLen-1))
+	assert.NotNil(t, err)
+}
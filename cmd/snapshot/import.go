@@ -0,0 +1,399 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/lstate"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// recordsPerTxn is the number of records stored in a single db transaction
+const recordsPerTxn = 1000
+
+// importer reads a snapshot file into the state of a node. Every node and
+// leaf is stored through the same calls fast sync uses, and is only accepted
+// if it was referenced by a node already verified against the roots of the
+// snapshot block header.
+type importer struct {
+	logger *logrus.Logger
+
+	database *db.Database
+	app      *application.Application
+	sstore   *lstate.Store
+
+	bh *objs.BlockHeader
+
+	// keys referenced by stored nodes that have not been read yet
+	stateNodes  map[string]int
+	stateLeaves map[string][]byte
+	hdrNodes    map[string]int
+	hdrLeaves   map[uint32][]byte
+
+	counts fileCounts
+}
+
+func newImporter(logger *logrus.Logger, stateDb *badger.DB, txnDb *badger.DB) (*importer, error) {
+	database := &db.Database{}
+	if err := database.Init(stateDb); err != nil {
+		return nil, err
+	}
+	dph := &deposit.Handler{}
+	if err := dph.Init(); err != nil {
+		return nil, err
+	}
+	app := &application.Application{}
+	if err := app.Init(database, txnDb, dph); err != nil {
+		return nil, err
+	}
+	sstore := &lstate.Store{}
+	if err := sstore.Init(database); err != nil {
+		return nil, err
+	}
+	return &importer{
+		logger:      logger,
+		database:    database,
+		app:         app,
+		sstore:      sstore,
+		stateNodes:  make(map[string]int),
+		stateLeaves: make(map[string][]byte),
+		hdrNodes:    make(map[string]int),
+		hdrLeaves:   make(map[uint32][]byte),
+	}, nil
+}
+
+// run imports the snapshot file read from r and returns the snapshot block
+// header.
+func (im *importer) run(r io.Reader, chainID uint32) (*objs.BlockHeader, error) {
+	fr, err := newFileReader(r)
+	if err != nil {
+		return nil, err
+	}
+	rt, payload, err := fr.next()
+	if err != nil {
+		return nil, err
+	}
+	if rt != recordSnapshotHeader {
+		return nil, errors.New("snapshot file does not start with a snapshot block header")
+	}
+	bh := &objs.BlockHeader{}
+	if err := bh.UnmarshalBinary(payload); err != nil {
+		return nil, err
+	}
+	if err := im.checkHeader(bh, chainID); err != nil {
+		return nil, err
+	}
+	im.bh = bh
+	if err := im.begin(); err != nil {
+		return nil, err
+	}
+	for {
+		done, err := im.storeRecords(fr)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+	}
+	if err := im.finalize(); err != nil {
+		return nil, err
+	}
+	return bh, nil
+}
+
+// checkHeader verifies the snapshot block header against the chain this
+// node knows about. If the node has seen the snapshot on Ethereum the
+// block hashes must match, otherwise the header must be signed by the
+// validator set the node has for that height.
+func (im *importer) checkHeader(bh *objs.BlockHeader, chainID uint32) error {
+	if chainID != 0 && bh.BClaims.ChainID != chainID {
+		return fmt.Errorf("snapshot is for chain %d, not %d", bh.BClaims.ChainID, chainID)
+	}
+	if err := bh.ValidateSignatures(&crypto.BNGroupValidator{}); err != nil {
+		return err
+	}
+	bhsh, err := bh.BlockHash()
+	if err != nil {
+		return err
+	}
+	height := bh.BClaims.Height
+	return im.database.View(func(txn *badger.Txn) error {
+		ownState, err := im.database.GetOwnState(txn)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return errors.New("state db is not initialized; start the node once before importing a snapshot")
+			}
+			return err
+		}
+		if ownState.SyncToBH.BClaims.Height >= height {
+			return fmt.Errorf("node is already synced to height %d", ownState.SyncToBH.BClaims.Height)
+		}
+		known, err := im.database.GetSnapshotBlockHeader(txn, height)
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if known != nil {
+			knownHash, err := known.BlockHash()
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(knownHash, bhsh) {
+				return fmt.Errorf("snapshot at height %d does not match the snapshot recorded on Ethereum", height)
+			}
+			return nil
+		}
+		vs, err := im.database.GetValidatorSet(txn, height)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(vs.GroupKey, bh.GroupKey) {
+			return fmt.Errorf("snapshot at height %d is not signed by the known validator set", height)
+		}
+		return nil
+	})
+}
+
+// begin drops any partial fast sync and seeds the roots of the snapshot.
+// The snapshot is recorded as the fast sync target with its roots pending,
+// so a node that stops before the import finished resumes it by fast sync
+// or, if it syncs to another snapshot, drops the imported state.
+func (im *importer) begin() error {
+	if err := im.dropPendingKeys(); err != nil {
+		return err
+	}
+	bhsh, err := im.bh.BlockHash()
+	if err != nil {
+		return err
+	}
+	height := im.bh.BClaims.Height
+	stateRoot := im.bh.BClaims.StateRoot
+	hdrRoot := im.bh.BClaims.HeaderRoot
+	err = im.database.Update(func(txn *badger.Txn) error {
+		if err := im.app.BeginSnapShotSync(txn); err != nil {
+			return err
+		}
+		if err := im.database.SetFastSyncTarget(txn, height, stateRoot, hdrRoot, bhsh); err != nil {
+			return err
+		}
+		if !bytes.Equal(stateRoot, make([]byte, constants.HashLen)) {
+			if err := im.database.SetPendingNodeKey(txn, stateRoot, 0); err != nil {
+				return err
+			}
+		}
+		if err := im.database.SetPendingHdrNodeKey(txn, hdrRoot, 0); err != nil {
+			return err
+		}
+		return im.database.SetPendingHdrLeafKey(txn, im.database.MakeHeaderTrieKeyFromHeight(height), bhsh)
+	})
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stateRoot, make([]byte, constants.HashLen)) {
+		im.stateNodes[string(stateRoot)] = 0
+	}
+	if !bytes.Equal(hdrRoot, make([]byte, constants.HashLen)) {
+		im.hdrNodes[string(hdrRoot)] = 0
+	}
+	return nil
+}
+
+func (im *importer) dropPendingKeys() error {
+	if err := im.database.DropPendingNodeKeys(); err != nil {
+		return err
+	}
+	if err := im.database.DropPendingLeafKeys(); err != nil {
+		return err
+	}
+	if err := im.database.DropPendingHdrNodeKeys(); err != nil {
+		return err
+	}
+	return im.database.DropPendingHdrLeafKeys()
+}
+
+// storeRecords stores up to recordsPerTxn records in a single transaction.
+// It returns true once the end record has been read.
+func (im *importer) storeRecords(fr *fileReader) (bool, error) {
+	done := false
+	err := im.database.Update(func(txn *badger.Txn) error {
+		for i := 0; i < recordsPerTxn; i++ {
+			rt, payload, err := fr.next()
+			if err != nil {
+				return err
+			}
+			if rt == recordEnd {
+				done = true
+				return im.checkEnd(payload)
+			}
+			if err := im.storeRecord(txn, rt, payload); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	im.logger.Infof("Imported %d state nodes, %d state leaves, %d header nodes and %d block headers", im.counts.StateNodes, im.counts.StateLeaves, im.counts.HdrNodes, im.counts.BlockHeaders)
+	return done, nil
+}
+
+func (im *importer) storeRecord(txn *badger.Txn, rt recordType, payload []byte) error {
+	switch rt {
+	case recordStateNode:
+		layer, root, batch, err := unmarshalNode(payload)
+		if err != nil {
+			return err
+		}
+		expected, ok := im.stateNodes[string(root)]
+		if !ok || expected != layer {
+			return fmt.Errorf("unexpected state node %x", root)
+		}
+		children, newLayer, lvs, err := im.app.StoreSnapShotNode(txn, batch, root, layer)
+		if err != nil {
+			return err
+		}
+		delete(im.stateNodes, string(root))
+		for _, child := range children {
+			im.stateNodes[string(child)] = newLayer
+		}
+		for _, lf := range lvs {
+			im.stateLeaves[string(lf.Key)] = utils.CopySlice(lf.Value)
+		}
+		im.counts.StateNodes++
+	case recordStateLeaf:
+		key, value, data, err := unmarshalLeaf(payload)
+		if err != nil {
+			return err
+		}
+		expected, ok := im.stateLeaves[string(key)]
+		if !ok || !bytes.Equal(expected, value) {
+			return fmt.Errorf("unexpected state leaf %x", key)
+		}
+		if err := im.app.StoreSnapShotStateData(txn, key, value, data); err != nil {
+			return err
+		}
+		delete(im.stateLeaves, string(key))
+		im.counts.StateLeaves++
+	case recordHdrNode:
+		layer, root, batch, err := unmarshalNode(payload)
+		if err != nil {
+			return err
+		}
+		expected, ok := im.hdrNodes[string(root)]
+		if !ok || expected != layer {
+			return fmt.Errorf("unexpected header node %x", root)
+		}
+		children, newLayer, lvs, err := im.database.SetSnapShotHdrNode(txn, batch, root, layer)
+		if err != nil {
+			return err
+		}
+		delete(im.hdrNodes, string(root))
+		for _, child := range children {
+			im.hdrNodes[string(child)] = newLayer
+		}
+		for _, lf := range lvs {
+			bhHeight, err := utils.UnmarshalUint32(lf.Key[0:4])
+			if err != nil {
+				return err
+			}
+			im.hdrLeaves[bhHeight] = utils.CopySlice(lf.Value)
+		}
+		im.counts.HdrNodes++
+	case recordBlockHeader:
+		hdr := &objs.BlockHeader{}
+		if err := hdr.UnmarshalBinary(payload); err != nil {
+			return err
+		}
+		bhsh, err := hdr.BlockHash()
+		if err != nil {
+			return err
+		}
+		expected, ok := im.hdrLeaves[hdr.BClaims.Height]
+		if !ok || !bytes.Equal(expected, bhsh) {
+			return fmt.Errorf("unexpected block header at height %d", hdr.BClaims.Height)
+		}
+		if err := im.database.SetCommittedBlockHeaderFastSync(txn, hdr); err != nil {
+			return err
+		}
+		delete(im.hdrLeaves, hdr.BClaims.Height)
+		im.counts.BlockHeaders++
+	default:
+		return fmt.Errorf("unknown record type %d", rt)
+	}
+	return nil
+}
+
+// checkEnd verifies that the file was complete
+func (im *importer) checkEnd(payload []byte) error {
+	written := &fileCounts{}
+	if err := written.UnmarshalBinary(payload); err != nil {
+		return err
+	}
+	if *written != im.counts {
+		return errors.New("snapshot file record counts do not match")
+	}
+	if len(im.stateNodes) != 0 || len(im.stateLeaves) != 0 || len(im.hdrNodes) != 0 || len(im.hdrLeaves) != 0 {
+		return fmt.Errorf("snapshot file is incomplete: missing %d state nodes, %d state leaves, %d header nodes and %d block headers", len(im.stateNodes), len(im.stateLeaves), len(im.hdrNodes), len(im.hdrLeaves))
+	}
+	return nil
+}
+
+// finalize sets the roots of the snapshot and advances the local state of
+// the node to the snapshot block header in the same way fast sync does once
+// it completes.
+func (im *importer) finalize() error {
+	if err := im.dropPendingKeys(); err != nil {
+		return err
+	}
+	bh := im.bh
+	return im.database.Update(func(txn *badger.Txn) error {
+		if err := im.database.DeleteFastSyncTarget(txn); err != nil {
+			return err
+		}
+		if err := im.app.FinalizeSnapShotRoot(txn, bh.BClaims.StateRoot, bh.BClaims.Height); err != nil {
+			return err
+		}
+		if err := im.database.SetCommittedBlockHeaderFastSync(txn, bh); err != nil {
+			return err
+		}
+		if err := im.database.UpdateHeaderTrieRootFastSync(txn, bh); err != nil {
+			return err
+		}
+		if err := im.database.SetSnapshotBlockHeader(txn, bh); err != nil {
+			return err
+		}
+		rs, err := im.sstore.LoadLocalState(txn)
+		if err != nil {
+			return err
+		}
+		rc, err := bh.GetRCert()
+		if err != nil {
+			return err
+		}
+		rs.OwnValidatingState.SetRoundStarted(time.Now())
+		if err := rs.OwnRoundState().SetRCert(rc); err != nil {
+			return err
+		}
+		rs.OwnState.SyncToBH = bh
+		if rs.OwnState.MaxBHSeen.BClaims.Height < bh.BClaims.Height {
+			rs.OwnState.MaxBHSeen = bh
+		}
+		if bh.BClaims.Height%constants.EpochLength == 0 {
+			rs.OwnState.CanonicalSnapShot = rs.OwnState.PendingSnapShot
+			rs.OwnState.PendingSnapShot = bh
+		}
+		return im.sstore.WriteState(txn, rs)
+	})
+}
@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"context"
+	"os"
+
+	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/spf13/cobra"
+)
+
+// Command is the parent of the snapshot export and import commands
+var Command = cobra.Command{
+	Use:   "snapshot",
+	Short: "Exports and imports state snapshots",
+	Long:  "snapshot moves the state of the chain at a snapshot height between nodes without live peers. The node must not be running against the same state db."}
+
+// ExportCommand is the cobra.Command for writing a snapshot file
+var ExportCommand = cobra.Command{
+	Use:   "export <file>",
+	Short: "Writes a snapshot of the local state to a file",
+	Long:  "export writes the snapshot block header, the state trie and the header trie at a snapshot height to a file. The most recent snapshot is exported unless snapshot.height is set.",
+	Args:  cobra.ExactArgs(1),
+	Run:   exportSnapshot}
+
+// ImportCommand is the cobra.Command for loading a snapshot file
+var ImportCommand = cobra.Command{
+	Use:   "import <file>",
+	Short: "Loads a snapshot file into the local state",
+	Long:  "import verifies a snapshot file against the roots of its snapshot block header while storing it, and advances the node to the snapshot height. The state db must have been initialized by running the node once. If the import is interrupted, the node fast syncs to the snapshot when it starts.",
+	Args:  cobra.ExactArgs(1),
+	Run:   importSnapshot}
+
+func exportSnapshot(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("snapshot")
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	stateDb, err := utils.OpenBadger(ctx.Done(), config.Configuration.Chain.StateDbPath, false)
+	if err != nil {
+		logger.Fatalf("Could not open state db: %v", err)
+	}
+
+	e, err := newExporter(logger, ctx.Done(), stateDb)
+	if err != nil {
+		logger.Fatalf("Could not setup export: %v", err)
+	}
+	bh, err := e.snapshotHeader(uint32(config.Configuration.Snapshot.Height))
+	if err != nil {
+		logger.Fatalf("Could not find snapshot: %v", err)
+	}
+	logger.Infof("Exporting snapshot at height %d to %v", bh.BClaims.Height, args[0])
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		logger.Fatalf("Could not create snapshot file: %v", err)
+	}
+	defer f.Close()
+	if err := e.export(f, bh); err != nil {
+		logger.Fatalf("Export failed: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		logger.Fatalf("Could not write snapshot file: %v", err)
+	}
+	logger.Infof("Exported %d state nodes, %d state leaves, %d header nodes and %d block headers", e.counts.StateNodes, e.counts.StateLeaves, e.counts.HdrNodes, e.counts.BlockHeaders)
+}
+
+func importSnapshot(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("snapshot")
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		logger.Fatalf("Could not open snapshot file: %v", err)
+	}
+	defer f.Close()
+
+	chain := config.Configuration.Chain
+	stateDb, err := utils.OpenBadger(ctx.Done(), chain.StateDbPath, chain.StateDbInMemory)
+	if err != nil {
+		logger.Fatalf("Could not open state db: %v", err)
+	}
+	txnDb, err := utils.OpenBadger(ctx.Done(), chain.TransactionDbPath, chain.TransactionDbInMemory)
+	if err != nil {
+		logger.Fatalf("Could not open transaction db: %v", err)
+	}
+
+	im, err := newImporter(logger, stateDb, txnDb)
+	if err != nil {
+		logger.Fatalf("Could not setup import: %v", err)
+	}
+	bh, err := im.run(f, uint32(chain.ID))
+	if err != nil {
+		logger.Fatalf("Import failed: %v", err)
+	}
+	logger.Infof("Imported snapshot at height %d", bh.BClaims.Height)
+}
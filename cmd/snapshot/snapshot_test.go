@@ -0,0 +1,436 @@
+package snapshot
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	aobjs "github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/consensus/admin"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChainID = uint32(42)
+
+type testKeys struct {
+	secpSigner *crypto.Secp256k1Signer
+	bnSigner   *crypto.BNGroupSigner
+	vs         *objs.ValidatorSet
+}
+
+func makeTestKeys(t *testing.T) *testKeys {
+	secpSigner := &crypto.Secp256k1Signer{}
+	assert.Nil(t, secpSigner.SetPrivk(crypto.Hasher([]byte("secret"))))
+	secpPubk, err := secpSigner.Pubkey()
+	assert.Nil(t, err)
+	// a group of one signs with its own key
+	bnSigner := &crypto.BNGroupSigner{}
+	bnSigner.SetPrivk(crypto.Hasher([]byte("secret")))
+	groupKey, err := bnSigner.PubkeyShare()
+	assert.Nil(t, err)
+	assert.Nil(t, bnSigner.SetGroupPubk(groupKey))
+	vs := &objs.ValidatorSet{
+		Validators: []*objs.Validator{{VAddr: crypto.GetAccount(secpPubk), GroupShare: groupKey}},
+		GroupKey:   groupKey,
+		NotBefore:  1,
+	}
+	return &testKeys{secpSigner: secpSigner, bnSigner: bnSigner, vs: vs}
+}
+
+type testNode struct {
+	stateDb  *badger.DB
+	txnDb    *badger.DB
+	database *db.Database
+	app      *application.Application
+	dph      *deposit.Handler
+}
+
+// newTestNode opens an in memory node and writes the genesis block the way
+// a node does when it learns about its first validator set
+func newTestNode(t *testing.T, keys *testKeys) *testNode {
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	stateDb, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	txnDb, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	database := &db.Database{}
+	assert.Nil(t, database.Init(stateDb))
+	dph := &deposit.Handler{}
+	assert.Nil(t, dph.Init())
+	app := &application.Application{}
+	assert.Nil(t, app.Init(database, txnDb, dph))
+
+	secpPubk, err := keys.secpSigner.Pubkey()
+	assert.Nil(t, err)
+	ah := &admin.Handlers{}
+	assert.Nil(t, ah.Init(testChainID, database, crypto.Hasher([]byte("symmetric")), app, secpPubk))
+	defer ah.Close()
+	mutex := &sync.Mutex{}
+	go func() {
+		for {
+			select {
+			case <-closeChan:
+				return
+			case <-ah.RequestLock:
+				ah.ReceiveLock <- interfaces.Lockable(mutex)
+			}
+		}
+	}()
+	assert.Nil(t, ah.AddValidatorSet(keys.vs))
+
+	return &testNode{stateDb: stateDb, txnDb: txnDb, database: database, app: app, dph: dph}
+}
+
+// spendDeposits adds deposits and moves each of them into a new value store
+func spendDeposits(t *testing.T, node *testNode, signer *crypto.Secp256k1Signer, num int) []interfaces.Transaction {
+	pubk, err := signer.Pubkey()
+	assert.Nil(t, err)
+	account := crypto.GetAccount(pubk)
+	owner := &aobjs.ValueStoreOwner{SVA: aobjs.ValueStoreSVA, CurveSpec: constants.CurveSecp256k1, Account: account}
+
+	txs := []interfaces.Transaction{}
+	err = node.database.Update(func(txn *badger.Txn) error {
+		for i := 0; i < num; i++ {
+			depositID := utils.ForceSliceToLength([]byte(strconv.Itoa(i+1)), constants.HashLen)
+			value := uint256.One()
+			if err := node.dph.Add(txn, testChainID, depositID, big.NewInt(1), &aobjs.Owner{CurveSpec: constants.CurveSecp256k1, Account: account}); err != nil {
+				return err
+			}
+			deps, _, _, err := node.dph.Get(txn, [][]byte{depositID})
+			if err != nil {
+				return err
+			}
+			dep, err := deps[0].ValueStore()
+			if err != nil {
+				return err
+			}
+			txIn, err := dep.MakeTxIn()
+			if err != nil {
+				return err
+			}
+			out := &aobjs.TXOut{}
+			err = out.NewValueStore(&aobjs.ValueStore{
+				VSPreImage: &aobjs.VSPreImage{TXOutIdx: 0, Value: value, ChainID: testChainID, Owner: owner},
+				TxHash:     make([]byte, constants.HashLen),
+			})
+			if err != nil {
+				return err
+			}
+			tx := &aobjs.Tx{Vin: []*aobjs.TXIn{txIn}, Vout: []*aobjs.TXOut{out}}
+			if err := tx.SetTxHash(); err != nil {
+				return err
+			}
+			if err := dep.Sign(tx.Vin[0], signer); err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	return txs
+}
+
+// commitSnapshot applies txs in the block after genesis and records that
+// block as a snapshot signed by the validator set
+func commitSnapshot(t *testing.T, node *testNode, keys *testKeys, txs []interfaces.Transaction) *objs.BlockHeader {
+	var bh *objs.BlockHeader
+	err := node.database.Update(func(txn *badger.Txn) error {
+		genesis, err := node.database.GetCommittedBlockHeader(txn, 1)
+		if err != nil {
+			return err
+		}
+		prevBlock, err := genesis.BlockHash()
+		if err != nil {
+			return err
+		}
+		headerRoot, err := node.database.GetHeaderRootForProposal(txn)
+		if err != nil {
+			return err
+		}
+		stateRoot, err := node.app.ApplyState(txn, testChainID, 2, txs)
+		if err != nil {
+			return err
+		}
+		txHashes := [][]byte{}
+		for _, tx := range txs {
+			txHash, err := tx.TxHash()
+			if err != nil {
+				return err
+			}
+			txHashes = append(txHashes, txHash)
+		}
+		txRoot, err := objs.MakeTxRoot(txHashes)
+		if err != nil {
+			return err
+		}
+		bclaims := &objs.BClaims{
+			ChainID:    testChainID,
+			Height:     2,
+			TxCount:    uint32(len(txs)),
+			PrevBlock:  prevBlock,
+			TxRoot:     txRoot,
+			StateRoot:  stateRoot,
+			HeaderRoot: headerRoot,
+		}
+		bhsh, err := bclaims.BlockHash()
+		if err != nil {
+			return err
+		}
+		sig, err := keys.bnSigner.Sign(bhsh)
+		if err != nil {
+			return err
+		}
+		bh = &objs.BlockHeader{BClaims: bclaims, SigGroup: sig, TxHshLst: txHashes}
+		if err := node.database.SetCommittedBlockHeader(txn, bh); err != nil {
+			return err
+		}
+		return node.database.SetSnapshotBlockHeader(txn, bh)
+	})
+	assert.Nil(t, err)
+	return bh
+}
+
+// stateRoot returns the root of the state trie of node
+func stateRoot(t *testing.T, node *testNode) []byte {
+	txn := node.stateDb.NewTransaction(true)
+	defer txn.Discard()
+	root, err := node.app.ApplyState(txn, testChainID, 3, nil)
+	assert.Nil(t, err)
+	return root
+}
+
+func exportTo(t *testing.T, node *testNode, height uint32) ([]byte, *objs.BlockHeader) {
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	e, err := newExporter(logging.GetLogger("snapshot"), closeChan, node.stateDb)
+	assert.Nil(t, err)
+	bh, err := e.snapshotHeader(height)
+	assert.Nil(t, err)
+	buf := &bytes.Buffer{}
+	assert.Nil(t, e.export(buf, bh))
+	return buf.Bytes(), bh
+}
+
+func importFrom(t *testing.T, node *testNode, raw []byte, chainID uint32) (*objs.BlockHeader, error) {
+	im, err := newImporter(logging.GetLogger("snapshot"), node.stateDb, node.txnDb)
+	assert.Nil(t, err)
+	return im.run(bytes.NewReader(raw), chainID)
+}
+
+// rewrite copies a snapshot file while fn changes its records
+func rewrite(t *testing.T, raw []byte, fn func(rt recordType, payload []byte) []byte) []byte {
+	fr, err := newFileReader(bytes.NewReader(raw))
+	assert.Nil(t, err)
+	buf := &bytes.Buffer{}
+	fw, err := newFileWriter(buf)
+	assert.Nil(t, err)
+	for {
+		rt, payload, err := fr.next()
+		assert.Nil(t, err)
+		assert.Nil(t, fw.write(rt, fn(rt, payload)))
+		if rt == recordEnd {
+			break
+		}
+	}
+	assert.Nil(t, fw.flush())
+	return buf.Bytes()
+}
+
+func TestExportImport(t *testing.T) {
+	keys := makeTestKeys(t)
+	src := newTestNode(t, keys)
+	txs := spendDeposits(t, src, keys.secpSigner, 40)
+	bh := commitSnapshot(t, src, keys, txs)
+	assert.Equal(t, bh.BClaims.StateRoot, stateRoot(t, src))
+
+	raw, exported := exportTo(t, src, 0)
+	assert.Equal(t, uint32(2), exported.BClaims.Height)
+
+	dst := newTestNode(t, keys)
+	assert.NotEqual(t, bh.BClaims.StateRoot, stateRoot(t, dst))
+	imported, err := importFrom(t, dst, raw, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), imported.BClaims.Height)
+
+	// The imported state has the root of the snapshot and holds every
+	// value store created before it
+	assert.Equal(t, bh.BClaims.StateRoot, stateRoot(t, dst))
+	err = dst.database.View(func(txn *badger.Txn) error {
+		for _, tx := range txs {
+			utxoIDs, err := tx.(*aobjs.Tx).GeneratedUTXOID()
+			if err != nil {
+				return err
+			}
+			for _, utxoID := range utxoIDs {
+				ok, err := dst.app.UTXOContains(txn, utxoID)
+				if err != nil {
+					return err
+				}
+				assert.True(t, ok)
+			}
+		}
+		ownState, err := dst.database.GetOwnState(txn)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, uint32(2), ownState.SyncToBH.BClaims.Height)
+		root, err := dst.database.GetHeaderTrieRoot(txn, 2)
+		if err != nil {
+			return err
+		}
+		srcRoot, err := func() ([]byte, error) {
+			var root []byte
+			err := src.database.View(func(txn *badger.Txn) error {
+				var err error
+				root, err = src.database.GetHeaderTrieRoot(txn, 2)
+				return err
+			})
+			return root, err
+		}()
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, srcRoot, root)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	// The imported node exports the same snapshot
+	again, _ := exportTo(t, dst, 2)
+	assert.Equal(t, raw, again)
+}
+
+func TestImportRejects(t *testing.T) {
+	keys := makeTestKeys(t)
+	src := newTestNode(t, keys)
+	bh := commitSnapshot(t, src, keys, spendDeposits(t, src, keys.secpSigner, 40))
+	raw, _ := exportTo(t, src, bh.BClaims.Height)
+
+	// Every case imports into a node of its own
+	reject := func(raw []byte, chainID uint32) error {
+		dst := newTestNode(t, keys)
+		_, err := importFrom(t, dst, raw, chainID)
+		assert.NotNil(t, err)
+		return err
+	}
+
+	t.Run("Truncated", func(t *testing.T) {
+		err := reject(raw[:len(raw)-30], testChainID)
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+
+	t.Run("WrongCount", func(t *testing.T) {
+		err := reject(rewrite(t, raw, func(rt recordType, payload []byte) []byte {
+			if rt != recordEnd {
+				return payload
+			}
+			counts := &fileCounts{}
+			assert.Nil(t, counts.UnmarshalBinary(payload))
+			counts.StateLeaves++
+			return counts.MarshalBinary()
+		}), testChainID)
+		assert.Contains(t, err.Error(), "counts do not match")
+	})
+
+	t.Run("UnexpectedNode", func(t *testing.T) {
+		seen := false
+		err := reject(rewrite(t, raw, func(rt recordType, payload []byte) []byte {
+			if rt != recordStateNode || seen {
+				return payload
+			}
+			seen = true
+			layer, root, batch, err := unmarshalNode(payload)
+			assert.Nil(t, err)
+			return marshalNode(layer, crypto.Hasher(root), batch)
+		}), testChainID)
+		assert.Contains(t, err.Error(), "unexpected state node")
+	})
+
+	t.Run("UnexpectedLeaf", func(t *testing.T) {
+		seen := false
+		err := reject(rewrite(t, raw, func(rt recordType, payload []byte) []byte {
+			if rt != recordStateLeaf || seen {
+				return payload
+			}
+			seen = true
+			key, value, data, err := unmarshalLeaf(payload)
+			assert.Nil(t, err)
+			return marshalLeaf(key, crypto.Hasher(value), data)
+		}), testChainID)
+		assert.Contains(t, err.Error(), "unexpected state leaf")
+	})
+
+	t.Run("WrongChain", func(t *testing.T) {
+		err := reject(raw, testChainID+1)
+		assert.Contains(t, err.Error(), "snapshot is for chain")
+	})
+}
+
+func TestImportInterrupted(t *testing.T) {
+	keys := makeTestKeys(t)
+	src := newTestNode(t, keys)
+	bh := commitSnapshot(t, src, keys, spendDeposits(t, src, keys.secpSigner, 40))
+	raw, _ := exportTo(t, src, bh.BClaims.Height)
+	bhsh, err := bh.BlockHash()
+	assert.Nil(t, err)
+
+	// A node that stops importing is left to fast sync to the snapshot
+	dst := newTestNode(t, keys)
+	_, err = importFrom(t, dst, raw[:len(raw)-30], testChainID)
+	assert.NotNil(t, err)
+	err = dst.database.View(func(txn *badger.Txn) error {
+		height, stateRoot, hdrRoot, blockHash, err := dst.database.GetFastSyncTarget(txn)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, uint32(2), height)
+		assert.Equal(t, bh.BClaims.StateRoot, stateRoot)
+		assert.Equal(t, bh.BClaims.HeaderRoot, hdrRoot)
+		assert.Equal(t, bhsh, blockHash)
+		layer, err := dst.database.GetPendingNodeKey(txn, bh.BClaims.StateRoot)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 0, layer)
+		ownState, err := dst.database.GetOwnState(txn)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, uint32(1), ownState.SyncToBH.BClaims.Height)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	// Importing again completes the sync
+	_, err = importFrom(t, dst, raw, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, bh.BClaims.StateRoot, stateRoot(t, dst))
+	err = dst.database.View(func(txn *badger.Txn) error {
+		_, _, _, _, err := dst.database.GetFastSyncTarget(txn)
+		assert.Equal(t, badger.ErrKeyNotFound, err)
+		count, err := dst.database.CountPendingNodeKeys(txn)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 0, count)
+		return nil
+	})
+	assert.Nil(t, err)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"os"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/MadBase/MadNet/blockchain"
 	"github.com/MadBase/MadNet/blockchain/interfaces"
 	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -73,17 +75,56 @@ var DepositCommand = cobra.Command{
 	Long:  "",
 	Run:   utilsNode}
 
+// NewSigner creates the signer of Ethereum transactions configured. Keystore
+// passcodes come from the passcodes file, the environment or a prompt.
+func NewSigner() (blockchain.Signer, error) {
+	eth := config.Configuration.Ethereum
+
+	switch eth.Signer {
+	case "", "keystore":
+		var passcodes blockchain.PasscodeSource
+		if eth.Passcodes != "" {
+			var err error
+			passcodes, err = blockchain.PasscodeFile(eth.Passcodes)
+			if err != nil {
+				return nil, err
+			}
+		} else if _, present := os.LookupEnv(constants.EthereumPasscodeEnv); present {
+			passcodes = blockchain.PasscodeEnv(constants.EthereumPasscodeEnv)
+		} else {
+			passcodes = blockchain.PasscodePrompt()
+		}
+		return blockchain.NewKeystoreSigner(eth.Keystore, passcodes), nil
+	default:
+		api, err := blockchain.ParseRemoteSignerAPI(eth.Signer)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", err, eth.Signer)
+		}
+		return blockchain.NewRemoteSigner(eth.SignerEndpoint, api, eth.Timeout, eth.MaxGasPriceWei())
+	}
+}
+
 func setupEthereum(logger *logrus.Entry) (interfaces.Ethereum, error) {
+	signer, err := NewSigner()
+	if err != nil {
+		return nil, err
+	}
+
 	logger.Info("Connecting to Ethereum endpoint ...")
 	eth, err := blockchain.NewEthereumEndpoint(
-		config.Configuration.Ethereum.Endpoint,
-		config.Configuration.Ethereum.Keystore,
-		config.Configuration.Ethereum.Passcodes,
+		config.Configuration.Ethereum.Endpoints(),
+		uint64(config.Configuration.Ethereum.ChainID),
+		signer,
 		config.Configuration.Ethereum.DefaultAccount,
 		config.Configuration.Ethereum.Timeout,
 		config.Configuration.Ethereum.RetryCount,
 		config.Configuration.Ethereum.RetryDelay,
-		config.Configuration.Ethereum.FinalityDelay)
+		config.Configuration.Ethereum.FinalityDelay,
+		config.Configuration.Ethereum.EndpointMinimumPeers,
+		config.Configuration.Ethereum.EndpointQuorum,
+		config.Configuration.Ethereum.EndpointMaxHeadAge,
+		config.Configuration.Ethereum.MaxGasPriceWei(),
+		uint64(config.Configuration.Ethereum.TxnReplaceAfter))
 
 	if err != nil {
 		return nil, err
@@ -109,8 +150,12 @@ func LogStatus(logger *logrus.Entry, eth interfaces.Ethereum) {
 		return
 	}
 
+	// A remote signer keeps the keys to itself
+	publicKey := "held by signer"
 	keys, err := eth.GetAccountKeys(acct.Address)
-	if err != nil {
+	if err == nil {
+		publicKey = fmt.Sprintf("0x%x", crypto.FromECDSAPub(&keys.PrivateKey.PublicKey))
+	} else if err != blockchain.ErrRemoteKey {
 		logger.Warnf("Failed to retrieve account %v keys: %v", acct.Address.Hex(), err)
 		return
 	}
@@ -176,7 +221,7 @@ func LogStatus(logger *logrus.Entry, eth interfaces.Ethereum) {
 	logger.Infof("  Validators contract: %v", c.ValidatorsAddress().Hex())
 	logger.Info(strings.Repeat("-", 80))
 	logger.Infof(" Default Account: %v", acct.Address.Hex())
-	logger.Infof("              Public key: 0x%x", crypto.FromECDSAPub(&keys.PrivateKey.PublicKey))
+	logger.Infof("              Public key: %v", publicKey)
 	logger.Infof("             Wei balance: %v", weiBalance)
 	logger.Infof("   Staking token balance: %v", stakingTokenBalance)
 	logger.Infof("   Utility token balance: %v", utilityTokenBalance)
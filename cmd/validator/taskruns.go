@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/blockchain/tasks"
+	"github.com/MadBase/MadNet/localrpc"
+	pb "github.com/MadBase/MadNet/proto"
+)
+
+var _ localrpc.TaskRuns = taskRuns{}
+
+// taskRuns serves the runs of the task manager over the local RPC
+type taskRuns struct {
+	manager tasks.Manager
+}
+
+func (t taskRuns) Runs() []*pb.TaskRunsResponse_Run {
+	out := []*pb.TaskRunsResponse_Run{}
+	for _, run := range t.manager.Runs() {
+		out = append(out, taskRunMsg(run))
+	}
+	return out
+}
+
+func (t taskRuns) FindRun(key string) (*pb.TaskRunsResponse_Run, bool) {
+	run, present := t.manager.FindRun(key)
+	if !present {
+		return nil, false
+	}
+	return taskRunMsg(run), true
+}
+
+func taskRunMsg(run *objects.TaskRun) *pb.TaskRunsResponse_Run {
+	msg := &pb.TaskRunsResponse_Run{
+		Key:      run.Key,
+		Name:     run.Name,
+		Status:   run.Status.String(),
+		Reason:   run.Reason,
+		Deadline: run.Deadline,
+	}
+	for _, attempt := range run.Attempts {
+		msg.Attempts = append(msg.Attempts, &pb.TaskRunsResponse_Attempt{
+			Started: localrpc.UnixOrZero(attempt.Started),
+			Block:   attempt.Block,
+			Err:     attempt.Err,
+		})
+	}
+	return msg
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MadBase/MadNet/blockchain/monitor"
+	"github.com/MadBase/MadNet/blockchain/objects"
+	"github.com/MadBase/MadNet/blockchain/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskRuns(t *testing.T) {
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	// Runs of an earlier process are loaded from the database
+	database := monitor.NewDatabase(ctx, "", true)
+	assert.Nil(t, database.UpdateTaskRun(&objects.TaskRun{
+		Key:      "snapshot-3",
+		Name:     "tasks.SnapshotTask",
+		Status:   objects.TaskDeadLetter,
+		Reason:   "gave up after 1 attempts",
+		Attempts: []objects.TaskAttempt{{Block: 12, Err: "failed to take snapshot"}}}))
+	assert.Nil(t, database.UpdateTaskRun(&objects.TaskRun{Key: "snapshot-4", Status: objects.TaskSucceeded}))
+
+	manager := tasks.NewManager()
+	assert.Nil(t, manager.SetStore(database))
+	runs := taskRuns{manager}
+
+	assert.Equal(t, 2, len(runs.Runs()))
+
+	run, present := runs.FindRun("snapshot-3")
+	assert.True(t, present)
+	assert.Equal(t, "tasks.SnapshotTask", run.Name)
+	assert.Equal(t, "DeadLetter", run.Status)
+	assert.Equal(t, "gave up after 1 attempts", run.Reason)
+	assert.Equal(t, "failed to take snapshot", run.Attempts[0].Err)
+	assert.Equal(t, uint64(12), run.Attempts[0].Block)
+	assert.Equal(t, uint64(0), run.Attempts[0].Started)
+
+	_, present = runs.FindRun("snapshot-5")
+	assert.False(t, present)
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // Command is the cobra.Command specifically for running as a node
@@ -62,6 +63,11 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	logger.Infof("Starting node with args %v", args)
 	defer func() { logger.Warning("Goodbye.") }()
 
+	// A remote signer can't sign consensus messages
+	if err := config.Configuration.Ethereum.ValidatorSigner(); err != nil {
+		logger.Fatalf("Invalid configuration: %v", err)
+	}
+
 	//////////////////////////////////////////////////////////////////////////////
 	//////////////////////////////////////////////////////////////////////////////
 	//INITIALIZE LOCAL CONFIG VARS////////////////////////////////////////////////
@@ -78,14 +84,18 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	monitorDbPath := config.Configuration.Chain.MonitorDbPath
 	monitorDbInMemory := config.Configuration.Chain.MonitorDbInMemory
 
-	ethEndpoint := config.Configuration.Ethereum.Endpoint
-	ethKeystore := config.Configuration.Ethereum.Keystore
-	ethPasscodes := config.Configuration.Ethereum.Passcodes
+	ethEndpoints := config.Configuration.Ethereum.Endpoints()
+	ethChainID := uint64(config.Configuration.Ethereum.ChainID)
 	ethDefaultAccount := config.Configuration.Ethereum.DefaultAccount
 	ethTimeout := config.Configuration.Ethereum.Timeout
 	ethRetryCount := config.Configuration.Ethereum.RetryCount
 	ethRetryDelay := config.Configuration.Ethereum.RetryDelay
 	ethFinalityDelay := config.Configuration.Ethereum.FinalityDelay
+	ethMinPeers := config.Configuration.Ethereum.EndpointMinimumPeers
+	ethQuorum := config.Configuration.Ethereum.EndpointQuorum
+	ethMaxHeadAge := config.Configuration.Ethereum.EndpointMaxHeadAge
+	ethMaxGasPrice := config.Configuration.Ethereum.MaxGasPriceWei()
+	ethTxnReplaceAfter := uint64(config.Configuration.Ethereum.TxnReplaceAfter)
 
 	batchSize := config.Configuration.Monitor.BatchSize
 	registryAddress := common.HexToAddress(config.Configuration.Ethereum.RegistryAddress)
@@ -101,8 +111,27 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	peerLimitMax := config.Configuration.Transport.PeerLimitMax
 	firewallMode := config.Configuration.Transport.FirewallMode
 	firewallHost := config.Configuration.Transport.FirewallHost
+	sentries := config.Configuration.Transport.Sentries()
+	privatePeers := config.Configuration.Transport.PrivatePeerIdentities()
+	allowedIdentities := config.Configuration.Transport.AllowedIdentities()
+	allowedHosts := config.Configuration.Transport.AllowedHosts()
 	p2PListeningAddress := config.Configuration.Transport.P2PListeningAddress
 	xportPrivateKey := config.Configuration.Transport.PrivateKey
+	banThreshold := config.Configuration.Transport.BanThreshold
+	banDuration := config.Configuration.Transport.BanDuration
+
+	gossipRateLimit := peering.RateLimit{
+		Rate:  config.Configuration.Transport.GossipRateLimit,
+		Burst: config.Configuration.Transport.GossipRateBurst,
+	}
+	requestRateLimit := peering.RateLimit{
+		Rate:  config.Configuration.Transport.RequestRateLimit,
+		Burst: config.Configuration.Transport.RequestRateBurst,
+	}
+	snapShotRateLimit := peering.RateLimit{
+		Rate:  config.Configuration.Transport.SnapShotRateLimit,
+		Burst: config.Configuration.Transport.SnapShotRateBurst,
+	}
 
 	lStateListenAddr := config.Configuration.Transport.LocalStateListeningAddress
 
@@ -112,16 +141,25 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	//////////////////////////////////////////////////////////////////////////////
 
 	// Ethereum connection setup
+	ethSigner, err := utils.NewSigner()
+	if err != nil {
+		logger.Fatalf("Could not create signer: %v", err)
+	}
 	logger.Infof("Connecting to Ethereum...")
 	eth, err := blockchain.NewEthereumEndpoint(
-		ethEndpoint,
-		ethKeystore,
-		ethPasscodes,
+		ethEndpoints,
+		ethChainID,
+		ethSigner,
 		ethDefaultAccount,
 		ethTimeout,
 		ethRetryCount,
 		ethRetryDelay,
-		ethFinalityDelay)
+		ethFinalityDelay,
+		ethMinPeers,
+		ethQuorum,
+		ethMaxHeadAge,
+		ethMaxGasPrice,
+		ethTxnReplaceAfter)
 	if err != nil {
 		logger.Fatalf("NewEthereumEndpoint(...) failed: %v", err)
 		panic(err)
@@ -191,12 +229,15 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	}
 	defer rawMonDb.Close()
 	monitorDb := monitor.NewDatabaseFromExisting(rawMonDb)
+	eth.SetNonceStore(monitorDb)
 
 	//////////////////////////////////////////////////////////////////////////////
 	//////////////////////////////////////////////////////////////////////////////
 	//CONSTRUCT CONSENSUS AND APPLICATION OBJECTS/////////////////////////////////
 	//////////////////////////////////////////////////////////////////////////////
 	inboundRPCDispatch := proto.NewInboundRPCDispatch()
+	rateLimiter := peering.NewRateLimiter(gossipRateLimit, requestRateLimit, snapShotRateLimit)
+	inboundRPCDispatch.SetRateLimiter(rateLimiter)
 	stateRPCDispatch := proto.NewLocalStateDispatch()
 	conDB := &db.Database{}
 	pool := &evidence.Pool{}
@@ -233,12 +274,23 @@ func validatorNode(cmd *cobra.Command, args []string) {
 		peerLimitMax,
 		firewallMode,
 		firewallHost,
+		peering.Restrictions{
+			Sentries:          sentries,
+			PrivatePeers:      privatePeers,
+			AllowedIdentities: allowedIdentities,
+			AllowedHosts:      allowedHosts,
+		},
 		p2PListeningAddress,
 		xportPrivateKey,
+		banThreshold,
+		banDuration,
+		stateDb,
 	)
 	if err != nil {
 		panic(err)
 	}
+	// the gossip relayed by sentries and private peers is not rate limited
+	rateLimiter.Exempt(peerManager.PrivatePeers()...)
 
 	// Setup the local RPC server
 	stateRPC, err := localrpc.NewStateServerHandler(
@@ -315,6 +367,9 @@ func validatorNode(cmd *cobra.Command, args []string) {
 
 	// Setup Request Bus Services
 	svcs := monitor.NewServices(eth, conDB, dph, ah, batchSize)
+	if err := svcs.Tasks().SetStore(monitorDb); err != nil {
+		panic(err)
+	}
 
 	// Setup Request Bus
 	mb, err := monitor.NewBus(rbus.NewRBus(), svcs)
@@ -346,7 +401,7 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	}
 
 	// Setup the local RPC server handler
-	if err := stateRPCHandler.Init(conDB, app, gh, publicKey, sync.Safe); err != nil {
+	if err := stateRPCHandler.Init(conDB, app, gh, stateHandler, peerManager, taskRuns{svcs.Tasks()}, publicKey, sync.Safe); err != nil {
 		panic(err)
 	}
 
@@ -388,6 +443,10 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	stateRPCDispatch.RegisterLocalStateIterateNameSpace(stateRPCHandler)
 	stateRPCDispatch.RegisterLocalStateGetData(stateRPCHandler)
 	stateRPCDispatch.RegisterLocalStateGetTxBlockNumber(stateRPCHandler)
+	stateRPCDispatch.RegisterLocalStateGetSyncStatus(stateRPCHandler)
+	stateRPCDispatch.RegisterLocalStateGetPeerBans(stateRPCHandler)
+	stateRPCDispatch.RegisterLocalStateGetPeerInfo(stateRPCHandler)
+	stateRPCDispatch.RegisterLocalStateGetTaskRuns(stateRPCHandler)
 
 	//////////////////////////////////////////////////////////////////////////////
 	//////////////////////////////////////////////////////////////////////////////
@@ -431,6 +490,20 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	signals := make(chan os.Signal)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	defer signal.Stop(reloads)
+	go func() {
+		for {
+			select {
+			case <-reloads:
+				reloadAllowlist(logger, viper.GetViper(), peerManager)
+			case <-peerManager.CloseChan():
+				return
+			}
+		}
+	}()
+
 	sync.Start()
 	select {
 	case <-peerManager.CloseChan():
@@ -443,6 +516,45 @@ func validatorNode(cmd *cobra.Command, args []string) {
 	defer func() { logger.Warning("Starting graceful unwind of core processes.") }()
 }
 
+// reloadAllowlist reads the transport allowlist again and applies it to the
+// running peer manager. The configuration file is read through the viper the
+// flags are bound to, so a list given as a flag keeps overriding the file.
+func reloadAllowlist(logger *logrus.Logger, v *viper.Viper, peerManager *peering.PeerManager) {
+	whitelist, whitelistHosts, err := readAllowlist(v, config.Configuration.ConfigurationFileName)
+	if err != nil {
+		logger.Errorf("Could not reload the allowlist: %v", err)
+		return
+	}
+	prev := config.Configuration.Transport
+	tc := prev
+	tc.Whitelist = whitelist
+	tc.WhitelistHosts = whitelistHosts
+	if len(prev.AllowedIdentities()) > 0 && len(tc.AllowedIdentities()) == 0 {
+		logger.Errorf("The reloaded allowlist has no identities, every peer is allowed to connect")
+	}
+	if len(prev.AllowedHosts()) > 0 && len(tc.AllowedHosts()) == 0 {
+		logger.Errorf("The reloaded allowlist has no hosts, every host is used from discovery")
+	}
+	if err := peerManager.ReloadAllowlist(tc.AllowedIdentities(), tc.AllowedHosts()); err != nil {
+		logger.Errorf("Could not reload the allowlist: %v", err)
+		return
+	}
+	config.Configuration.Transport.Whitelist = whitelist
+	config.Configuration.Transport.WhitelistHosts = whitelistHosts
+	logger.Infof("Reloaded the allowlist with %d identities and %d hosts", len(tc.AllowedIdentities()), len(tc.AllowedHosts()))
+}
+
+// readAllowlist reads the configuration file into v and returns the
+// transport allowlist, which comes from a flag instead when one was given
+func readAllowlist(v *viper.Viper, file string) (string, string, error) {
+	v.SetConfigFile(file)
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		return "", "", err
+	}
+	return v.GetString("transport.whitelist"), v.GetString("transport.whitelistHosts"), nil
+}
+
 // countSignals will cause a forced exit on repeated Ctrl+C commands
 // this is a convient escape from a deadlock during shutdown
 func countSignals(logger *logrus.Logger, num int, c chan os.Signal) {
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadAllowlistKeepsFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allowlist-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("[transport]\nwhitelist = \"\"\nwhitelistHosts = \"10.0.0.0/8\"\n"), 0600))
+
+	var whitelist, whitelistHosts string
+	flags := pflag.NewFlagSet("validator", pflag.ContinueOnError)
+	flags.StringVar(&whitelist, "transport.whitelist", "", "")
+	flags.StringVar(&whitelistHosts, "transport.whitelistHosts", "", "")
+	assert.Nil(t, flags.Parse([]string{"--transport.whitelist=ident1,ident2"}))
+
+	v := viper.New()
+	assert.Nil(t, v.BindPFlag("transport.whitelist", flags.Lookup("transport.whitelist")))
+	assert.Nil(t, v.BindPFlag("transport.whitelistHosts", flags.Lookup("transport.whitelistHosts")))
+
+	// The list given as a flag isn't replaced by the file's empty one
+	identities, hosts, err := readAllowlist(v, file)
+	assert.Nil(t, err)
+	assert.Equal(t, "ident1,ident2", identities)
+	assert.Equal(t, "10.0.0.0/8", hosts)
+
+	// Later changes to the file are picked up for keys without a flag
+	assert.Nil(t, ioutil.WriteFile(file, []byte("[transport]\nwhitelist = \"ident3\"\nwhitelistHosts = \"10.1.0.0/16\"\n"), 0600))
+	identities, hosts, err = readAllowlist(v, file)
+	assert.Nil(t, err)
+	assert.Equal(t, "ident1,ident2", identities)
+	assert.Equal(t, "10.1.0.0/16", hosts)
+
+	_, _, err = readAllowlist(v, filepath.Join(dir, "missing.toml"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,353 @@
+package verify
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	aobjs "github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// UTXODiff describes a single UTXO touched by a block that failed
+// verification. InReplay reports if the UTXO exists in the replayed state
+// after the block was applied and InNode reports if it exists in the state
+// of the node being verified. Since the node state is at the chain tip, a
+// created UTXO may legitimately be missing from it if it was spent later.
+type UTXODiff struct {
+	UTXOID   []byte
+	Consumed bool
+	InReplay bool
+	InNode   bool
+	Differs  bool
+}
+
+// MismatchError is returned when a replayed block does not reproduce the
+// roots committed in its block header.
+type MismatchError struct {
+	Height   uint32
+	Field    string
+	Expected []byte
+	Actual   []byte
+	UTXOs    []*UTXODiff
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s mismatch at height %d: expected %x, got %x", e.Field, e.Height, e.Expected, e.Actual)
+}
+
+// replayer re-applies the committed chain of a source database onto a
+// fresh in-memory application.
+type replayer struct {
+	logger *logrus.Logger
+
+	srcDB  *db.Database
+	srcApp *application.Application
+
+	dstDB  *db.Database
+	dstApp *application.Application
+	dstDph *deposit.Handler
+}
+
+func newReplayer(logger *logrus.Logger, closeChan <-chan struct{}, stateDb *badger.DB) (*replayer, error) {
+	srcDB := &db.Database{}
+	if err := srcDB.Init(stateDb); err != nil {
+		return nil, err
+	}
+	srcTxnDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		return nil, err
+	}
+	srcDph := &deposit.Handler{}
+	if err := srcDph.Init(); err != nil {
+		return nil, err
+	}
+	srcApp := &application.Application{}
+	if err := srcApp.Init(srcDB, srcTxnDb, srcDph); err != nil {
+		return nil, err
+	}
+
+	dstStateDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		return nil, err
+	}
+	dstTxnDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		return nil, err
+	}
+	dstDB := &db.Database{}
+	if err := dstDB.Init(dstStateDb); err != nil {
+		return nil, err
+	}
+	dstDph := &deposit.Handler{}
+	if err := dstDph.Init(); err != nil {
+		return nil, err
+	}
+	dstApp := &application.Application{}
+	if err := dstApp.Init(dstDB, dstTxnDb, dstDph); err != nil {
+		return nil, err
+	}
+
+	return &replayer{
+		logger: logger,
+		srcDB:  srcDB,
+		srcApp: srcApp,
+		dstDB:  dstDB,
+		dstApp: dstApp,
+		dstDph: dstDph,
+	}, nil
+}
+
+// run replays every committed block starting at height one and returns the
+// last height that was verified. The first block that fails verification
+// is reported as a *MismatchError.
+func (r *replayer) run() (uint32, error) {
+	height := uint32(1)
+	for {
+		ok, err := r.verifyHeight(height)
+		if err != nil {
+			return height - 1, err
+		}
+		if !ok {
+			return height - 1, nil
+		}
+		if height%1000 == 0 {
+			r.logger.Infof("Verified through height %d", height)
+		}
+		height++
+	}
+}
+
+// verifyHeight replays a single block. It returns false if no block has
+// been committed at the given height.
+func (r *replayer) verifyHeight(height uint32) (bool, error) {
+	var bh *objs.BlockHeader
+	var txs []interfaces.Transaction
+	var deposits []*aobjs.TXOut
+	var depositIDs [][]byte
+	err := r.srcDB.View(func(txn *badger.Txn) error {
+		hdr, err := r.srcDB.GetCommittedBlockHeader(txn, height)
+		if err != nil {
+			return err
+		}
+		bh = hdr
+		if len(bh.TxHshLst) == 0 {
+			return nil
+		}
+		found, missing, err := r.srcApp.MinedTxGet(txn, bh.TxHshLst)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%d mined txs missing from the local db at height %d", len(missing), height)
+		}
+		txs, err = orderTxs(bh.TxHshLst, found)
+		if err != nil {
+			return err
+		}
+		depositIDs, deposits, err = r.getDeposits(txn, txs)
+		return err
+	})
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			if height == 1 {
+				return false, fmt.Errorf("no block header committed at height 1; replay requires the full chain history")
+			}
+			return false, nil
+		}
+		return false, err
+	}
+
+	txHashes := make([][]byte, len(txs))
+	for i := 0; i < len(txs); i++ {
+		txHash, err := txs[i].TxHash()
+		if err != nil {
+			return false, err
+		}
+		txHashes[i] = txHash
+	}
+	txRoot, err := objs.MakeTxRoot(txHashes)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(txRoot, bh.BClaims.TxRoot) {
+		return false, &MismatchError{
+			Height:   height,
+			Field:    "TxRoot",
+			Expected: utils.CopySlice(bh.BClaims.TxRoot),
+			Actual:   txRoot,
+		}
+	}
+
+	err = r.dstDB.Update(func(txn *badger.Txn) error {
+		for i := 0; i < len(deposits); i++ {
+			if err := r.addDeposit(txn, depositIDs[i], deposits[i]); err != nil {
+				return err
+			}
+		}
+		stateRoot, err := r.dstApp.ApplyState(txn, bh.BClaims.ChainID, height, txs)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(stateRoot, bh.BClaims.StateRoot) {
+			diff, err := r.diffUTXOs(txn, txs)
+			if err != nil {
+				return err
+			}
+			return &MismatchError{
+				Height:   height,
+				Field:    "StateRoot",
+				Expected: utils.CopySlice(bh.BClaims.StateRoot),
+				Actual:   stateRoot,
+				UTXOs:    diff,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// orderTxs returns the transactions in the order given by the tx hash list
+// of the block header.
+func orderTxs(txHashes [][]byte, found []interfaces.Transaction) ([]interfaces.Transaction, error) {
+	byHash := make(map[string]interfaces.Transaction, len(found))
+	for i := 0; i < len(found); i++ {
+		txHash, err := found[i].TxHash()
+		if err != nil {
+			return nil, err
+		}
+		byHash[string(txHash)] = found[i]
+	}
+	out := make([]interfaces.Transaction, len(txHashes))
+	for i := 0; i < len(txHashes); i++ {
+		tx, ok := byHash[string(txHashes[i])]
+		if !ok {
+			return nil, fmt.Errorf("mined tx %x not returned by the local db", txHashes[i])
+		}
+		out[i] = tx
+	}
+	return out, nil
+}
+
+// getDeposits loads the deposits consumed by txs from the source database.
+// Deposits are injected from Ethereum rather than created by transactions,
+// so they must be copied into the replay state before the block is applied.
+func (r *replayer) getDeposits(txn *badger.Txn, txs []interfaces.Transaction) ([][]byte, []*aobjs.TXOut, error) {
+	txv, err := toTxVec(txs)
+	if err != nil {
+		return nil, nil, err
+	}
+	utxoIDs, err := txv.ConsumedUTXOIDOnlyDeposits()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make([]*aobjs.TXOut, len(utxoIDs))
+	for i := 0; i < len(utxoIDs); i++ {
+		utxoBytes, err := r.srcApp.GetSnapShotStateData(txn, utxoIDs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		utxo := &aobjs.TXOut{}
+		if err := utxo.UnmarshalBinary(utxoBytes); err != nil {
+			return nil, nil, err
+		}
+		out[i] = utxo
+	}
+	return utxoIDs, out, nil
+}
+
+func (r *replayer) addDeposit(txn *badger.Txn, utxoID []byte, utxo *aobjs.TXOut) error {
+	chainID, err := utxo.ChainID()
+	if err != nil {
+		return err
+	}
+	value, err := utxo.Value()
+	if err != nil {
+		return err
+	}
+	valueBytes, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	owner, err := utxo.GenericOwner()
+	if err != nil {
+		return err
+	}
+	return r.dstDph.Add(txn, chainID, utxoID, new(big.Int).SetBytes(valueBytes), owner)
+}
+
+// diffUTXOs reports every UTXO consumed or created by txs along with its
+// presence in the replayed state and in the node state.
+func (r *replayer) diffUTXOs(dstTxn *badger.Txn, txs []interfaces.Transaction) ([]*UTXODiff, error) {
+	txv, err := toTxVec(txs)
+	if err != nil {
+		return nil, err
+	}
+	consumed, err := txv.ConsumedUTXOID()
+	if err != nil {
+		return nil, err
+	}
+	generated, err := txv.GeneratedUTXOID()
+	if err != nil {
+		return nil, err
+	}
+	diffs := []*UTXODiff{}
+	for i := 0; i < len(consumed); i++ {
+		diffs = append(diffs, &UTXODiff{UTXOID: utils.CopySlice(consumed[i]), Consumed: true})
+	}
+	for i := 0; i < len(generated); i++ {
+		diffs = append(diffs, &UTXODiff{UTXOID: utils.CopySlice(generated[i])})
+	}
+	err = r.srcDB.View(func(srcTxn *badger.Txn) error {
+		for _, d := range diffs {
+			replayed, err := r.dstApp.UTXOGet(dstTxn, [][]byte{d.UTXOID})
+			if err != nil {
+				return err
+			}
+			node, err := r.srcApp.UTXOGet(srcTxn, [][]byte{d.UTXOID})
+			if err != nil {
+				return err
+			}
+			d.InReplay = len(replayed) > 0
+			d.InNode = len(node) > 0
+			if d.InReplay && d.InNode {
+				a, err := replayed[0].MarshalBinary()
+				if err != nil {
+					return err
+				}
+				b, err := node[0].MarshalBinary()
+				if err != nil {
+					return err
+				}
+				d.Differs = !bytes.Equal(a, b)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+func toTxVec(txs []interfaces.Transaction) (aobjs.TxVec, error) {
+	txv := make(aobjs.TxVec, len(txs))
+	for i := 0; i < len(txs); i++ {
+		tx, ok := txs[i].(*aobjs.Tx)
+		if !ok {
+			return nil, fmt.Errorf("unexpected transaction type %T", txs[i])
+		}
+		txv[i] = tx
+	}
+	return txv, nil
+}
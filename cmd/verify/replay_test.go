@@ -0,0 +1,272 @@
+package verify
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	aobjs "github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const replayChainID = uint32(42)
+
+// replayNode holds the databases and application of the node whose chain
+// is replayed
+type replayNode struct {
+	stateDb  *badger.DB
+	database *db.Database
+	app      *application.Application
+	dph      *deposit.Handler
+}
+
+func newReplayNode(t *testing.T) *replayNode {
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	stateDb, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	txnDb, err := utils.OpenBadger(closeChan, "", true)
+	assert.Nil(t, err)
+	database := &db.Database{}
+	assert.Nil(t, database.Init(stateDb))
+	dph := &deposit.Handler{}
+	assert.Nil(t, dph.Init())
+	app := &application.Application{}
+	assert.Nil(t, app.Init(database, txnDb, dph))
+	return &replayNode{stateDb: stateDb, database: database, app: app, dph: dph}
+}
+
+// spendDeposits returns transactions moving num new deposits into value stores
+func (n *replayNode) spendDeposits(t *testing.T, num int) []interfaces.Transaction {
+	signer := &crypto.Secp256k1Signer{}
+	assert.Nil(t, signer.SetPrivk(crypto.Hasher([]byte("secret"))))
+	pubk, err := signer.Pubkey()
+	assert.Nil(t, err)
+	account := crypto.GetAccount(pubk)
+	owner := &aobjs.ValueStoreOwner{SVA: aobjs.ValueStoreSVA, CurveSpec: constants.CurveSecp256k1, Account: account}
+
+	txs := []interfaces.Transaction{}
+	err = n.database.Update(func(txn *badger.Txn) error {
+		for i := 0; i < num; i++ {
+			depositID := utils.ForceSliceToLength([]byte(strconv.Itoa(i+1)), constants.HashLen)
+			if err := n.dph.Add(txn, replayChainID, depositID, big.NewInt(1), &aobjs.Owner{CurveSpec: constants.CurveSecp256k1, Account: account}); err != nil {
+				return err
+			}
+			deps, _, _, err := n.dph.Get(txn, [][]byte{depositID})
+			if err != nil {
+				return err
+			}
+			dep, err := deps[0].ValueStore()
+			if err != nil {
+				return err
+			}
+			txIn, err := dep.MakeTxIn()
+			if err != nil {
+				return err
+			}
+			out := &aobjs.TXOut{}
+			err = out.NewValueStore(&aobjs.ValueStore{
+				VSPreImage: &aobjs.VSPreImage{TXOutIdx: 0, Value: uint256.One(), ChainID: replayChainID, Owner: owner},
+				TxHash:     make([]byte, constants.HashLen),
+			})
+			if err != nil {
+				return err
+			}
+			tx := &aobjs.Tx{Vin: []*aobjs.TXIn{txIn}, Vout: []*aobjs.TXOut{out}}
+			if err := tx.SetTxHash(); err != nil {
+				return err
+			}
+			if err := dep.Sign(tx.Vin[0], signer); err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	return txs
+}
+
+// commitChain commits numBlocks signed block headers, the second of which
+// holds txs
+func (n *replayNode) commitChain(t *testing.T, numBlocks int, txs []interfaces.Transaction) {
+	signer := &crypto.BNGroupSigner{}
+	signer.SetPrivk(crypto.Hasher([]byte("secret")))
+	groupKey, err := signer.PubkeyShare()
+	assert.Nil(t, err)
+	assert.Nil(t, signer.SetGroupPubk(groupKey))
+
+	prevBlock := make([]byte, constants.HashLen)
+	for height := uint32(1); height <= uint32(numBlocks); height++ {
+		err := n.database.Update(func(txn *badger.Txn) error {
+			headerRoot, err := n.database.GetHeaderRootForProposal(txn)
+			if err == badger.ErrKeyNotFound {
+				headerRoot, err = make([]byte, constants.HashLen), nil
+			}
+			if err != nil {
+				return err
+			}
+			blockTxs := []interfaces.Transaction{}
+			if height == 2 {
+				blockTxs = txs
+			}
+			stateRoot, err := n.app.ApplyState(txn, replayChainID, height, blockTxs)
+			if err != nil {
+				return err
+			}
+			txHashes := [][]byte{}
+			for _, tx := range blockTxs {
+				txHash, err := tx.TxHash()
+				if err != nil {
+					return err
+				}
+				txHashes = append(txHashes, txHash)
+			}
+			txRoot, err := objs.MakeTxRoot(txHashes)
+			if err != nil {
+				return err
+			}
+			bclaims := &objs.BClaims{
+				ChainID:    replayChainID,
+				Height:     height,
+				TxCount:    uint32(len(txHashes)),
+				PrevBlock:  prevBlock,
+				TxRoot:     txRoot,
+				StateRoot:  stateRoot,
+				HeaderRoot: headerRoot,
+			}
+			bhsh, err := bclaims.BlockHash()
+			if err != nil {
+				return err
+			}
+			sig, err := signer.Sign(bhsh)
+			if err != nil {
+				return err
+			}
+			prevBlock = bhsh
+			return n.database.SetCommittedBlockHeader(txn, &objs.BlockHeader{BClaims: bclaims, SigGroup: sig, TxHshLst: txHashes})
+		})
+		assert.Nil(t, err)
+	}
+}
+
+// tamperHeader changes the claims of the block header committed at height
+func (n *replayNode) tamperHeader(t *testing.T, height uint32, tamper func(*objs.BClaims)) {
+	err := n.database.Update(func(txn *badger.Txn) error {
+		bh, err := n.database.GetCommittedBlockHeader(txn, height)
+		if err != nil {
+			return err
+		}
+		tamper(bh.BClaims)
+		return n.database.SetCommittedBlockHeaderFastSync(txn, bh)
+	})
+	assert.Nil(t, err)
+}
+
+// committedStateRoot returns the state root of the block header at height
+func (n *replayNode) committedStateRoot(t *testing.T, height uint32) []byte {
+	var root []byte
+	err := n.database.View(func(txn *badger.Txn) error {
+		bh, err := n.database.GetCommittedBlockHeader(txn, height)
+		if err != nil {
+			return err
+		}
+		root = bh.BClaims.StateRoot
+		return nil
+	})
+	assert.Nil(t, err)
+	return root
+}
+
+func newTestReplayer(t *testing.T, n *replayNode) *replayer {
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	r, err := newReplayer(logging.GetLogger("verify"), closeChan, n.stateDb)
+	assert.Nil(t, err)
+	return r
+}
+
+func TestReplay(t *testing.T) {
+	n := newReplayNode(t)
+	n.commitChain(t, 3, n.spendDeposits(t, 2))
+
+	r := newTestReplayer(t, n)
+	height, err := r.run()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), height)
+
+	// The replayed state is the state the node committed to
+	txn := r.dstDB.DB().NewTransaction(true)
+	defer txn.Discard()
+	root, err := r.dstApp.ApplyState(txn, replayChainID, 4, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, n.committedStateRoot(t, 3), root)
+
+	// The deposits were copied from the node and spent by the replay
+	depositID := utils.ForceSliceToLength([]byte("1"), constants.HashLen)
+	found, _, spent, err := r.dstDph.Get(txn, [][]byte{depositID})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(found))
+	assert.Equal(t, 1, len(spent))
+}
+
+func TestReplayTamperedStateRoot(t *testing.T) {
+	n := newReplayNode(t)
+	n.commitChain(t, 3, n.spendDeposits(t, 2))
+	n.tamperHeader(t, 2, func(bc *objs.BClaims) {
+		bc.StateRoot = crypto.Hasher([]byte("tampered"))
+	})
+
+	height, err := newTestReplayer(t, n).run()
+	assert.Equal(t, uint32(1), height)
+	var mm *MismatchError
+	if assert.True(t, errors.As(err, &mm)) {
+		assert.Equal(t, uint32(2), mm.Height)
+		assert.Equal(t, "StateRoot", mm.Field)
+		assert.Equal(t, crypto.Hasher([]byte("tampered")), mm.Expected)
+
+		// Both spent deposits and both value stores they created are reported
+		assert.Equal(t, 4, len(mm.UTXOs))
+		consumed := 0
+		for _, d := range mm.UTXOs {
+			if d.Consumed {
+				consumed++
+				continue
+			}
+			assert.True(t, d.InReplay)
+			assert.True(t, d.InNode)
+			assert.False(t, d.Differs)
+		}
+		assert.Equal(t, 2, consumed)
+	}
+}
+
+func TestReplayTamperedTxRoot(t *testing.T) {
+	n := newReplayNode(t)
+	n.commitChain(t, 3, n.spendDeposits(t, 2))
+	n.tamperHeader(t, 2, func(bc *objs.BClaims) {
+		bc.TxRoot = crypto.Hasher([]byte("tampered"))
+	})
+
+	height, err := newTestReplayer(t, n).run()
+	assert.Equal(t, uint32(1), height)
+	var mm *MismatchError
+	if assert.True(t, errors.As(err, &mm)) {
+		assert.Equal(t, uint32(2), mm.Height)
+		assert.Equal(t, "TxRoot", mm.Field)
+	}
+}
@@ -0,0 +1,57 @@
+package verify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/spf13/cobra"
+)
+
+// Command is the cobra.Command for replaying the local chain and verifying
+// the committed state
+var Command = cobra.Command{
+	Use:   "verify-chain",
+	Short: "Replays the local chain and verifies state roots",
+	Long:  "verify-chain re-applies the mined transactions of every committed block to a fresh in-memory state and compares the resulting state and tx roots against the committed block headers. The node must not be running against the same state db.",
+	Run:   verifyChain}
+
+func verifyChain(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("verify")
+
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	stateDbPath := config.Configuration.Chain.StateDbPath
+	logger.Infof("Verifying chain in state db %v", stateDbPath)
+
+	stateDb, err := utils.OpenBadger(ctx.Done(), stateDbPath, false)
+	if err != nil {
+		logger.Fatalf("Could not open state db: %v", err)
+	}
+
+	r, err := newReplayer(logger, ctx.Done(), stateDb)
+	if err != nil {
+		logger.Fatalf("Could not setup replay: %v", err)
+	}
+
+	height, err := r.run()
+	if err != nil {
+		var mm *MismatchError
+		if errors.As(err, &mm) {
+			logger.Errorf("Verification failed after %d good blocks: %v", height, mm)
+			for _, d := range mm.UTXOs {
+				action := "created"
+				if d.Consumed {
+					action = "consumed"
+				}
+				logger.Errorf("  utxo %x %-8s inReplay:%-5v inNode:%-5v differs:%v", d.UTXOID, action, d.InReplay, d.InNode, d.Differs)
+			}
+			logger.Fatal("Chain verification failed")
+		}
+		logger.Fatalf("Chain verification could not complete after %d blocks: %v", height, err)
+	}
+	logger.Infof("Chain verified through height %d", height)
+}
@@ -207,3 +207,12 @@ func TestConfigFileArray(t *testing.T) {
 
 	fmt.Printf("bootnodes:%v\n", bootnodes)
 }
+
+func TestValidatorSigner(t *testing.T) {
+	for _, signer := range []string{"", "keystore"} {
+		assert.Nil(t, ethereumConfig{Signer: signer}.ValidatorSigner())
+	}
+	for _, signer := range []string{"clef", "web3signer"} {
+		assert.Equal(t, ErrValidatorRemoteSigner, ethereumConfig{Signer: signer}.ValidatorSigner())
+	}
+}
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -15,6 +18,7 @@ type bootnodeConfig struct {
 	Name             string
 	ListeningAddress string
 	CacheSize        int
+	DatabasePath     string
 }
 
 type chainConfig struct {
@@ -28,24 +32,32 @@ type chainConfig struct {
 }
 
 type ethereumConfig struct {
+	ChainID              int
 	DefaultAccount       string
 	DeployAccount        string
 	Endpoint             string
 	EndpointMinimumPeers int
+	EndpointQuorum       int
+	EndpointMaxHeadAge   time.Duration
 	FinalityDelay        int
 	Keystore             string
+	MaxGasPrice          int
 	MerkleProofContract  string
 	Passcodes            string
 	RegistryAddress      string
 	RetryCount           int
 	RetryDelay           time.Duration
+	Signer               string
+	SignerEndpoint       string
 	StartingBlock        int
 	TestEther            string
 	Timeout              time.Duration
+	TxnReplaceAfter      int
 }
 
 type monitorConfig struct {
 	BatchSize int
+	Confirm   bool
 	Interval  time.Duration
 }
 
@@ -57,12 +69,26 @@ type transportConfig struct {
 	PeerLimitMax               int
 	FirewallMode               bool
 	FirewallHost               string
+	SentryAddresses            string
+	PrivatePeers               string
 	Whitelist                  string
+	WhitelistHosts             string
 	PrivateKey                 string
 	BootNodeAddresses          string
 	P2PListeningAddress        string
 	DiscoveryListeningAddress  string
 	LocalStateListeningAddress string
+	BanThreshold               int
+	BanDuration                time.Duration
+	GossipRateLimit            int
+	GossipRateBurst            int
+	RequestRateLimit           int
+	RequestRateBurst           int
+	SnapShotRateLimit          int
+	SnapShotRateBurst          int
+	BandwidthLimit             int
+	PeerBandwidthLimit         int
+	Compression                bool
 }
 
 type deployConfig struct {
@@ -70,6 +96,10 @@ type deployConfig struct {
 	TestMigrations bool
 }
 
+type snapshotConfig struct {
+	Height int
+}
+
 type utilsConfig struct {
 	Status bool
 }
@@ -87,6 +117,7 @@ type configuration struct {
 	Deploy                deployConfig
 	Ethereum              ethereumConfig
 	Monitor               monitorConfig
+	Snapshot              snapshotConfig
 	Transport             transportConfig
 	Utils                 utilsConfig
 	Validator             validatorConfig
@@ -135,6 +166,31 @@ func SetValue(ptr interface{}, value interface{}) {
 	}
 }
 
+// Endpoints returns the urls of the Ethereum endpoints in order of preference
+func (e ethereumConfig) Endpoints() []string {
+	return splitList(e.Endpoint)
+}
+
+// ErrValidatorRemoteSigner is returned for a validator configured with a
+// remote signer
+var ErrValidatorRemoteSigner = errors.New("validators sign consensus messages with the key of the default account, ethereum.signer must be keystore")
+
+// ValidatorSigner checks that a validator can run with the signer configured.
+// Consensus messages are signed with the key of the Ethereum account the
+// validator is registered with, so the key must be in the keystore.
+func (e ethereumConfig) ValidatorSigner() error {
+	switch e.Signer {
+	case "", "keystore":
+		return nil
+	}
+	return ErrValidatorRemoteSigner
+}
+
+// MaxGasPriceWei returns the highest gas price offered for a transaction
+func (e ethereumConfig) MaxGasPriceWei() *big.Int {
+	return new(big.Int).Mul(big.NewInt(int64(e.MaxGasPrice)), big.NewInt(constants.OneBillion))
+}
+
 func (t transportConfig) BootNodes() []string {
 	bootNodeAddresses := strings.Split(t.BootNodeAddresses, ",")
 	for idx := range bootNodeAddresses {
@@ -142,3 +198,38 @@ func (t transportConfig) BootNodes() []string {
 	}
 	return bootNodeAddresses
 }
+
+// Sentries returns the addresses of the sentry nodes of a validator
+func (t transportConfig) Sentries() []string {
+	return splitList(t.SentryAddresses)
+}
+
+// PrivatePeerIdentities returns the identities of the peers whose addresses
+// must never be shared with other peers
+func (t transportConfig) PrivatePeerIdentities() []string {
+	return splitList(t.PrivatePeers)
+}
+
+// AllowedIdentities returns the transport identities of the peers a node
+// may connect to. An empty list allows every peer.
+func (t transportConfig) AllowedIdentities() []string {
+	return splitList(t.Whitelist)
+}
+
+// AllowedHosts returns the hosts, IP addresses and CIDR ranges of the peers
+// a node may learn through discovery. An empty list allows every host.
+func (t transportConfig) AllowedHosts() []string {
+	return splitList(t.WhitelistHosts)
+}
+
+// splitList splits a comma separated list and drops the empty entries
+func splitList(list string) []string {
+	out := []string{}
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
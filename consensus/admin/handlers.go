@@ -43,6 +43,8 @@ type Handlers struct {
 	appHandler  appmock.Application
 	RequestLock chan struct{}
 	ReceiveLock chan interfaces.Lockable
+	// clock is the time the round of a new validator set starts at
+	clock func() time.Time
 }
 
 // Init creates all fields and binds external services
@@ -60,9 +62,16 @@ func (ah *Handlers) Init(chainID uint32, database *db.Database, secret []byte, a
 	ah.ethAcct = crypto.GetAccount(ethPubk)
 	ah.RequestLock = make(chan struct{})
 	ah.ReceiveLock = make(chan interfaces.Lockable)
+	ah.clock = time.Now
 	return nil
 }
 
+// SetClock replaces the wall clock the first round of a new validator set
+// is started with. It must be called after Init and before the handlers run.
+func (ah *Handlers) SetClock(fn func() time.Time) {
+	ah.clock = fn
+}
+
 // Close shuts down all workers
 func (ah *Handlers) Close() {
 	ah.closeOnce.Do(func() {
@@ -161,7 +170,7 @@ func (ah *Handlers) AddValidatorSet(v *objs.ValidatorSet) error {
 				VAddr:    ah.ethAcct,
 				GroupKey: v.GroupKey,
 			}
-			ownValidatingState.SetRoundStarted()
+			ownValidatingState.SetRoundStarted(ah.clock())
 			if err := ah.database.SetOwnValidatingState(txn, ownValidatingState); err != nil {
 				utils.DebugTrace(ah.logger, err)
 				return err
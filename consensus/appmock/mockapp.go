@@ -3,6 +3,7 @@ package appmock
 import (
 	trie "github.com/MadBase/MadNet/badgerTrie"
 	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/crypto"
 	"github.com/MadBase/MadNet/errorz"
 	"github.com/MadBase/MadNet/interfaces"
@@ -31,16 +32,19 @@ func (m *MockApplication) SetNextValidValue(vv *objs.Proposal) {
 
 // New returns a mocked Application
 func New() *MockApplication {
-	return &MockApplication{logging.GetLogger("MockApp"), nil, false}
+	return &MockApplication{logging.GetLogger(constants.LoggerApp), nil, false}
 }
 
 // ApplyState is defined on the interface object
 func (m *MockApplication) ApplyState(*badger.Txn, uint32, uint32, []interfaces.Transaction) ([]byte, error) {
-	return nil, nil
+	return make([]byte, constants.HashLen), nil
 }
 
 //GetValidProposal is defined on the interface object
 func (m *MockApplication) GetValidProposal(txn *badger.Txn, chainID, height, maxBytes uint32) ([]interfaces.Transaction, []byte, error) {
+	if m.validValue == nil {
+		return nil, make([]byte, constants.HashLen), nil
+	}
 	return nil, m.validValue.PClaims.BClaims.StateRoot, nil
 }
 
@@ -9,6 +9,7 @@ import (
 	trie "github.com/MadBase/MadNet/badgerTrie"
 	"github.com/MadBase/MadNet/consensus/objs"
 	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/errorz"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/MadBase/MadNet/utils"
 	"github.com/dgraph-io/badger/v2"
@@ -1783,3 +1784,49 @@ func (sbhki *StagedBlockHeaderKeyIter) Next() (uint32, *objs.BlockHeader, bool,
 func (sbhki *StagedBlockHeaderKeyIter) Close() {
 	sbhki.it.Close()
 }
+
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (db *Database) makeFastSyncTargetKey() []byte {
+	return dbprefix.PrefixFastSyncTarget()
+}
+
+// SetFastSyncTarget records the snapshot that fast sync is currently
+// downloading so that an interrupted sync can resume from its pending keys.
+func (db *Database) SetFastSyncTarget(txn *badger.Txn, height uint32, stateRoot []byte, hdrRoot []byte, blockHash []byte) error {
+	if len(stateRoot) != constants.HashLen || len(hdrRoot) != constants.HashLen || len(blockHash) != constants.HashLen {
+		return errorz.ErrInvalid{}.New("invalid fast sync target")
+	}
+	v := utils.MarshalUint32(height)
+	v = append(v, utils.CopySlice(stateRoot)...)
+	v = append(v, utils.CopySlice(hdrRoot)...)
+	v = append(v, utils.CopySlice(blockHash)...)
+	return utils.SetValue(txn, db.makeFastSyncTargetKey(), v)
+}
+
+// GetFastSyncTarget returns the height, state root, header root and block
+// hash of the snapshot recorded by SetFastSyncTarget.
+func (db *Database) GetFastSyncTarget(txn *badger.Txn) (uint32, []byte, []byte, []byte, error) {
+	v, err := utils.GetValue(txn, db.makeFastSyncTargetKey())
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	if len(v) != 4+3*constants.HashLen {
+		return 0, nil, nil, nil, errorz.ErrInvalid{}.New("invalid fast sync target")
+	}
+	height, err := utils.UnmarshalUint32(v[:4])
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	v = v[4:]
+	stateRoot := utils.CopySlice(v[:constants.HashLen])
+	hdrRoot := utils.CopySlice(v[constants.HashLen : 2*constants.HashLen])
+	blockHash := utils.CopySlice(v[2*constants.HashLen:])
+	return height, stateRoot, hdrRoot, blockHash, nil
+}
+
+func (db *Database) DeleteFastSyncTarget(txn *badger.Txn) error {
+	return utils.DeleteValue(txn, db.makeFastSyncTargetKey())
+}
@@ -754,3 +754,50 @@ func TestStagedBHKeyIter(t *testing.T) {
 	}
 
 }
+
+func TestFastSyncTarget(t *testing.T) {
+	tbd, db, _ := newDB(t)
+	defer tbd.Close()
+	badgerD := tbd.db
+
+	stateRoot := crypto.Hasher([]byte("state"))
+	hdrRoot := crypto.Hasher([]byte("hdr"))
+	blockHash := crypto.Hasher([]byte("block"))
+	err := badgerD.Update(func(txn *badger.Txn) error {
+		_, _, _, _, err := db.GetFastSyncTarget(txn)
+		if err != badger.ErrKeyNotFound {
+			t.Fatalf("expected key not found, got %v", err)
+		}
+		if err := db.SetFastSyncTarget(txn, 1024, stateRoot, hdrRoot, blockHash[:4]); err == nil {
+			t.Fatal("should have raised error for short hash")
+		}
+		return db.SetFastSyncTarget(txn, 1024, stateRoot, hdrRoot, blockHash)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = badgerD.Update(func(txn *badger.Txn) error {
+		height, sr, hr, bh, err := db.GetFastSyncTarget(txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if height != 1024 {
+			t.Fatalf("wrong height %d", height)
+		}
+		if !bytes.Equal(sr, stateRoot) || !bytes.Equal(hr, hdrRoot) || !bytes.Equal(bh, blockHash) {
+			t.Fatal("roots not equal")
+		}
+		if err := db.DeleteFastSyncTarget(txn); err != nil {
+			t.Fatal(err)
+		}
+		_, _, _, _, err = db.GetFastSyncTarget(txn)
+		if err != badger.ErrKeyNotFound {
+			t.Fatalf("expected key not found, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/MadBase/MadNet/interfaces"
 	"github.com/MadBase/MadNet/logging"
 	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/types"
 	"github.com/dgraph-io/badger/v2"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/peer"
@@ -115,6 +116,9 @@ func (mb *Handlers) sendErr(ctx context.Context, err error, eC chan<- error) boo
 }
 
 func (mb *Handlers) handleStateUpdateErrors(ctx context.Context, err error, eC chan<- error) error {
+	if err == nil {
+		mb.reportPeer(ctx, types.PeerUseful)
+	}
 	etestStale := &errorz.ErrStale{}
 	if errors.As(err, &etestStale) {
 		mb.sendErr(ctx, err, eC)
@@ -410,6 +414,30 @@ func (mb *Handlers) preventGossip(ctx context.Context, rawmsg []byte, isTx bool,
 	mb.peerSub.PreventGossipConsensus(nodeAddr, rawmsg)
 }
 
+// reportPeer reports the sender of a gossip message to the peer manager
+func (mb *Handlers) reportPeer(ctx context.Context, ev types.PeerEvent) {
+	peerAddr, ok := peer.FromContext(ctx)
+	if !ok {
+		return
+	}
+	nodeAddr, ok := peerAddr.Addr.(interfaces.NodeAddr)
+	if !ok {
+		return
+	}
+	mb.peerSub.ReportPeer(nodeAddr, ev)
+}
+
+// reportInvalid penalizes the sender of a message that failed validation
+// before it was queued. Errors raised while storing a message are not
+// reported since duplicate and late messages from honest peers raise them
+// as well.
+func (mb *Handlers) reportInvalid(ctx context.Context, err error) {
+	etestInvalid := &errorz.ErrInvalid{}
+	if errors.As(err, &etestInvalid) {
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
+	}
+}
+
 func (mb *Handlers) setupHandler(pctx context.Context) (context.Context, func(), chan error, error) {
 	ctx, cf := context.WithTimeout(pctx, constants.SrvrMsgTimeout)
 	select {
@@ -460,10 +488,12 @@ func (mb *Handlers) HandleP2PGossipProposal(pctx context.Context, msg *pb.Gossip
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipProposalAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipProposalAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -492,10 +522,12 @@ func (mb *Handlers) HandleP2PGossipPreVote(pctx context.Context, msg *pb.GossipP
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipPreVoteAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipPreVoteAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -524,10 +556,12 @@ func (mb *Handlers) HandleP2PGossipPreVoteNil(pctx context.Context, msg *pb.Goss
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipPreVoteNilAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipPreVoteNilAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -556,10 +590,12 @@ func (mb *Handlers) HandleP2PGossipPreCommit(pctx context.Context, msg *pb.Gossi
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipPreCommitAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipPreCommitAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -588,10 +624,12 @@ func (mb *Handlers) HandleP2PGossipPreCommitNil(pctx context.Context, msg *pb.Go
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipPreCommitNilAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipPreCommitNilAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -622,10 +660,12 @@ func (mb *Handlers) HandleP2PGossipNextRound(pctx context.Context, msg *pb.Gossi
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipNextRoundAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipNextRoundAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -656,10 +696,12 @@ func (mb *Handlers) HandleP2PGossipNextHeight(pctx context.Context, msg *pb.Goss
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipNextHeightAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipNextHeightAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, false)
@@ -690,10 +732,12 @@ func (mb *Handlers) HandleP2PGossipBlockHeader(pctx context.Context, msg *pb.Gos
 	err = obj.UnmarshalBinary(rawmsg)
 	if err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportPeer(ctx, types.PeerInvalidGossip)
 		return &pb.GossipBlockHeaderAck{}, err
 	}
 	if err := mb.shandlers.PreValidate(obj); err != nil {
 		utils.DebugTrace(mb.logger, err)
+		mb.reportInvalid(ctx, err)
 		return &pb.GossipBlockHeaderAck{}, err
 	}
 	mb.preventGossip(ctx, rawmsg, false, true)
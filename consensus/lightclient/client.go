@@ -0,0 +1,310 @@
+// Package lightclient follows the chain without executing it. Starting from
+// a trusted block header and validator set, the client downloads block
+// headers from its peers and accepts a header only if it extends the last
+// verified header and carries a group signature from the validator set in
+// effect at its height. Block headers do not carry validator sets, so the
+// sets that take effect later are read from a ValidatorSetSource while the
+// client follows the chain.
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/errorz"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// headerBatchSize is the number of block headers requested at once
+	headerBatchSize = 32
+	// retainHeaders is the number of verified block headers kept in memory
+	retainHeaders = 1024
+)
+
+// HeaderSource downloads block headers by height. It is satisfied by
+// request.Client, which serves the request through the P2P GetBlockHeaders
+// RPC.
+type HeaderSource interface {
+	RequestP2PGetBlockHeaders(ctx context.Context, blockNums []uint32) ([]*objs.BlockHeader, error)
+}
+
+// ValidatorSetSource returns the validator sets that take effect above
+// height. Full nodes learn validator sets from the ETHDKG events on
+// Ethereum, so a source should read them from there or from a node the
+// caller trusts.
+type ValidatorSetSource interface {
+	ValidatorSets(ctx context.Context, height uint32) ([]*objs.ValidatorSet, error)
+}
+
+// Client verifies and stores the block headers of a single chain.
+type Client struct {
+	sync.RWMutex
+	logger  *logrus.Logger
+	source  HeaderSource
+	vsSrc   ValidatorSetSource
+	bnVal   *crypto.BNGroupValidator
+	chainID uint32
+	vsets   []*objs.ValidatorSet
+	headers map[uint32]*objs.BlockHeader
+	latest  *objs.BlockHeader
+}
+
+// Init initializes the client from a trusted block header and the
+// validator set in effect at the height of that header. Neither is
+// checked against the chain; the caller must obtain both from a source it
+// trusts, such as a local node or the validator set events on Ethereum.
+func (c *Client) Init(source HeaderSource, trusted *objs.BlockHeader, vs *objs.ValidatorSet) error {
+	if trusted == nil || trusted.BClaims == nil {
+		return errorz.ErrInvalid{}.New("trusted block header not initialized")
+	}
+	if vs == nil || len(vs.GroupKey) == 0 {
+		return errorz.ErrInvalid{}.New("trusted validator set not initialized")
+	}
+	if vs.NotBefore > trusted.BClaims.Height {
+		return errorz.ErrInvalid{}.New("trusted validator set is not in effect at the trusted height")
+	}
+	c.logger = logging.GetLogger(constants.LoggerConsensus)
+	c.source = source
+	c.bnVal = &crypto.BNGroupValidator{}
+	c.chainID = trusted.BClaims.ChainID
+	c.vsets = []*objs.ValidatorSet{vs}
+	c.headers = make(map[uint32]*objs.BlockHeader)
+	if err := c.checkSignature(trusted); err != nil {
+		return err
+	}
+	c.store(trusted)
+	return nil
+}
+
+// SetValidatorSetSource sets the source Update reads new validator sets
+// from. Without a source the sets must be added with AddValidatorSet.
+func (c *Client) SetValidatorSetSource(vsSrc ValidatorSetSource) {
+	c.Lock()
+	defer c.Unlock()
+	c.vsSrc = vsSrc
+}
+
+// AddValidatorSet registers a validator set that takes effect at the
+// height given by its NotBefore field. Headers at or above that height are
+// only accepted if they are signed by the group key of the new set, so the
+// client stops advancing at a validator set change until the new set has
+// been added.
+func (c *Client) AddValidatorSet(vs *objs.ValidatorSet) error {
+	if vs == nil || len(vs.GroupKey) == 0 {
+		return errorz.ErrInvalid{}.New("validator set not initialized")
+	}
+	c.Lock()
+	defer c.Unlock()
+	if vs.NotBefore <= c.latest.BClaims.Height {
+		return errorz.ErrStale{}.New("validator set takes effect below the latest verified height")
+	}
+	for i, v := range c.vsets {
+		if v.NotBefore == vs.NotBefore {
+			c.vsets[i] = vs
+			return nil
+		}
+	}
+	c.vsets = append(c.vsets, vs)
+	sort.Slice(c.vsets, func(i, j int) bool {
+		return c.vsets[i].NotBefore < c.vsets[j].NotBefore
+	})
+	return nil
+}
+
+// ValidatorSet returns the validator set in effect at height
+func (c *Client) ValidatorSet(height uint32) (*objs.ValidatorSet, error) {
+	c.RLock()
+	defer c.RUnlock()
+	vs := c.validatorSet(height)
+	if vs == nil {
+		return nil, errorz.ErrInvalid{}.New("no validator set in effect at height")
+	}
+	return vs, nil
+}
+
+// Latest returns the highest verified block header
+func (c *Client) Latest() *objs.BlockHeader {
+	c.RLock()
+	defer c.RUnlock()
+	return c.latest
+}
+
+// Header returns the verified block header at height. Only the most recent
+// headers are retained.
+func (c *Client) Header(height uint32) (*objs.BlockHeader, error) {
+	c.RLock()
+	defer c.RUnlock()
+	bh, ok := c.headers[height]
+	if !ok {
+		return nil, errorz.ErrInvalid{}.New("no verified block header at height")
+	}
+	return bh, nil
+}
+
+// AddHeader verifies bh against the latest verified header and stores it
+// if it is the next header of the chain.
+func (c *Client) AddHeader(bh *objs.BlockHeader) error {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.verify(bh); err != nil {
+		return err
+	}
+	c.store(bh)
+	return nil
+}
+
+// Update reads the new validator sets from the ValidatorSetSource if there
+// is one, then downloads the headers that follow the latest verified header
+// and verifies them in order. It returns the number of headers added. The
+// request for a batch of headers fails as a whole if the peer does not
+// have all of them, so a failed batch is retried for the next header
+// alone before giving up.
+func (c *Client) Update(ctx context.Context) (int, error) {
+	if err := c.updateValidatorSets(ctx); err != nil {
+		return 0, err
+	}
+	next := c.Latest().BClaims.Height + 1
+	blockNums := make([]uint32, headerBatchSize)
+	for i := range blockNums {
+		blockNums[i] = next + uint32(i)
+	}
+	hdrs, err := c.source.RequestP2PGetBlockHeaders(ctx, blockNums)
+	if err != nil {
+		utils.DebugTrace(c.logger, err)
+		hdrs, err = c.source.RequestP2PGetBlockHeaders(ctx, blockNums[:1])
+		if err != nil {
+			return 0, err
+		}
+	}
+	added := 0
+	for _, bh := range hdrs {
+		if err := c.AddHeader(bh); err != nil {
+			utils.DebugTrace(c.logger, err)
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+// Follow calls Update until ctx is cancelled. Once the client has caught
+// up with its peers it waits for interval between updates.
+func (c *Client) Follow(ctx context.Context, interval time.Duration) error {
+	for {
+		added, err := c.Update(ctx)
+		if err != nil {
+			utils.DebugTrace(c.logger, err)
+		}
+		if added > 0 {
+			c.logger.Debugf("Light client verified headers up to height %v", c.Latest().BClaims.Height)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// updateValidatorSets adds the validator sets of the ValidatorSetSource that
+// take effect above the latest verified height
+func (c *Client) updateValidatorSets(ctx context.Context) error {
+	c.RLock()
+	vsSrc := c.vsSrc
+	height := c.latest.BClaims.Height
+	c.RUnlock()
+	if vsSrc == nil {
+		return nil
+	}
+	vsets, err := vsSrc.ValidatorSets(ctx, height)
+	if err != nil {
+		return err
+	}
+	for _, vs := range vsets {
+		if err := c.AddValidatorSet(vs); err != nil {
+			etest := &errorz.ErrStale{}
+			if errors.As(err, &etest) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// verify checks that bh directly extends the latest verified header and
+// that it is signed by the validator set in effect at its height.
+func (c *Client) verify(bh *objs.BlockHeader) error {
+	if bh == nil || bh.BClaims == nil {
+		return errorz.ErrInvalid{}.New("block header not initialized")
+	}
+	if bh.BClaims.ChainID != c.chainID {
+		return errorz.ErrInvalid{}.New("wrong chainID")
+	}
+	if bh.BClaims.Height != c.latest.BClaims.Height+1 {
+		return errorz.ErrInvalid{}.New("block header does not follow the latest verified height")
+	}
+	prevHash, err := c.latest.BlockHash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(bh.BClaims.PrevBlock, prevHash) {
+		return errorz.ErrInvalid{}.New("prev block hash mismatch")
+	}
+	return c.checkSignature(bh)
+}
+
+// checkSignature validates the group signature on bh and checks that the
+// group key that produced it is the key of the validator set in effect at
+// the height of bh. The genesis header carries no group signature.
+func (c *Client) checkSignature(bh *objs.BlockHeader) error {
+	if err := bh.ValidateSignatures(c.bnVal); err != nil {
+		return err
+	}
+	if bh.BClaims.Height == 1 {
+		return nil
+	}
+	vs := c.validatorSet(bh.BClaims.Height)
+	if vs == nil {
+		return errorz.ErrInvalid{}.New("no validator set in effect at height")
+	}
+	if !bytes.Equal(bh.GroupKey, vs.GroupKey) {
+		return errorz.ErrInvalid{}.New("group key mismatch")
+	}
+	return nil
+}
+
+// validatorSet returns the set with the highest NotBefore that is at or
+// below height.
+func (c *Client) validatorSet(height uint32) *objs.ValidatorSet {
+	var out *objs.ValidatorSet
+	for _, vs := range c.vsets {
+		if vs.NotBefore > height {
+			break
+		}
+		out = vs
+	}
+	return out
+}
+
+// store records bh as the latest verified header and drops the header
+// that falls out of the retention window.
+func (c *Client) store(bh *objs.BlockHeader) {
+	height := bh.BClaims.Height
+	c.headers[height] = bh
+	c.latest = bh
+	if height > retainHeaders {
+		delete(c.headers, height-retainHeaders)
+	}
+}
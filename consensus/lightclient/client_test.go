@@ -0,0 +1,224 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	bn256 "github.com/MadBase/MadNet/crypto/bn256/cloudflare"
+	"github.com/MadBase/MadNet/errorz"
+)
+
+// testSource serves block headers from a fixed chain. Like the P2P handler
+// it fails the whole request if any requested height is missing.
+type testSource struct {
+	chain map[uint32]*objs.BlockHeader
+}
+
+func (ts *testSource) RequestP2PGetBlockHeaders(ctx context.Context, blockNums []uint32) ([]*objs.BlockHeader, error) {
+	out := []*objs.BlockHeader{}
+	for _, h := range blockNums {
+		bh, ok := ts.chain[h]
+		if !ok {
+			return nil, errorz.ErrBadResponse
+		}
+		out = append(out, bh)
+	}
+	return out, nil
+}
+
+// makeGroupSigner returns a signer holding the group secret itself, so its
+// signatures are valid group signatures under the returned group key.
+func makeGroupSigner(t *testing.T, secret int64) (*crypto.BNGroupSigner, []byte) {
+	msk := big.NewInt(secret)
+	groupKey := new(bn256.G2).ScalarBaseMult(msk).Marshal()
+	s := &crypto.BNGroupSigner{}
+	s.SetPrivk(msk.Bytes())
+	if err := s.SetGroupPubk(groupKey); err != nil {
+		t.Fatal(err)
+	}
+	return s, groupKey
+}
+
+// makeChain builds a chain of n headers. Headers from height switchAt on
+// are signed by second instead of first.
+func makeChain(t *testing.T, n uint32, first, second *crypto.BNGroupSigner, switchAt uint32) map[uint32]*objs.BlockHeader {
+	txRoot, err := objs.MakeTxRoot([][]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := make(map[uint32]*objs.BlockHeader)
+	prev := make([]byte, constants.HashLen)
+	for h := uint32(1); h <= n; h++ {
+		bh := &objs.BlockHeader{
+			BClaims: &objs.BClaims{
+				ChainID:    42,
+				Height:     h,
+				PrevBlock:  prev,
+				TxRoot:     txRoot,
+				StateRoot:  crypto.Hasher([]byte("state")),
+				HeaderRoot: crypto.Hasher([]byte("header")),
+			},
+			TxHshLst: [][]byte{},
+		}
+		bhsh, err := bh.BlockHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer := first
+		if h >= switchAt {
+			signer = second
+		}
+		if h > 1 {
+			sig, err := signer.Sign(bhsh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bh.SigGroup = sig
+		} else {
+			bh.SigGroup = make([]byte, constants.CurveBN256EthSigLen)
+		}
+		chain[h] = bh
+		prev = bhsh
+	}
+	return chain
+}
+
+func newClient(t *testing.T, source *testSource, groupKey []byte) *Client {
+	c := &Client{}
+	vs := &objs.ValidatorSet{GroupKey: groupKey, NotBefore: 1}
+	if err := c.Init(source, source.chain[1], vs); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// updateUntilError calls Update until it fails and returns the error
+func updateUntilError(t *testing.T, c *Client) error {
+	for i := 0; i < 100; i++ {
+		if _, err := c.Update(context.Background()); err != nil {
+			return err
+		}
+	}
+	t.Fatal("update did not stop")
+	return nil
+}
+
+func TestClientFollowsChain(t *testing.T) {
+	signer, groupKey := makeGroupSigner(t, 100)
+	source := &testSource{makeChain(t, 50, signer, signer, 51)}
+	c := newClient(t, source, groupKey)
+	// at the tip the request for the next header fails
+	updateUntilError(t, c)
+	if c.Latest().BClaims.Height != 50 {
+		t.Fatalf("expected height 50, got %d", c.Latest().BClaims.Height)
+	}
+	for h := uint32(1); h <= 50; h++ {
+		bh, err := c.Header(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bh.BClaims.Height != h {
+			t.Fatalf("wrong header at height %d", h)
+		}
+	}
+}
+
+func TestClientValidatorSetChange(t *testing.T) {
+	signer1, groupKey1 := makeGroupSigner(t, 100)
+	signer2, groupKey2 := makeGroupSigner(t, 200)
+	source := &testSource{makeChain(t, 10, signer1, signer2, 6)}
+	c := newClient(t, source, groupKey1)
+	err := updateUntilError(t, c)
+	etestInvalid := &errorz.ErrInvalid{}
+	if !errors.As(err, &etestInvalid) {
+		t.Fatalf("expected a group key mismatch, got %v", err)
+	}
+	if c.Latest().BClaims.Height != 5 {
+		t.Fatalf("expected to stop at height 5, got %d", c.Latest().BClaims.Height)
+	}
+	if err := c.AddValidatorSet(&objs.ValidatorSet{GroupKey: groupKey2, NotBefore: 6}); err != nil {
+		t.Fatal(err)
+	}
+	updateUntilError(t, c)
+	if c.Latest().BClaims.Height != 10 {
+		t.Fatalf("expected height 10, got %d", c.Latest().BClaims.Height)
+	}
+	vs, err := c.ValidatorSet(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs.NotBefore != 1 {
+		t.Fatal("wrong validator set at height 3")
+	}
+	err = c.AddValidatorSet(&objs.ValidatorSet{GroupKey: groupKey2, NotBefore: 4})
+	etest := &errorz.ErrStale{}
+	if !errors.As(err, &etest) {
+		t.Fatalf("expected a stale error, got %v", err)
+	}
+}
+
+// testVSSource serves validator sets that take effect above a height
+type testVSSource struct {
+	vsets []*objs.ValidatorSet
+}
+
+func (ts *testVSSource) ValidatorSets(ctx context.Context, height uint32) ([]*objs.ValidatorSet, error) {
+	out := []*objs.ValidatorSet{}
+	for _, vs := range ts.vsets {
+		if vs.NotBefore > height {
+			out = append(out, vs)
+		}
+	}
+	return out, nil
+}
+
+func TestClientFollowsValidatorSets(t *testing.T) {
+	signer1, groupKey1 := makeGroupSigner(t, 100)
+	signer2, groupKey2 := makeGroupSigner(t, 200)
+	source := &testSource{makeChain(t, 10, signer1, signer2, 6)}
+	c := newClient(t, source, groupKey1)
+	vsSrc := &testVSSource{}
+	c.SetValidatorSetSource(vsSrc)
+	updateUntilError(t, c)
+	if c.Latest().BClaims.Height != 5 {
+		t.Fatalf("expected to stop at height 5, got %d", c.Latest().BClaims.Height)
+	}
+	// once the source has the new set the client moves past the change
+	vsSrc.vsets = append(vsSrc.vsets, &objs.ValidatorSet{GroupKey: groupKey2, NotBefore: 6})
+	updateUntilError(t, c)
+	if c.Latest().BClaims.Height != 10 {
+		t.Fatalf("expected height 10, got %d", c.Latest().BClaims.Height)
+	}
+	vs, err := c.ValidatorSet(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs.NotBefore != 6 {
+		t.Fatal("wrong validator set at height 8")
+	}
+}
+
+func TestClientRejectsForgedHeader(t *testing.T) {
+	signer, groupKey := makeGroupSigner(t, 100)
+	forger, _ := makeGroupSigner(t, 300)
+	chain := makeChain(t, 3, signer, signer, 4)
+	forged := makeChain(t, 3, forger, forger, 4)
+	c := newClient(t, &testSource{chain}, groupKey)
+	etest := &errorz.ErrInvalid{}
+	err := c.AddHeader(forged[2])
+	if !errors.As(err, &etest) {
+		t.Fatalf("expected an invalid error, got %v", err)
+	}
+	err = c.AddHeader(chain[3])
+	if !errors.As(err, &etest) {
+		t.Fatalf("expected an invalid error, got %v", err)
+	}
+	if err := c.AddHeader(chain[2]); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/MadBase/MadNet/errorz"
 	"github.com/MadBase/MadNet/utils"
@@ -45,6 +46,9 @@ type Engine struct {
 	EthPubk []byte
 
 	dm *dman.DMan
+
+	// clock is the time source of the consensus step timeouts
+	clock func() time.Time
 }
 
 // Init will initialize the Consensus Engine and all sub modules
@@ -69,6 +73,7 @@ func (ce *Engine) Init(database *db.Database, dm *dman.DMan, app appmock.Applica
 		ce.ethAcct = crypto.GetAccount(ce.EthPubk)
 	}
 	ce.logger = logging.GetLogger(constants.LoggerConsensus)
+	ce.clock = time.Now
 	ce.fastSync = &SnapShotManager{
 		appHandler: app,
 		requestBus: ce.RequestBus,
@@ -108,6 +113,17 @@ func (ce *Engine) Status(status map[string]interface{}) (map[string]interface{},
 	return status, nil
 }
 
+// SetClock replaces the wall clock the consensus step timeouts are measured
+// with. It must be called after Init and before the engine runs.
+func (ce *Engine) SetClock(fn func() time.Time) {
+	ce.clock = fn
+}
+
+// SyncStatus returns the progress of fast sync
+func (ce *Engine) SyncStatus() *SyncStatus {
+	return ce.fastSync.Status()
+}
+
 func (ce *Engine) UpdateLocalState() (bool, error) {
 	var isSync bool
 	updateLocalState := true
@@ -148,7 +164,7 @@ func (ce *Engine) UpdateLocalState() (bool, error) {
 				VAddr:    ownState.VAddr,
 				GroupKey: ownState.GroupKey,
 			}
-			ovs.SetRoundStarted()
+			ovs.SetRoundStarted(ce.clock())
 			err := ce.database.SetOwnValidatingState(txn, ovs)
 			if err != nil {
 				return err
@@ -379,9 +395,10 @@ func (ce *Engine) updateLocalStateInternal(txn *badger.Txn, rs *RoundStates) (bo
 	PCCurrent := os.PCCurrent(rcert)
 	PCNCurrent := os.PCNCurrent(rcert)
 	NRCurrent := os.NRCurrent(rcert)
-	PTOExpired := rs.OwnValidatingState.PTOExpired()
-	PVTOExpired := rs.OwnValidatingState.PVTOExpired()
-	PCTOExpired := rs.OwnValidatingState.PCTOExpired()
+	now := ce.clock()
+	PTOExpired := rs.OwnValidatingState.PTOExpired(now)
+	PVTOExpired := rs.OwnValidatingState.PVTOExpired(now)
+	PCTOExpired := rs.OwnValidatingState.PCTOExpired(now)
 
 	// dispatch to handlers
 	if NRCurrent {
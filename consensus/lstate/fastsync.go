@@ -3,6 +3,7 @@ package lstate
 import (
 	"bytes"
 	"context"
+	"errors"
 	"sync"
 
 	"github.com/MadBase/MadNet/consensus/appmock"
@@ -11,6 +12,7 @@ import (
 	"github.com/MadBase/MadNet/consensus/request"
 	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/errorz"
+	"github.com/MadBase/MadNet/interfaces"
 	"github.com/MadBase/MadNet/logging"
 	"github.com/MadBase/MadNet/utils"
 	"github.com/dgraph-io/badger/v2"
@@ -38,12 +40,14 @@ type nodeResponse struct {
 	root  []byte
 	layer int
 	batch []byte
+	peer  interfaces.NodeAddr
 }
 
 type stateResponse struct {
 	key   []byte
 	value []byte
 	data  []byte
+	peer  interfaces.NodeAddr
 }
 
 type nodeCache struct {
@@ -202,6 +206,7 @@ type SnapShotManager struct {
 	currentHeight            uint32
 	fullCanonicalBlockHeader *objs.BlockHeader
 	logger                   *logrus.Logger
+	tracker                  *syncTracker
 }
 
 // Init initializes the SnapShotManager
@@ -234,9 +239,27 @@ func (ndm *SnapShotManager) Init(database *db.Database) error {
 	}
 	ndm.currentDLs = make(map[nodeKey]bool)
 	ndm.database = database
+	ndm.tracker = &syncTracker{peers: make(map[string]*PeerSyncStats)}
+	if ndm.requestBus != nil {
+		ndm.requestBus.AddObserver(ndm.tracker.observe)
+	}
 	return nil
 }
 
+// Status returns the progress of the current fast sync
+func (ndm *SnapShotManager) Status() *SyncStatus {
+	return ndm.tracker.get()
+}
+
+// reportInvalid lowers the reputation of the peer that served data which
+// failed validation while being stored
+func (ndm *SnapShotManager) reportInvalid(peer interfaces.NodeAddr, err error) {
+	etestInvalid := &errorz.ErrInvalid{}
+	if ndm.requestBus != nil && errors.As(err, &etestInvalid) {
+		ndm.requestBus.ReportInvalid(peer)
+	}
+}
+
 func (ndm *SnapShotManager) startFastSync(txn *badger.Txn, height uint32, stateRoot []byte, hdrRoot []byte, canonicalBlockHash []byte) error {
 	// stop all previous downloads
 	ndm.currentCtxCancel()
@@ -246,11 +269,6 @@ func (ndm *SnapShotManager) startFastSync(txn *badger.Txn, height uint32, stateR
 	ndm.currentCtx = subCtx
 	ndm.currentCtxCancel = cf
 	ndm.currentDLs = make(map[nodeKey]bool)
-	// cleanup the db of any previous data
-	if err := ndm.cleanupDatabase(txn); err != nil {
-		utils.DebugTrace(ndm.logger, err)
-		return err
-	}
 	// call dropBefore on the caches
 	ndm.hcache.dropBefore(height)
 	ndm.ncache.dropBefore(height)
@@ -258,14 +276,50 @@ func (ndm *SnapShotManager) startFastSync(txn *badger.Txn, height uint32, stateR
 	ndm.hscache.dropBefore(height)
 	// set the currentHeight
 	ndm.currentHeight = height
-	// insert the request for the root node into the database
-	if !bytes.Equal(stateRoot, make([]byte, constants.HashLen)) {
-		// Do NOT request all-zero byte slice stateRoot
-		if err := ndm.database.SetPendingNodeKey(txn, stateRoot, 0); err != nil {
+	// the pending keys of an earlier run are only kept if they belong to the
+	// same target or to a target with the same state root
+	prevHeight, prevStateRoot, prevHdrRoot, prevBlockHash, err := ndm.database.GetFastSyncTarget(txn)
+	if err != nil {
+		if err != badger.ErrKeyNotFound {
 			utils.DebugTrace(ndm.logger, err)
 			return err
 		}
+		prevStateRoot = nil
+	}
+	sameState := prevStateRoot != nil && bytes.Equal(prevStateRoot, stateRoot)
+	if sameState && prevHeight == height && bytes.Equal(prevHdrRoot, hdrRoot) && bytes.Equal(prevBlockHash, canonicalBlockHash) {
+		ndm.logger.Infof("FastSync: resuming sync to snapshot at height %v", height)
+		ndm.tracker.start(height, true)
+		return nil
+	}
+	if sameState {
+		// the state trie is unchanged so only the header trie is restarted,
+		// which is not reported as resumed since the header keys are dropped
+		ndm.logger.Infof("FastSync: resuming state sync for snapshot at height %v", height)
+		if err := ndm.cleanupHdrKeys(); err != nil {
+			utils.DebugTrace(ndm.logger, err)
+			return err
+		}
+	} else {
+		// cleanup the db of any previous data
+		if err := ndm.cleanupDatabase(txn); err != nil {
+			utils.DebugTrace(ndm.logger, err)
+			return err
+		}
+		// insert the request for the root node into the database
+		if !bytes.Equal(stateRoot, make([]byte, constants.HashLen)) {
+			// Do NOT request all-zero byte slice stateRoot
+			if err := ndm.database.SetPendingNodeKey(txn, stateRoot, 0); err != nil {
+				utils.DebugTrace(ndm.logger, err)
+				return err
+			}
+		}
+	}
+	if err := ndm.database.SetFastSyncTarget(txn, height, stateRoot, hdrRoot, canonicalBlockHash); err != nil {
+		utils.DebugTrace(ndm.logger, err)
+		return err
 	}
+	ndm.tracker.start(height, false)
 	if err := ndm.database.SetPendingHdrNodeKey(txn, hdrRoot, 0); err != nil {
 		utils.DebugTrace(ndm.logger, err)
 		return err
@@ -284,6 +338,20 @@ func (ndm *SnapShotManager) cleanupDatabase(txn *badger.Txn) error {
 		utils.DebugTrace(ndm.logger, err)
 		return err
 	}
+	if err := ndm.cleanupHdrKeys(); err != nil {
+		utils.DebugTrace(ndm.logger, err)
+		return err
+	}
+	if err := ndm.database.DropPendingNodeKeys(); err != nil {
+		utils.DebugTrace(ndm.logger, err)
+		return err
+	}
+	return nil
+}
+
+// cleanupHdrKeys drops the pending header keys and the block headers staged
+// from the leaves of the header trie they belong to
+func (ndm *SnapShotManager) cleanupHdrKeys() error {
 	if err := ndm.database.DropPendingHdrLeafKeys(); err != nil {
 		utils.DebugTrace(ndm.logger, err)
 		return err
@@ -292,7 +360,7 @@ func (ndm *SnapShotManager) cleanupDatabase(txn *badger.Txn) error {
 		utils.DebugTrace(ndm.logger, err)
 		return err
 	}
-	if err := ndm.database.DropPendingNodeKeys(); err != nil {
+	if err := ndm.database.DropStagedBlockHeaderKeys(); err != nil {
 		utils.DebugTrace(ndm.logger, err)
 		return err
 	}
@@ -322,6 +390,7 @@ func (ndm *SnapShotManager) Update(txn *badger.Txn, snapShotHeight uint32, syncT
 		return false, err
 	}
 	ndm.logger.Debugf("FastSync: %v %v %v %v %v", nhcount, hlcount, sbhcount, nkcount, nlcount)
+	ndm.tracker.setCounts(nhcount, nkcount, nlcount, hlcount, sbhcount)
 	if err := ndm.updateDls(txn, snapShotHeight, stateRoot, hdrRoot, nhcount, nkcount, nlcount); err != nil {
 		utils.DebugTrace(ndm.logger, err)
 		return false, err
@@ -370,6 +439,11 @@ func (ndm *SnapShotManager) finalizeSync(txn *badger.Txn, height uint32, stateRo
 		utils.DebugTrace(ndm.logger, err)
 		return err
 	}
+	if err := ndm.database.DeleteFastSyncTarget(txn); err != nil {
+		utils.DebugTrace(ndm.logger, err)
+		return err
+	}
+	ndm.tracker.finish()
 	return nil
 }
 
@@ -437,6 +511,7 @@ func (ndm *SnapShotManager) syncHdrNodes(txn *badger.Txn, snapShotHeight uint32,
 		if err != nil {
 			// should not return if err invalid
 			utils.DebugTrace(ndm.logger, err)
+			ndm.reportInvalid(resp.peer, err)
 			continue
 		}
 		for i := 0; i < len(lvs); i++ {
@@ -455,6 +530,7 @@ func (ndm *SnapShotManager) syncHdrNodes(txn *badger.Txn, snapShotHeight uint32,
 			utils.DebugTrace(ndm.logger, err)
 			return err
 		}
+		ndm.tracker.stored(1)
 		// store new pending keys to db
 		for _, kk := range pendingBatch {
 			ok, err := ndm.database.ContainsSnapShotHdrNode(txn, kk)
@@ -518,15 +594,17 @@ func (ndm *SnapShotManager) downloadWithRetryHdrNode(ctx context.Context, wg *sy
 			// continue doing retries
 		}
 		var resp []byte
+		var peer interfaces.NodeAddr
 		err := func() error {
 			subCtx, cf := context.WithTimeout(ctx, constants.MsgTimeout)
 			defer cf()
-			tmp, err := ndm.requestBus.RequestP2PGetSnapShotHdrNode(subCtx, root)
+			tmp, server, err := ndm.requestBus.RequestP2PGetSnapShotHdrNode(subCtx, root)
 			if err != nil {
 				utils.DebugTrace(ndm.logger, err)
 				return err
 			}
 			resp = tmp
+			peer = server
 			return nil
 		}()
 		if err != nil {
@@ -539,6 +617,7 @@ func (ndm *SnapShotManager) downloadWithRetryHdrNode(ctx context.Context, wg *sy
 			layer: layer,
 			root:  root,
 			batch: resp,
+			peer:  peer,
 		}
 		//    store to the cache
 		ndm.hcache.insert(height, nr)
@@ -563,6 +642,7 @@ func (ndm *SnapShotManager) syncStateNodes(txn *badger.Txn, height uint32, state
 		if err != nil {
 			// should not return if err invalid
 			utils.DebugTrace(ndm.logger, err)
+			ndm.reportInvalid(resp.peer, err)
 			continue
 		}
 		for i := 0; i < len(lvs); i++ {
@@ -579,6 +659,7 @@ func (ndm *SnapShotManager) syncStateNodes(txn *badger.Txn, height uint32, state
 			utils.DebugTrace(ndm.logger, err)
 			return err
 		}
+		ndm.tracker.stored(1)
 		// store new pending keys to db
 		for _, kk := range pendingBatch {
 			if err := ndm.database.SetPendingNodeKey(txn, kk, newLayer); err != nil {
@@ -634,15 +715,17 @@ func (ndm *SnapShotManager) downloadWithRetryStateNode(ctx context.Context, wg *
 			// continue doing retries
 		}
 		var resp []byte
+		var peer interfaces.NodeAddr
 		err := func() error {
 			subCtx, cf := context.WithTimeout(ctx, constants.MsgTimeout)
 			defer cf()
-			tmp, err := ndm.requestBus.RequestP2PGetSnapShotNode(subCtx, height, root)
+			tmp, server, err := ndm.requestBus.RequestP2PGetSnapShotNode(subCtx, height, root)
 			if err != nil {
 				utils.DebugTrace(ndm.logger, err)
 				return err
 			}
 			resp = tmp
+			peer = server
 			return nil
 		}()
 		if err != nil {
@@ -655,6 +738,7 @@ func (ndm *SnapShotManager) downloadWithRetryStateNode(ctx context.Context, wg *
 			layer: layer,
 			root:  root,
 			batch: resp,
+			peer:  peer,
 		}
 		//    store to the cache
 		ndm.ncache.insert(height, nr)
@@ -678,6 +762,7 @@ func (ndm *SnapShotManager) syncStateLeaves(txn *badger.Txn, height uint32, stat
 		if err != nil {
 			// should not return if err invalid
 			utils.DebugTrace(ndm.logger, err)
+			ndm.reportInvalid(resp.peer, err)
 			continue
 		}
 		// remove the keys from the pending set in the database
@@ -686,6 +771,7 @@ func (ndm *SnapShotManager) syncStateLeaves(txn *badger.Txn, height uint32, stat
 			utils.DebugTrace(ndm.logger, err)
 			return err
 		}
+		ndm.tracker.stored(1)
 	}
 	return nil
 }
@@ -734,15 +820,17 @@ func (ndm *SnapShotManager) downloadWithRetryStateLeaf(ctx context.Context, wg *
 			// continue doing retries
 		}
 		var resp []byte
+		var peer interfaces.NodeAddr
 		err := func() error {
 			subCtx, cf := context.WithTimeout(ctx, constants.MsgTimeout)
 			defer cf()
-			tmp, err := ndm.requestBus.RequestP2PGetSnapShotStateData(subCtx, key)
+			tmp, server, err := ndm.requestBus.RequestP2PGetSnapShotStateData(subCtx, key)
 			if err != nil {
 				utils.DebugTrace(ndm.logger, err)
 				return err
 			}
 			resp = tmp
+			peer = server
 			return nil
 		}()
 		if err != nil {
@@ -755,6 +843,7 @@ func (ndm *SnapShotManager) downloadWithRetryStateLeaf(ctx context.Context, wg *
 			key:   utils.CopySlice(key),
 			value: utils.CopySlice(value),
 			data:  utils.CopySlice(resp),
+			peer:  peer,
 		}
 		//    store to the cache
 		ndm.nscache.insert(height, sr)
@@ -788,6 +877,7 @@ func (ndm *SnapShotManager) syncHdrLeaves(txn *badger.Txn, height uint32, stateR
 			utils.DebugTrace(ndm.logger, err)
 			return err
 		}
+		ndm.tracker.stored(1)
 	}
 	return nil
 }
@@ -0,0 +1,463 @@
+package lstate
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/application"
+	"github.com/MadBase/MadNet/application/deposit"
+	aobjs "github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/consensus/request"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/interfaces"
+	_ "github.com/MadBase/MadNet/peering" // registers the snappy compressor
+	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/types"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"google.golang.org/grpc"
+)
+
+const fastSyncChainID = uint32(42)
+
+// fastSyncNode holds the databases and application of a node taking part in
+// a fast sync
+type fastSyncNode struct {
+	database *db.Database
+	app      *application.Application
+	dph      *deposit.Handler
+}
+
+func newFastSyncNode(tb testing.TB, closeChan chan struct{}) *fastSyncNode {
+	stateDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	txnDb, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	database := &db.Database{}
+	if err := database.Init(stateDb); err != nil {
+		tb.Fatal(err)
+	}
+	dph := &deposit.Handler{}
+	if err := dph.Init(); err != nil {
+		tb.Fatal(err)
+	}
+	app := &application.Application{}
+	if err := app.Init(database, txnDb, dph); err != nil {
+		tb.Fatal(err)
+	}
+	return &fastSyncNode{database: database, app: app, dph: dph}
+}
+
+// spendDeposits returns transactions moving num new deposits into value stores
+func (n *fastSyncNode) spendDeposits(tb testing.TB, num int) []interfaces.Transaction {
+	signer := &crypto.Secp256k1Signer{}
+	if err := signer.SetPrivk(crypto.Hasher([]byte("secret"))); err != nil {
+		tb.Fatal(err)
+	}
+	pubk, err := signer.Pubkey()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	account := crypto.GetAccount(pubk)
+	owner := &aobjs.ValueStoreOwner{SVA: aobjs.ValueStoreSVA, CurveSpec: constants.CurveSecp256k1, Account: account}
+
+	txs := []interfaces.Transaction{}
+	err = n.database.Update(func(txn *badger.Txn) error {
+		for i := 0; i < num; i++ {
+			depositID := utils.ForceSliceToLength([]byte(strconv.Itoa(i+1)), constants.HashLen)
+			if err := n.dph.Add(txn, fastSyncChainID, depositID, big.NewInt(1), &aobjs.Owner{CurveSpec: constants.CurveSecp256k1, Account: account}); err != nil {
+				return err
+			}
+			deps, _, _, err := n.dph.Get(txn, [][]byte{depositID})
+			if err != nil {
+				return err
+			}
+			dep, err := deps[0].ValueStore()
+			if err != nil {
+				return err
+			}
+			txIn, err := dep.MakeTxIn()
+			if err != nil {
+				return err
+			}
+			out := &aobjs.TXOut{}
+			err = out.NewValueStore(&aobjs.ValueStore{
+				VSPreImage: &aobjs.VSPreImage{TXOutIdx: 0, Value: uint256.One(), ChainID: fastSyncChainID, Owner: owner},
+				TxHash:     make([]byte, constants.HashLen),
+			})
+			if err != nil {
+				return err
+			}
+			tx := &aobjs.Tx{Vin: []*aobjs.TXIn{txIn}, Vout: []*aobjs.TXOut{out}}
+			if err := tx.SetTxHash(); err != nil {
+				return err
+			}
+			if err := dep.Sign(tx.Vin[0], signer); err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return txs
+}
+
+// commitChain commits numBlocks signed block headers, the second of which
+// holds txs, and returns the last one as the snapshot to sync to
+func (n *fastSyncNode) commitChain(tb testing.TB, numBlocks int, txs []interfaces.Transaction) *objs.BlockHeader {
+	signer := &crypto.BNGroupSigner{}
+	signer.SetPrivk(crypto.Hasher([]byte("secret")))
+	groupKey, err := signer.PubkeyShare()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := signer.SetGroupPubk(groupKey); err != nil {
+		tb.Fatal(err)
+	}
+
+	var bh *objs.BlockHeader
+	prevBlock := make([]byte, constants.HashLen)
+	for height := uint32(1); height <= uint32(numBlocks); height++ {
+		err := n.database.Update(func(txn *badger.Txn) error {
+			headerRoot, err := n.database.GetHeaderRootForProposal(txn)
+			if err == badger.ErrKeyNotFound {
+				headerRoot, err = make([]byte, constants.HashLen), nil
+			}
+			if err != nil {
+				return err
+			}
+			blockTxs := []interfaces.Transaction{}
+			if height == 2 {
+				blockTxs = txs
+			}
+			stateRoot, err := n.app.ApplyState(txn, fastSyncChainID, height, blockTxs)
+			if err != nil {
+				return err
+			}
+			txHashes := [][]byte{}
+			for _, tx := range blockTxs {
+				txHash, err := tx.TxHash()
+				if err != nil {
+					return err
+				}
+				txHashes = append(txHashes, txHash)
+			}
+			txRoot, err := objs.MakeTxRoot(txHashes)
+			if err != nil {
+				return err
+			}
+			bclaims := &objs.BClaims{
+				ChainID:    fastSyncChainID,
+				Height:     height,
+				TxCount:    uint32(len(txHashes)),
+				PrevBlock:  prevBlock,
+				TxRoot:     txRoot,
+				StateRoot:  stateRoot,
+				HeaderRoot: headerRoot,
+			}
+			bhsh, err := bclaims.BlockHash()
+			if err != nil {
+				return err
+			}
+			sig, err := signer.Sign(bhsh)
+			if err != nil {
+				return err
+			}
+			bh = &objs.BlockHeader{BClaims: bclaims, SigGroup: sig, TxHshLst: txHashes}
+			prevBlock = bhsh
+			return n.database.SetCommittedBlockHeader(txn, bh)
+		})
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return bh
+}
+
+// fastSyncServer serves the requests of fast sync from the request handler
+// of a node
+type fastSyncServer struct {
+	pb.UnimplementedP2PServer
+	handler *request.Handler
+}
+
+func (s *fastSyncServer) GetBlockHeaders(ctx context.Context, r *pb.GetBlockHeadersRequest) (*pb.GetBlockHeadersResponse, error) {
+	return s.handler.HandleP2PGetBlockHeaders(ctx, r)
+}
+
+func (s *fastSyncServer) GetSnapShotNode(ctx context.Context, r *pb.GetSnapShotNodeRequest) (*pb.GetSnapShotNodeResponse, error) {
+	return s.handler.HandleP2PGetSnapShotNode(ctx, r)
+}
+
+func (s *fastSyncServer) GetSnapShotHdrNode(ctx context.Context, r *pb.GetSnapShotHdrNodeRequest) (*pb.GetSnapShotHdrNodeResponse, error) {
+	return s.handler.HandleP2PGetSnapShotHdrNode(ctx, r)
+}
+
+func (s *fastSyncServer) GetSnapShotStateData(ctx context.Context, r *pb.GetSnapShotStateDataRequest) (*pb.GetSnapShotStateDataResponse, error) {
+	return s.handler.HandleP2PGetSnapShotStateData(ctx, r)
+}
+
+// fastSyncClient is the gRPC client of the one peer fast sync downloads from
+type fastSyncClient struct {
+	pb.P2PClient
+	addr      interfaces.NodeAddr
+	closeChan chan struct{}
+}
+
+func (c *fastSyncClient) Close() error {
+	return nil
+}
+
+func (c *fastSyncClient) NodeAddr() interfaces.NodeAddr {
+	return c.addr
+}
+
+func (c *fastSyncClient) CloseChan() <-chan struct{} {
+	return c.closeChan
+}
+
+// fastSyncPeers is a peer subscription that leases the same peer for every
+// request
+type fastSyncPeers struct {
+	client *fastSyncClient
+}
+
+func (ps *fastSyncPeers) CloseChan() <-chan struct{} {
+	return ps.client.closeChan
+}
+
+func (ps *fastSyncPeers) PeerLease(ctx context.Context) (interfaces.PeerLease, error) {
+	return ps, nil
+}
+
+func (ps *fastSyncPeers) CapablePeerLease(ctx context.Context, rpc string) (interfaces.PeerLease, error) {
+	return ps, nil
+}
+
+func (ps *fastSyncPeers) RequestLease(ctx context.Context, hsh []byte) (interfaces.PeerLease, error) {
+	return ps, nil
+}
+
+func (ps *fastSyncPeers) P2PClient() (interfaces.P2PClient, error) {
+	return ps.client, nil
+}
+
+func (ps *fastSyncPeers) Do(fn func(interfaces.PeerLease) error) {
+	_ = fn(ps)
+}
+
+func (ps *fastSyncPeers) PreventGossipTx(addr interfaces.NodeAddr, hsh []byte) {}
+
+func (ps *fastSyncPeers) PreventGossipConsensus(addr interfaces.NodeAddr, hsh []byte) {}
+
+func (ps *fastSyncPeers) GossipConsensus(hsh []byte, fn func(context.Context, interfaces.PeerLease) error) {
+}
+
+func (ps *fastSyncPeers) GossipTx(hsh []byte, fn func(context.Context, interfaces.PeerLease) error) {
+}
+
+func (ps *fastSyncPeers) ReportPeer(addr interfaces.NodeAddr, ev types.PeerEvent) {}
+
+// throttledConn delays writes to emulate a link of rate bytes per second and
+// counts the bytes written
+type throttledConn struct {
+	net.Conn
+	rate    int
+	written *uint64
+}
+
+func (tc *throttledConn) Write(b []byte) (int, error) {
+	time.Sleep(time.Duration(len(b)) * time.Second / time.Duration(tc.rate))
+	atomic.AddUint64(tc.written, uint64(len(b)))
+	return tc.Conn.Write(b)
+}
+
+type throttledListener struct {
+	net.Listener
+	rate    int
+	written *uint64
+}
+
+func (tl *throttledListener) Accept() (net.Conn, error) {
+	conn, err := tl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &throttledConn{Conn: conn, rate: tl.rate, written: tl.written}, nil
+}
+
+// serveFastSync serves src over a gRPC connection on a link of rate bytes per
+// second and returns a request client for it. The requests, and so the
+// responses, are compressed with snappy if compress is set. The bytes sent
+// both ways are counted in written.
+func serveFastSync(tb testing.TB, src *fastSyncNode, rate int, compress bool, written *uint64, closeChan chan struct{}) *request.Client {
+	handler := &request.Handler{}
+	if err := handler.Init(src.database, src.app); err != nil {
+		tb.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	pb.RegisterP2PServer(srv, &fastSyncServer{handler: handler})
+	go srv.Serve(&throttledListener{Listener: l, rate: rate, written: written})
+
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			return &throttledConn{Conn: conn, rate: rate, written: written}, nil
+		}),
+	}
+	if compress {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(constants.CompressionSnappy)))
+	}
+	conn, err := grpc.Dial(l.Addr().String(), opts...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	go func() {
+		<-closeChan
+		conn.Close()
+		srv.Stop()
+		handler.Exit()
+	}()
+
+	addr, err := transport.RandomNodeAddr()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	client := &request.Client{}
+	peers := &fastSyncPeers{client: &fastSyncClient{P2PClient: pb.NewP2PClient(conn), addr: addr, closeChan: closeChan}}
+	if err := client.Init(peers); err != nil {
+		tb.Fatal(err)
+	}
+	return client
+}
+
+// fastSync runs the snapshot manager of dst against client until it synced
+// to snapshot, updating it as often and with the same heights as the engine
+func fastSync(tb testing.TB, dst *fastSyncNode, client *request.Client, snapshot *objs.BlockHeader) {
+	ndm := &SnapShotManager{appHandler: dst.app, requestBus: client}
+	if err := ndm.Init(dst.database); err != nil {
+		tb.Fatal(err)
+	}
+	defer ndm.currentCtxCancel()
+	bhsh, err := snapshot.BlockHash()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for done := false; !done; {
+		err := dst.database.Update(func(txn *badger.Txn) error {
+			syncToHeight := uint32(0)
+			mrcbh, err := dst.database.GetMostRecentCommittedBlockHeaderFastSync(txn)
+			switch err {
+			case nil:
+				syncToHeight = mrcbh.BClaims.Height
+			case badger.ErrKeyNotFound:
+			default:
+				return err
+			}
+			done, err = ndm.Update(txn, snapshot.BClaims.Height, syncToHeight, snapshot.BClaims.StateRoot, snapshot.BClaims.HeaderRoot, bhsh)
+			return err
+		})
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if !done {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestFastSyncCompressed(t *testing.T) {
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+
+	src := newFastSyncNode(t, closeChan)
+	snapshot := src.commitChain(t, 40, src.spendDeposits(t, 40))
+	written := new(uint64)
+	client := serveFastSync(t, src, 10*1024*1024, true, written, closeChan)
+
+	dst := newFastSyncNode(t, closeChan)
+	fastSync(t, dst, client, snapshot)
+
+	// The synced node has the state and every block header of the snapshot
+	err := dst.database.Update(func(txn *badger.Txn) error {
+		root, err := dst.app.ApplyState(txn, fastSyncChainID, snapshot.BClaims.Height+1, nil)
+		if err != nil {
+			return err
+		}
+		if string(root) != string(snapshot.BClaims.StateRoot) {
+			t.Fatal("state root of the synced node differs from the snapshot")
+		}
+		for height := uint32(1); height <= snapshot.BClaims.Height; height++ {
+			if _, err := dst.database.GetCommittedBlockHeader(txn, height); err != nil {
+				t.Fatalf("block header %d missing: %v", height, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// benchmarkFastSync fast syncs a snapshot of numBlocks block headers and
+// numDeposits value stores from one node to another over a gRPC connection
+// on a 10MB/s link, as a node two epochs behind its peers does. The whole
+// sync is timed: downloads, validation and storage on the syncing node.
+func benchmarkFastSync(b *testing.B, compress bool) {
+	const numBlocks = 1024
+	const numDeposits = 2048
+	const rate = 10 * 1024 * 1024
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+
+	src := newFastSyncNode(b, closeChan)
+	snapshot := src.commitChain(b, numBlocks, src.spendDeposits(b, numDeposits))
+	written := new(uint64)
+	client := serveFastSync(b, src, rate, compress, written, closeChan)
+
+	b.ResetTimer()
+	atomic.StoreUint64(written, 0)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		dstClose := make(chan struct{})
+		dst := newFastSyncNode(b, dstClose)
+		b.StartTimer()
+		fastSync(b, dst, client, snapshot)
+		b.StopTimer()
+		close(dstClose)
+		b.StartTimer()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadUint64(written))/float64(b.N), "wireB/op")
+}
+
+func BenchmarkFastSync(b *testing.B) {
+	b.Run("uncompressed", func(b *testing.B) { benchmarkFastSync(b, false) })
+	b.Run("snappy", func(b *testing.B) { benchmarkFastSync(b, true) })
+}
@@ -0,0 +1,136 @@
+package lstate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/interfaces"
+)
+
+// PeerSyncStats holds the contribution of a single peer to fast sync.
+type PeerSyncStats struct {
+	Identity  string
+	Addr      string
+	Responses uint64
+	Failures  uint64
+	Bytes     uint64
+}
+
+// SyncStatus is a snapshot of the progress of fast sync.
+type SyncStatus struct {
+	// Active is true while a snapshot is being downloaded
+	Active bool
+	// Resumed is true if the download continued from all the pending keys
+	// persisted by an earlier run
+	Resumed            bool
+	SnapShotHeight     uint32
+	PendingHdrNodes    int
+	PendingHdrLeaves   int
+	PendingStateNodes  int
+	PendingStateLeaves int
+	StagedBlockHeaders int
+	// Stored is the number of nodes, leaves and headers stored since the
+	// download was started
+	Stored    uint64
+	StartedAt time.Time
+	// ItemsPerSecond is the average rate at which items were stored
+	ItemsPerSecond float64
+	// ETA is an estimate of the remaining time. It only accounts for the
+	// keys that are currently pending, and every stored node may add more.
+	ETA   time.Duration
+	Peers []*PeerSyncStats
+}
+
+// syncTracker records fast sync progress for status reporting.
+type syncTracker struct {
+	sync.RWMutex
+	status SyncStatus
+	peers  map[string]*PeerSyncStats
+}
+
+func (st *syncTracker) start(height uint32, resumed bool) {
+	st.Lock()
+	defer st.Unlock()
+	st.status = SyncStatus{
+		Active:         true,
+		Resumed:        resumed,
+		SnapShotHeight: height,
+		StartedAt:      time.Now(),
+	}
+	st.peers = make(map[string]*PeerSyncStats)
+}
+
+func (st *syncTracker) finish() {
+	st.Lock()
+	defer st.Unlock()
+	st.status.Active = false
+	st.status.ETA = 0
+}
+
+func (st *syncTracker) setCounts(nhcount, nkcount, nlcount, hlcount, sbhcount int) {
+	st.Lock()
+	defer st.Unlock()
+	st.status.PendingHdrNodes = nhcount
+	st.status.PendingStateNodes = nkcount
+	st.status.PendingStateLeaves = nlcount
+	st.status.PendingHdrLeaves = hlcount
+	st.status.StagedBlockHeaders = sbhcount
+}
+
+func (st *syncTracker) stored(n int) {
+	st.Lock()
+	defer st.Unlock()
+	st.status.Stored += uint64(n)
+}
+
+// observe is registered with the request client and attributes the
+// responses received during fast sync to the peer that sent them.
+func (st *syncTracker) observe(peer interfaces.NodeAddr, method string, size int, err error) {
+	switch method {
+	case "GetSnapShotNode", "GetSnapShotHdrNode", "GetSnapShotStateData", "GetBlockHeaders":
+	default:
+		return
+	}
+	st.Lock()
+	defer st.Unlock()
+	if !st.status.Active {
+		return
+	}
+	ps, ok := st.peers[peer.Identity()]
+	if !ok {
+		ps = &PeerSyncStats{Identity: peer.Identity(), Addr: peer.String()}
+		st.peers[peer.Identity()] = ps
+	}
+	if err != nil {
+		ps.Failures++
+		return
+	}
+	ps.Responses++
+	ps.Bytes += uint64(size)
+}
+
+func (st *syncTracker) get() *SyncStatus {
+	st.RLock()
+	defer st.RUnlock()
+	out := st.status
+	if out.Active {
+		elapsed := time.Since(out.StartedAt).Seconds()
+		if elapsed > 0 {
+			out.ItemsPerSecond = float64(out.Stored) / elapsed
+		}
+		pending := out.PendingHdrNodes + out.PendingHdrLeaves + out.PendingStateNodes + out.PendingStateLeaves + out.StagedBlockHeaders
+		if out.ItemsPerSecond > 0 {
+			out.ETA = time.Duration(float64(pending) / out.ItemsPerSecond * float64(time.Second))
+		}
+	}
+	out.Peers = make([]*PeerSyncStats, 0, len(st.peers))
+	for _, ps := range st.peers {
+		cp := *ps
+		out.Peers = append(out.Peers, &cp)
+	}
+	sort.Slice(out.Peers, func(i, j int) bool {
+		return out.Peers[i].Bytes > out.Peers[j].Bytes
+	})
+	return &out
+}
@@ -131,40 +131,37 @@ func (b *OwnValidatingState) MarshalCapn(seg *capnp.Segment) (mdefs.OwnValidatin
 	return bh, nil
 }
 
-func (b *OwnValidatingState) PTOExpired() bool {
+func (b *OwnValidatingState) PTOExpired(now time.Time) bool {
 	rs := b.RoundStarted
-	return rs+int64(constants.ProposalStepTO)/constants.OneBillion < time.Now().Unix()
+	return rs+int64(constants.ProposalStepTO)/constants.OneBillion < now.Unix()
 }
 
-func (b *OwnValidatingState) PVTOExpired() bool {
+func (b *OwnValidatingState) PVTOExpired(now time.Time) bool {
 	rs := b.PreVoteStepStarted
-	return rs+int64(constants.PreVoteStepTO)/constants.OneBillion < time.Now().Unix()
+	return rs+int64(constants.PreVoteStepTO)/constants.OneBillion < now.Unix()
 }
 
-func (b *OwnValidatingState) PCTOExpired() bool {
+func (b *OwnValidatingState) PCTOExpired(now time.Time) bool {
 	rs := b.PreCommitStepStarted
-	return rs+int64(constants.PreCommitStepTO)/constants.OneBillion < time.Now().Unix()
+	return rs+int64(constants.PreCommitStepTO)/constants.OneBillion < now.Unix()
 }
 
-func (b *OwnValidatingState) DBRNRExpired() bool {
+func (b *OwnValidatingState) DBRNRExpired(now time.Time) bool {
 	rs := b.PreCommitStepStarted
-	return rs+int64(constants.DBRNRTO)/constants.OneBillion < time.Now().Unix()
+	return rs+int64(constants.DBRNRTO)/constants.OneBillion < now.Unix()
 }
 
-func (b *OwnValidatingState) SetRoundStarted() {
-	now := time.Now()
+func (b *OwnValidatingState) SetRoundStarted(now time.Time) {
 	b.RoundStarted = now.Unix()
 	b.PreVoteStepStarted = 0
 	b.PreCommitStepStarted = 0
 }
 
-func (b *OwnValidatingState) SetPreVoteStepStarted() {
-	now := time.Now()
+func (b *OwnValidatingState) SetPreVoteStepStarted(now time.Time) {
 	b.PreVoteStepStarted = now.Unix()
 	b.PreCommitStepStarted = 0
 }
 
-func (b *OwnValidatingState) SetPreCommitStepStarted() {
-	now := time.Now()
+func (b *OwnValidatingState) SetPreCommitStepStarted(now time.Time) {
 	b.PreCommitStepStarted = now.Unix()
 }
@@ -2,6 +2,9 @@ package request
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/MadBase/MadNet/consensus/objs"
 	"github.com/MadBase/MadNet/constants"
@@ -10,17 +13,32 @@ import (
 	"github.com/MadBase/MadNet/interfaces"
 	"github.com/MadBase/MadNet/logging"
 	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/types"
 	"github.com/MadBase/MadNet/utils"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrNoPeer is returned by a request while no active peer can serve it. The
+// request may be retried once more peers connect.
+var ErrNoPeer = errors.New("no active peer can serve the request")
+
+// ResponseObserver is called with the outcome of every request answered
+// by a peer. size is the number of bytes returned and err is non nil if the
+// request failed or the response was rejected.
+type ResponseObserver func(peer interfaces.NodeAddr, method string, size int, err error)
+
 // Client serves incoming requests and handles routing of outgoing
 // requests for data from the consensus system.
 type Client struct {
-	peerSub  interfaces.PeerSubscription
-	logger   *logrus.Logger
-	secpVal  *crypto.Secp256k1Validator
-	groupVal *crypto.BNGroupValidator
+	sync.RWMutex
+	peerSub   interfaces.PeerSubscription
+	logger    *logrus.Logger
+	secpVal   *crypto.Secp256k1Validator
+	groupVal  *crypto.BNGroupValidator
+	observers []ResponseObserver
+	backoff   map[string]time.Time
 }
 
 // Init initializes the object
@@ -29,21 +47,128 @@ func (rb *Client) Init(peerSub interfaces.PeerSubscription) error {
 	rb.peerSub = peerSub
 	rb.groupVal = &crypto.BNGroupValidator{}
 	rb.secpVal = &crypto.Secp256k1Validator{}
+	rb.backoff = make(map[string]time.Time)
 	return nil
 }
 
-func (rb *Client) RequestP2PGetSnapShotNode(ctx context.Context, height uint32, key []byte) ([]byte, error) {
+// AddObserver registers fn to be called with the outcome of every request
+// sent to a peer.
+func (rb *Client) AddObserver(fn ResponseObserver) {
+	rb.Lock()
+	defer rb.Unlock()
+	rb.observers = append(rb.observers, fn)
+}
+
+func (rb *Client) observe(client interfaces.P2PClient, method string, size int, err error) {
+	rb.report(client.NodeAddr(), err)
+	rb.RLock()
+	defer rb.RUnlock()
+	for _, fn := range rb.observers {
+		fn(client.NodeAddr(), method, size, err)
+	}
+}
+
+// report updates the reputation of a peer from the outcome of a request.
+// Failures that may not be the fault of the peer are not reported. A peer
+// that rate limited the request is backed off instead.
+func (rb *Client) report(peer interfaces.NodeAddr, err error) {
+	switch {
+	case status.Code(err) == codes.ResourceExhausted:
+		rb.Lock()
+		rb.backoff[peer.Identity()] = time.Now().Add(constants.RateLimitBackoff)
+		rb.Unlock()
+	case err == nil:
+		rb.peerSub.ReportPeer(peer, types.PeerUseful)
+	case err == errorz.ErrBadResponse:
+		rb.peerSub.ReportPeer(peer, types.PeerInvalidResponse)
+	case errors.Is(err, context.DeadlineExceeded), status.Code(err) == codes.DeadlineExceeded:
+		rb.peerSub.ReportPeer(peer, types.PeerTimeout)
+	}
+}
+
+// ReportInvalid lowers the reputation of a peer that answered a request
+// with data that failed validation after the request returned.
+func (rb *Client) ReportInvalid(peer interfaces.NodeAddr) {
+	if peer == nil {
+		return
+	}
+	rb.peerSub.ReportPeer(peer, types.PeerInvalidResponse)
+}
+
+// backedOff returns true if the peer rate limited a recent request
+func (rb *Client) backedOff(peer interfaces.NodeAddr) bool {
+	rb.Lock()
+	defer rb.Unlock()
+	until, ok := rb.backoff[peer.Identity()]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(rb.backoff, peer.Identity())
+		return false
+	}
+	return true
+}
+
+// lease returns a peer lease from get that prefers peers which have not
+// rate limited a recent request
+func (rb *Client) lease(ctx context.Context, get func(context.Context) (interfaces.PeerLease, error)) (interfaces.PeerLease, error) {
+	var peerLease interfaces.PeerLease
+	for i := 0; i < constants.RateLimitLeaseAttempts; i++ {
+		pl, err := get(ctx)
+		if err != nil {
+			if peerLease != nil {
+				return peerLease, nil
+			}
+			return nil, err
+		}
+		peerLease = pl
+		client, err := pl.P2PClient()
+		if err != nil || !rb.backedOff(client.NodeAddr()) {
+			return pl, nil
+		}
+	}
+	return peerLease, nil
+}
+
+// leaseErr returns the error of a request whose peer lease failed with err
+func (rb *Client) leaseErr(ctx context.Context, err error) error {
+	if err == ctx.Err() {
+		return err
+	}
+	select {
+	case <-rb.peerSub.CloseChan():
+		return errorz.ErrClosing
+	default:
+		return ErrNoPeer
+	}
+}
+
+// capable returns a lease getter for peers that serve the RPC method rpc
+func (rb *Client) capable(rpc string) func(context.Context) (interfaces.PeerLease, error) {
+	return func(ctx context.Context) (interfaces.PeerLease, error) {
+		return rb.peerSub.CapablePeerLease(ctx, rpc)
+	}
+}
+
+func totalLen(b [][]byte) int {
+	n := 0
+	for i := range b {
+		n += len(b[i])
+	}
+	return n
+}
+
+func (rb *Client) RequestP2PGetSnapShotNode(ctx context.Context, height uint32, key []byte) ([]byte, interfaces.NodeAddr, error) {
 	req := &pb.GetSnapShotNodeRequest{
 		Height:   height,
 		NodeHash: key,
 	}
 	var node []byte
-	peerLease, err := rb.peerSub.PeerLease(ctx)
+	var server interfaces.NodeAddr
+	peerLease, err := rb.lease(ctx, rb.capable("GetSnapShotNode"))
 	if err != nil {
-		if err == ctx.Err() {
-			return nil, err
-		}
-		return nil, errorz.ErrClosing
+		return nil, nil, rb.leaseErr(ctx, err)
 	}
 	var reqErr error
 
@@ -55,33 +180,34 @@ func (rb *Client) RequestP2PGetSnapShotNode(ctx context.Context, height uint32,
 		resp, err := client.GetSnapShotNode(ctx, req)
 		if err != nil {
 			utils.DebugTrace(rb.logger, err)
+			rb.observe(client, "GetSnapShotNode", 0, err)
 			return err
 		}
 		node = resp.Node
+		rb.observe(client, "GetSnapShotNode", len(node), nil)
+		server = client.NodeAddr()
 		return nil
 	}
 	if reqErr != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	peerLease.Do(fn)
 	if node == nil {
-		return nil, errorz.ErrBadResponse
+		return nil, nil, errorz.ErrBadResponse
 	}
-	return node, nil
+	return node, server, nil
 }
 
-func (rb *Client) RequestP2PGetSnapShotHdrNode(ctx context.Context, key []byte) ([]byte, error) {
+func (rb *Client) RequestP2PGetSnapShotHdrNode(ctx context.Context, key []byte) ([]byte, interfaces.NodeAddr, error) {
 	req := &pb.GetSnapShotHdrNodeRequest{
 		NodeHash: key,
 	}
 	var node []byte
-	peerLease, err := rb.peerSub.PeerLease(ctx)
+	var server interfaces.NodeAddr
+	peerLease, err := rb.lease(ctx, rb.capable("GetSnapShotHdrNode"))
 	if err != nil {
 		utils.DebugTrace(rb.logger, err)
-		if err == ctx.Err() {
-			return nil, err
-		}
-		return nil, errorz.ErrClosing
+		return nil, nil, rb.leaseErr(ctx, err)
 	}
 	var reqErr error
 
@@ -94,20 +220,23 @@ func (rb *Client) RequestP2PGetSnapShotHdrNode(ctx context.Context, key []byte)
 		resp, err := client.GetSnapShotHdrNode(ctx, req)
 		if err != nil {
 			utils.DebugTrace(rb.logger, err)
+			rb.observe(client, "GetSnapShotHdrNode", 0, err)
 			return err
 		}
 		node = resp.Node
+		rb.observe(client, "GetSnapShotHdrNode", len(node), nil)
+		server = client.NodeAddr()
 		return nil
 	}
 	if reqErr != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	peerLease.Do(fn)
 	if node == nil {
 		utils.DebugTrace(rb.logger, err)
-		return nil, errorz.ErrBadResponse
+		return nil, nil, errorz.ErrBadResponse
 	}
-	return node, nil
+	return node, server, nil
 }
 
 func (rb *Client) RequestP2PGetBlockHeaders(ctx context.Context, blockNums []uint32) ([]*objs.BlockHeader, error) {
@@ -115,14 +244,10 @@ func (rb *Client) RequestP2PGetBlockHeaders(ctx context.Context, blockNums []uin
 		BlockNumbers: blockNums,
 	}
 	var hdrs []*objs.BlockHeader
-	hsh := utils.MarshalUint32(blockNums[0])
-	peerLease, err := rb.peerSub.RequestLease(ctx, hsh)
+	peerLease, err := rb.lease(ctx, rb.capable("GetBlockHeaders"))
 	if err != nil {
 		utils.DebugTrace(rb.logger, err)
-		if err == ctx.Err() {
-			return nil, err
-		}
-		return nil, errorz.ErrClosing
+		return nil, rb.leaseErr(ctx, err)
 	}
 	byteCount := 0
 	var reqErr error
@@ -136,32 +261,38 @@ func (rb *Client) RequestP2PGetBlockHeaders(ctx context.Context, blockNums []uin
 		resp, err := client.GetBlockHeaders(ctx, req)
 		if err != nil {
 			utils.DebugTrace(rb.logger, err)
+			rb.observe(client, "GetBlockHeaders", 0, err)
 			return err
 		}
 		tmpHdrs := []*objs.BlockHeader{}
 		if len(resp.BlockHeaders) > len(blockNums) {
 			reqErr = errorz.ErrBadResponse
+			rb.observe(client, "GetBlockHeaders", 0, reqErr)
 			return errorz.ErrInvalid{}.New("too many headers")
 		}
 		for _, hdrbytes := range resp.BlockHeaders {
 			byteCount = byteCount + len(utils.CopySlice(hdrbytes))
 			if byteCount > constants.MaxBytes {
 				reqErr = errorz.ErrBadResponse
+				rb.observe(client, "GetBlockHeaders", byteCount, reqErr)
 				return errorz.ErrInvalid{}.New("too big of hdr msg")
 			}
 			hdr := &objs.BlockHeader{}
 			err := hdr.UnmarshalBinary(utils.CopySlice(hdrbytes))
 			if err != nil {
 				reqErr = errorz.ErrBadResponse
+				rb.observe(client, "GetBlockHeaders", byteCount, reqErr)
 				return err
 			}
 			if err := hdr.ValidateSignatures(rb.groupVal); err != nil {
 				reqErr = errorz.ErrBadResponse
+				rb.observe(client, "GetBlockHeaders", byteCount, reqErr)
 				return errorz.ErrInvalid{}.New("bad signatures")
 			}
 			tmpHdrs = append(tmpHdrs, hdr)
 		}
 		hdrs = tmpHdrs
+		rb.observe(client, "GetBlockHeaders", byteCount, nil)
 		return nil
 	}
 	if reqErr != nil {
@@ -181,12 +312,9 @@ func (rb *Client) RequestP2PGetPendingTx(ctx context.Context, txHashes [][]byte)
 		TxHashes: txHashes,
 	}
 	var transactions [][]byte
-	peerLease, err := rb.peerSub.RequestLease(ctx, txHashes[0])
+	peerLease, err := rb.lease(ctx, rb.capable("GetPendingTxs"))
 	if err != nil {
-		if err == ctx.Err() {
-			return nil, err
-		}
-		return nil, errorz.ErrClosing
+		return nil, rb.leaseErr(ctx, err)
 	}
 	var reqErr error
 
@@ -197,9 +325,11 @@ func (rb *Client) RequestP2PGetPendingTx(ctx context.Context, txHashes [][]byte)
 		}
 		resp, err := client.GetPendingTxs(ctx, req)
 		if err != nil {
+			rb.observe(client, "GetPendingTxs", 0, err)
 			return err
 		}
 		transactions = resp.Txs
+		rb.observe(client, "GetPendingTxs", totalLen(transactions), nil)
 		return nil
 	}
 	if reqErr != nil {
@@ -217,12 +347,9 @@ func (rb *Client) RequestP2PGetMinedTxs(ctx context.Context, txHashes [][]byte)
 		TxHashes: txHashes,
 	}
 	var transactions [][]byte
-	peerLease, err := rb.peerSub.PeerLease(ctx)
+	peerLease, err := rb.lease(ctx, rb.capable("GetMinedTxs"))
 	if err != nil {
-		if err == ctx.Err() {
-			return nil, err
-		}
-		return nil, errorz.ErrClosing
+		return nil, rb.leaseErr(ctx, err)
 	}
 	var reqErr error
 
@@ -233,9 +360,11 @@ func (rb *Client) RequestP2PGetMinedTxs(ctx context.Context, txHashes [][]byte)
 		}
 		resp, err := client.GetMinedTxs(ctx, req)
 		if err != nil {
+			rb.observe(client, "GetMinedTxs", 0, err)
 			return err
 		}
 		transactions = resp.Txs
+		rb.observe(client, "GetMinedTxs", totalLen(transactions), nil)
 		return nil
 	}
 	if reqErr != nil {
@@ -248,17 +377,15 @@ func (rb *Client) RequestP2PGetMinedTxs(ctx context.Context, txHashes [][]byte)
 	return transactions, nil
 }
 
-func (rb *Client) RequestP2PGetSnapShotStateData(ctx context.Context, key []byte) ([]byte, error) {
+func (rb *Client) RequestP2PGetSnapShotStateData(ctx context.Context, key []byte) ([]byte, interfaces.NodeAddr, error) {
 	req := &pb.GetSnapShotStateDataRequest{
 		Key: key,
 	}
 	var leaf []byte
-	peerLease, err := rb.peerSub.PeerLease(ctx)
+	var server interfaces.NodeAddr
+	peerLease, err := rb.lease(ctx, rb.capable("GetSnapShotStateData"))
 	if err != nil {
-		if err == ctx.Err() {
-			return nil, err
-		}
-		return nil, errorz.ErrClosing
+		return nil, nil, rb.leaseErr(ctx, err)
 	}
 	var reqErr error
 
@@ -269,17 +396,20 @@ func (rb *Client) RequestP2PGetSnapShotStateData(ctx context.Context, key []byte
 		}
 		resp, err := client.GetSnapShotStateData(ctx, req)
 		if err != nil {
+			rb.observe(client, "GetSnapShotStateData", 0, err)
 			return err
 		}
 		leaf = resp.Data
+		rb.observe(client, "GetSnapShotStateData", len(leaf), nil)
+		server = client.NodeAddr()
 		return nil
 	}
 	if reqErr != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	peerLease.Do(fn)
 	if leaf == nil {
-		return nil, errorz.ErrBadResponse
+		return nil, nil, errorz.ErrBadResponse
 	}
-	return leaf, nil
+	return leaf, server, nil
 }
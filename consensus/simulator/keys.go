@@ -0,0 +1,94 @@
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/crypto"
+	bn256 "github.com/MadBase/MadNet/crypto/bn256/cloudflare"
+	"github.com/MadBase/MadNet/utils"
+)
+
+// validatorKeys holds the key material of a single simulated validator.
+type validatorKeys struct {
+	secpPrivk  []byte
+	secpPubk   []byte
+	secpSigner *crypto.Secp256k1Signer
+	bnPrivk    []byte
+	groupShare []byte
+	bnSigner   *crypto.BNGroupSigner
+}
+
+// makeKeys deterministically derives the keys of n validators from seed.
+// The group shares are built by running the dealerless EthDKG share
+// arithmetic locally, so the resulting group key verifies threshold
+// signatures exactly as it would after a real EthDKG round.
+func makeKeys(seed int64, n int) ([]byte, []*validatorKeys, error) {
+	threshold := crypto.CalcThreshold(n)
+	privCoefs := make([][]*big.Int, n)
+	msk := big.NewInt(0)
+	for i := 0; i < n; i++ {
+		coefs := make([]*big.Int, threshold+1)
+		for j := 0; j < len(coefs); j++ {
+			h := crypto.Hasher([]byte(fmt.Sprintf("sim-dkg-%d-%d-%d", seed, i, j)))
+			coefs[j] = new(big.Int).Mod(new(big.Int).SetBytes(h), bn256.Order)
+		}
+		privCoefs[i] = coefs
+		msk.Add(msk, coefs[0])
+	}
+	msk.Mod(msk, bn256.Order)
+	groupKey := new(bn256.G2).ScalarBaseMult(msk).Marshal()
+
+	keys := make([]*validatorKeys, n)
+	for j := 0; j < n; j++ {
+		shares := make([]*big.Int, n)
+		for i := 0; i < n; i++ {
+			shares[i] = bn256.PrivatePolyEval(privCoefs[i], j+1)
+		}
+		gsk := bn256.GenerateGroupSecretKeyPortion(shares)
+		bnSigner := &crypto.BNGroupSigner{}
+		bnSigner.SetPrivk(gsk.Bytes())
+		if err := bnSigner.SetGroupPubk(groupKey); err != nil {
+			return nil, nil, err
+		}
+		groupShare, err := bnSigner.PubkeyShare()
+		if err != nil {
+			return nil, nil, err
+		}
+		secpPrivk := crypto.Hasher([]byte(fmt.Sprintf("sim-secp-%d-%d", seed, j)))
+		secpSigner := &crypto.Secp256k1Signer{}
+		if err := secpSigner.SetPrivk(secpPrivk); err != nil {
+			return nil, nil, err
+		}
+		secpPubk, err := secpSigner.Pubkey()
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[j] = &validatorKeys{
+			secpPrivk:  secpPrivk,
+			secpPubk:   secpPubk,
+			secpSigner: secpSigner,
+			bnPrivk:    gsk.Bytes(),
+			groupShare: groupShare,
+			bnSigner:   bnSigner,
+		}
+	}
+	return groupKey, keys, nil
+}
+
+// makeValidatorSet builds the genesis validator set for keys.
+func makeValidatorSet(groupKey []byte, keys []*validatorKeys) *objs.ValidatorSet {
+	vlst := make([]*objs.Validator, len(keys))
+	for i, k := range keys {
+		vlst[i] = &objs.Validator{
+			VAddr:      crypto.GetAccount(k.secpPubk),
+			GroupShare: utils.CopySlice(k.groupShare),
+		}
+	}
+	return &objs.ValidatorSet{
+		Validators: vlst,
+		GroupKey:   utils.CopySlice(groupKey),
+		NotBefore:  1,
+	}
+}
@@ -0,0 +1,93 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/crypto"
+)
+
+type marshaller interface {
+	MarshalBinary() ([]byte, error)
+}
+
+// decode unmarshals the wire encoding of a consensus object
+func decode(kind msgKind, msg []byte) (interface{}, error) {
+	switch kind {
+	case kindProposal:
+		obj := &objs.Proposal{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindPreVote:
+		obj := &objs.PreVote{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindPreVoteNil:
+		obj := &objs.PreVoteNil{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindPreCommit:
+		obj := &objs.PreCommit{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindPreCommitNil:
+		obj := &objs.PreCommitNil{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindNextRound:
+		obj := &objs.NextRound{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindNextHeight:
+		obj := &objs.NextHeight{}
+		return obj, obj.UnmarshalBinary(msg)
+	case kindBlockHeader:
+		obj := &objs.BlockHeader{}
+		return obj, obj.UnmarshalBinary(msg)
+	default:
+		return nil, fmt.Errorf("unknown message kind %d", kind)
+	}
+}
+
+// equivocate builds a message that conflicts with msg and is signed by the
+// same validator. A byzantine node sends msg to half of its peers and the
+// conflicting message to the other half:
+//
+//	a proposal is paired with a proposal for a different state root
+//	a prevote is paired with a prevote nil in the same round
+//	a precommit is paired with a precommit nil in the same round
+//
+// Other messages have no conflicting counterpart and nil is returned.
+func equivocate(kind msgKind, msg []byte, signer *crypto.Secp256k1Signer) (msgKind, []byte, error) {
+	switch kind {
+	case kindProposal:
+		p := &objs.Proposal{}
+		if err := p.UnmarshalBinary(msg); err != nil {
+			return 0, nil, err
+		}
+		p.PClaims.BClaims.StateRoot = crypto.Hasher(p.PClaims.BClaims.StateRoot, []byte("equivocate"))
+		if err := p.Sign(signer); err != nil {
+			return 0, nil, err
+		}
+		out, err := p.MarshalBinary()
+		return kindProposal, out, err
+	case kindPreVote:
+		pv := &objs.PreVote{}
+		if err := pv.UnmarshalBinary(msg); err != nil {
+			return 0, nil, err
+		}
+		pvn, err := pv.Proposal.PClaims.RCert.PreVoteNil(signer)
+		if err != nil {
+			return 0, nil, err
+		}
+		out, err := pvn.MarshalBinary()
+		return kindPreVoteNil, out, err
+	case kindPreCommit:
+		pc := &objs.PreCommit{}
+		if err := pc.UnmarshalBinary(msg); err != nil {
+			return 0, nil, err
+		}
+		pcn, err := pc.Proposal.PClaims.RCert.PreCommitNil(signer)
+		if err != nil {
+			return 0, nil, err
+		}
+		out, err := pcn.MarshalBinary()
+		return kindPreCommitNil, out, err
+	default:
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,213 @@
+package simulator
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// msgKind identifies the consensus object carried by an envelope.
+type msgKind int
+
+const (
+	kindProposal msgKind = iota
+	kindPreVote
+	kindPreVoteNil
+	kindPreCommit
+	kindPreCommitNil
+	kindNextRound
+	kindNextHeight
+	kindBlockHeader
+)
+
+func (k msgKind) String() string {
+	switch k {
+	case kindProposal:
+		return "Proposal"
+	case kindPreVote:
+		return "PreVote"
+	case kindPreVoteNil:
+		return "PreVoteNil"
+	case kindPreCommit:
+		return "PreCommit"
+	case kindPreCommitNil:
+		return "PreCommitNil"
+	case kindNextRound:
+		return "NextRound"
+	case kindNextHeight:
+		return "NextHeight"
+	case kindBlockHeader:
+		return "BlockHeader"
+	default:
+		return "Unknown"
+	}
+}
+
+// envelope is a single gossip message in flight between two nodes.
+// Messages travel in their wire encoding so every delivery goes through
+// the same unmarshal and validation path as a real peer.
+type envelope struct {
+	seq       uint64
+	deliverAt uint64
+	from      int
+	to        int
+	kind      msgKind
+	msg       []byte
+}
+
+// network models the links between simulated nodes. All randomness comes
+// from a seeded source, so a run is reproducible for a given seed.
+//
+// The link state is also read by the request path of the in-memory peer
+// subscriptions, which runs on the download goroutines of each node, so
+// it is guarded by a lock.
+type network struct {
+	sync.RWMutex
+	rnd       *rand.Rand
+	seq       uint64
+	queue     []*envelope
+	dropRate  float64
+	minDelay  uint64
+	maxDelay  uint64
+	cut       map[[2]int]bool
+	partition []int
+	down      map[int]bool
+}
+
+func newNetwork(seed int64) *network {
+	return &network{
+		rnd:  rand.New(rand.NewSource(seed)),
+		cut:  make(map[[2]int]bool),
+		down: make(map[int]bool),
+	}
+}
+
+// connected reports if a message may currently travel from a to b.
+func (n *network) connected(a, b int) bool {
+	n.RLock()
+	defer n.RUnlock()
+	return n.connectedLocked(a, b)
+}
+
+func (n *network) connectedLocked(a, b int) bool {
+	if n.down[a] || n.down[b] {
+		return false
+	}
+	if n.cut[[2]int{a, b}] {
+		return false
+	}
+	if n.partition != nil && n.partition[a] != n.partition[b] {
+		return false
+	}
+	return true
+}
+
+func (n *network) setDown(idx int, down bool) {
+	n.Lock()
+	defer n.Unlock()
+	n.down[idx] = down
+}
+
+func (n *network) setPartition(groups [][]int, size int) {
+	n.Lock()
+	defer n.Unlock()
+	p := make([]int, size)
+	for i := range p {
+		// nodes not listed in any group are isolated
+		p[i] = -1 - i
+	}
+	for g, members := range groups {
+		for _, idx := range members {
+			p[idx] = g
+		}
+	}
+	n.partition = p
+}
+
+func (n *network) heal() {
+	n.Lock()
+	defer n.Unlock()
+	n.partition = nil
+	n.cut = make(map[[2]int]bool)
+}
+
+func (n *network) setLink(from, to int, up bool) {
+	n.Lock()
+	defer n.Unlock()
+	if up {
+		delete(n.cut, [2]int{from, to})
+		return
+	}
+	n.cut[[2]int{from, to}] = true
+}
+
+func (n *network) setDropRate(rate float64) {
+	n.Lock()
+	defer n.Unlock()
+	n.dropRate = rate
+}
+
+func (n *network) setDelay(min, max uint64) {
+	n.Lock()
+	defer n.Unlock()
+	if max < min {
+		max = min
+	}
+	n.minDelay = min
+	n.maxDelay = max
+}
+
+// send queues a message for delivery. Messages across a broken link or
+// selected by the drop rate are discarded at send time. It returns false
+// if the message was discarded.
+func (n *network) send(now uint64, from, to int, kind msgKind, msg []byte) bool {
+	n.Lock()
+	defer n.Unlock()
+	if !n.connectedLocked(from, to) {
+		return false
+	}
+	if n.dropRate > 0 && n.rnd.Float64() < n.dropRate {
+		return false
+	}
+	delay := n.minDelay
+	if n.maxDelay > n.minDelay {
+		delay += uint64(n.rnd.Int63n(int64(n.maxDelay - n.minDelay + 1)))
+	}
+	n.seq++
+	n.queue = append(n.queue, &envelope{
+		seq:       n.seq,
+		deliverAt: now + delay,
+		from:      from,
+		to:        to,
+		kind:      kind,
+		msg:       msg,
+	})
+	return true
+}
+
+// due removes and returns every queued message that should be delivered at
+// or before now, ordered by delivery time and then by send order. A message
+// whose link broke while it was in flight is discarded.
+func (n *network) due(now uint64) []*envelope {
+	n.Lock()
+	defer n.Unlock()
+	out := []*envelope{}
+	keep := n.queue[:0]
+	for _, e := range n.queue {
+		if e.deliverAt > now {
+			keep = append(keep, e)
+			continue
+		}
+		if n.connectedLocked(e.from, e.to) {
+			out = append(out, e)
+		}
+	}
+	n.queue = keep
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].deliverAt != out[j].deliverAt {
+			return out[i].deliverAt < out[j].deliverAt
+		}
+		return out[i].seq < out[j].seq
+	})
+	return out
+}
@@ -0,0 +1,401 @@
+package simulator
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/consensus/admin"
+	"github.com/MadBase/MadNet/consensus/appmock"
+	"github.com/MadBase/MadNet/consensus/db"
+	"github.com/MadBase/MadNet/consensus/dman"
+	"github.com/MadBase/MadNet/consensus/lstate"
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/consensus/request"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/errorz"
+	"github.com/dgraph-io/badger/v2"
+)
+
+// node is a single simulated validator. The badger database outlives the
+// services built on top of it, so a crash followed by a restart behaves
+// like a process restart against the same disk.
+type node struct {
+	idx       int
+	keys      *validatorKeys
+	rawDB     *badger.DB
+	byzantine bool
+
+	running   bool
+	closeChan chan struct{}
+	mutex     *sync.Mutex
+	database  *db.Database
+	sstore    *lstate.Store
+	ah        *admin.Handlers
+	dm        *dman.DMan
+	engine    *lstate.Engine
+	handlers  *lstate.Handlers
+	reqServer *request.Handler
+	synced    bool
+
+	lastSent map[msgKind]string
+}
+
+// start builds the consensus services of the node on top of its database.
+// The genesis validator set and the group key share are only written the
+// first time the node is started. The consensus step timeouts are measured
+// with clock.
+func (n *node) start(chainID uint32, secret []byte, vs *objs.ValidatorSet, net *network, size int, handlers handlerSource, clock func() time.Time) error {
+	n.closeChan = make(chan struct{})
+	n.mutex = &sync.Mutex{}
+	n.lastSent = make(map[msgKind]string)
+	n.synced = false
+
+	n.database = &db.Database{}
+	if err := n.database.Init(n.rawDB); err != nil {
+		return err
+	}
+	n.sstore = &lstate.Store{}
+	if err := n.sstore.Init(n.database); err != nil {
+		return err
+	}
+	app := appmock.New()
+
+	n.ah = &admin.Handlers{}
+	if err := n.ah.Init(chainID, n.database, secret, app, n.keys.secpPubk); err != nil {
+		return err
+	}
+	n.ah.SetClock(clock)
+	go n.serveLock(n.ah, n.closeChan)
+
+	peerSub := &peerSubscription{
+		self:      n.idx,
+		size:      size,
+		chainID:   chainID,
+		net:       net,
+		handlers:  handlers,
+		closeChan: n.closeChan,
+	}
+	reqClient := &request.Client{}
+	if err := reqClient.Init(peerSub); err != nil {
+		return err
+	}
+	n.reqServer = &request.Handler{}
+	if err := n.reqServer.Init(n.database, app); err != nil {
+		return err
+	}
+	n.dm = &dman.DMan{}
+	if err := n.dm.Init(n.database, app, reqClient); err != nil {
+		return err
+	}
+	n.dm.Start()
+	n.engine = &lstate.Engine{}
+	if err := n.engine.Init(n.database, n.dm, app, n.keys.secpSigner, n.ah, n.keys.secpPubk, reqClient); err != nil {
+		return err
+	}
+	n.engine.SetClock(clock)
+	n.handlers = &lstate.Handlers{}
+	if err := n.handlers.Init(n.database, n.dm); err != nil {
+		return err
+	}
+
+	hasGenesis := false
+	err := n.database.View(func(txn *badger.Txn) error {
+		_, err := n.database.GetLastSnapshot(txn)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		hasGenesis = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !hasGenesis {
+		if err := n.ah.AddPrivateKey(n.keys.bnPrivk, constants.CurveBN256Eth); err != nil {
+			return err
+		}
+		if err := n.ah.AddValidatorSet(vs); err != nil {
+			return err
+		}
+	}
+	n.running = true
+	return nil
+}
+
+// stop shuts the services of the node down. The download actors of the
+// node have no shutdown path, so they are abandoned; their peer
+// subscription stops serving them once closeChan is closed.
+func (n *node) stop() {
+	if !n.running {
+		return
+	}
+	n.running = false
+	close(n.closeChan)
+	n.ah.Close()
+	n.reqServer.Exit()
+}
+
+// serveLock hands the node mutex to the admin handlers on request in the
+// same way the synchronizer does for a running validator.
+func (n *node) serveLock(ah *admin.Handlers, closeChan <-chan struct{}) {
+	for {
+		select {
+		case <-closeChan:
+			return
+		case <-ah.RequestLock:
+			select {
+			case ah.ReceiveLock <- n.mutex:
+			case <-closeChan:
+				return
+			}
+		}
+	}
+}
+
+// step runs a single iteration of the state loop of the node. As in the
+// synchronizer, a node that is not in sync runs the sync logic instead of
+// the consensus logic until it catches up.
+func (n *node) step() error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.synced {
+		ok, err := n.engine.UpdateLocalState()
+		if err != nil {
+			return err
+		}
+		n.synced = ok
+		return nil
+	}
+	ok, err := n.engine.Sync()
+	if err != nil {
+		return err
+	}
+	n.synced = ok
+	return nil
+}
+
+// inSync mirrors the drop logic of the gossip handlers; consensus messages
+// are only accepted when the node is at most one block behind the highest
+// block header it has seen.
+func (n *node) inSync() (bool, error) {
+	var inSync bool
+	err := n.database.View(func(txn *badger.Txn) error {
+		os, err := n.database.GetOwnState(txn)
+		if err != nil {
+			return err
+		}
+		inSync = os.MaxBHSeen.BClaims.Height-os.SyncToBH.BClaims.Height <= 1
+		return nil
+	})
+	return inSync, err
+}
+
+// deliver runs a message received from a peer through the same validation
+// and storage path that the gossip handlers use. It reports if the message
+// was accepted. As in the gossip handlers, a message that fails validation
+// or is rejected as stale or invalid is dropped; any other storage error
+// is returned.
+func (n *node) deliver(kind msgKind, msg []byte) (bool, error) {
+	obj, err := decode(kind, msg)
+	if err != nil {
+		return false, nil
+	}
+	if kind != kindBlockHeader {
+		ok, err := n.inSync()
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	if err := n.handlers.PreValidate(obj); err != nil {
+		return false, nil
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	switch v := obj.(type) {
+	case *objs.Proposal:
+		err = n.handlers.AddProposal(v)
+	case *objs.PreVote:
+		err = n.handlers.AddPreVote(v)
+	case *objs.PreVoteNil:
+		err = n.handlers.AddPreVoteNil(v)
+	case *objs.PreCommit:
+		err = n.handlers.AddPreCommit(v)
+	case *objs.PreCommitNil:
+		err = n.handlers.AddPreCommitNil(v)
+	case *objs.NextRound:
+		err = n.handlers.AddNextRound(v)
+	case *objs.NextHeight:
+		err = n.handlers.AddNextHeight(v)
+	case *objs.BlockHeader:
+		err = n.handlers.AddBlockHeader(v)
+	}
+	if err != nil {
+		return false, filterErr(err)
+	}
+	return true, nil
+}
+
+// filterErr drops the errors the gossip handlers treat as a rejection of
+// the message rather than a failure of the node.
+func filterErr(err error) error {
+	etestStale := &errorz.ErrStale{}
+	if errors.As(err, &etestStale) {
+		return nil
+	}
+	etestInvalid := &errorz.ErrInvalid{}
+	if errors.As(err, &etestInvalid) {
+		return nil
+	}
+	return err
+}
+
+// outbound returns the wire encoding of every message the node currently
+// wants to gossip, in a fixed order.
+func (n *node) outbound() ([]msgKind, [][]byte, error) {
+	p, pv, pvn, pc, pcn, nr, nh, err := n.sstore.GetGossipValues()
+	if err != nil {
+		return nil, nil, err
+	}
+	var bh *objs.BlockHeader
+	err = n.database.View(func(txn *badger.Txn) error {
+		v, err := n.database.GetBroadcastBlockHeader(txn)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		bh = v
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	kinds := []msgKind{}
+	msgs := [][]byte{}
+	add := func(kind msgKind, v marshaller, isNil bool) error {
+		if isNil {
+			return nil
+		}
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		kinds = append(kinds, kind)
+		msgs = append(msgs, b)
+		return nil
+	}
+	if err := add(kindProposal, p, p == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindPreVote, pv, pv == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindPreVoteNil, pvn, pvn == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindPreCommit, pc, pc == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindPreCommitNil, pcn, pcn == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindNextRound, nr, nr == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindNextHeight, nh, nh == nil); err != nil {
+		return nil, nil, err
+	}
+	if err := add(kindBlockHeader, bh, bh == nil); err != nil {
+		return nil, nil, err
+	}
+	return kinds, msgs, nil
+}
+
+// syncTarget returns the height of the next block header the node has to
+// download. It returns false if the node is not behind.
+func (n *node) syncTarget() (uint32, bool, error) {
+	var target uint32
+	var behind bool
+	err := n.database.View(func(txn *badger.Txn) error {
+		os, err := n.database.GetOwnState(txn)
+		if err != nil {
+			return err
+		}
+		target = os.SyncToBH.BClaims.Height + 1
+		behind = os.MaxBHSeen.BClaims.Height >= target
+		return nil
+	})
+	return target, behind, err
+}
+
+// hasHeader reports if the node has committed a block header at height
+func (n *node) hasHeader(height uint32) (bool, error) {
+	found := false
+	err := n.database.View(func(txn *badger.Txn) error {
+		_, err := n.database.GetCommittedBlockHeader(txn, height)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		found = true
+		return nil
+	})
+	return found, err
+}
+
+// height returns the height the node has synchronized to
+func (n *node) height() (uint32, error) {
+	var height uint32
+	err := n.database.View(func(txn *badger.Txn) error {
+		os, err := n.database.GetOwnState(txn)
+		if err != nil {
+			return err
+		}
+		height = os.SyncToBH.BClaims.Height
+		return nil
+	})
+	return height, err
+}
+
+// committed returns the hash of every block header the node has committed
+// from height from onwards, indexed by height, along with the height the
+// node has synchronized to. Heights without a committed header, such as
+// those skipped by a fast sync, are left out.
+func (n *node) committed(from uint32) (map[uint32][]byte, uint32, error) {
+	out := make(map[uint32][]byte)
+	height, err := n.height()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = n.database.View(func(txn *badger.Txn) error {
+		for h := from; h <= height; h++ {
+			bh, err := n.database.GetCommittedBlockHeader(txn, h)
+			if err != nil {
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				return err
+			}
+			hsh, err := bh.BlockHash()
+			if err != nil {
+				return err
+			}
+			out[h] = hsh
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, height, nil
+}
@@ -0,0 +1,360 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/consensus/request"
+	"github.com/MadBase/MadNet/errorz"
+	"github.com/MadBase/MadNet/interfaces"
+	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/types"
+	"google.golang.org/grpc"
+)
+
+var (
+	errUnreachable  = errors.New("simulator: peer unreachable")
+	errNotSupported = errors.New("simulator: method not supported")
+)
+
+var _ interfaces.PeerSubscription = (*peerSubscription)(nil)
+var _ interfaces.PeerLease = (*peerLease)(nil)
+var _ interfaces.P2PClient = (*p2pClient)(nil)
+var _ interfaces.NodeAddr = (*nodeAddr)(nil)
+
+// handlerSource resolves the request handler of a live node. It returns
+// nil if the node is not running.
+type handlerSource func(idx int) *request.Handler
+
+// peerSubscription is an in-memory replacement for the peering
+// subscription of a single node. Requests are served directly by the
+// request handlers of the other simulated nodes, subject to the state of
+// the simulated network. Consensus gossip does not flow through here; the
+// simulator delivers it itself so that ordering stays deterministic.
+type peerSubscription struct {
+	sync.Mutex
+	self      int
+	size      int
+	chainID   uint32
+	net       *network
+	handlers  handlerSource
+	closeChan <-chan struct{}
+	next      int
+}
+
+// peers returns the nodes currently reachable from this node, starting at
+// a rotating offset so repeated requests are spread across peers.
+func (ps *peerSubscription) peers() []int {
+	ps.Lock()
+	start := ps.next
+	ps.next = (ps.next + 1) % ps.size
+	ps.Unlock()
+	out := []int{}
+	for i := 0; i < ps.size; i++ {
+		idx := (start + i) % ps.size
+		if idx == ps.self {
+			continue
+		}
+		if !ps.net.connected(ps.self, idx) || !ps.net.connected(idx, ps.self) {
+			continue
+		}
+		if ps.handlers(idx) == nil {
+			continue
+		}
+		out = append(out, idx)
+	}
+	return out
+}
+
+// CloseChan returns a channel that is closed when the node is stopped
+func (ps *peerSubscription) CloseChan() <-chan struct{} {
+	return ps.closeChan
+}
+
+// PeerLease blocks until a peer is reachable and returns a lease on it.
+// The download actors of a stopped node keep retrying forever, so once the
+// node is stopped a lease waits out the request timeout instead of failing
+// fast; this keeps the abandoned retries from spinning.
+func (ps *peerSubscription) PeerLease(ctx context.Context) (interfaces.PeerLease, error) {
+	for {
+		select {
+		case <-ps.closeChan:
+			<-ctx.Done()
+			return nil, ctx.Err()
+		default:
+		}
+		if peers := ps.peers(); len(peers) > 0 {
+			return ps.lease(peers[0]), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// CapablePeerLease returns a lease on any reachable peer. Every simulated
+// node runs the same code, so all peers serve every RPC.
+func (ps *peerSubscription) CapablePeerLease(ctx context.Context, rpc string) (interfaces.PeerLease, error) {
+	return ps.PeerLease(ctx)
+}
+
+// RequestLease returns a lease on any reachable peer. The simulator does
+// not track which peers have seen which objects, so hsh is ignored.
+func (ps *peerSubscription) RequestLease(ctx context.Context, hsh []byte) (interfaces.PeerLease, error) {
+	return ps.PeerLease(ctx)
+}
+
+// PreventGossipTx is a no-op in the simulator
+func (ps *peerSubscription) PreventGossipTx(addr interfaces.NodeAddr, hsh []byte) {}
+
+// PreventGossipConsensus is a no-op in the simulator
+func (ps *peerSubscription) PreventGossipConsensus(addr interfaces.NodeAddr, hsh []byte) {}
+
+// ReportPeer is a no-op in the simulator; peers are never banned
+func (ps *peerSubscription) ReportPeer(addr interfaces.NodeAddr, ev types.PeerEvent) {}
+
+// GossipConsensus invokes fn once for every reachable peer
+func (ps *peerSubscription) GossipConsensus(hsh []byte, fn func(context.Context, interfaces.PeerLease) error) {
+	ps.gossip(fn)
+}
+
+// GossipTx invokes fn once for every reachable peer
+func (ps *peerSubscription) GossipTx(hsh []byte, fn func(context.Context, interfaces.PeerLease) error) {
+	ps.gossip(fn)
+}
+
+func (ps *peerSubscription) gossip(fn func(context.Context, interfaces.PeerLease) error) {
+	for _, idx := range ps.peers() {
+		_ = fn(context.Background(), ps.lease(idx))
+	}
+}
+
+func (ps *peerSubscription) lease(to int) *peerLease {
+	return &peerLease{
+		client: &p2pClient{
+			from:      ps.self,
+			to:        to,
+			addr:      newNodeAddr(to, ps.chainID),
+			net:       ps.net,
+			handlers:  ps.handlers,
+			closeChan: ps.closeChan,
+		},
+	}
+}
+
+// peerLease is a lease on a single simulated peer
+type peerLease struct {
+	client *p2pClient
+}
+
+// P2PClient returns the client of the leased peer
+func (pl *peerLease) P2PClient() (interfaces.P2PClient, error) {
+	return pl.client, nil
+}
+
+// Do invokes fn with the lease
+func (pl *peerLease) Do(fn func(interfaces.PeerLease) error) {
+	_ = fn(pl)
+}
+
+// p2pClient serves the request methods of the P2P protocol by calling the
+// request handler of the remote node in process. Reachability is checked
+// on every call so a partition or crash takes effect on open leases too.
+type p2pClient struct {
+	from      int
+	to        int
+	addr      *nodeAddr
+	net       *network
+	handlers  handlerSource
+	closeChan <-chan struct{}
+}
+
+func (c *p2pClient) remote() (*request.Handler, error) {
+	select {
+	case <-c.closeChan:
+		return nil, errorz.ErrClosing
+	default:
+	}
+	if !c.net.connected(c.from, c.to) || !c.net.connected(c.to, c.from) {
+		return nil, errUnreachable
+	}
+	h := c.handlers(c.to)
+	if h == nil {
+		return nil, errUnreachable
+	}
+	return h, nil
+}
+
+// Close is a no-op in the simulator
+func (c *p2pClient) Close() error {
+	return nil
+}
+
+// NodeAddr returns the address of the remote node
+func (c *p2pClient) NodeAddr() interfaces.NodeAddr {
+	return c.addr
+}
+
+// CloseChan returns a channel that is closed when the local node is stopped
+func (c *p2pClient) CloseChan() <-chan struct{} {
+	return c.closeChan
+}
+
+// Status is not supported by the simulator
+func (c *p2pClient) Status(ctx context.Context, in *pb.StatusRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error) {
+	return nil, errNotSupported
+}
+
+// GetBlockHeaders is served by the remote request handler
+func (c *p2pClient) GetBlockHeaders(ctx context.Context, in *pb.GetBlockHeadersRequest, opts ...grpc.CallOption) (*pb.GetBlockHeadersResponse, error) {
+	h, err := c.remote()
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleP2PGetBlockHeaders(ctx, in)
+}
+
+// GetMinedTxs is served by the remote request handler
+func (c *p2pClient) GetMinedTxs(ctx context.Context, in *pb.GetMinedTxsRequest, opts ...grpc.CallOption) (*pb.GetMinedTxsResponse, error) {
+	h, err := c.remote()
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleP2PGetMinedTxs(ctx, in)
+}
+
+// GetPendingTxs is served by the remote request handler
+func (c *p2pClient) GetPendingTxs(ctx context.Context, in *pb.GetPendingTxsRequest, opts ...grpc.CallOption) (*pb.GetPendingTxsResponse, error) {
+	h, err := c.remote()
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleP2PGetPendingTxs(ctx, in)
+}
+
+// GetSnapShotNode is served by the remote request handler
+func (c *p2pClient) GetSnapShotNode(ctx context.Context, in *pb.GetSnapShotNodeRequest, opts ...grpc.CallOption) (*pb.GetSnapShotNodeResponse, error) {
+	h, err := c.remote()
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleP2PGetSnapShotNode(ctx, in)
+}
+
+// GetSnapShotStateData is served by the remote request handler
+func (c *p2pClient) GetSnapShotStateData(ctx context.Context, in *pb.GetSnapShotStateDataRequest, opts ...grpc.CallOption) (*pb.GetSnapShotStateDataResponse, error) {
+	h, err := c.remote()
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleP2PGetSnapShotStateData(ctx, in)
+}
+
+// GetSnapShotHdrNode is served by the remote request handler
+func (c *p2pClient) GetSnapShotHdrNode(ctx context.Context, in *pb.GetSnapShotHdrNodeRequest, opts ...grpc.CallOption) (*pb.GetSnapShotHdrNodeResponse, error) {
+	h, err := c.remote()
+	if err != nil {
+		return nil, err
+	}
+	return h.HandleP2PGetSnapShotHdrNode(ctx, in)
+}
+
+// GossipTransaction is not supported by the simulator
+func (c *p2pClient) GossipTransaction(ctx context.Context, in *pb.GossipTransactionMessage, opts ...grpc.CallOption) (*pb.GossipTransactionAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipProposal is not supported by the simulator
+func (c *p2pClient) GossipProposal(ctx context.Context, in *pb.GossipProposalMessage, opts ...grpc.CallOption) (*pb.GossipProposalAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipPreVote is not supported by the simulator
+func (c *p2pClient) GossipPreVote(ctx context.Context, in *pb.GossipPreVoteMessage, opts ...grpc.CallOption) (*pb.GossipPreVoteAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipPreVoteNil is not supported by the simulator
+func (c *p2pClient) GossipPreVoteNil(ctx context.Context, in *pb.GossipPreVoteNilMessage, opts ...grpc.CallOption) (*pb.GossipPreVoteNilAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipPreCommit is not supported by the simulator
+func (c *p2pClient) GossipPreCommit(ctx context.Context, in *pb.GossipPreCommitMessage, opts ...grpc.CallOption) (*pb.GossipPreCommitAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipPreCommitNil is not supported by the simulator
+func (c *p2pClient) GossipPreCommitNil(ctx context.Context, in *pb.GossipPreCommitNilMessage, opts ...grpc.CallOption) (*pb.GossipPreCommitNilAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipNextRound is not supported by the simulator
+func (c *p2pClient) GossipNextRound(ctx context.Context, in *pb.GossipNextRoundMessage, opts ...grpc.CallOption) (*pb.GossipNextRoundAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipNextHeight is not supported by the simulator
+func (c *p2pClient) GossipNextHeight(ctx context.Context, in *pb.GossipNextHeightMessage, opts ...grpc.CallOption) (*pb.GossipNextHeightAck, error) {
+	return nil, errNotSupported
+}
+
+// GossipBlockHeader is not supported by the simulator
+func (c *p2pClient) GossipBlockHeader(ctx context.Context, in *pb.GossipBlockHeaderMessage, opts ...grpc.CallOption) (*pb.GossipBlockHeaderAck, error) {
+	return nil, errNotSupported
+}
+
+// GetPeers is not supported by the simulator
+func (c *p2pClient) GetPeers(ctx context.Context, in *pb.GetPeersRequest, opts ...grpc.CallOption) (*pb.GetPeersResponse, error) {
+	return nil, errNotSupported
+}
+
+// nodeAddr identifies a simulated node
+type nodeAddr struct {
+	idx     int
+	chainID uint32
+}
+
+func newNodeAddr(idx int, chainID uint32) *nodeAddr {
+	return &nodeAddr{idx: idx, chainID: chainID}
+}
+
+// Network returns the network name of the simulated transport
+func (na *nodeAddr) Network() string {
+	return "sim"
+}
+
+// String returns the p2p address of the node
+func (na *nodeAddr) String() string {
+	return na.P2PAddr()
+}
+
+// Identity returns the name of the node
+func (na *nodeAddr) Identity() string {
+	return fmt.Sprintf("node%d", na.idx)
+}
+
+// P2PAddr returns the p2p address of the node
+func (na *nodeAddr) P2PAddr() string {
+	return fmt.Sprintf("%s@%s:%d", na.Identity(), na.Host(), na.Port())
+}
+
+// ChainID returns the chain the node belongs to
+func (na *nodeAddr) ChainID() types.ChainIdentifier {
+	return types.ChainIdentifier(na.chainID)
+}
+
+// Host returns the host of the node
+func (na *nodeAddr) Host() string {
+	return "sim"
+}
+
+// Port returns the port of the node
+func (na *nodeAddr) Port() int {
+	return na.idx
+}
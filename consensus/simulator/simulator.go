@@ -0,0 +1,506 @@
+// Package simulator runs several consensus engines in a single process
+// against each other. Each validator gets an in-memory database, the mock
+// application and an in-memory peer subscription, and all of them are
+// driven from one goroutine on a virtual clock.
+//
+// Consensus gossip is routed by the simulator through a network model that
+// can drop, delay and partition messages, so the order in which state
+// changes are applied is determined by the configured seed. Block header
+// downloads used to catch up after a crash or partition go through the
+// regular download manager and request handlers and run on their own
+// goroutines as they would in a node; a tick waits for such a download
+// whenever a reachable peer can serve it, so catching up takes the same
+// number of ticks on every run.
+//
+// The consensus engine and admin handlers of every validator measure the
+// step timeouts with the virtual clock of their simulator, so simulations
+// may run in parallel.
+package simulator
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/consensus/objs"
+	"github.com/MadBase/MadNet/consensus/request"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the parameters of a simulation
+type Config struct {
+	// Validators is the number of validators. Defaults to 4.
+	Validators int
+	// Seed drives key generation and every random choice of the network.
+	Seed int64
+	// ChainID of the simulated chain. Defaults to 42.
+	ChainID uint32
+	// TickDuration is the virtual time that passes per tick. Defaults to
+	// 250ms.
+	TickDuration time.Duration
+	// RegossipTicks is the interval in ticks at which every node resends
+	// its current messages, as the regossip loop of a node does. Defaults
+	// to 8.
+	RegossipTicks uint64
+	// SyncTimeout bounds the real time a tick waits for a node that is
+	// catching up to download the next block header from a reachable peer.
+	// Defaults to 5s.
+	SyncTimeout time.Duration
+}
+
+// SafetyError is returned when two nodes committed different blocks at the
+// same height.
+type SafetyError struct {
+	Height uint32
+	NodeA  int
+	HashA  []byte
+	NodeB  int
+	HashB  []byte
+}
+
+func (e *SafetyError) Error() string {
+	return fmt.Sprintf("conflicting commits at height %d: node %d has %x, node %d has %x", e.Height, e.NodeA, e.HashA, e.NodeB, e.HashB)
+}
+
+// LivenessError is returned when the running nodes fail to reach a height
+// within the allowed number of ticks.
+type LivenessError struct {
+	Height  uint32
+	Ticks   int
+	Heights []uint32
+}
+
+func (e *LivenessError) Error() string {
+	return fmt.Sprintf("height %d not reached within %d ticks: heights %v", e.Height, e.Ticks, e.Heights)
+}
+
+// Stats counts the messages handled by the simulated network
+type Stats struct {
+	Sent      uint64
+	Discarded uint64
+	Accepted  uint64
+	Rejected  uint64
+}
+
+// Simulator drives a set of validators
+type Simulator struct {
+	sync.RWMutex
+	cfg       Config
+	logger    *logrus.Logger
+	closeChan chan struct{}
+	closeOnce sync.Once
+	net       *network
+	nodes     []*node
+	vs        *objs.ValidatorSet
+	secret    []byte
+	tick      uint64
+	now       time.Time
+	stats     Stats
+
+	// first committed block hash seen at each height and the node it was
+	// seen on, used for the safety check
+	commits     map[uint32][]byte
+	commitNodes map[uint32]int
+	checked     []uint32
+}
+
+// New creates a simulator and starts all validators at genesis
+func New(cfg Config) (*Simulator, error) {
+	if cfg.Validators == 0 {
+		cfg.Validators = 4
+	}
+	if cfg.ChainID == 0 {
+		cfg.ChainID = 42
+	}
+	if cfg.TickDuration == 0 {
+		cfg.TickDuration = 250 * time.Millisecond
+	}
+	if cfg.RegossipTicks == 0 {
+		cfg.RegossipTicks = 8
+	}
+	if cfg.SyncTimeout == 0 {
+		cfg.SyncTimeout = 5 * time.Second
+	}
+	groupKey, keys, err := makeKeys(cfg.Seed, cfg.Validators)
+	if err != nil {
+		return nil, err
+	}
+	s := &Simulator{
+		cfg:         cfg,
+		logger:      logging.GetLogger(constants.LoggerConsensus),
+		closeChan:   make(chan struct{}),
+		net:         newNetwork(cfg.Seed),
+		vs:          makeValidatorSet(groupKey, keys),
+		secret:      crypto.Hasher([]byte(fmt.Sprintf("sim-secret-%d", cfg.Seed))),
+		now:         time.Unix(1600000000, 0),
+		commits:     make(map[uint32][]byte),
+		commitNodes: make(map[uint32]int),
+		checked:     make([]uint32, cfg.Validators),
+	}
+	for i := 0; i < cfg.Validators; i++ {
+		rawDB, err := utils.OpenBadger(s.closeChan, "", true)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.nodes = append(s.nodes, &node{
+			idx:   i,
+			keys:  keys[i],
+			rawDB: rawDB,
+		})
+	}
+	for i := 0; i < cfg.Validators; i++ {
+		if err := s.startNode(i); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Close stops all validators and releases their databases.
+func (s *Simulator) Close() {
+	s.closeOnce.Do(func() {
+		s.Lock()
+		for _, n := range s.nodes {
+			n.stop()
+		}
+		s.Unlock()
+		close(s.closeChan)
+	})
+}
+
+func (s *Simulator) clock() time.Time {
+	return s.now
+}
+
+// handler returns the request handler of a running node
+func (s *Simulator) handler(idx int) *request.Handler {
+	s.RLock()
+	defer s.RUnlock()
+	n := s.nodes[idx]
+	if !n.running {
+		return nil
+	}
+	return n.reqServer
+}
+
+func (s *Simulator) startNode(idx int) error {
+	s.Lock()
+	defer s.Unlock()
+	n := s.nodes[idx]
+	if n.running {
+		return nil
+	}
+	s.net.setDown(idx, false)
+	if err := n.start(s.cfg.ChainID, s.secret, s.vs, s.net, len(s.nodes), s.handler, s.clock); err != nil {
+		return err
+	}
+	s.logger.Debugf("simulator: node %d started at tick %d", idx, s.tick)
+	return nil
+}
+
+// Crash stops a validator. Its database is kept and it stops sending and
+// receiving messages until it is restarted.
+func (s *Simulator) Crash(idx int) {
+	s.Lock()
+	defer s.Unlock()
+	s.net.setDown(idx, true)
+	s.nodes[idx].stop()
+	s.logger.Infof("simulator: node %d crashed at tick %d", idx, s.tick)
+}
+
+// Restart starts a crashed validator again on top of its old database
+func (s *Simulator) Restart(idx int) error {
+	return s.startNode(idx)
+}
+
+// SetByzantine makes a validator equivocate on every proposal, prevote and
+// precommit it sends.
+func (s *Simulator) SetByzantine(idx int, byzantine bool) {
+	s.nodes[idx].byzantine = byzantine
+}
+
+// Partition splits the network into the given groups of validators.
+// Validators that are not listed are isolated.
+func (s *Simulator) Partition(groups ...[]int) {
+	s.net.setPartition(groups, len(s.nodes))
+}
+
+// Heal removes all partitions and broken links
+func (s *Simulator) Heal() {
+	s.net.heal()
+}
+
+// SetLink breaks or restores the one way link between two validators
+func (s *Simulator) SetLink(from, to int, up bool) {
+	s.net.setLink(from, to, up)
+}
+
+// SetDropRate sets the probability that a message is dropped
+func (s *Simulator) SetDropRate(rate float64) {
+	s.net.setDropRate(rate)
+}
+
+// SetDelay sets the range of ticks a message spends in flight
+func (s *Simulator) SetDelay(min, max uint64) {
+	s.net.setDelay(min, max)
+}
+
+// Ticks returns the number of ticks run so far
+func (s *Simulator) Ticks() uint64 {
+	return s.tick
+}
+
+// Now returns the virtual time
+func (s *Simulator) Now() time.Time {
+	return s.now
+}
+
+// Stats returns the message counters of the simulated network
+func (s *Simulator) Stats() Stats {
+	return s.stats
+}
+
+// Heights returns the height every validator has synchronized to
+func (s *Simulator) Heights() ([]uint32, error) {
+	out := make([]uint32, len(s.nodes))
+	for i, n := range s.nodes {
+		h, err := n.height()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = h
+	}
+	return out, nil
+}
+
+// Tick advances the virtual clock by one step. Messages that are due are
+// delivered first, then every running validator runs its state loop once
+// in index order and sends out any new messages.
+func (s *Simulator) Tick() error {
+	s.tick++
+	s.now = s.now.Add(s.cfg.TickDuration)
+	for _, e := range s.net.due(s.tick) {
+		n := s.nodes[e.to]
+		if !n.running {
+			continue
+		}
+		ok, err := n.deliver(e.kind, e.msg)
+		if err != nil {
+			return fmt.Errorf("node %d: delivering %v from node %d: %v", e.to, e.kind, e.from, err)
+		}
+		if ok {
+			s.stats.Accepted++
+		} else {
+			s.stats.Rejected++
+		}
+	}
+	for _, n := range s.nodes {
+		if !n.running {
+			continue
+		}
+		if err := s.step(n); err != nil {
+			return fmt.Errorf("node %d: %v", n.idx, err)
+		}
+		if err := s.broadcast(n); err != nil {
+			return fmt.Errorf("node %d: %v", n.idx, err)
+		}
+	}
+	return s.checkSafety()
+}
+
+// step runs the state loop of n once. Block headers are downloaded on the
+// goroutines of the download manager, so a node that is catching up is
+// stepped repeatedly until the header it needs arrives, as long as some
+// reachable peer can serve it. This keeps the number of ticks a catch up
+// takes independent of goroutine scheduling.
+func (s *Simulator) step(n *node) error {
+	deadline := time.Now().Add(s.cfg.SyncTimeout)
+	for {
+		wasSynced := n.synced
+		target, behind, err := n.syncTarget()
+		if err != nil {
+			return err
+		}
+		if err := n.step(); err != nil {
+			return err
+		}
+		if wasSynced || n.synced || !behind {
+			return nil
+		}
+		_, stillBehind, err := n.syncTarget()
+		if err != nil {
+			return err
+		}
+		h, err := n.height()
+		if err != nil {
+			return err
+		}
+		if h >= target || !stillBehind {
+			return nil
+		}
+		ok, err := s.canServe(n.idx, target)
+		if err != nil {
+			return err
+		}
+		if !ok || time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// canServe reports if a running peer reachable from idx has committed the
+// block header at height.
+func (s *Simulator) canServe(idx int, height uint32) (bool, error) {
+	for _, p := range s.nodes {
+		if p.idx == idx || !p.running {
+			continue
+		}
+		if !s.net.connected(idx, p.idx) || !s.net.connected(p.idx, idx) {
+			continue
+		}
+		ok, err := p.hasHeader(height)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// broadcast sends every message of n that changed since the last tick to
+// all other validators, and all of them on regossip ticks.
+func (s *Simulator) broadcast(n *node) error {
+	kinds, msgs, err := n.outbound()
+	if err != nil {
+		return err
+	}
+	regossip := s.tick%s.cfg.RegossipTicks == 0
+	for i := 0; i < len(kinds); i++ {
+		hsh := string(crypto.Hasher(msgs[i]))
+		if !regossip && n.lastSent[kinds[i]] == hsh {
+			continue
+		}
+		n.lastSent[kinds[i]] = hsh
+		altKind, alt := kinds[i], msgs[i]
+		if n.byzantine {
+			k, m, err := equivocate(kinds[i], msgs[i], n.keys.secpSigner)
+			if err != nil {
+				return err
+			}
+			if m != nil {
+				altKind, alt = k, m
+			}
+		}
+		peers := []int{}
+		for j := range s.nodes {
+			if j != n.idx {
+				peers = append(peers, j)
+			}
+		}
+		for j, to := range peers {
+			kind, msg := kinds[i], msgs[i]
+			if j >= len(peers)/2 {
+				kind, msg = altKind, alt
+			}
+			if s.net.send(s.tick, n.idx, to, kind, msg) {
+				s.stats.Sent++
+			} else {
+				s.stats.Discarded++
+			}
+		}
+	}
+	return nil
+}
+
+// checkSafety compares the blocks committed since the last check against
+// the blocks committed by every other validator at the same height.
+func (s *Simulator) checkSafety() error {
+	for _, n := range s.nodes {
+		commits, height, err := n.committed(s.checked[n.idx] + 1)
+		if err != nil {
+			return err
+		}
+		for h := s.checked[n.idx] + 1; h <= height; h++ {
+			hsh, ok := commits[h]
+			if !ok {
+				continue
+			}
+			prev, ok := s.commits[h]
+			if !ok {
+				s.commits[h] = hsh
+				s.commitNodes[h] = n.idx
+				continue
+			}
+			if !bytes.Equal(prev, hsh) {
+				return &SafetyError{
+					Height: h,
+					NodeA:  s.commitNodes[h],
+					HashA:  prev,
+					NodeB:  n.idx,
+					HashB:  hsh,
+				}
+			}
+		}
+		if height > s.checked[n.idx] {
+			s.checked[n.idx] = height
+		}
+	}
+	return nil
+}
+
+// Run runs the given number of ticks
+func (s *Simulator) Run(ticks int) error {
+	for i := 0; i < ticks; i++ {
+		if err := s.Tick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunUntilHeight runs until every running validator has reached height. A
+// *LivenessError is returned if this does not happen within maxTicks, and
+// a *SafetyError as soon as two validators commit conflicting blocks.
+func (s *Simulator) RunUntilHeight(height uint32, maxTicks int) error {
+	for i := 0; i < maxTicks; i++ {
+		if err := s.Tick(); err != nil {
+			return err
+		}
+		done := true
+		for _, n := range s.nodes {
+			if !n.running {
+				continue
+			}
+			h, err := n.height()
+			if err != nil {
+				return err
+			}
+			if h < height {
+				done = false
+				break
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+	heights, err := s.Heights()
+	if err != nil {
+		return err
+	}
+	return &LivenessError{
+		Height:  height,
+		Ticks:   maxTicks,
+		Heights: heights,
+	}
+}
@@ -0,0 +1,123 @@
+package simulator
+
+import (
+	"testing"
+)
+
+func newSimulator(t *testing.T, cfg Config) *Simulator {
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func maxHeight(t *testing.T, s *Simulator) uint32 {
+	heights, err := s.Heights()
+	if err != nil {
+		t.Fatal(err)
+	}
+	max := uint32(0)
+	for _, h := range heights {
+		if h > max {
+			max = h
+		}
+	}
+	return max
+}
+
+func TestSimulatorCommits(t *testing.T) {
+	s := newSimulator(t, Config{Seed: 1})
+	if err := s.RunUntilHeight(6, 500); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimulatorDeterministic(t *testing.T) {
+	run := func() ([]uint32, Stats) {
+		s := newSimulator(t, Config{Seed: 2})
+		s.SetDropRate(0.1)
+		s.SetDelay(0, 2)
+		if err := s.Run(120); err != nil {
+			t.Fatal(err)
+		}
+		heights, err := s.Heights()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats := s.Stats()
+		s.Close()
+		return heights, stats
+	}
+	h1, s1 := run()
+	h2, s2 := run()
+	for i := range h1 {
+		if h1[i] != h2[i] {
+			t.Fatalf("heights differ between runs: %v vs %v", h1, h2)
+		}
+	}
+	if s1 != s2 {
+		t.Fatalf("stats differ between runs: %+v vs %+v", s1, s2)
+	}
+}
+
+func TestSimulatorLossyNetwork(t *testing.T) {
+	s := newSimulator(t, Config{Seed: 3})
+	s.SetDropRate(0.2)
+	s.SetDelay(0, 3)
+	if err := s.RunUntilHeight(5, 2000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimulatorPartition(t *testing.T) {
+	s := newSimulator(t, Config{Seed: 4})
+	if err := s.RunUntilHeight(3, 500); err != nil {
+		t.Fatal(err)
+	}
+	// neither half holds the three of four signatures needed to commit
+	s.Partition([]int{0, 1}, []int{2, 3})
+	before := maxHeight(t, s)
+	if err := s.Run(200); err != nil {
+		t.Fatal(err)
+	}
+	during := maxHeight(t, s)
+	if during > before+1 {
+		t.Fatalf("chain advanced from %d to %d without a quorum", before, during)
+	}
+	s.Heal()
+	if err := s.RunUntilHeight(during+2, 3000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimulatorCrashRestart(t *testing.T) {
+	s := newSimulator(t, Config{Seed: 5})
+	if err := s.RunUntilHeight(3, 500); err != nil {
+		t.Fatal(err)
+	}
+	s.Crash(3)
+	if err := s.RunUntilHeight(7, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restart(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RunUntilHeight(maxHeight(t, s)+2, 3000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimulatorByzantine(t *testing.T) {
+	s := newSimulator(t, Config{Seed: 6})
+	if err := s.RunUntilHeight(3, 500); err != nil {
+		t.Fatal(err)
+	}
+	// an equivocating validator can stall the round it votes in, so only
+	// safety is asserted here; Run fails on the first conflicting commit
+	s.SetByzantine(0, true)
+	if err := s.Run(300); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -123,3 +123,19 @@ func PrefixPendingHdrLeafKey() []byte {
 func PrefixStagedBlockHeaderKey() []byte {
 	return []byte("a3")
 }
+
+func PrefixFastSyncTarget() []byte {
+	return []byte("a4")
+}
+
+func PrefixPeerBan() []byte {
+	return []byte("a5")
+}
+
+func PrefixPeerAddr() []byte {
+	return []byte("a6")
+}
+
+func PrefixBootNodeRecord() []byte {
+	return []byte("a7")
+}
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 const (
 	// EpochLength is the number of blocks in an epoch for MadNet
 	EpochLength uint32 = 1024
@@ -15,6 +17,31 @@ const (
 
 	// OneBillion is 1e9 as an integer
 	OneBillion = 1000000000
+
+	// MonitorReorgDepth is the number of Ethereum blocks below the highest
+	// processed block that the monitor can roll back after a reorg
+	MonitorReorgDepth uint64 = 128
+
+	// EthereumHealthInterval is how often the Ethereum endpoints are checked
+	EthereumHealthInterval = 10 * time.Second
+
+	// EthereumNonceDropTimeout is how long a transaction may be unknown to the
+	// endpoint before it's considered dropped and its nonce is used again
+	EthereumNonceDropTimeout = 2 * time.Minute
+
+	// EthereumPasscodeEnv is the environment variable with the keystore
+	// passcode when there is no passcodes file
+	EthereumPasscodeEnv = "MADNET_ETHEREUM_PASSCODE"
+
+	// SnapshotSlotBlocks is how many Ethereum blocks a validator waits for the
+	// one in the slot before it to submit a snapshot
+	SnapshotSlotBlocks uint64 = 8
+
+	// TaskMaxBackoff is the longest a task waits between attempts by default
+	TaskMaxBackoff = 1 * time.Minute
+
+	// TaskRunRetention is how many finished task runs are remembered
+	TaskRunRetention = 256
 )
 
 // CurveSpec specifies the particular elliptic curve we are dealing with
@@ -87,4 +114,4 @@ var ValidLoggers []string = []string{"madnet", "consensus", "transport", "app",
 	"gossipbus", "badger", "peerMan", "localRPC", "dman", "peer", "yamux",
 	"ethereum", "main", "deploy", "utils", "monitor", "dkg",
 	"services", "settings", "validator", "muxHandler", "bootnode", "p2pmux",
-	"status"}
+	"status", "verify", "snapshot"}
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // GRPC Server Configuration Params
 // Setup to provide backpressure
 const (
@@ -13,3 +15,69 @@ const (
 	P2PStreamWorkers     = 4
 	DiscoStreamWorkers   = 1
 )
+
+// Private peers such as the validator behind a sentry or the sentries of a
+// validator carry the consensus traffic of the validator and get larger
+// consensus gossip queues with more workers than public peers.
+const (
+	PrivatePeerMsgQSize    = 4096
+	PrivatePeerMsgQWorkers = 16
+)
+
+// Peer reputation defaults used when the transport configuration does not
+// set a ban threshold or ban duration.
+const (
+	DefaultPeerBanThreshold = -100
+	DefaultPeerBanDuration  = time.Hour
+)
+
+// Address book limits. Entries that have not been seen for AddrBookMaxAge or
+// whose failed dials outnumber the successful connections by
+// AddrBookMaxFailures are dropped.
+const (
+	AddrBookMaxSize       = 1000
+	AddrBookMaxAge        = 72 * time.Hour
+	AddrBookMaxFailures   = 10
+	AddrBookPruneInterval = 5 * time.Minute
+)
+
+// Nodes announce a signed record to a bootnode every NodeRecordRefresh. The
+// record expires after NodeRecordTTL. Bootnodes probe the nodes they list
+// every BootNodeProbeInterval with at most BootNodeProbeWorkers dials at once
+// and drop a node after BootNodeProbeFailures failed probes in a row.
+const (
+	NodeRecordTTL         = 6 * time.Hour
+	NodeRecordRefresh     = time.Hour
+	BootNodeProbeInterval = time.Minute
+	BootNodeProbeWorkers  = 16
+	BootNodeProbeFailures = 3
+)
+
+// Per peer rate limit defaults used when the transport configuration does not
+// set them. Rates are in calls per second and bursts are the number of calls a
+// peer may make at once.
+const (
+	DefaultGossipRateLimit   = 200
+	DefaultGossipRateBurst   = 400
+	DefaultRequestRateLimit  = 20
+	DefaultRequestRateBurst  = 40
+	DefaultSnapShotRateLimit = 50
+	DefaultSnapShotRateBurst = 100
+)
+
+// A peer that rate limited a request is skipped for RateLimitBackoff when
+// choosing the peer for the next request. At most RateLimitLeaseAttempts
+// peers are tried before a peer that is backed off is used anyway.
+const (
+	RateLimitBackoff       = 5 * time.Second
+	RateLimitLeaseAttempts = 4
+)
+
+// CompressionSnappy is the name of the snappy payload compression advertised
+// in the peer capabilities and used as the grpc encoding.
+const CompressionSnappy = "snappy"
+
+// MaxMessageSize is the largest P2P message in bytes a node accepts, which
+// it advertises in its capabilities. It leaves room above MaxBytes of
+// transactions for the rest of the message.
+const MaxMessageSize = 4 * 1024 * 1024
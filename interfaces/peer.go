@@ -1,6 +1,10 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+
+	"github.com/MadBase/MadNet/types"
+)
 
 // Peer is an element of the peer tree.
 // This interface allows inspection of both the peer and
@@ -25,9 +29,11 @@ type PeerLease interface {
 type PeerSubscription interface {
 	CloseChan() <-chan struct{}
 	PeerLease(ctx context.Context) (PeerLease, error)
+	CapablePeerLease(ctx context.Context, rpc string) (PeerLease, error)
 	RequestLease(ctx context.Context, hsh []byte) (PeerLease, error)
 	PreventGossipTx(addr NodeAddr, hsh []byte)
 	PreventGossipConsensus(addr NodeAddr, hsh []byte)
 	GossipConsensus(hsh []byte, fn func(context.Context, PeerLease) error)
 	GossipTx(hsh []byte, fn func(context.Context, PeerLease) error)
+	ReportPeer(addr NodeAddr, ev types.PeerEvent)
 }
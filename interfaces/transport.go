@@ -32,7 +32,9 @@ type P2PConn interface {
 	NodeAddr() NodeAddr
 	Protocol() types.Protocol
 	ProtoVersion() types.ProtoVersion
+	Capabilities() *types.Capabilities
 	CloseChan() <-chan struct{}
+	Bandwidth() types.Bandwidth
 }
 
 // P2PMuxConn is a multiplexed P2PConn as is returned by the P2PMuxTransport.
@@ -58,6 +60,8 @@ type P2PTransport interface {
 	Accept() (P2PConn, error)
 	Dial(NodeAddr, types.Protocol) (P2PConn, error)
 	Close() error
+	Bandwidth() map[types.Protocol]types.Bandwidth
+	PeerBandwidth(identity string) map[types.Protocol]types.Bandwidth
 }
 
 // P2PMux handles the handshake protocol of the multiplexing protocol.
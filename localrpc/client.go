@@ -456,3 +456,76 @@ func (lrpc *Client) GetBlockHeightForTx(ctx context.Context, txHash []byte) (uin
 	}
 	return resp.BlockHeight, nil
 }
+
+// GetSyncStatus returns the progress of fast sync
+func (lrpc *Client) GetSyncStatus(ctx context.Context) (*pb.SyncStatusResponse, error) {
+	if err := lrpc.entrancyGuard(); err != nil {
+		return nil, err
+	}
+	defer lrpc.wg.Done()
+	var subCtx context.Context
+	var cancel func()
+	if _, ok := ctx.Deadline(); !ok {
+		subCtx, cancel = context.WithTimeout(ctx, lrpc.TimeOut)
+		defer cancel()
+	} else {
+		subCtx = ctx
+	}
+	request := &pb.SyncStatusRequest{}
+	return lrpc.client.GetSyncStatus(subCtx, request)
+}
+
+// GetPeerBans returns the peers that are banned for misbehaving
+func (lrpc *Client) GetPeerBans(ctx context.Context) (*pb.PeerBansResponse, error) {
+	if err := lrpc.entrancyGuard(); err != nil {
+		return nil, err
+	}
+	defer lrpc.wg.Done()
+	var subCtx context.Context
+	var cancel func()
+	if _, ok := ctx.Deadline(); !ok {
+		subCtx, cancel = context.WithTimeout(ctx, lrpc.TimeOut)
+		defer cancel()
+	} else {
+		subCtx = ctx
+	}
+	request := &pb.PeerBansRequest{}
+	return lrpc.client.GetPeerBans(subCtx, request)
+}
+
+// GetPeerInfo returns the connection and traffic details of the peers
+func (lrpc *Client) GetPeerInfo(ctx context.Context) (*pb.PeerInfoResponse, error) {
+	if err := lrpc.entrancyGuard(); err != nil {
+		return nil, err
+	}
+	defer lrpc.wg.Done()
+	var subCtx context.Context
+	var cancel func()
+	if _, ok := ctx.Deadline(); !ok {
+		subCtx, cancel = context.WithTimeout(ctx, lrpc.TimeOut)
+		defer cancel()
+	} else {
+		subCtx = ctx
+	}
+	request := &pb.PeerInfoRequest{}
+	return lrpc.client.GetPeerInfo(subCtx, request)
+}
+
+// GetTaskRuns returns the history of task runs, optionally only the run under
+// key or the runs in status
+func (lrpc *Client) GetTaskRuns(ctx context.Context, key string, status string) (*pb.TaskRunsResponse, error) {
+	if err := lrpc.entrancyGuard(); err != nil {
+		return nil, err
+	}
+	defer lrpc.wg.Done()
+	var subCtx context.Context
+	var cancel func()
+	if _, ok := ctx.Deadline(); !ok {
+		subCtx, cancel = context.WithTimeout(ctx, lrpc.TimeOut)
+		defer cancel()
+	} else {
+		subCtx = ctx
+	}
+	request := &pb.TaskRunsRequest{Key: key, Status: status}
+	return lrpc.client.GetTaskRuns(subCtx, request)
+}
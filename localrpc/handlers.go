@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/MadBase/MadNet/application"
@@ -16,7 +17,9 @@ import (
 	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/crypto"
 	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/peering"
 	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/types"
 	"github.com/MadBase/MadNet/utils"
 	"github.com/dgraph-io/badger/v2"
 	"github.com/sirupsen/logrus"
@@ -35,6 +38,17 @@ var _ pb.LocalStateGetMinedTransactionHandler = (*Handlers)(nil)
 var _ pb.LocalStateGetValueForOwnerHandler = (*Handlers)(nil)
 var _ pb.LocalStateIterateNameSpaceHandler = (*Handlers)(nil)
 var _ pb.LocalStateGetUTXOHandler = (*Handlers)(nil)
+var _ pb.LocalStateGetSyncStatusHandler = (*Handlers)(nil)
+var _ pb.LocalStateGetPeerBansHandler = (*Handlers)(nil)
+var _ pb.LocalStateGetPeerInfoHandler = (*Handlers)(nil)
+var _ pb.LocalStateGetTaskRunsHandler = (*Handlers)(nil)
+
+// TaskRuns is the history of the tasks run against Ethereum, kept by the task
+// manager of the node
+type TaskRuns interface {
+	Runs() []*pb.TaskRunsResponse_Run
+	FindRun(key string) (*pb.TaskRunsResponse_Run, bool)
+}
 
 // Handlers is the server side of the local RPC system. Handlers dispatches
 // requests to other systems for processing.
@@ -48,6 +62,9 @@ type Handlers struct {
 
 	AppHandler *application.Application
 	GossipBus  *gossip.Handlers
+	Engine     *lstate.Engine
+	Peers      *peering.PeerManager
+	Tasks      TaskRuns
 
 	logger *logrus.Logger
 
@@ -59,7 +76,7 @@ type Handlers struct {
 }
 
 // Init will initialize the Consensus Engine and all sub modules
-func (srpc *Handlers) Init(database *db.Database, app *application.Application, gh *gossip.Handlers, pubk []byte, safe func() bool) error {
+func (srpc *Handlers) Init(database *db.Database, app *application.Application, gh *gossip.Handlers, engine *lstate.Engine, peers *peering.PeerManager, taskRuns TaskRuns, pubk []byte, safe func() bool) error {
 	background := context.Background()
 	ctx, cf := context.WithCancel(background)
 	srpc.cancelCtx = cf
@@ -68,6 +85,9 @@ func (srpc *Handlers) Init(database *db.Database, app *application.Application,
 	srpc.database = database
 	srpc.AppHandler = app
 	srpc.GossipBus = gh
+	srpc.Engine = engine
+	srpc.Peers = peers
+	srpc.Tasks = taskRuns
 	srpc.EthPubk = pubk
 	srpc.sstore = &lstate.Store{}
 	err := srpc.sstore.Init(database)
@@ -661,3 +681,148 @@ func (srpc *Handlers) HandleLocalStateGetTxBlockNumber(ctx context.Context, req
 	result := &pb.TxBlockNumberResponse{BlockHeight: height}
 	return result, nil
 }
+
+// HandleLocalStateGetSyncStatus is not gated on being in sync since it
+// reports the progress of catching up.
+func (srpc *Handlers) HandleLocalStateGetSyncStatus(ctx context.Context, req *pb.SyncStatusRequest) (*pb.SyncStatusResponse, error) {
+	srpc.logger.Debugf("HandleLocalStateGetSyncStatus: %v", req)
+	st := srpc.Engine.SyncStatus()
+	result := &pb.SyncStatusResponse{
+		Active:             st.Active,
+		Resumed:            st.Resumed,
+		SnapShotHeight:     st.SnapShotHeight,
+		PendingHdrNodes:    uint32(st.PendingHdrNodes),
+		PendingHdrLeaves:   uint32(st.PendingHdrLeaves),
+		PendingStateNodes:  uint32(st.PendingStateNodes),
+		PendingStateLeaves: uint32(st.PendingStateLeaves),
+		StagedBlockHeaders: uint32(st.StagedBlockHeaders),
+		Stored:             st.Stored,
+		ItemsPerSecond:     st.ItemsPerSecond,
+		ETASeconds:         uint64(st.ETA.Seconds()),
+	}
+	for _, p := range st.Peers {
+		result.Peers = append(result.Peers, &pb.SyncStatusResponse_Peer{
+			Identity:  p.Identity,
+			Addr:      p.Addr,
+			Responses: p.Responses,
+			Failures:  p.Failures,
+			Bytes:     p.Bytes,
+		})
+	}
+	return result, nil
+}
+
+// HandleLocalStateGetPeerBans is not gated on being in sync since peers
+// are banned while catching up as well.
+func (srpc *Handlers) HandleLocalStateGetPeerBans(ctx context.Context, req *pb.PeerBansRequest) (*pb.PeerBansResponse, error) {
+	srpc.logger.Debugf("HandleLocalStateGetPeerBans: %v", req)
+	result := &pb.PeerBansResponse{}
+	for _, ban := range srpc.Peers.Bans() {
+		result.Bans = append(result.Bans, &pb.PeerBansResponse_Ban{
+			Identity: ban.Identity,
+			Addr:     ban.Addr,
+			Reason:   ban.Reason,
+			Until:    uint64(ban.Until.Unix()),
+		})
+	}
+	return result, nil
+}
+
+// UnixOrZero returns the unix time of t or zero if t is not set
+func UnixOrZero(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.Unix())
+}
+
+// bandwidthList converts the traffic per protocol ordered by protocol
+func bandwidthList(bw map[types.Protocol]types.Bandwidth) []*pb.PeerInfoResponse_Bandwidth {
+	protocols := []types.Protocol{}
+	for p := range bw {
+		protocols = append(protocols, p)
+	}
+	sort.Slice(protocols, func(i, j int) bool { return protocols[i] < protocols[j] })
+	out := []*pb.PeerInfoResponse_Bandwidth{}
+	for _, p := range protocols {
+		out = append(out, bandwidthMsg(p.String(), bw[p]))
+	}
+	return out
+}
+
+func bandwidthMsg(name string, bw types.Bandwidth) *pb.PeerInfoResponse_Bandwidth {
+	return &pb.PeerInfoResponse_Bandwidth{
+		Protocol:      name,
+		BytesSent:     bw.BytesSent,
+		BytesReceived: bw.BytesReceived,
+	}
+}
+
+// HandleLocalStateGetPeerInfo is not gated on being in sync since peering
+// problems are most likely to be debugged while a node is out of sync.
+func (srpc *Handlers) HandleLocalStateGetPeerInfo(ctx context.Context, req *pb.PeerInfoRequest) (*pb.PeerInfoResponse, error) {
+	srpc.logger.Debugf("HandleLocalStateGetPeerInfo: %v", req)
+	result := &pb.PeerInfoResponse{}
+	actives, inactives := srpc.Peers.PeerInfo()
+	for _, p := range actives {
+		active := &pb.PeerInfoResponse_Active{
+			Identity:     p.Identity,
+			Addr:         p.Addr,
+			Inbound:      p.Inbound,
+			ProtoVersion: uint32(p.ProtoVersion),
+			ChainID:      uint32(p.ChainID),
+			LastGossip:   UnixOrZero(p.LastGossip),
+			Score:        int64(p.Score),
+			Bandwidth:    bandwidthList(p.Bandwidth),
+			ClientStream: bandwidthMsg("client", p.ClientStream),
+			ServerStream: bandwidthMsg("server", p.ServerStream),
+		}
+		if !p.Connected.IsZero() {
+			active.ConnectedSeconds = uint64(time.Since(p.Connected).Seconds())
+		}
+		for _, t := range p.Traffic {
+			active.Traffic = append(active.Traffic, &pb.PeerInfoResponse_Traffic{
+				Method:        t.Method,
+				MsgsSent:      t.MsgsSent,
+				MsgsReceived:  t.MsgsReceived,
+				BytesSent:     t.BytesSent,
+				BytesReceived: t.BytesReceived,
+			})
+		}
+		result.Actives = append(result.Actives, active)
+	}
+	for _, p := range inactives {
+		result.Inactives = append(result.Inactives, &pb.PeerInfoResponse_Inactive{
+			Identity:      p.Identity,
+			Addr:          p.Addr,
+			CooldownUntil: UnixOrZero(p.CooldownUntil),
+		})
+	}
+	result.Totals = bandwidthList(srpc.Peers.Bandwidth())
+	return result, nil
+}
+
+// HandleLocalStateGetTaskRuns is not gated on being in sync since tasks
+// run against Ethereum, which does not depend on the sync of this node.
+func (srpc *Handlers) HandleLocalStateGetTaskRuns(ctx context.Context, req *pb.TaskRunsRequest) (*pb.TaskRunsResponse, error) {
+	srpc.logger.Debugf("HandleLocalStateGetTaskRuns: %v", req)
+	runs := []*pb.TaskRunsResponse_Run{}
+	if req.Key != "" {
+		run, present := srpc.Tasks.FindRun(req.Key)
+		if !present {
+			return nil, fmt.Errorf("task run %q not found", req.Key)
+		}
+		runs = append(runs, run)
+	} else {
+		runs = srpc.Tasks.Runs()
+		sort.Slice(runs, func(i, j int) bool { return runs[i].Key < runs[j].Key })
+	}
+	result := &pb.TaskRunsResponse{}
+	for _, run := range runs {
+		if req.Status != "" && run.Status != req.Status {
+			continue
+		}
+		result.Runs = append(result.Runs, run)
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package localrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	pb "github.com/MadBase/MadNet/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTaskRuns map[string]*pb.TaskRunsResponse_Run
+
+func (runs testTaskRuns) Runs() []*pb.TaskRunsResponse_Run {
+	out := []*pb.TaskRunsResponse_Run{}
+	for _, run := range runs {
+		out = append(out, run)
+	}
+	return out
+}
+
+func (runs testTaskRuns) FindRun(key string) (*pb.TaskRunsResponse_Run, bool) {
+	run, present := runs[key]
+	return run, present
+}
+
+func TestHandleLocalStateGetTaskRuns(t *testing.T) {
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+
+	runs := testTaskRuns{
+		"snapshot-3": {
+			Key:      "snapshot-3",
+			Name:     "tasks.SnapshotTask",
+			Status:   "DeadLetter",
+			Reason:   "gave up after 1 attempts",
+			Attempts: []*pb.TaskRunsResponse_Attempt{{Block: 12, Err: "failed to take snapshot"}}},
+		"snapshot-4": {Key: "snapshot-4", Status: "Succeeded"},
+	}
+	srpc := &Handlers{Tasks: runs, logger: logging.GetLogger(constants.LoggerLocalRPC)}
+
+	resp, err := srpc.HandleLocalStateGetTaskRuns(ctx, &pb.TaskRunsRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(resp.Runs))
+	assert.Equal(t, "snapshot-3", resp.Runs[0].Key)
+	assert.Equal(t, "snapshot-4", resp.Runs[1].Key)
+
+	resp, err = srpc.HandleLocalStateGetTaskRuns(ctx, &pb.TaskRunsRequest{Status: "DeadLetter"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Runs))
+	assert.Equal(t, "snapshot-3", resp.Runs[0].Key)
+
+	resp, err = srpc.HandleLocalStateGetTaskRuns(ctx, &pb.TaskRunsRequest{Key: "snapshot-3"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Runs))
+	run := resp.Runs[0]
+	assert.Equal(t, "DeadLetter", run.Status)
+	assert.Equal(t, "gave up after 1 attempts", run.Reason)
+	assert.Equal(t, "failed to take snapshot", run.Attempts[0].Err)
+
+	resp, err = srpc.HandleLocalStateGetTaskRuns(ctx, &pb.TaskRunsRequest{Key: "snapshot-4", Status: "DeadLetter"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(resp.Runs))
+
+	_, err = srpc.HandleLocalStateGetTaskRuns(ctx, &pb.TaskRunsRequest{Key: "snapshot-5"})
+	assert.NotNil(t, err)
+}
@@ -105,25 +105,24 @@ func (ps *activePeerStore) len() int {
 	return len(ps.store)
 }
 
-// random returns a random active peer
-func (ps *activePeerStore) random() (string, bool) {
+// random returns a random active peer whose identity is not in exclude
+func (ps *activePeerStore) random(exclude map[string]bool) (string, bool) {
 	ps.RLock()
 	defer ps.RUnlock()
-	if len(ps.store) == 0 {
+	addrs := []string{}
+	for _, v := range ps.store {
+		if !exclude[v.NodeAddr().Identity()] {
+			addrs = append(addrs, v.NodeAddr().P2PAddr())
+		}
+	}
+	if len(addrs) == 0 {
 		return "", false
 	}
-	i := 0
-	index, err := randomElement(len(ps.store))
+	index, err := randomElement(len(addrs))
 	if err != nil {
 		return "", false
 	}
-	for _, v := range ps.store {
-		if i == index {
-			return v.NodeAddr().P2PAddr(), true
-		}
-		i++
-	}
-	panic("unreachable")
+	return addrs[index], true
 }
 
 // random returns a random active peer
@@ -0,0 +1,289 @@
+package peering
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/constants/dbprefix"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// addrSource records how the local node learned about a peer
+type addrSource uint8
+
+// These types designate the source of an address book entry.
+// If this value is sourceBootnode, the peer was returned by a bootnode.
+// If this value is sourceGetPeers, the peer was returned by another peer.
+// If this value is sourceInbound, the peer connected to the local node.
+const (
+	sourceBootnode = addrSource(iota + 1)
+	sourceGetPeers
+	sourceInbound
+)
+
+func (as addrSource) String() string {
+	switch as {
+	case sourceBootnode:
+		return "bootnode"
+	case sourceGetPeers:
+		return "getPeers"
+	case sourceInbound:
+		return "inbound"
+	default:
+		return "unknown"
+	}
+}
+
+// addrBookEntry is a peer known to the local node
+type addrBookEntry struct {
+	addr      interfaces.NodeAddr
+	source    addrSource
+	lastSeen  time.Time
+	successes uint32
+	failures  uint32
+}
+
+func (e *addrBookEntry) MarshalBinary() []byte {
+	out := []byte{byte(e.source)}
+	out = append(out, utils.MarshalInt64(e.lastSeen.Unix())...)
+	out = append(out, utils.MarshalUint32(e.successes)...)
+	out = append(out, utils.MarshalUint32(e.failures)...)
+	out = append(out, []byte(e.addr.P2PAddr())...)
+	return out
+}
+
+func (e *addrBookEntry) UnmarshalBinary(v []byte) error {
+	if len(v) < 17 {
+		return errors.New("invalid address book entry")
+	}
+	addr, err := (*transport.NodeAddr).Unmarshal(nil, string(v[17:]))
+	if err != nil {
+		return err
+	}
+	lastSeen, _ := utils.UnmarshalInt64(v[1:9])
+	e.source = addrSource(v[0])
+	e.lastSeen = time.Unix(lastSeen, 0)
+	e.successes, _ = utils.UnmarshalUint32(v[9:13])
+	e.failures, _ = utils.UnmarshalUint32(v[13:17])
+	e.addr = addr
+	return nil
+}
+
+// stale returns true if the entry should be dropped from the book
+func (e *addrBookEntry) stale(now time.Time) bool {
+	if now.Sub(e.lastSeen) > constants.AddrBookMaxAge {
+		return true
+	}
+	return e.failures >= e.successes+constants.AddrBookMaxFailures
+}
+
+// addrBook persists the peers the local node has learned about so that a
+// restarted node can rejoin the network without the bootnodes. Entries are
+// written through to the database if it is set.
+type addrBook struct {
+	sync.Mutex
+	logger   *logrus.Logger
+	database *badger.DB
+	entries  map[string]*addrBookEntry
+}
+
+func newAddrBook(logger *logrus.Logger, database *badger.DB) (*addrBook, error) {
+	ab := &addrBook{
+		logger:   logger,
+		database: database,
+		entries:  make(map[string]*addrBookEntry),
+	}
+	if err := ab.load(); err != nil {
+		return nil, err
+	}
+	return ab, nil
+}
+
+func (ab *addrBook) makeKey(identity string) []byte {
+	key := dbprefix.PrefixPeerAddr()
+	key = append(key, []byte(identity)...)
+	return key
+}
+
+// load reads the persisted entries and drops the stale ones
+func (ab *addrBook) load() error {
+	if ab.database == nil {
+		return nil
+	}
+	now := time.Now()
+	stale := [][]byte{}
+	err := ab.database.View(func(txn *badger.Txn) error {
+		prefix := dbprefix.PrefixPeerAddr()
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			itm := iter.Item()
+			key := itm.KeyCopy(nil)
+			value, err := itm.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			e := &addrBookEntry{}
+			if err := e.UnmarshalBinary(value); err != nil {
+				utils.DebugTrace(ab.logger, err)
+				stale = append(stale, key)
+				continue
+			}
+			if e.stale(now) {
+				stale = append(stale, key)
+				continue
+			}
+			ab.entries[e.addr.Identity()] = e
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return ab.database.Update(func(txn *badger.Txn) error {
+		for _, key := range stale {
+			if err := utils.DeleteValue(txn, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (ab *addrBook) persist(e *addrBookEntry) {
+	if ab.database == nil {
+		return
+	}
+	err := ab.database.Update(func(txn *badger.Txn) error {
+		return utils.SetValue(txn, ab.makeKey(e.addr.Identity()), e.MarshalBinary())
+	})
+	if err != nil {
+		utils.DebugTrace(ab.logger, err)
+	}
+}
+
+func (ab *addrBook) remove(identity string) {
+	delete(ab.entries, identity)
+	if ab.database == nil {
+		return
+	}
+	err := ab.database.Update(func(txn *badger.Txn) error {
+		return utils.DeleteValue(txn, ab.makeKey(identity))
+	})
+	if err != nil {
+		utils.DebugTrace(ab.logger, err)
+	}
+}
+
+// evict drops the least recently seen entry
+func (ab *addrBook) evict() {
+	var oldest *addrBookEntry
+	for _, e := range ab.entries {
+		if oldest == nil || e.lastSeen.Before(oldest.lastSeen) {
+			oldest = e
+		}
+	}
+	if oldest != nil {
+		ab.remove(oldest.addr.Identity())
+	}
+}
+
+// learned records a peer the local node was told about or was contacted
+// by. The source of an existing entry is kept.
+func (ab *addrBook) learned(addr interfaces.NodeAddr, source addrSource) {
+	ab.Lock()
+	defer ab.Unlock()
+	e, ok := ab.entries[addr.Identity()]
+	if !ok {
+		if len(ab.entries) >= constants.AddrBookMaxSize {
+			ab.evict()
+		}
+		e = &addrBookEntry{source: source}
+		ab.entries[addr.Identity()] = e
+	}
+	e.addr = addr
+	e.lastSeen = time.Now()
+	ab.persist(e)
+}
+
+// succeeded records a connection to a peer
+func (ab *addrBook) succeeded(addr interfaces.NodeAddr, source addrSource) {
+	ab.Lock()
+	defer ab.Unlock()
+	e, ok := ab.entries[addr.Identity()]
+	if !ok {
+		if len(ab.entries) >= constants.AddrBookMaxSize {
+			ab.evict()
+		}
+		e = &addrBookEntry{source: source}
+		ab.entries[addr.Identity()] = e
+	}
+	e.addr = addr
+	e.lastSeen = time.Now()
+	e.successes++
+	ab.persist(e)
+}
+
+// failed records a failed dial to a peer
+func (ab *addrBook) failed(addr interfaces.NodeAddr) {
+	ab.Lock()
+	defer ab.Unlock()
+	e, ok := ab.entries[addr.Identity()]
+	if !ok {
+		return
+	}
+	e.failures++
+	if e.stale(time.Now()) {
+		ab.remove(addr.Identity())
+		return
+	}
+	ab.persist(e)
+}
+
+// prune drops the stale entries
+func (ab *addrBook) prune() {
+	ab.Lock()
+	defer ab.Unlock()
+	now := time.Now()
+	for id, e := range ab.entries {
+		if e.stale(now) {
+			ab.remove(id)
+		}
+	}
+}
+
+// addrs returns the known peers, most recently seen first
+func (ab *addrBook) addrs() []interfaces.NodeAddr {
+	ab.Lock()
+	defer ab.Unlock()
+	entries := make([]*addrBookEntry, 0, len(ab.entries))
+	for _, e := range ab.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastSeen.After(entries[j].lastSeen)
+	})
+	out := make([]interfaces.NodeAddr, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.addr)
+	}
+	return out
+}
+
+func (ab *addrBook) len() int {
+	ab.Lock()
+	defer ab.Unlock()
+	return len(ab.entries)
+}
@@ -0,0 +1,128 @@
+package peering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/utils"
+)
+
+func TestAddrBookPersist(t *testing.T) {
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	database, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.GetLogger(constants.LoggerPeerMan)
+	ab, err := newAddrBook(logger, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	na1, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	na2, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.learned(na1, sourceBootnode)
+	ab.succeeded(na2, sourceInbound)
+	ab.failed(na1)
+
+	// entries survive a restart
+	ab2, err := newAddrBook(logger, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ab2.len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", ab2.len())
+	}
+	e1 := ab2.entries[na1.Identity()]
+	if e1 == nil || e1.source != sourceBootnode || e1.failures != 1 || e1.successes != 0 {
+		t.Fatalf("bad entry: %+v", e1)
+	}
+	if e1.addr.P2PAddr() != na1.P2PAddr() {
+		t.Fatal("address not restored")
+	}
+	e2 := ab2.entries[na2.Identity()]
+	if e2 == nil || e2.source != sourceInbound || e2.successes != 1 {
+		t.Fatalf("bad entry: %+v", e2)
+	}
+}
+
+func TestAddrBookAging(t *testing.T) {
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	database, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.GetLogger(constants.LoggerPeerMan)
+	ab, err := newAddrBook(logger, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	na1, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	na2, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.learned(na1, sourceGetPeers)
+	ab.learned(na2, sourceGetPeers)
+
+	// peers that keep failing are dropped
+	for i := 0; i < constants.AddrBookMaxFailures; i++ {
+		ab.failed(na1)
+	}
+	if ab.len() != 1 {
+		t.Fatal("failing peer not dropped")
+	}
+
+	// peers that have not been seen for too long are dropped
+	ab.Lock()
+	ab.entries[na2.Identity()].lastSeen = time.Now().Add(-constants.AddrBookMaxAge - time.Minute)
+	ab.persist(ab.entries[na2.Identity()])
+	ab.Unlock()
+	ab2, err := newAddrBook(logger, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ab2.len() != 0 {
+		t.Fatal("stale peer loaded")
+	}
+	ab.prune()
+	if ab.len() != 0 {
+		t.Fatal("stale peer not pruned")
+	}
+}
+
+func TestAddrBookOrder(t *testing.T) {
+	logger := logging.GetLogger(constants.LoggerPeerMan)
+	ab, err := newAddrBook(logger, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	na1, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	na2, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.learned(na1, sourceGetPeers)
+	ab.learned(na2, sourceGetPeers)
+	ab.entries[na1.Identity()].lastSeen = time.Now().Add(-time.Hour)
+	addrs := ab.addrs()
+	if len(addrs) != 2 || addrs[0].Identity() != na2.Identity() {
+		t.Fatal("addresses not ordered by last seen")
+	}
+}
@@ -0,0 +1,50 @@
+package peering
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowlistPeering(t *testing.T) {
+	setTestBootNode(t)
+	nodeKey, _ := newTestKey(t)
+	allowedKey, allowedIdentity := newTestKey(t)
+	otherKey, otherIdentity := newTestKey(t)
+	node := newTestNode(t, nodeKey, nil, nil, []string{allowedIdentity})
+	allowed := newTestNode(t, allowedKey, nil, nil, nil)
+	other := newTestNode(t, otherKey, nil, nil, nil)
+
+	// peers that are not on the allowlist can not connect and are not dialed
+	other.dialP2P(node.addr)
+	node.dialP2P(other.addr)
+	allowed.dialP2P(node.addr)
+	waitActive(t, node, allowed.addr)
+	time.Sleep(500 * time.Millisecond)
+	if node.active.contains(other.addr) || other.active.contains(node.addr) {
+		t.Fatal("peer that is not on the allowlist connected")
+	}
+	if !node.discoverable(allowed.addr) || node.discoverable(other.addr) {
+		t.Fatal("discovery not restricted to the allowlist")
+	}
+
+	// a reload disconnects the peers that are no longer allowed
+	if err := node.ReloadAllowlist([]string{otherIdentity}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if node.active.contains(allowed.addr) {
+		t.Fatal("peer removed from the allowlist still connected")
+	}
+	node.dialP2P(other.addr)
+	waitActive(t, node, other.addr)
+
+	// discovered peers must be at an allowed host
+	if err := node.ReloadAllowlist(nil, []string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	if node.discoverable(other.addr) {
+		t.Fatal("peer at a host that is not allowed discoverable")
+	}
+	if !node.allowed(other.addr) {
+		t.Fatal("host allowlist applied to connections")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"errors"
 
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/interfaces"
 	"google.golang.org/grpc"
 )
@@ -17,6 +18,10 @@ import (
 // be bound to the *grpc.ClientConn.
 type clientHandler struct {
 	closeChan chan struct{}
+	// peerStats records the traffic of the connections if it is set
+	peerStats *peerStatsStore
+	// compress enables compression towards peers that support it
+	compress bool
 }
 
 // Close will block further outbound dialing
@@ -48,14 +53,22 @@ func (rpcch *clientHandler) HandleConnection(p2pconn interfaces.P2PConn) (*grpc.
 			return nil, errors.New("connection is nil")
 		}
 	}
-	conn, err := grpc.Dial(
-		p2pconn.RemoteAddr().String(), // THIS WILL NEVER BE DIALED
-		grpc.WithTimeout(time.Second*5),
+	opts := []grpc.DialOption{
+		grpc.WithTimeout(time.Second * 5),
 		grpc.WithContextDialer(contextDialer),
 		grpc.WithInsecure(),
 		grpc.WithBlock(),
 		grpc.WithDisableRetry(),
 		grpc.WithDisableHealthCheck(),
+	}
+	if rpcch.peerStats != nil {
+		opts = append(opts, grpc.WithStatsHandler(&statsHandler{store: rpcch.peerStats, addr: p2pconn.NodeAddr()}))
+	}
+	opts = append(opts, messageSizeOptions(p2pconn)...)
+	opts = append(opts, compressionOptions(p2pconn, rpcch.compress)...)
+	conn, err := grpc.Dial(
+		p2pconn.RemoteAddr().String(), // THIS WILL NEVER BE DIALED
+		opts...,
 	)
 	if err != nil {
 		if conn != nil {
@@ -77,3 +90,15 @@ func (rpcch *clientHandler) HandleConnection(p2pconn interfaces.P2PConn) (*grpc.
 func newClientHandler() *clientHandler {
 	return &clientHandler{}
 }
+
+// messageSizeOptions returns the dial options that keep the requests sent
+// over conn within the message size the remote peer advertised and the
+// responses within the size the local node accepts. Peers that do not
+// exchange capabilities advertise no size and are sent the grpc default.
+func messageSizeOptions(conn interfaces.P2PConn) []grpc.DialOption {
+	callOpts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(constants.MaxMessageSize)}
+	if caps := conn.Capabilities(); caps != nil && caps.MaxMessageSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(int(caps.MaxMessageSize)))
+	}
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(callOpts...)}
+}
@@ -0,0 +1,54 @@
+package peering
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/MadBase/MadNet/constants"
+	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMessageSizeOptions(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.MaxRecvMsgSize(constants.MaxMessageSize))
+	pb.RegisterP2PServer(srv, &syncServer{leaves: make([][]byte, 1024)})
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	request := func(caps *types.Capabilities, numKeys int) error {
+		opts := []grpc.DialOption{grpc.WithInsecure(), grpc.WithBlock()}
+		opts = append(opts, messageSizeOptions(&capsConn{caps: caps})...)
+		conn, err := grpc.Dial(l.Addr().String(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		keys := make([][]byte, numKeys)
+		for i := range keys {
+			keys[i] = make([]byte, 4)
+			binary.BigEndian.PutUint32(keys[i], uint32(i))
+		}
+		_, err = pb.NewP2PClient(conn).GetMinedTxs(context.Background(), &pb.GetMinedTxsRequest{TxHashes: keys})
+		return err
+	}
+
+	small := &types.Capabilities{MaxMessageSize: 256}
+	if err := request(small, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := request(small, 1024); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("request larger than the peer accepts was sent: %v", err)
+	}
+	if err := request(types.BaseCapabilities(), 1024); err != nil {
+		t.Fatalf("request to a peer without a message size failed: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package peering
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/golang/snappy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+func init() {
+	encoding.RegisterCompressor(newSnappyCompressor())
+}
+
+const (
+	// payloads smaller than minCompressSize are sent raw since the savings
+	// on small messages do not pay for the overhead
+	minCompressSize = 256
+	// maxDecompressedSize bounds the size of a decompressed payload to the
+	// default grpc receive limit
+	maxDecompressedSize = 4 * 1024 * 1024
+	// every payload starts with a flag byte that marks if the remainder is
+	// raw or a snappy block
+	flagRaw    byte = 0
+	flagSnappy byte = 1
+)
+
+// errDecompress is returned for payloads that can not be decompressed
+var errDecompress = errors.New("invalid compressed payload")
+
+// snappyCompressor is a grpc compressor that encodes each message as a single
+// snappy block. Every node registers it so that it can always decode
+// compressed payloads, but a node only compresses the requests it sends to
+// peers that advertise support during the handshake. The server answers a
+// compressed request with a compressed response.
+type snappyCompressor struct {
+	writers sync.Pool
+}
+
+func newSnappyCompressor() *snappyCompressor {
+	c := &snappyCompressor{}
+	c.writers.New = func() interface{} {
+		return &snappyWriter{pool: &c.writers}
+	}
+	return c
+}
+
+// Name See docs for encoding.Compressor
+func (c *snappyCompressor) Name() string {
+	return constants.CompressionSnappy
+}
+
+// Compress See docs for encoding.Compressor
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	sw := c.writers.Get().(*snappyWriter)
+	sw.w = w
+	return sw, nil
+}
+
+// Decompress See docs for encoding.Compressor
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	payload, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 || len(payload) > maxDecompressedSize {
+		return nil, errDecompress
+	}
+	switch payload[0] {
+	case flagRaw:
+		return bytes.NewReader(payload[1:]), nil
+	case flagSnappy:
+		n, err := snappy.DecodedLen(payload[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n > maxDecompressedSize {
+			return nil, errDecompress
+		}
+		out, err := snappy.Decode(make([]byte, n), payload[1:])
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(out), nil
+	default:
+		return nil, errDecompress
+	}
+}
+
+// snappyWriter buffers a message and compresses it on Close
+type snappyWriter struct {
+	w    io.Writer
+	buf  bytes.Buffer
+	enc  []byte
+	pool *sync.Pool
+}
+
+// Write See docs for io.Writer
+func (sw *snappyWriter) Write(p []byte) (int, error) {
+	return sw.buf.Write(p)
+}
+
+// Close writes the message to the underlying writer and returns the writer
+// to the pool. Messages that are small or do not compress are sent raw.
+func (sw *snappyWriter) Close() error {
+	defer func() {
+		sw.w = nil
+		sw.buf.Reset()
+		sw.pool.Put(sw)
+	}()
+	raw := sw.buf.Bytes()
+	if len(raw) >= minCompressSize {
+		sw.enc = snappy.Encode(sw.enc[:cap(sw.enc)], raw)
+		if len(sw.enc) < len(raw) {
+			return writeFlagged(sw.w, flagSnappy, sw.enc)
+		}
+	}
+	return writeFlagged(sw.w, flagRaw, raw)
+}
+
+func writeFlagged(w io.Writer, flag byte, payload []byte) error {
+	if _, err := w.Write([]byte{flag}); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// compressionOptions returns the dial options that compress the requests
+// sent over conn when both the local node and the remote peer support it
+func compressionOptions(conn interfaces.P2PConn, enabled bool) []grpc.DialOption {
+	if !enabled || !conn.Capabilities().SupportsCompression(constants.CompressionSnappy) {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.UseCompressor(constants.CompressionSnappy))}
+}
@@ -0,0 +1,257 @@
+package peering
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/application/objs"
+	"github.com/MadBase/MadNet/application/objs/uint256"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/interfaces"
+	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestSnappyCompressor(t *testing.T) {
+	c := encoding.GetCompressor(constants.CompressionSnappy)
+	if c == nil {
+		t.Fatal("snappy compressor not registered")
+	}
+	for i, msg := range [][]byte{bytes.Repeat([]byte("madnet"), 1000), []byte("small"), bytes.Repeat([]byte("madnet"), 1000)} {
+		buf := &bytes.Buffer{}
+		w, err := c.Compress(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// small payloads are sent raw behind the flag byte
+		if len(msg) >= minCompressSize && buf.Len() >= len(msg) {
+			t.Fatalf("payload %d not compressed", i)
+		}
+		if len(msg) < minCompressSize && buf.Len() != len(msg)+1 {
+			t.Fatalf("payload %d not sent raw", i)
+		}
+		r, err := c.Decompress(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, msg) {
+			t.Fatalf("payload %d changed by compression", i)
+		}
+	}
+}
+
+type capsConn struct {
+	interfaces.P2PConn
+	caps *types.Capabilities
+}
+
+func (c *capsConn) Capabilities() *types.Capabilities {
+	return c.caps
+}
+
+func TestCompressionOptions(t *testing.T) {
+	snappy := &capsConn{caps: &types.Capabilities{Compression: []string{constants.CompressionSnappy}}}
+	if len(compressionOptions(snappy, true)) != 1 {
+		t.Fatal("compression not used when both peers support it")
+	}
+	if len(compressionOptions(snappy, false)) != 0 {
+		t.Fatal("compression used when disabled locally")
+	}
+	if len(compressionOptions(&capsConn{caps: types.BaseCapabilities()}, true)) != 0 {
+		t.Fatal("compression used with a peer that does not support it")
+	}
+}
+
+// syncServer serves the state leaves of a snapshot
+type syncServer struct {
+	pb.UnimplementedP2PServer
+	leaves [][]byte
+}
+
+func (s *syncServer) GetSnapShotStateData(ctx context.Context, r *pb.GetSnapShotStateDataRequest) (*pb.GetSnapShotStateDataResponse, error) {
+	return &pb.GetSnapShotStateDataResponse{Data: s.leaves[binary.BigEndian.Uint32(r.Key)]}, nil
+}
+
+// GetMinedTxs stands in for the batched responses and returns the leaves at
+// the requested indices
+func (s *syncServer) GetMinedTxs(ctx context.Context, r *pb.GetMinedTxsRequest) (*pb.GetMinedTxsResponse, error) {
+	txs := make([][]byte, len(r.TxHashes))
+	for i, key := range r.TxHashes {
+		txs[i] = s.leaves[binary.BigEndian.Uint32(key)]
+	}
+	return &pb.GetMinedTxsResponse{Txs: txs}, nil
+}
+
+// throttledConn delays writes to emulate a link of rate bytes per second and
+// counts the bytes written
+type throttledConn struct {
+	net.Conn
+	rate    int
+	written *uint64
+}
+
+func (tc *throttledConn) Write(b []byte) (int, error) {
+	time.Sleep(time.Duration(len(b)) * time.Second / time.Duration(tc.rate))
+	atomic.AddUint64(tc.written, uint64(len(b)))
+	return tc.Conn.Write(b)
+}
+
+type throttledListener struct {
+	net.Listener
+	rate    int
+	written *uint64
+}
+
+func (tl *throttledListener) Accept() (net.Conn, error) {
+	conn, err := tl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &throttledConn{Conn: conn, rate: tl.rate, written: tl.written}, nil
+}
+
+// makeLeaves returns n value stores owned by a few accounts as they are
+// stored in the state trie
+func makeLeaves(b *testing.B, n int) [][]byte {
+	owners := make([]*objs.ValueStoreOwner, 16)
+	for i := range owners {
+		acct := make([]byte, constants.OwnerLen)
+		if _, err := rand.Read(acct); err != nil {
+			b.Fatal(err)
+		}
+		owners[i] = &objs.ValueStoreOwner{}
+		owners[i].New(acct, constants.CurveSecp256k1)
+	}
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		val, err := new(uint256.Uint256).FromUint64(uint64(i + 1))
+		if err != nil {
+			b.Fatal(err)
+		}
+		idx := make([]byte, 4)
+		binary.BigEndian.PutUint32(idx, uint32(i))
+		vs := &objs.ValueStore{
+			VSPreImage: &objs.VSPreImage{
+				ChainID: 1,
+				Value:   val,
+				Owner:   owners[i%len(owners)],
+			},
+			TxHash: crypto.Hasher(idx),
+		}
+		leaves[i], err = vs.MarshalBinary()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return leaves
+}
+
+// benchmarkTransport downloads the state leaves of a snapshot with the same
+// parallelism as fast sync over a link of 10MB/s. The leaves are requested
+// one per call as fast sync does when batch is one and batch leaves per call
+// otherwise, which is the shape of the transaction and proposal downloads.
+// Only the gRPC transport is timed; the leaves are served from memory and
+// are neither verified nor stored as they are in a real fast sync.
+func benchmarkTransport(b *testing.B, compress bool, batch int) {
+	const numLeaves = 2048
+	const workers = 8
+	const rate = 10 * 1024 * 1024
+	leaves := makeLeaves(b, numLeaves)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	written := new(uint64)
+	srv := grpc.NewServer()
+	pb.RegisterP2PServer(srv, &syncServer{leaves: leaves})
+	go srv.Serve(&throttledListener{Listener: l, rate: rate, written: written})
+	defer srv.Stop()
+
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			return &throttledConn{Conn: conn, rate: rate, written: written}, nil
+		}),
+	}
+	if compress {
+		opts = append(opts, compressionOptions(&capsConn{caps: &types.Capabilities{Compression: []string{constants.CompressionSnappy}}}, true)...)
+	}
+	conn, err := grpc.Dial(l.Addr().String(), opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewP2PClient(conn)
+
+	fetch := func(start int) error {
+		keys := [][]byte{}
+		for j := start; j < start+batch && j < numLeaves; j++ {
+			key := make([]byte, 4)
+			binary.BigEndian.PutUint32(key, uint32(j))
+			keys = append(keys, key)
+		}
+		if batch == 1 {
+			_, err := client.GetSnapShotStateData(context.Background(), &pb.GetSnapShotStateDataRequest{Key: keys[0]})
+			return err
+		}
+		_, err := client.GetMinedTxs(context.Background(), &pb.GetMinedTxsRequest{TxHashes: keys})
+		return err
+	}
+
+	b.ResetTimer()
+	atomic.StoreUint64(written, 0)
+	for i := 0; i < b.N; i++ {
+		wg := sync.WaitGroup{}
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for j := w * batch; j < numLeaves; j += workers * batch {
+					if err := fetch(j); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadUint64(written))/float64(b.N), "wireB/op")
+}
+
+func BenchmarkSnapShotTransport(b *testing.B) {
+	b.Run("uncompressed", func(b *testing.B) { benchmarkTransport(b, false, 1) })
+	b.Run("snappy", func(b *testing.B) { benchmarkTransport(b, true, 1) })
+}
+
+func BenchmarkBatchTransport(b *testing.B) {
+	b.Run("uncompressed", func(b *testing.B) { benchmarkTransport(b, false, 64) })
+	b.Run("snappy", func(b *testing.B) { benchmarkTransport(b, true, 64) })
+}
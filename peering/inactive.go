@@ -14,6 +14,10 @@ type inactivePeerStore struct {
 	// map of peer p2p addr and time last added
 	store    map[string]interfaces.NodeAddr
 	cooldown map[string]uint64
+	// map of peer identity and the time the cooldown ends
+	cooldownUntil map[string]time.Time
+	// address of the peers in cooldown
+	cooldownAddr map[string]interfaces.NodeAddr
 	// close broadcast channel
 	closeChan chan struct{}
 	// protect double closure
@@ -48,21 +52,36 @@ func (ps *inactivePeerStore) del(c interfaces.NodeAddr) {
 	}
 }
 
+// retain drops the peers for which keep returns false
+func (ps *inactivePeerStore) retain(keep func(interfaces.NodeAddr) bool) {
+	ps.Lock()
+	defer ps.Unlock()
+	for ident, addr := range ps.store {
+		if !keep(addr) {
+			delete(ps.store, ident)
+		}
+	}
+}
+
 // delete a peer
 func (ps *inactivePeerStore) backoff(c interfaces.NodeAddr) {
 	pid := makePid()
 	ps.cooldown[c.Identity()] = pid
+	// Random delay between 20 and 40 seconds
+	// Note: 20 > 0 so no error will occur
+	delay, _ := randomElement(20)
+	delay = delay + 20
+	ps.cooldownUntil[c.Identity()] = time.Now().Add(time.Second * time.Duration(delay))
+	ps.cooldownAddr[c.Identity()] = c
 	go func() {
-		// Random delay between 20 and 40 seconds
-		// Note: 20 > 0 so no error will occur
-		delay, _ := randomElement(20)
-		delay = delay + 20
 		time.Sleep(time.Second * time.Duration(delay))
 		ps.Lock()
 		defer ps.Unlock()
 		pidLater := ps.cooldown[c.Identity()]
 		if pidLater == pid {
 			delete(ps.cooldown, c.Identity())
+			delete(ps.cooldownUntil, c.Identity())
+			delete(ps.cooldownAddr, c.Identity())
 		}
 	}()
 }
@@ -91,26 +110,26 @@ func (ps *inactivePeerStore) randomPop() (interfaces.NodeAddr, bool) {
 	panic(fmt.Sprintf("unreachable index with %d nodes: %d", len(ps.store), index))
 }
 
-// get a random peer. intended to provide random peers when a remote
-// peer performs a discovery dial against the local node
-func (ps *inactivePeerStore) random() (string, bool) {
+// get a random peer whose identity is not in exclude. intended to provide
+// random peers when a remote peer performs a discovery dial against the
+// local node
+func (ps *inactivePeerStore) random(exclude map[string]bool) (string, bool) {
 	ps.RLock()
 	defer ps.RUnlock()
-	if len(ps.store) == 0 {
+	addrs := []string{}
+	for _, v := range ps.store {
+		if !exclude[v.Identity()] {
+			addrs = append(addrs, v.P2PAddr())
+		}
+	}
+	if len(addrs) == 0 {
 		return "", false
 	}
-	index, err := randomElement(len(ps.store))
+	index, err := randomElement(len(addrs))
 	if err != nil {
 		return "", false
 	}
-	i := 0
-	for _, v := range ps.store {
-		if i == index {
-			return v.P2PAddr(), true
-		}
-		i++
-	}
-	panic(fmt.Sprintf("unreachable index with %d nodes: %d", len(ps.store), index))
+	return addrs[index], true
 }
 
 func (ps *inactivePeerStore) len() int {
@@ -118,3 +137,36 @@ func (ps *inactivePeerStore) len() int {
 	defer ps.RUnlock()
 	return len(ps.store)
 }
+
+// InactivePeerInfo describes a known peer that is not connected. Peers in
+// cooldown may not be added back to the store until CooldownUntil.
+type InactivePeerInfo struct {
+	Addr          string
+	Identity      string
+	CooldownUntil time.Time
+}
+
+// info returns the peers in the store and the peers in cooldown
+func (ps *inactivePeerStore) info() []*InactivePeerInfo {
+	ps.RLock()
+	defer ps.RUnlock()
+	out := []*InactivePeerInfo{}
+	for id, c := range ps.store {
+		out = append(out, &InactivePeerInfo{
+			Addr:          c.P2PAddr(),
+			Identity:      id,
+			CooldownUntil: ps.cooldownUntil[id],
+		})
+	}
+	for id, c := range ps.cooldownAddr {
+		if _, ok := ps.store[id]; ok {
+			continue
+		}
+		out = append(out, &InactivePeerInfo{
+			Addr:          c.P2PAddr(),
+			Identity:      id,
+			CooldownUntil: ps.cooldownUntil[id],
+		})
+	}
+	return out
+}
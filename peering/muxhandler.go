@@ -4,6 +4,7 @@ import (
 	"net"
 	"sync"
 
+	"github.com/MadBase/MadNet/config"
 	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/interfaces"
 	"github.com/MadBase/MadNet/logging"
@@ -22,6 +23,10 @@ type MuxHandler struct {
 	ch        *clientHandler
 	sh        *ServerHandler
 	logger    *logrus.Logger
+	peerStats *peerStatsStore
+	// privatePeers are the identities whose consensus gossip is prioritized.
+	// It is set before the handler is used and not modified afterwards.
+	privatePeers map[string]bool
 }
 
 // Close will shutdown the server handler.
@@ -44,6 +49,7 @@ func (rpcm *MuxHandler) Close() error {
 // Both the client and the server side connections may be shut down using the
 // original P2PMuxConn Close method.
 func (rpcm *MuxHandler) HandleConnection(conn interfaces.P2PMuxConn) (interfaces.P2PClient, error) {
+	rpcm.peerStats.connected(conn.NodeAddr().Identity())
 	switch conn.Initiator() {
 	case types.SelfInitiatedConnection:
 		return rpcm.gRPCclientHandler(conn)
@@ -74,12 +80,7 @@ func (rpcm *MuxHandler) gRPCserverHandler(conn interfaces.P2PMuxConn) (interface
 		nodeAddr:     conn.NodeAddr(),
 		conn:         conn,
 	}
-	c.consensusQueue, err = newMsgQueue(constants.ConsensusMsgQSize, constants.ConsensusMsgQWorkers, c)
-	if err != nil {
-		return nil, err
-	}
-	c.txQueue, err = newMsgQueue(constants.TxMsgQSize, constants.TxMsgQWorkers, c)
-	if err != nil {
+	if err := rpcm.setupQueues(c); err != nil {
 		return nil, err
 	}
 	return c, nil
@@ -105,25 +106,42 @@ func (rpcm *MuxHandler) gRPCclientHandler(conn interfaces.P2PMuxConn) (interface
 		nodeAddr:     conn.NodeAddr(),
 		conn:         conn,
 	}
-	c.consensusQueue, err = newMsgQueue(constants.ConsensusMsgQSize, constants.ConsensusMsgQWorkers, c)
-	if err != nil {
+	if err := rpcm.setupQueues(c); err != nil {
 		return nil, err
 	}
-	c.txQueue, err = newMsgQueue(constants.TxMsgQSize, constants.TxMsgQWorkers, c)
+	return c, nil
+}
+
+// setupQueues creates the gossip queues of a client. Private peers get a
+// larger consensus queue so that the consensus messages to and from the
+// validator are not delayed by the gossip of public peers.
+func (rpcm *MuxHandler) setupQueues(c *p2PClient) error {
+	qSize, qWorkers := constants.ConsensusMsgQSize, constants.ConsensusMsgQWorkers
+	if rpcm.privatePeers[c.nodeAddr.Identity()] {
+		qSize, qWorkers = constants.PrivatePeerMsgQSize, constants.PrivatePeerMsgQWorkers
+	}
+	var err error
+	c.consensusQueue, err = newMsgQueue(qSize, qWorkers, c)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return c, nil
+	c.txQueue, err = newMsgQueue(constants.TxMsgQSize, constants.TxMsgQWorkers, c)
+	return err
 }
 
 // NewMuxServerHandler creates a new multiplexed grpc tunneling system for
 // P2PMuxConn objects.
 func NewMuxServerHandler(logger *logrus.Logger, addr net.Addr, service interfaces.P2PServer) *MuxHandler {
-	sh := newP2PServerHandler(logger, addr, service)
+	peerStats := newPeerStatsStore()
+	sh := newP2PServerHandler(logger, addr, service, peerStats)
 	ch := newClientHandler()
+	ch.peerStats = peerStats
+	ch.compress = config.Configuration.Transport.Compression
 	return &MuxHandler{
-		ch:     ch,
-		sh:     sh,
-		logger: logger,
+		ch:           ch,
+		sh:           sh,
+		logger:       logger,
+		peerStats:    peerStats,
+		privatePeers: make(map[string]bool),
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/types"
 	"github.com/MadBase/MadNet/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -29,6 +30,12 @@ func (c *p2PClient) NodeAddr() interfaces.NodeAddr {
 	return c.nodeAddr
 }
 
+// Capabilities returns the capabilities the remote peer advertised during
+// the handshake
+func (c *p2PClient) Capabilities() *types.Capabilities {
+	return c.conn.ClientConn().Capabilities()
+}
+
 func (c *p2PClient) Do(fn func(interfaces.PeerLease) error) {
 	err := fn(c)
 	if err != nil {
@@ -2,8 +2,10 @@ package peering
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -15,9 +17,14 @@ import (
 	"github.com/MadBase/MadNet/transport"
 	"github.com/MadBase/MadNet/types"
 	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrSentryFirewallMode is returned when a node is configured with sentries
+// and firewall mode at the same time
+var ErrSentryFirewallMode = errors.New("sentries can not be used in firewall mode")
+
 // PeerManager is a self contained system for management of peering.
 // Other packages that need to send data to peers may subscribe to the
 // peer manager and be notified of active peers. This notification
@@ -38,6 +45,8 @@ type PeerManager struct {
 	transport                interfaces.P2PTransport
 	inactive                 *inactivePeerStore
 	active                   *activePeerStore
+	reputation               *reputationStore
+	addrBook                 *addrBook
 	subscribers              map[int]*PeerSubscription
 	subscriberCount          int
 	peeringCompleteThreshold int
@@ -45,11 +54,39 @@ type PeerManager struct {
 	fireWallMode             bool
 	fireWallHost             interfaces.NodeAddr
 	peeringComplete          bool
+	// transportKey signs the node record announced to bootnodes
+	transportKey string
+	// sentries are the only peers of a validator in sentry mode keyed by
+	// identity
+	sentries map[string]interfaces.NodeAddr
+	// privatePeers are the identities of the peers whose addresses are never
+	// shared with other peers. The sentries of a validator are private peers.
+	privatePeers map[string]bool
+	// allowlist restricts the peers and the discovered hosts of the node
+	allowlist *transport.Allowlist
+}
+
+// Restrictions limits the peers of a node and the peer addresses it shares
+type Restrictions struct {
+	// Sentries are the addresses of the only peers of a validator in sentry
+	// mode. Their addresses are never shared with other peers.
+	Sentries []string // config.Configuration.Transport.SentryAddresses
+	// PrivatePeers are the identities of the peers whose addresses are never
+	// shared with other peers
+	PrivatePeers []string // config.Configuration.Transport.PrivatePeers
+	// AllowedIdentities are the only identities the node connects to if not
+	// empty
+	AllowedIdentities []string // config.Configuration.Transport.Whitelist
+	// AllowedHosts are the only hosts the node uses discovered peers at if
+	// not empty
+	AllowedHosts []string // config.Configuration.Transport.WhitelistHosts
 }
 
 // NewPeerManager creates a new peer manager based on the Configuration
-// values passed to the process.
-func NewPeerManager(p2pServer interfaces.P2PServer, chainID uint32, pLimMin int, pLimMax int, fwMode bool, fwHost, listenAddr, tprivk string) (*PeerManager, error) {
+// values passed to the process. Peer bans and the address book are persisted
+// to database if it is not nil. The peers of the node and the addresses it
+// shares are limited by restrictions.
+func NewPeerManager(p2pServer interfaces.P2PServer, chainID uint32, pLimMin int, pLimMax int, fwMode bool, fwHost string, restrictions Restrictions, listenAddr, tprivk string, banThreshold int, banDuration time.Duration, database *badger.DB) (*PeerManager, error) {
 	logger := logging.GetLogger(constants.LoggerPeerMan)
 	ctx := context.Background()
 	subCtx, cf := context.WithCancel(ctx)
@@ -65,7 +102,25 @@ func NewPeerManager(p2pServer interfaces.P2PServer, chainID uint32, pLimMin int,
 		cf()
 		return nil, err
 	}
-	p2ptransport, err := transport.NewP2PTransport(logging.GetLogger(constants.LoggerTransport), types.ChainIdentifier(chainID), tprivk, port, host) // config.Configuration.Chain.ID, config.Configuration.Transport.PrivateKey
+	allowlist, err := transport.NewAllowlist(restrictions.AllowedIdentities, restrictions.AllowedHosts)
+	if err != nil {
+		utils.DebugTrace(logger, err)
+		cf()
+		return nil, err
+	}
+	p2ptransport, err := transport.NewP2PTransport(logging.GetLogger(constants.LoggerTransport), types.ChainIdentifier(chainID), tprivk, port, host, allowlist) // config.Configuration.Chain.ID, config.Configuration.Transport.PrivateKey
+	if err != nil {
+		utils.DebugTrace(logger, err)
+		cf()
+		return nil, err
+	}
+	reputation, err := newReputationStore(logger, database, banThreshold, banDuration) // config.Configuration.Transport.BanThreshold, config.Configuration.Transport.BanDuration
+	if err != nil {
+		utils.DebugTrace(logger, err)
+		cf()
+		return nil, err
+	}
+	book, err := newAddrBook(logger, database)
 	if err != nil {
 		utils.DebugTrace(logger, err)
 		cf()
@@ -91,14 +146,22 @@ func NewPeerManager(p2pServer interfaces.P2PServer, chainID uint32, pLimMin int,
 			closeOnce: sync.Once{},
 		},
 		inactive: &inactivePeerStore{
-			store:     make(map[string]interfaces.NodeAddr),
-			cooldown:  make(map[string]uint64),
-			closeChan: make(chan struct{}),
-			closeOnce: sync.Once{},
+			store:         make(map[string]interfaces.NodeAddr),
+			cooldown:      make(map[string]uint64),
+			cooldownUntil: make(map[string]time.Time),
+			cooldownAddr:  make(map[string]interfaces.NodeAddr),
+			closeChan:     make(chan struct{}),
+			closeOnce:     sync.Once{},
 		},
+		reputation:       reputation,
+		addrBook:         book,
 		mux:              &transport.P2PMux{},
 		transport:        p2ptransport,
 		p2pServerHandler: NewMuxServerHandler(logger, p2ptransport.NodeAddr(), p2pServer),
+		transportKey:     tprivk,
+		sentries:         make(map[string]interfaces.NodeAddr),
+		privatePeers:     make(map[string]bool),
+		allowlist:        allowlist,
 	}
 	pm.discServerHandler = NewDiscoveryServerHandler(logger, p2ptransport.NodeAddr(), pm)
 	if fwMode { // config.Configuration.Transport.FirewallMode
@@ -110,6 +173,26 @@ func NewPeerManager(p2pServer interfaces.P2PServer, chainID uint32, pLimMin int,
 		}
 		pm.fireWallHost = naddr
 	}
+	for _, sentry := range restrictions.Sentries {
+		naddr, err := transport.NewNodeAddr(sentry)
+		if err != nil {
+			return nil, err
+		}
+		pm.sentries[naddr.Identity()] = naddr
+		pm.privatePeers[naddr.Identity()] = true
+	}
+	if pm.sentryMode() {
+		if pm.fireWallMode {
+			return nil, ErrSentryFirewallMode
+		}
+		pm.logger.Infof("RUNNING IN SENTRY MODE WITH %d SENTRIES", len(pm.sentries))
+	}
+	for _, ident := range restrictions.PrivatePeers {
+		pm.privatePeers[ident] = true
+	}
+	for ident := range pm.privatePeers {
+		pm.p2pServerHandler.privatePeers[ident] = true
+	}
 	// make sure bootnodes parse
 	if _, err := pm.bootNodes.randomBootNode(); err != nil {
 		utils.DebugTrace(pm.logger, err)
@@ -120,6 +203,7 @@ func NewPeerManager(p2pServer interfaces.P2PServer, chainID uint32, pLimMin int,
 
 // Start launches the background loops of the peer manager
 func (ps *PeerManager) Start() {
+	ps.seedInactive()
 	ps.wg.Add(2)
 	go ps.runDiscoveryLoops()
 	go ps.acceptLoop()
@@ -132,6 +216,58 @@ func (ps *PeerManager) isMe(addr interfaces.NodeAddr) bool {
 	return addr.Identity() == ps.transport.NodeAddr().Identity()
 }
 
+// sentryMode returns true if the node only peers with its sentries
+func (ps *PeerManager) sentryMode() bool {
+	return len(ps.sentries) > 0
+}
+
+// allowed returns true if the node may connect to addr. Only the peers on
+// the allowlist are allowed and in sentry mode only the sentries.
+func (ps *PeerManager) allowed(addr interfaces.NodeAddr) bool {
+	if !ps.allowlist.AllowIdentity(addr.Identity()) {
+		return false
+	}
+	if !ps.sentryMode() {
+		return true
+	}
+	_, ok := ps.sentries[addr.Identity()]
+	return ok
+}
+
+// discoverable returns true if a peer learned through discovery may be used
+func (ps *PeerManager) discoverable(addr interfaces.NodeAddr) bool {
+	return ps.allowed(addr) && ps.allowlist.AllowHost(addr.Host())
+}
+
+// ReloadAllowlist replaces the identities and hosts of the allowlist and
+// disconnects the peers that are no longer allowed
+func (ps *PeerManager) ReloadAllowlist(identities, hosts []string) error {
+	if err := ps.allowlist.Reload(identities, hosts); err != nil {
+		return err
+	}
+	peers, _ := ps.active.getPeers()
+	ps.Lock()
+	defer ps.Unlock()
+	for _, p := range peers {
+		if !ps.allowed(p.NodeAddr()) {
+			ps.logger.Infof("Disconnecting peer %s that is no longer allowed", p.NodeAddr().P2PAddr())
+			ps.active.del(p.NodeAddr())
+		}
+	}
+	ps.inactive.retain(ps.discoverable)
+	return nil
+}
+
+// PrivatePeers returns the identities of the sentries and private peers
+func (ps *PeerManager) PrivatePeers() []string {
+	out := []string{}
+	for ident := range ps.privatePeers {
+		out = append(out, ident)
+	}
+	sort.Strings(out)
+	return out
+}
+
 // CloseChan returns a channel that is closed when the peerManager is
 // shutting down.
 func (ps *PeerManager) CloseChan() <-chan struct{} {
@@ -182,6 +318,14 @@ func (ps *PeerManager) acceptLoop() {
 			utils.DebugTrace(ps.logger, err)
 			return
 		}
+		if !ps.allowed(conn.NodeAddr()) {
+			ps.logger.Debugf("Dropping connection from %s that is not allowed", conn.NodeAddr().P2PAddr())
+			err := conn.Close()
+			if err != nil {
+				utils.DebugTrace(ps.logger, err)
+			}
+			continue
+		}
 		switch conn.Protocol() {
 		case types.P2PProtocol:
 			go ps.handleP2P(conn)
@@ -202,6 +346,9 @@ func (ps *PeerManager) handleDisc(conn interfaces.P2PConn) {
 		defer conn.Close()
 		time.Sleep(7 * time.Second)
 	}()
+	if !ps.discoverable(conn.NodeAddr()) {
+		return
+	}
 	err := ps.discServerHandler.HandleConnection(conn)
 	if err != nil {
 		return
@@ -209,16 +356,25 @@ func (ps *PeerManager) handleDisc(conn interfaces.P2PConn) {
 	func() {
 		ps.Lock()
 		defer ps.Unlock()
-		if !ps.active.contains(conn.NodeAddr()) {
+		if !ps.active.contains(conn.NodeAddr()) && !ps.reputation.banned(conn.NodeAddr()) {
 			ps.inactive.add(conn.NodeAddr())
 		}
 	}()
+	ps.addrBook.learned(conn.NodeAddr(), sourceInbound)
 }
 
 // handle p2p dials from remote peers by tracking the connection
 // in local stores and notifying subscribers
 func (ps *PeerManager) handleP2P(conn interfaces.P2PConn) {
 	ps.logger.Debugf("New connection in peerManager from %s", conn.NodeAddr().P2PAddr())
+	if ps.reputation.banned(conn.NodeAddr()) {
+		ps.logger.Debugf("Dropping connection from banned peer %s", conn.NodeAddr().P2PAddr())
+		err := conn.Close()
+		if err != nil {
+			utils.DebugTrace(ps.logger, err)
+		}
+		return
+	}
 	ctx, cf := context.WithDeadline(ps.ctx, time.Now().Add(time.Second*5))
 	defer cf()
 	muxconn, err := ps.mux.HandleConnection(ctx, conn)
@@ -247,6 +403,7 @@ func (ps *PeerManager) handleP2P(conn interfaces.P2PConn) {
 		ps.inactive.del(client.NodeAddr())
 		ps.notify(client)
 	}()
+	ps.addrBook.succeeded(client.NodeAddr(), sourceInbound)
 }
 
 func (ps *PeerManager) notify(c interfaces.P2PClient) {
@@ -270,6 +427,7 @@ func (ps *PeerManager) Subscribe() interfaces.PeerSubscription {
 		closeChan:  make(chan struct{}),
 		log:        logging.GetLogger(constants.LoggerPeer),
 		closeOnce:  sync.Once{},
+		report:     ps.reportPeer,
 		actives: &activePeerStore{
 			store:     make(map[string]interfaces.P2PClient),
 			pid:       make(map[string]uint64),
@@ -281,11 +439,84 @@ func (ps *PeerManager) Subscribe() interfaces.PeerSubscription {
 	return sub
 }
 
+// reportPeer updates the reputation of a peer and disconnects the peer if
+// it is banned as a result. Private peers are never banned.
+func (ps *PeerManager) reportPeer(addr interfaces.NodeAddr, ev types.PeerEvent) {
+	if ps.privatePeers[addr.Identity()] {
+		return
+	}
+	ban, ok := ps.reputation.report(addr, ev)
+	if !ok {
+		return
+	}
+	ps.logger.Warningf("Banning peer %s until %v: %s", ban.Addr, ban.Until.Format(time.RFC3339), ban.Reason)
+	ps.Lock()
+	defer ps.Unlock()
+	ps.active.del(addr)
+	ps.inactive.del(addr)
+}
+
+// Bans returns the peers that are currently banned
+func (ps *PeerManager) Bans() []*PeerBan {
+	return ps.reputation.list()
+}
+
+// PeerInfo returns the connection and traffic details of the active peers
+// and the inactive peers with their cooldowns
+func (ps *PeerManager) PeerInfo() ([]*PeerInfo, []*InactivePeerInfo) {
+	actives := []*PeerInfo{}
+	identities := make(map[string]bool)
+	peers, _ := ps.active.getPeers()
+	for _, p := range peers {
+		addr := p.NodeAddr()
+		identities[addr.Identity()] = true
+		pi := &PeerInfo{
+			Addr:      addr.P2PAddr(),
+			Identity:  addr.Identity(),
+			ChainID:   addr.ChainID(),
+			Score:     ps.reputation.score(addr),
+			Bandwidth: ps.transport.PeerBandwidth(addr.Identity()),
+		}
+		if c, ok := p.(*p2PClient); ok {
+			pi.Inbound = c.conn.Initiator() == types.PeerInitiatedConnection
+			pi.ProtoVersion = c.conn.ClientConn().ProtoVersion()
+			pi.ClientStream = c.conn.ClientConn().Bandwidth()
+			pi.ServerStream = c.conn.ServerConn().Bandwidth()
+		}
+		ps.p2pServerHandler.peerStats.info(pi)
+		actives = append(actives, pi)
+	}
+	ps.p2pServerHandler.peerStats.retain(identities)
+	sort.Slice(actives, func(i, j int) bool {
+		return actives[i].Connected.Before(actives[j].Connected)
+	})
+	inactives := []*InactivePeerInfo{}
+	for _, ip := range ps.inactive.info() {
+		if identities[ip.Identity] {
+			continue
+		}
+		inactives = append(inactives, ip)
+	}
+	sort.Slice(inactives, func(i, j int) bool {
+		return inactives[i].Identity < inactives[j].Identity
+	})
+	return actives, inactives
+}
+
+// Bandwidth returns the traffic of all connections since start per protocol
+func (ps *PeerManager) Bandwidth() map[types.Protocol]types.Bandwidth {
+	return ps.transport.Bandwidth()
+}
+
 // dialp2p dials remote peers
 func (ps *PeerManager) dialP2P(addr interfaces.NodeAddr) {
+	if !ps.allowed(addr) || ps.reputation.banned(addr) {
+		return
+	}
 	conn, err := ps.transport.Dial(addr, types.P2PProtocol)
 	if err != nil {
 		utils.DebugTrace(ps.logger, err)
+		ps.addrBook.failed(addr)
 		return
 	}
 	go ps.handleP2P(conn)
@@ -306,19 +537,20 @@ func (ps *PeerManager) HandleP2PGetPeers(ctx context.Context, req *pb.GetPeersRe
 	return ps.GetPeers(ctx, req)
 }
 
-// GetPeers is the handler for the get peers request.
+// GetPeers is the handler for the get peers request. Nodes in firewall or
+// sentry mode do not share peers and private peers are never shared.
 func (ps *PeerManager) GetPeers(ctx context.Context, req *pb.GetPeersRequest) (*pb.GetPeersResponse, error) {
 	resp := &pb.GetPeersResponse{
 		Peers: []string{},
 	}
-	if ps.fireWallMode {
+	if ps.fireWallMode || ps.sentryMode() {
 		return resp, nil
 	}
-	active, ok := ps.active.random()
+	active, ok := ps.active.random(ps.privatePeers)
 	if ok {
 		resp.Peers = append(resp.Peers, active)
 	}
-	inactive, ok := ps.inactive.random()
+	inactive, ok := ps.inactive.random(ps.privatePeers)
 	if ok {
 		resp.Peers = append(resp.Peers, inactive)
 	}
@@ -334,6 +566,8 @@ func (ps *PeerManager) GetPeers(ctx context.Context, req *pb.GetPeersRequest) (*
 func (ps *PeerManager) Status(smap map[string]interface{}) (map[string]interface{}, error) {
 	active, inactive := ps.Counts()
 	smap["Peers"] = fmt.Sprintf("%d/%d/%d/%d", ps.peeringMaxThreshold, active, ps.peeringCompleteThreshold, inactive)
+	bw := ps.transport.Bandwidth()[types.P2PProtocol]
+	smap["P2PKiB"] = fmt.Sprintf("%d/%d", bw.BytesSent/1024, bw.BytesReceived/1024)
 	return smap, nil
 }
 
@@ -353,12 +587,15 @@ func (ps *PeerManager) runDiscoveryLoops() {
 	defer ps.Close()
 	defer ps.wg.Done()
 	defer func() { ps.logger.Warning("Discovery loop exit") }()
-	ps.wg.Add(5)
+	ps.wg.Add(8)
 	go ps.doLoop("bootnode", ps.discoDialBootnode, time.Second*31)
+	go ps.doLoop("announce", ps.announce, constants.NodeRecordRefresh)
 	go ps.doLoop("inactive", ps.dialInactive, time.Second*13)
 	go ps.doLoop("active", ps.getPeersActive, time.Second*17)
 	go ps.doLoop("firewall", ps.dialFirewall, time.Second*10)
+	go ps.doLoop("sentries", ps.dialSentries, time.Second*10)
 	go ps.doLoop("peerStatus", ps.peerStatus, time.Second*3)
+	go ps.doLoop("addrBook", ps.addrBook.prune, constants.AddrBookPruneInterval)
 	<-ps.CloseChan()
 }
 
@@ -389,6 +626,9 @@ func (ps *PeerManager) peerStatus() {
 }
 
 func (ps *PeerManager) getPeersActive() {
+	if ps.sentryMode() {
+		return
+	}
 	smap := make(map[string]interface{})
 	_, err := ps.Status(smap)
 	if err != nil {
@@ -413,21 +653,25 @@ func (ps *PeerManager) getPeersActive() {
 			if err != nil {
 				continue
 			}
-			if ps.isMe(p) {
+			if ps.isMe(p) || !ps.discoverable(p) {
 				continue
 			}
 			func() {
 				ps.Lock()
 				defer ps.Unlock()
-				if !ps.active.contains(p) {
+				if !ps.active.contains(p) && !ps.reputation.banned(p) {
 					ps.inactive.add(p)
 				}
 			}()
+			ps.addrBook.learned(p, sourceGetPeers)
 		}
 	}
 }
 
 func (ps *PeerManager) discoDialBootnode() {
+	if ps.sentryMode() {
+		return
+	}
 	smap := make(map[string]interface{})
 	_, err := ps.Status(smap)
 	if err != nil {
@@ -450,21 +694,79 @@ func (ps *PeerManager) discoDialBootnode() {
 		// add all peers as inactive
 		for i := 0; i < len(peers); i++ {
 			p := peers[i]
-			if ps.isMe(p) {
+			if ps.isMe(p) || !ps.discoverable(p) {
 				continue
 			}
 			func() {
 				ps.Lock()
 				defer ps.Unlock()
-				if !ps.active.contains(p) {
+				if !ps.active.contains(p) && !ps.reputation.banned(p) {
 					ps.inactive.add(p)
 				}
 			}()
+			ps.addrBook.learned(p, sourceBootnode)
 		}
 	}
 }
 
+// announce refreshes the record of the local node on a bootnode so that the
+// node stays listed while it has enough peers to not need the bootnode
+func (ps *PeerManager) announce() {
+	if ps.fireWallMode || ps.sentryMode() {
+		return
+	}
+	bn, err := ps.bootNodes.randomBootNode()
+	if err != nil {
+		utils.DebugTrace(ps.logger, err)
+		return
+	}
+	if _, err := ps.bootNodeProtocol(bn); err != nil {
+		utils.DebugTrace(ps.logger, err)
+	}
+}
+
+// nodeRecord returns the signed record of the local node or nil if the node
+// should not be listed by bootnodes. Nodes in firewall or sentry mode are
+// not reachable by other peers and are never listed.
+func (ps *PeerManager) nodeRecord() []byte {
+	if ps.fireWallMode || ps.sentryMode() {
+		return nil
+	}
+	local := ps.transport.NodeAddr()
+	r, err := transport.NewNodeRecord(ps.transportKey, local.ChainID(), []interfaces.NodeAddr{local}, constants.NodeRecordTTL)
+	if err != nil {
+		utils.DebugTrace(ps.logger, err)
+		return nil
+	}
+	rb, err := r.MarshalBinary()
+	if err != nil {
+		utils.DebugTrace(ps.logger, err)
+		return nil
+	}
+	return rb
+}
+
+// seedInactive adds the peers from the address book to the inactive store so
+// that a restarted node can rejoin the network without a bootnode
+func (ps *PeerManager) seedInactive() {
+	if ps.sentryMode() {
+		return
+	}
+	addrs := ps.addrBook.addrs()
+	ps.Lock()
+	defer ps.Unlock()
+	for _, p := range addrs {
+		if ps.isMe(p) || !ps.discoverable(p) || ps.active.contains(p) || ps.reputation.banned(p) {
+			continue
+		}
+		ps.inactive.add(p)
+	}
+}
+
 func (ps *PeerManager) dialInactive() {
+	if ps.sentryMode() {
+		return
+	}
 	smap := make(map[string]interface{})
 	_, err := ps.Status(smap)
 	if err != nil {
@@ -472,6 +774,9 @@ func (ps *PeerManager) dialInactive() {
 	}
 	ps.logger.WithFields(smap).Debug("Running dial inactive")
 	active, inactive := ps.Counts()
+	if active < ps.peeringMaxThreshold && inactive == 0 {
+		ps.seedInactive()
+	}
 	if active < ps.peeringMaxThreshold {
 		naddr, ok := ps.inactive.randomPop()
 		if !ok {
@@ -492,6 +797,15 @@ func (ps *PeerManager) dialFirewall() {
 	}
 }
 
+// dialSentries keeps a connection to every sentry of a node in sentry mode
+func (ps *PeerManager) dialSentries() {
+	for _, sentry := range ps.sentries {
+		if !ps.active.contains(sentry) {
+			ps.dialP2P(sentry)
+		}
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
 //BOOTNODE DIALER //////////////////////////////////////////////////////////////
@@ -511,7 +825,7 @@ func (ps *PeerManager) bootNodeProtocol(nodeAddr interfaces.NodeAddr) ([]interfa
 	bnc := pb.NewBootNodeClient(gconn)
 	timeoutCtx, cf := context.WithTimeout(ps.ctx, time.Second*11)
 	defer cf()
-	resp, err := bnc.KnownNodes(timeoutCtx, &pb.BootNodeRequest{})
+	resp, err := bnc.KnownNodes(timeoutCtx, &pb.BootNodeRequest{Record: ps.nodeRecord()})
 	if err != nil {
 		return nil, err
 	}
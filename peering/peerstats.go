@@ -0,0 +1,187 @@
+package peering
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/types"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+)
+
+// RPCTraffic counts the messages and bytes exchanged with a peer for one
+// RPC method. Requests and responses are both counted in the direction they
+// travelled.
+type RPCTraffic struct {
+	Method        string
+	MsgsSent      uint64
+	MsgsReceived  uint64
+	BytesSent     uint64
+	BytesReceived uint64
+}
+
+// PeerInfo describes an active peer
+type PeerInfo struct {
+	Addr         string
+	Identity     string
+	Inbound      bool
+	Connected    time.Time
+	ProtoVersion types.ProtoVersion
+	ChainID      types.ChainIdentifier
+	LastGossip   time.Time
+	Score        int
+	Traffic      []*RPCTraffic
+	// Bandwidth is the traffic of the open connections to the peer per
+	// protocol as counted by the transport
+	Bandwidth map[types.Protocol]types.Bandwidth
+	// ClientStream and ServerStream are the traffic of the multiplexed
+	// streams that carry the calls of the local and the remote node
+	ClientStream types.Bandwidth
+	ServerStream types.Bandwidth
+}
+
+// peerStats is the traffic of the current connection to a peer
+type peerStats struct {
+	connected  time.Time
+	lastGossip time.Time
+	rpcs       map[string]*RPCTraffic
+}
+
+// peerStatsStore tracks the traffic of every peer the local node is
+// connected to
+type peerStatsStore struct {
+	sync.Mutex
+	peers map[string]*peerStats
+}
+
+func newPeerStatsStore() *peerStatsStore {
+	return &peerStatsStore{peers: make(map[string]*peerStats)}
+}
+
+// connected resets the stats of a peer when a new connection is made
+func (ps *peerStatsStore) connected(identity string) {
+	ps.Lock()
+	defer ps.Unlock()
+	ps.peers[identity] = &peerStats{
+		connected: time.Now(),
+		rpcs:      make(map[string]*RPCTraffic),
+	}
+}
+
+// record adds a message of size bytes to the traffic of a peer. Messages of
+// peers that are not connected are dropped.
+func (ps *peerStatsStore) record(identity, method string, sent bool, size int, isClient bool) {
+	ps.Lock()
+	defer ps.Unlock()
+	st, ok := ps.peers[identity]
+	if !ok {
+		return
+	}
+	rt, ok := st.rpcs[method]
+	if !ok {
+		rt = &RPCTraffic{Method: method}
+		st.rpcs[method] = rt
+	}
+	if sent {
+		rt.MsgsSent++
+		rt.BytesSent += uint64(size)
+		return
+	}
+	rt.MsgsReceived++
+	rt.BytesReceived += uint64(size)
+	if !isClient && strings.HasPrefix(method, "Gossip") {
+		st.lastGossip = time.Now()
+	}
+}
+
+// info fills in the traffic of a peer
+func (ps *peerStatsStore) info(pi *PeerInfo) {
+	ps.Lock()
+	defer ps.Unlock()
+	st, ok := ps.peers[pi.Identity]
+	if !ok {
+		return
+	}
+	pi.Connected = st.connected
+	pi.LastGossip = st.lastGossip
+	for _, rt := range st.rpcs {
+		cp := *rt
+		pi.Traffic = append(pi.Traffic, &cp)
+	}
+	sort.Slice(pi.Traffic, func(i, j int) bool {
+		return pi.Traffic[i].Method < pi.Traffic[j].Method
+	})
+}
+
+// retain drops the stats of the peers that are not in identities. Peers that
+// connected recently may still be completing the handshake and are kept.
+func (ps *peerStatsStore) retain(identities map[string]bool) {
+	ps.Lock()
+	defer ps.Unlock()
+	cutoff := time.Now().Add(-time.Minute)
+	for id, st := range ps.peers {
+		if !identities[id] && st.connected.Before(cutoff) {
+			delete(ps.peers, id)
+		}
+	}
+}
+
+type rpcMethodKey struct{}
+
+// statsHandler is a grpc stats handler that records the payloads of every
+// RPC in the peerStatsStore. The server side handler finds the peer in the
+// context of the call. A client side handler is bound to the peer of its
+// connection.
+type statsHandler struct {
+	store *peerStatsStore
+	addr  interfaces.NodeAddr
+}
+
+var _ stats.Handler = (*statsHandler)(nil)
+
+func (sh *statsHandler) identity(ctx context.Context) (string, bool) {
+	if sh.addr != nil {
+		return sh.addr.Identity(), true
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	nodeAddr, ok := p.Addr.(interfaces.NodeAddr)
+	if !ok {
+		return "", false
+	}
+	return nodeAddr.Identity(), true
+}
+
+func (sh *statsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, rpcMethodKey{}, path.Base(info.FullMethodName))
+}
+
+func (sh *statsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	method, ok := ctx.Value(rpcMethodKey{}).(string)
+	if !ok {
+		return
+	}
+	switch st := s.(type) {
+	case *stats.InPayload:
+		if id, ok := sh.identity(ctx); ok {
+			sh.store.record(id, method, false, st.WireLength, st.IsClient())
+		}
+	case *stats.OutPayload:
+		if id, ok := sh.identity(ctx); ok {
+			sh.store.record(id, method, true, st.WireLength, st.IsClient())
+		}
+	}
+}
+
+func (sh *statsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (sh *statsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {}
@@ -0,0 +1,76 @@
+package peering
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MadBase/MadNet/transport"
+	"google.golang.org/grpc/stats"
+)
+
+func TestPeerStatsHandler(t *testing.T) {
+	store := newPeerStatsStore()
+	ctx := peerContext(t)
+	sh := &statsHandler{store: store}
+	id, ok := sh.identity(ctx)
+	if !ok {
+		t.Fatal("no identity in context")
+	}
+
+	// traffic of peers that are not connected is dropped
+	rctx := sh.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/proto.P2P/GossipProposal"})
+	sh.HandleRPC(rctx, &stats.InPayload{WireLength: 10})
+	pi := &PeerInfo{Identity: id}
+	store.info(pi)
+	if len(pi.Traffic) != 0 {
+		t.Fatal("traffic recorded before connecting")
+	}
+
+	store.connected(id)
+	sh.HandleRPC(rctx, &stats.InPayload{WireLength: 10})
+	sh.HandleRPC(rctx, &stats.InPayload{WireLength: 5})
+	sh.HandleRPC(rctx, &stats.OutPayload{WireLength: 2})
+	rctx = sh.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/proto.P2P/GetBlockHeaders"})
+	sh.HandleRPC(rctx, &stats.OutPayload{WireLength: 7})
+	pi = &PeerInfo{Identity: id}
+	store.info(pi)
+	if pi.Connected.IsZero() || pi.LastGossip.IsZero() {
+		t.Fatal("times not recorded")
+	}
+	if len(pi.Traffic) != 2 {
+		t.Fatalf("expected 2 methods, got %d", len(pi.Traffic))
+	}
+	hdrs, gossip := pi.Traffic[0], pi.Traffic[1]
+	if hdrs.Method != "GetBlockHeaders" || hdrs.MsgsSent != 1 || hdrs.BytesSent != 7 || hdrs.MsgsReceived != 0 {
+		t.Fatalf("bad traffic: %+v", hdrs)
+	}
+	if gossip.Method != "GossipProposal" || gossip.MsgsReceived != 2 || gossip.BytesReceived != 15 || gossip.MsgsSent != 1 || gossip.BytesSent != 2 {
+		t.Fatalf("bad traffic: %+v", gossip)
+	}
+
+	// a client side handler is bound to its peer
+	na, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.connected(na.Identity())
+	csh := &statsHandler{store: store, addr: na}
+	rctx = csh.TagRPC(context.Background(), &stats.RPCTagInfo{FullMethodName: "/proto.P2P/GossipTransaction"})
+	csh.HandleRPC(rctx, &stats.InPayload{Client: true, WireLength: 1})
+	pi = &PeerInfo{Identity: na.Identity()}
+	store.info(pi)
+	if len(pi.Traffic) != 1 || pi.Traffic[0].MsgsReceived != 1 {
+		t.Fatal("client side traffic not recorded")
+	}
+	if !pi.LastGossip.IsZero() {
+		t.Fatal("gossip ack counted as received gossip")
+	}
+
+	// peers that connected recently are kept while they finish the handshake
+	store.retain(map[string]bool{})
+	pi = &PeerInfo{Identity: id}
+	store.info(pi)
+	if pi.Connected.IsZero() {
+		t.Fatal("recently connected peer dropped")
+	}
+}
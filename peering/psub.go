@@ -6,9 +6,14 @@ import (
 	"sync"
 
 	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/types"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrNoCapablePeer is returned by CapablePeerLease when no active peer serves
+// the requested RPC
+var ErrNoCapablePeer = errors.New("no active peer supports the rpc")
+
 // PeerSubscription allows a remote service to maintain a reference to the
 // active peer set. This reference will be kept in sync with the local copy of
 // the active peer set and allows external services to perform P2P RPC with
@@ -20,6 +25,7 @@ type PeerSubscription struct {
 	closeChan  chan struct{}
 	actives    *activePeerStore
 	closeOnce  sync.Once
+	report     func(interfaces.NodeAddr, types.PeerEvent)
 }
 
 // CloseChan returns a channel that will be closed when the subscription
@@ -95,6 +101,33 @@ func (p *PeerSubscription) PeerLease(ctx context.Context) (interfaces.PeerLease,
 	return nil, errors.New("p2pclient is nil")
 }
 
+// CapablePeerLease returns a random active peer that serves the RPC method
+// rpc
+func (p *PeerSubscription) CapablePeerLease(ctx context.Context, rpc string) (interfaces.PeerLease, error) {
+	p.RLock()
+	defer p.RUnlock()
+	peers, ok := p.actives.getPeers()
+	if !ok {
+		return nil, errors.New("p2pclient is nil")
+	}
+	capable := []*p2PClient{}
+	for i := 0; i < len(peers); i++ {
+		client, ok := peers[i].(*p2PClient)
+		if ok && client.Capabilities().SupportsRPC(rpc) {
+			capable = append(capable, client)
+		}
+	}
+	if len(capable) == 0 {
+		return nil, ErrNoCapablePeer
+	}
+	index, err := randomElement(len(capable))
+	if err != nil {
+		p.log.Debugf("Error in PeerSubscription.CapablePeerLease at randomElement: %v", err)
+		return nil, err
+	}
+	return capable[index], nil
+}
+
 // GossipTx allows a service to Gossip a transaction all active peers
 func (p *PeerSubscription) GossipTx(hsh []byte, fn func(context.Context, interfaces.PeerLease) error) {
 	select {
@@ -168,6 +201,15 @@ func (p *PeerSubscription) PreventGossipTx(addr interfaces.NodeAddr, hsh []byte)
 	}
 }
 
+// ReportPeer allows a service to report the behavior of a remote peer to the
+// peer manager. Peers that misbehave too often are disconnected and banned.
+func (p *PeerSubscription) ReportPeer(addr interfaces.NodeAddr, ev types.PeerEvent) {
+	if p.report == nil || addr == nil {
+		return
+	}
+	p.report(addr, ev)
+}
+
 type peerFail struct{}
 
 func (p *peerFail) P2PClient() (interfaces.P2PClient, error) {
@@ -0,0 +1,162 @@
+package peering
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/interfaces"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrRateLimited is returned to a peer that exceeded its rate limit for an
+// RPC family. The caller should back off and send the request to a
+// different peer.
+var ErrRateLimited = status.Error(codes.ResourceExhausted, "rate limited")
+
+// These are the RPC families that share a rate limit. Methods are assigned
+// to a family by the prefix of their name so that new methods are limited
+// without changes here.
+const (
+	familyGossip   = "gossip"
+	familySnapShot = "snapshot"
+	familyRequest  = "request"
+)
+
+func rpcFamily(method string) string {
+	switch {
+	case strings.HasPrefix(method, "P2PGossip"):
+		return familyGossip
+	case strings.HasPrefix(method, "P2PGetSnapShot"):
+		return familySnapShot
+	default:
+		return familyRequest
+	}
+}
+
+// idle buckets are dropped once the limiter tracks more than maxBuckets
+const (
+	maxBuckets = 4096
+	bucketIdle = time.Minute
+)
+
+// RateLimit is the rate in calls per second and the burst size of a token
+// bucket
+type RateLimit struct {
+	Rate  int
+	Burst int
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket for the time elapsed since the last call and takes
+// a token if one is available
+func (tb *tokenBucket) take(limit RateLimit, now time.Time) bool {
+	tb.tokens += now.Sub(tb.last).Seconds() * float64(limit.Rate)
+	if tb.tokens > float64(limit.Burst) {
+		tb.tokens = float64(limit.Burst)
+	}
+	tb.last = now
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// RateLimiter enforces a token bucket per peer identity and RPC family on
+// inbound RPC calls. It implements the RateLimiter interface of the inbound
+// RPC dispatch.
+type RateLimiter struct {
+	sync.Mutex
+	limits  map[string]RateLimit
+	buckets map[string]*tokenBucket
+	exempt  map[string]bool
+}
+
+// withDefaults replaces the fields of l that are not positive
+func (l RateLimit) withDefaults(rate, burst int) RateLimit {
+	if l.Rate <= 0 {
+		l.Rate = rate
+	}
+	if l.Burst <= 0 {
+		l.Burst = burst
+	}
+	return l
+}
+
+// NewRateLimiter creates a rate limiter for the gossip, request and
+// snapshot RPC families. Rates and bursts that are not positive fall back to
+// the defaults.
+func NewRateLimiter(gossip, request, snapshot RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits: map[string]RateLimit{
+			familyGossip:   gossip.withDefaults(constants.DefaultGossipRateLimit, constants.DefaultGossipRateBurst),
+			familyRequest:  request.withDefaults(constants.DefaultRequestRateLimit, constants.DefaultRequestRateBurst),
+			familySnapShot: snapshot.withDefaults(constants.DefaultSnapShotRateLimit, constants.DefaultSnapShotRateBurst),
+		},
+		buckets: make(map[string]*tokenBucket),
+		exempt:  make(map[string]bool),
+	}
+}
+
+// Exempt disables the rate limits for the peers with the given identities.
+// It is used for private peers such as the validator behind a sentry, which
+// relay the gossip of the whole network.
+func (rl *RateLimiter) Exempt(identities ...string) {
+	rl.Lock()
+	defer rl.Unlock()
+	for _, ident := range identities {
+		rl.exempt[ident] = true
+	}
+}
+
+// Allow takes a token from the bucket of the calling peer for the family of
+// method. Calls without a peer identity are not limited.
+func (rl *RateLimiter) Allow(ctx context.Context, method string) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	nodeAddr, ok := p.Addr.(interfaces.NodeAddr)
+	if !ok {
+		return nil
+	}
+	family := rpcFamily(method)
+	key := nodeAddr.Identity() + "|" + family
+	rl.Lock()
+	defer rl.Unlock()
+	if rl.exempt[nodeAddr.Identity()] {
+		return nil
+	}
+	now := time.Now()
+	limit := rl.limits[family]
+	tb, ok := rl.buckets[key]
+	if !ok {
+		if len(rl.buckets) >= maxBuckets {
+			rl.prune(now)
+		}
+		tb = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = tb
+	}
+	if !tb.take(limit, now) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// prune drops the buckets that have not been used recently
+func (rl *RateLimiter) prune(now time.Time) {
+	for k, tb := range rl.buckets {
+		if now.Sub(tb.last) > bucketIdle {
+			delete(rl.buckets, k)
+		}
+	}
+}
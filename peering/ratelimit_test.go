@@ -0,0 +1,87 @@
+package peering
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/transport"
+	"google.golang.org/grpc/peer"
+)
+
+func peerContext(t *testing.T) context.Context {
+	na, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, ok := na.(net.Addr)
+	if !ok {
+		t.Fatal("node addr is not a net addr")
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{Rate: 1, Burst: 3}, RateLimit{Rate: 1, Burst: 1}, RateLimit{Rate: 1, Burst: 1})
+	ctx := peerContext(t)
+	for i := 0; i < 3; i++ {
+		if err := rl.Allow(ctx, "P2PGossipTransaction"); err != nil {
+			t.Fatalf("call %d limited within burst", i)
+		}
+	}
+	if err := rl.Allow(ctx, "P2PGossipProposal"); err != ErrRateLimited {
+		t.Fatal("gossip family not limited after burst")
+	}
+	// other families and peers have their own buckets
+	if err := rl.Allow(ctx, "P2PGetSnapShotNode"); err != nil {
+		t.Fatal("snapshot family limited by gossip calls")
+	}
+	if err := rl.Allow(ctx, "P2PGetSnapShotStateData"); err != ErrRateLimited {
+		t.Fatal("snapshot family not limited after burst")
+	}
+	if err := rl.Allow(peerContext(t), "P2PGossipTransaction"); err != nil {
+		t.Fatal("peer limited by calls of another peer")
+	}
+	// calls without a peer are not limited
+	for i := 0; i < 5; i++ {
+		if err := rl.Allow(context.Background(), "P2PGetBlockHeaders"); err != nil {
+			t.Fatal("call without peer limited")
+		}
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{}, RateLimit{Rate: 100, Burst: 1}, RateLimit{})
+	ctx := peerContext(t)
+	if err := rl.Allow(ctx, "P2PGetMinedTxs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.Allow(ctx, "P2PGetMinedTxs"); err != ErrRateLimited {
+		t.Fatal("not limited after burst")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := rl.Allow(ctx, "P2PGetMinedTxs"); err != nil {
+		t.Fatal("bucket not refilled")
+	}
+}
+
+func TestRateLimiterExempt(t *testing.T) {
+	rl := NewRateLimiter(RateLimit{Rate: 1, Burst: 1}, RateLimit{}, RateLimit{})
+	ctx := peerContext(t)
+	p, _ := peer.FromContext(ctx)
+	rl.Exempt(p.Addr.(interfaces.NodeAddr).Identity())
+	for i := 0; i < 5; i++ {
+		if err := rl.Allow(ctx, "P2PGossipPreVote"); err != nil {
+			t.Fatal("exempt peer limited")
+		}
+	}
+	other := peerContext(t)
+	if err := rl.Allow(other, "P2PGossipPreVote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.Allow(other, "P2PGossipPreVote"); err != ErrRateLimited {
+		t.Fatal("peer that is not exempt not limited")
+	}
+}
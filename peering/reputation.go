@@ -0,0 +1,262 @@
+package peering
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/constants/dbprefix"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/types"
+	"github.com/MadBase/MadNet/utils"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// The score of a peer starts at zero, is raised by useful data up to
+// maxPeerScore and is lowered by every penalty. A peer whose score falls
+// below the ban threshold is banned and starts over at zero once the ban
+// expires.
+const (
+	maxPeerScore = 100
+
+	usefulReward    = 1
+	timeoutPenalty  = 5
+	invalidPenalty  = 25
+	responsePenalty = 25
+)
+
+// PeerBan describes a peer that may not connect to the local node until the
+// ban expires.
+type PeerBan struct {
+	Identity string
+	Addr     string
+	Reason   string
+	Until    time.Time
+}
+
+func (pb *PeerBan) MarshalBinary() []byte {
+	out := utils.MarshalInt64(pb.Until.Unix())
+	out = append(out, utils.MarshalUint32(uint32(len(pb.Addr)))...)
+	out = append(out, []byte(pb.Addr)...)
+	out = append(out, []byte(pb.Reason)...)
+	return out
+}
+
+func (pb *PeerBan) UnmarshalBinary(v []byte) error {
+	if len(v) < 12 {
+		return errors.New("invalid peer ban")
+	}
+	until, _ := utils.UnmarshalInt64(v[0:8])
+	addrLen, _ := utils.UnmarshalUint32(v[8:12])
+	if uint32(len(v)-12) < addrLen {
+		return errors.New("invalid peer ban")
+	}
+	pb.Until = time.Unix(until, 0)
+	pb.Addr = string(v[12 : 12+addrLen])
+	pb.Reason = string(v[12+addrLen:])
+	return nil
+}
+
+// reputationStore tracks the score of every peer the local node has
+// interacted with and the peers that are currently banned. Bans are
+// persisted if a database is set.
+type reputationStore struct {
+	sync.Mutex
+	logger    *logrus.Logger
+	database  *badger.DB
+	threshold int
+	duration  time.Duration
+	scores    map[string]int
+	bans      map[string]*PeerBan
+}
+
+// newReputationStore creates a reputation store. A threshold that is not
+// negative would ban fresh peers on their first penalty and a duration that
+// is not positive would never ban at all, so both fall back to the defaults.
+func newReputationStore(logger *logrus.Logger, database *badger.DB, threshold int, duration time.Duration) (*reputationStore, error) {
+	if threshold >= 0 {
+		threshold = constants.DefaultPeerBanThreshold
+	}
+	if duration <= 0 {
+		duration = constants.DefaultPeerBanDuration
+	}
+	rs := &reputationStore{
+		logger:    logger,
+		database:  database,
+		threshold: threshold,
+		duration:  duration,
+		scores:    make(map[string]int),
+		bans:      make(map[string]*PeerBan),
+	}
+	if err := rs.load(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (rs *reputationStore) makeKey(identity string) []byte {
+	key := dbprefix.PrefixPeerBan()
+	key = append(key, []byte(identity)...)
+	return key
+}
+
+// load reads the persisted bans and drops the ones that have expired
+func (rs *reputationStore) load() error {
+	if rs.database == nil {
+		return nil
+	}
+	expired := [][]byte{}
+	err := rs.database.View(func(txn *badger.Txn) error {
+		prefix := dbprefix.PrefixPeerBan()
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		iter := txn.NewIterator(opts)
+		defer iter.Close()
+		for iter.Seek(prefix); iter.ValidForPrefix(prefix); iter.Next() {
+			itm := iter.Item()
+			key := itm.KeyCopy(nil)
+			value, err := itm.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			ban := &PeerBan{Identity: string(key[len(prefix):])}
+			if err := ban.UnmarshalBinary(value); err != nil {
+				utils.DebugTrace(rs.logger, err)
+				expired = append(expired, key)
+				continue
+			}
+			if time.Now().After(ban.Until) {
+				expired = append(expired, key)
+				continue
+			}
+			rs.bans[ban.Identity] = ban
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return rs.database.Update(func(txn *badger.Txn) error {
+		for _, key := range expired {
+			if err := utils.DeleteValue(txn, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (rs *reputationStore) persist(ban *PeerBan) {
+	if rs.database == nil {
+		return
+	}
+	err := rs.database.Update(func(txn *badger.Txn) error {
+		return utils.SetValue(txn, rs.makeKey(ban.Identity), ban.MarshalBinary())
+	})
+	if err != nil {
+		utils.DebugTrace(rs.logger, err)
+	}
+}
+
+func (rs *reputationStore) unpersist(identity string) {
+	if rs.database == nil {
+		return
+	}
+	err := rs.database.Update(func(txn *badger.Txn) error {
+		return utils.DeleteValue(txn, rs.makeKey(identity))
+	})
+	if err != nil {
+		utils.DebugTrace(rs.logger, err)
+	}
+}
+
+// report applies an event to the score of a peer. It returns the ban if the
+// event caused the peer to be banned.
+func (rs *reputationStore) report(addr interfaces.NodeAddr, ev types.PeerEvent) (*PeerBan, bool) {
+	rs.Lock()
+	defer rs.Unlock()
+	id := addr.Identity()
+	if _, ok := rs.bans[id]; ok {
+		return nil, false
+	}
+	score := rs.scores[id]
+	switch ev {
+	case types.PeerUseful:
+		score += usefulReward
+		if score > maxPeerScore {
+			score = maxPeerScore
+		}
+	case types.PeerTimeout:
+		score -= timeoutPenalty
+	case types.PeerInvalidGossip:
+		score -= invalidPenalty
+	case types.PeerInvalidResponse:
+		score -= responsePenalty
+	default:
+		return nil, false
+	}
+	if score >= rs.threshold {
+		rs.scores[id] = score
+		return nil, false
+	}
+	delete(rs.scores, id)
+	ban := &PeerBan{
+		Identity: id,
+		Addr:     addr.P2PAddr(),
+		Reason:   ev.String(),
+		Until:    time.Now().Add(rs.duration),
+	}
+	rs.bans[id] = ban
+	rs.persist(ban)
+	return ban, true
+}
+
+// banned returns true if the peer is currently banned
+func (rs *reputationStore) banned(addr interfaces.NodeAddr) bool {
+	rs.Lock()
+	defer rs.Unlock()
+	ban, ok := rs.bans[addr.Identity()]
+	if !ok {
+		return false
+	}
+	if time.Now().After(ban.Until) {
+		delete(rs.bans, ban.Identity)
+		rs.unpersist(ban.Identity)
+		return false
+	}
+	return true
+}
+
+// score returns the current score of a peer
+func (rs *reputationStore) score(addr interfaces.NodeAddr) int {
+	rs.Lock()
+	defer rs.Unlock()
+	return rs.scores[addr.Identity()]
+}
+
+// list returns the active bans ordered by expiry
+func (rs *reputationStore) list() []*PeerBan {
+	rs.Lock()
+	defer rs.Unlock()
+	now := time.Now()
+	out := []*PeerBan{}
+	for id, ban := range rs.bans {
+		if now.After(ban.Until) {
+			delete(rs.bans, id)
+			rs.unpersist(id)
+			continue
+		}
+		cp := *ban
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Until.Before(out[j].Until)
+	})
+	return out
+}
@@ -0,0 +1,117 @@
+package peering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/logging"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/types"
+	"github.com/MadBase/MadNet/utils"
+)
+
+func TestReputationBan(t *testing.T) {
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	database, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.GetLogger(constants.LoggerPeerMan)
+	rs, err := newReputationStore(logger, database, -50, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	na, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := rs.report(na, types.PeerUseful); ok {
+			t.Fatal("banned for useful data")
+		}
+	}
+	if _, ok := rs.report(na, types.PeerInvalidGossip); ok {
+		t.Fatal("banned above threshold")
+	}
+	if _, ok := rs.report(na, types.PeerInvalidGossip); ok {
+		t.Fatal("banned above threshold")
+	}
+	ban, ok := rs.report(na, types.PeerInvalidResponse)
+	if !ok {
+		t.Fatal("not banned below threshold")
+	}
+	if ban.Identity != na.Identity() || ban.Addr != na.P2PAddr() {
+		t.Fatal("wrong peer banned")
+	}
+	if !rs.banned(na) {
+		t.Fatal("ban not active")
+	}
+	if _, ok := rs.report(na, types.PeerInvalidGossip); ok {
+		t.Fatal("banned twice")
+	}
+
+	// bans survive a restart
+	rs2, err := newReputationStore(logger, database, -50, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs2.banned(na) {
+		t.Fatal("ban not loaded")
+	}
+	bans := rs2.list()
+	if len(bans) != 1 || bans[0].Identity != na.Identity() || bans[0].Reason != types.PeerInvalidResponse.String() {
+		t.Fatalf("bad bans: %v", bans)
+	}
+}
+
+func TestReputationExpiry(t *testing.T) {
+	closeChan := make(chan struct{})
+	defer close(closeChan)
+	database, err := utils.OpenBadger(closeChan, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.GetLogger(constants.LoggerPeerMan)
+	rs, err := newReputationStore(logger, database, -1, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	na, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rs.report(na, types.PeerTimeout); !ok {
+		t.Fatal("not banned below threshold")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if rs.banned(na) {
+		t.Fatal("ban did not expire")
+	}
+	if len(rs.list()) != 0 {
+		t.Fatal("expired ban listed")
+	}
+	rs2, err := newReputationStore(logger, database, -1, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs2.banned(na) {
+		t.Fatal("expired ban loaded")
+	}
+}
+
+func TestReputationDefaults(t *testing.T) {
+	logger := logging.GetLogger(constants.LoggerPeerMan)
+	rs, err := newReputationStore(logger, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	na, err := transport.RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rs.report(na, types.PeerInvalidGossip); ok {
+		t.Fatal("banned on first penalty with default threshold")
+	}
+}
@@ -0,0 +1,162 @@
+package peering
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/constants"
+	"github.com/MadBase/MadNet/crypto/secp256k1"
+	"github.com/MadBase/MadNet/interfaces"
+	pb "github.com/MadBase/MadNet/proto"
+	"github.com/MadBase/MadNet/transport"
+	"github.com/MadBase/MadNet/types"
+)
+
+// testNode is an in-process peer manager listening on loopback
+type testNode struct {
+	*PeerManager
+	addr interfaces.NodeAddr
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func newTestKey(t *testing.T) (string, string) {
+	privk, err := transport.NewTransportPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privkBytes, err := hex.DecodeString(privk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pubk := secp256k1.PrivKeyFromBytes(secp256k1.S256(), privkBytes)
+	return privk, hex.EncodeToString(pubk.SerializeCompressed())
+}
+
+// setTestBootNode configures a valid boot node that is never dialed since
+// the peer managers require one
+func setTestBootNode(t *testing.T) {
+	_, bootNodeIdentity := newTestKey(t)
+	config.Configuration.Transport.BootNodeAddresses = "0000002a|" + bootNodeIdentity + "@127.0.0.1:1"
+	t.Cleanup(func() { config.Configuration.Transport.BootNodeAddresses = "" })
+}
+
+func newTestNode(t *testing.T, privk string, sentries, privatePeers, allowed []string) *testNode {
+	dispatch := pb.NewInboundRPCDispatch()
+	listenAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(freePort(t)))
+	pm, err := NewPeerManager(pb.NewGeneratedP2PServer(dispatch), 42, 1, 8, false, "", Restrictions{Sentries: sentries, PrivatePeers: privatePeers, AllowedIdentities: allowed}, listenAddr, privk, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dispatch.RegisterP2PGetPeers(pm)
+	// only accept connections; the test dials explicitly instead of running
+	// the discovery loops
+	pm.wg.Add(1)
+	go pm.acceptLoop()
+	t.Cleanup(func() { pm.Close() })
+	return &testNode{PeerManager: pm, addr: pm.transport.NodeAddr()}
+}
+
+func waitActive(t *testing.T, n *testNode, addr interfaces.NodeAddr) {
+	for i := 0; i < 100; i++ {
+		if n.active.contains(addr) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("%s never connected", addr.P2PAddr())
+}
+
+func TestSentryTopology(t *testing.T) {
+	setTestBootNode(t)
+
+	sentryKey, _ := newTestKey(t)
+	publicKey, _ := newTestKey(t)
+	validatorKey, validatorIdentity := newTestKey(t)
+	sentry := newTestNode(t, sentryKey, nil, []string{validatorIdentity}, nil)
+	public := newTestNode(t, publicKey, nil, nil, nil)
+	validator := newTestNode(t, validatorKey, []string{sentry.addr.P2PAddr()}, nil, nil)
+	if validator.addr.Identity() != validatorIdentity {
+		t.Fatal("identity mismatch")
+	}
+	if !validator.sentryMode() {
+		t.Fatal("validator not in sentry mode")
+	}
+
+	// the validator only dials its sentries
+	validator.dialSentries()
+	waitActive(t, validator, sentry.addr)
+	waitActive(t, sentry, validator.addr)
+	validator.dialP2P(public.addr)
+	public.dialP2P(validator.addr)
+	public.dialP2P(sentry.addr)
+	waitActive(t, sentry, public.addr)
+	time.Sleep(500 * time.Millisecond)
+	if validator.active.contains(public.addr) || public.active.contains(validator.addr) {
+		t.Fatal("validator connected to a peer that is not a sentry")
+	}
+	if active, _ := validator.Counts(); active != 1 {
+		t.Fatalf("validator has %d peers", active)
+	}
+
+	// the validator does not share peers
+	resp, err := validator.GetPeers(context.Background(), &pb.GetPeersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Peers) != 0 {
+		t.Fatal("validator shared peers")
+	}
+
+	// the sentry never shares the validator
+	for i := 0; i < 20; i++ {
+		resp, err := sentry.GetPeers(context.Background(), &pb.GetPeersRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Peers) == 0 {
+			t.Fatal("sentry shared no peers")
+		}
+		for _, p := range resp.Peers {
+			addr, err := transport.NewNodeAddr(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if addr.Identity() == validatorIdentity {
+				t.Fatal("sentry shared the validator")
+			}
+		}
+	}
+
+	// the sentry relays the consensus gossip of the validator with priority
+	peer, ok := sentry.active.get(validator.addr)
+	if !ok {
+		t.Fatal("validator not active on sentry")
+	}
+	if peer.(*p2PClient).consensusQueue.lru.max != constants.PrivatePeerMsgQSize {
+		t.Fatal("validator queue not prioritized")
+	}
+	peer, ok = sentry.active.get(public.addr)
+	if !ok {
+		t.Fatal("public peer not active on sentry")
+	}
+	if peer.(*p2PClient).consensusQueue.lru.max != constants.ConsensusMsgQSize {
+		t.Fatal("public peer queue prioritized")
+	}
+	sentry.reportPeer(validator.addr, types.PeerInvalidGossip)
+	if !sentry.active.contains(validator.addr) {
+		t.Fatal("sentry banned the validator")
+	}
+}
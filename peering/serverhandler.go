@@ -76,8 +76,10 @@ func NewDiscoveryServerHandler(logger *logrus.Logger, addr net.Addr, service int
 }
 
 // NewP2PServerHandler returns a RPC ServerHandler for the Pz2P Service.
-func newP2PServerHandler(logger *logrus.Logger, addr net.Addr, service interfaces.P2PServer) *ServerHandler {
-	srvr := grpc.NewServer(grpc.ConnectionTimeout(constants.SrvrMsgTimeout), grpc.MaxConcurrentStreams(constants.MaxConcurrentStreams), grpc.NumStreamWorkers(constants.P2PStreamWorkers), grpc.ReadBufferSize(constants.ReadBufferSize))
+// The traffic of every call is recorded in peerStats and requests larger
+// than the advertised MaxMessageSize are rejected.
+func newP2PServerHandler(logger *logrus.Logger, addr net.Addr, service interfaces.P2PServer, peerStats *peerStatsStore) *ServerHandler {
+	srvr := grpc.NewServer(grpc.ConnectionTimeout(constants.SrvrMsgTimeout), grpc.MaxConcurrentStreams(constants.MaxConcurrentStreams), grpc.NumStreamWorkers(constants.P2PStreamWorkers), grpc.ReadBufferSize(constants.ReadBufferSize), grpc.MaxRecvMsgSize(constants.MaxMessageSize), grpc.StatsHandler(&statsHandler{store: peerStats}))
 	pb.RegisterP2PServer(srvr, service)
 	handler := &ServerHandler{
 		listener: NewListener(logger, addr),
@@ -37,7 +37,7 @@ var file_localstate_proto_rawDesc = []byte{
 	0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
 	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x15, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x73, 0x74,
 	0x61, 0x74, 0x65, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x8b,
-	0x0c, 0x0a, 0x0a, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x51, 0x0a,
+	0x0f, 0x0a, 0x0a, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x51, 0x0a,
 	0x07, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x2e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
 	0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52,
@@ -133,7 +133,31 @@ var file_localstate_proto_rawDesc = []byte{
 	0x74, 0x6f, 0x2e, 0x54, 0x78, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72,
 	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c,
 	0x22, 0x17, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x65, 0x74, 0x2d, 0x74, 0x78, 0x2d, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x2d, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x3a, 0x01, 0x2a, 0x62, 0x06, 0x70, 0x72,
+	0x63, 0x6b, 0x2d, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x3a, 0x01, 0x2a, 0x12, 0x64, 0x0a, 0x0d,
+	0x47, 0x65, 0x74, 0x53, 0x79, 0x6e, 0x63, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x53, 0x79, 0x6e, 0x63, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x22, 0x13, 0x2f, 0x76, 0x31, 0x2f,
+	0x67, 0x65, 0x74, 0x2d, 0x73, 0x79, 0x6e, 0x63, 0x2d, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x3a,
+	0x01, 0x2a, 0x12, 0x5c, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x42, 0x61, 0x6e,
+	0x73, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x42, 0x61,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x42, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11, 0x2f, 0x76, 0x31, 0x2f,
+	0x67, 0x65, 0x74, 0x2d, 0x70, 0x65, 0x65, 0x72, 0x2d, 0x62, 0x61, 0x6e, 0x73, 0x3a, 0x01, 0x2a,
+	0x12, 0x5c, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x65,
+	0x74, 0x2d, 0x70, 0x65, 0x65, 0x72, 0x2d, 0x69, 0x6e, 0x66, 0x6f, 0x3a, 0x01, 0x2a, 0x12, 0x5c,
+	0x0a, 0x0b, 0x47, 0x65, 0x74, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x75, 0x6e, 0x73, 0x12, 0x16, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x61,
+	0x73, 0x6b, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x65, 0x74, 0x2d,
+	0x74, 0x61, 0x73, 0x6b, 0x2d, 0x72, 0x75, 0x6e, 0x73, 0x3a, 0x01, 0x2a, 0x62, 0x06, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x33,
 }
 
@@ -152,20 +176,28 @@ var file_localstate_proto_goTypes = []interface{}{
 	(*TransactionData)(nil),                // 11: proto.TransactionData
 	(*EpochNumberRequest)(nil),             // 12: proto.EpochNumberRequest
 	(*TxBlockNumberRequest)(nil),           // 13: proto.TxBlockNumberRequest
-	(*GetDataResponse)(nil),                // 14: proto.GetDataResponse
-	(*GetValueResponse)(nil),               // 15: proto.GetValueResponse
-	(*IterateNameSpaceResponse)(nil),       // 16: proto.IterateNameSpaceResponse
-	(*MinedTransactionResponse)(nil),       // 17: proto.MinedTransactionResponse
-	(*BlockHeaderResponse)(nil),            // 18: proto.BlockHeaderResponse
-	(*UTXOResponse)(nil),                   // 19: proto.UTXOResponse
-	(*PendingTransactionResponse)(nil),     // 20: proto.PendingTransactionResponse
-	(*RoundStateForValidatorResponse)(nil), // 21: proto.RoundStateForValidatorResponse
-	(*ValidatorSetResponse)(nil),           // 22: proto.ValidatorSetResponse
-	(*BlockNumberResponse)(nil),            // 23: proto.BlockNumberResponse
-	(*ChainIDResponse)(nil),                // 24: proto.ChainIDResponse
-	(*TransactionDetails)(nil),             // 25: proto.TransactionDetails
-	(*EpochNumberResponse)(nil),            // 26: proto.EpochNumberResponse
-	(*TxBlockNumberResponse)(nil),          // 27: proto.TxBlockNumberResponse
+	(*SyncStatusRequest)(nil),              // 14: proto.SyncStatusRequest
+	(*PeerBansRequest)(nil),                // 15: proto.PeerBansRequest
+	(*PeerInfoRequest)(nil),                // 16: proto.PeerInfoRequest
+	(*TaskRunsRequest)(nil),                // 17: proto.TaskRunsRequest
+	(*GetDataResponse)(nil),                // 18: proto.GetDataResponse
+	(*GetValueResponse)(nil),               // 19: proto.GetValueResponse
+	(*IterateNameSpaceResponse)(nil),       // 20: proto.IterateNameSpaceResponse
+	(*MinedTransactionResponse)(nil),       // 21: proto.MinedTransactionResponse
+	(*BlockHeaderResponse)(nil),            // 22: proto.BlockHeaderResponse
+	(*UTXOResponse)(nil),                   // 23: proto.UTXOResponse
+	(*PendingTransactionResponse)(nil),     // 24: proto.PendingTransactionResponse
+	(*RoundStateForValidatorResponse)(nil), // 25: proto.RoundStateForValidatorResponse
+	(*ValidatorSetResponse)(nil),           // 26: proto.ValidatorSetResponse
+	(*BlockNumberResponse)(nil),            // 27: proto.BlockNumberResponse
+	(*ChainIDResponse)(nil),                // 28: proto.ChainIDResponse
+	(*TransactionDetails)(nil),             // 29: proto.TransactionDetails
+	(*EpochNumberResponse)(nil),            // 30: proto.EpochNumberResponse
+	(*TxBlockNumberResponse)(nil),          // 31: proto.TxBlockNumberResponse
+	(*SyncStatusResponse)(nil),             // 32: proto.SyncStatusResponse
+	(*PeerBansResponse)(nil),               // 33: proto.PeerBansResponse
+	(*PeerInfoResponse)(nil),               // 34: proto.PeerInfoResponse
+	(*TaskRunsResponse)(nil),               // 35: proto.TaskRunsResponse
 }
 var file_localstate_proto_depIdxs = []int32{
 	0,  // 0: proto.LocalState.GetData:input_type -> proto.GetDataRequest
@@ -182,22 +214,30 @@ var file_localstate_proto_depIdxs = []int32{
 	11, // 11: proto.LocalState.SendTransaction:input_type -> proto.TransactionData
 	12, // 12: proto.LocalState.GetEpochNumber:input_type -> proto.EpochNumberRequest
 	13, // 13: proto.LocalState.GetTxBlockNumber:input_type -> proto.TxBlockNumberRequest
-	14, // 14: proto.LocalState.GetData:output_type -> proto.GetDataResponse
-	15, // 15: proto.LocalState.GetValueForOwner:output_type -> proto.GetValueResponse
-	16, // 16: proto.LocalState.IterateNameSpace:output_type -> proto.IterateNameSpaceResponse
-	17, // 17: proto.LocalState.GetMinedTransaction:output_type -> proto.MinedTransactionResponse
-	18, // 18: proto.LocalState.GetBlockHeader:output_type -> proto.BlockHeaderResponse
-	19, // 19: proto.LocalState.GetUTXO:output_type -> proto.UTXOResponse
-	20, // 20: proto.LocalState.GetPendingTransaction:output_type -> proto.PendingTransactionResponse
-	21, // 21: proto.LocalState.GetRoundStateForValidator:output_type -> proto.RoundStateForValidatorResponse
-	22, // 22: proto.LocalState.GetValidatorSet:output_type -> proto.ValidatorSetResponse
-	23, // 23: proto.LocalState.GetBlockNumber:output_type -> proto.BlockNumberResponse
-	24, // 24: proto.LocalState.GetChainID:output_type -> proto.ChainIDResponse
-	25, // 25: proto.LocalState.SendTransaction:output_type -> proto.TransactionDetails
-	26, // 26: proto.LocalState.GetEpochNumber:output_type -> proto.EpochNumberResponse
-	27, // 27: proto.LocalState.GetTxBlockNumber:output_type -> proto.TxBlockNumberResponse
-	14, // [14:28] is the sub-list for method output_type
-	0,  // [0:14] is the sub-list for method input_type
+	14, // 14: proto.LocalState.GetSyncStatus:input_type -> proto.SyncStatusRequest
+	15, // 15: proto.LocalState.GetPeerBans:input_type -> proto.PeerBansRequest
+	16, // 16: proto.LocalState.GetPeerInfo:input_type -> proto.PeerInfoRequest
+	17, // 17: proto.LocalState.GetTaskRuns:input_type -> proto.TaskRunsRequest
+	18, // 18: proto.LocalState.GetData:output_type -> proto.GetDataResponse
+	19, // 19: proto.LocalState.GetValueForOwner:output_type -> proto.GetValueResponse
+	20, // 20: proto.LocalState.IterateNameSpace:output_type -> proto.IterateNameSpaceResponse
+	21, // 21: proto.LocalState.GetMinedTransaction:output_type -> proto.MinedTransactionResponse
+	22, // 22: proto.LocalState.GetBlockHeader:output_type -> proto.BlockHeaderResponse
+	23, // 23: proto.LocalState.GetUTXO:output_type -> proto.UTXOResponse
+	24, // 24: proto.LocalState.GetPendingTransaction:output_type -> proto.PendingTransactionResponse
+	25, // 25: proto.LocalState.GetRoundStateForValidator:output_type -> proto.RoundStateForValidatorResponse
+	26, // 26: proto.LocalState.GetValidatorSet:output_type -> proto.ValidatorSetResponse
+	27, // 27: proto.LocalState.GetBlockNumber:output_type -> proto.BlockNumberResponse
+	28, // 28: proto.LocalState.GetChainID:output_type -> proto.ChainIDResponse
+	29, // 29: proto.LocalState.SendTransaction:output_type -> proto.TransactionDetails
+	30, // 30: proto.LocalState.GetEpochNumber:output_type -> proto.EpochNumberResponse
+	31, // 31: proto.LocalState.GetTxBlockNumber:output_type -> proto.TxBlockNumberResponse
+	32, // 32: proto.LocalState.GetSyncStatus:output_type -> proto.SyncStatusResponse
+	33, // 33: proto.LocalState.GetPeerBans:output_type -> proto.PeerBansResponse
+	34, // 34: proto.LocalState.GetPeerInfo:output_type -> proto.PeerInfoResponse
+	35, // 35: proto.LocalState.GetTaskRuns:output_type -> proto.TaskRunsResponse
+	18, // [18:36] is the sub-list for method output_type
+	0,  // [0:18] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -270,6 +310,14 @@ type LocalStateClient interface {
 	GetEpochNumber(ctx context.Context, in *EpochNumberRequest, opts ...grpc.CallOption) (*EpochNumberResponse, error)
 	// Get the current block number
 	GetTxBlockNumber(ctx context.Context, in *TxBlockNumberRequest, opts ...grpc.CallOption) (*TxBlockNumberResponse, error)
+	// Get the progress of fast sync
+	GetSyncStatus(ctx context.Context, in *SyncStatusRequest, opts ...grpc.CallOption) (*SyncStatusResponse, error)
+	// Get the peers that are banned for misbehaving
+	GetPeerBans(ctx context.Context, in *PeerBansRequest, opts ...grpc.CallOption) (*PeerBansResponse, error)
+	// Get the connection and traffic details of the active and inactive peers
+	GetPeerInfo(ctx context.Context, in *PeerInfoRequest, opts ...grpc.CallOption) (*PeerInfoResponse, error)
+	// Get the history of snapshot and ETHDKG task runs
+	GetTaskRuns(ctx context.Context, in *TaskRunsRequest, opts ...grpc.CallOption) (*TaskRunsResponse, error)
 }
 
 type localStateClient struct {
@@ -406,6 +454,42 @@ func (c *localStateClient) GetTxBlockNumber(ctx context.Context, in *TxBlockNumb
 	return out, nil
 }
 
+func (c *localStateClient) GetSyncStatus(ctx context.Context, in *SyncStatusRequest, opts ...grpc.CallOption) (*SyncStatusResponse, error) {
+	out := new(SyncStatusResponse)
+	err := c.cc.Invoke(ctx, "/proto.LocalState/GetSyncStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localStateClient) GetPeerBans(ctx context.Context, in *PeerBansRequest, opts ...grpc.CallOption) (*PeerBansResponse, error) {
+	out := new(PeerBansResponse)
+	err := c.cc.Invoke(ctx, "/proto.LocalState/GetPeerBans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localStateClient) GetPeerInfo(ctx context.Context, in *PeerInfoRequest, opts ...grpc.CallOption) (*PeerInfoResponse, error) {
+	out := new(PeerInfoResponse)
+	err := c.cc.Invoke(ctx, "/proto.LocalState/GetPeerInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *localStateClient) GetTaskRuns(ctx context.Context, in *TaskRunsRequest, opts ...grpc.CallOption) (*TaskRunsResponse, error) {
+	out := new(TaskRunsResponse)
+	err := c.cc.Invoke(ctx, "/proto.LocalState/GetTaskRuns", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LocalStateServer is the server API for LocalState service.
 type LocalStateServer interface {
 	// Get only the raw data from a datastore UTXO that has been mined into chain
@@ -438,6 +522,14 @@ type LocalStateServer interface {
 	GetEpochNumber(context.Context, *EpochNumberRequest) (*EpochNumberResponse, error)
 	// Get the current block number
 	GetTxBlockNumber(context.Context, *TxBlockNumberRequest) (*TxBlockNumberResponse, error)
+	// Get the progress of fast sync
+	GetSyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusResponse, error)
+	// Get the peers that are banned for misbehaving
+	GetPeerBans(context.Context, *PeerBansRequest) (*PeerBansResponse, error)
+	// Get the connection and traffic details of the active and inactive peers
+	GetPeerInfo(context.Context, *PeerInfoRequest) (*PeerInfoResponse, error)
+	// Get the history of snapshot and ETHDKG task runs
+	GetTaskRuns(context.Context, *TaskRunsRequest) (*TaskRunsResponse, error)
 }
 
 // UnimplementedLocalStateServer can be embedded to have forward compatible implementations.
@@ -486,6 +578,18 @@ func (*UnimplementedLocalStateServer) GetEpochNumber(context.Context, *EpochNumb
 func (*UnimplementedLocalStateServer) GetTxBlockNumber(context.Context, *TxBlockNumberRequest) (*TxBlockNumberResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTxBlockNumber not implemented")
 }
+func (*UnimplementedLocalStateServer) GetSyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSyncStatus not implemented")
+}
+func (*UnimplementedLocalStateServer) GetPeerBans(context.Context, *PeerBansRequest) (*PeerBansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeerBans not implemented")
+}
+func (*UnimplementedLocalStateServer) GetPeerInfo(context.Context, *PeerInfoRequest) (*PeerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeerInfo not implemented")
+}
+func (*UnimplementedLocalStateServer) GetTaskRuns(context.Context, *TaskRunsRequest) (*TaskRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskRuns not implemented")
+}
 
 func RegisterLocalStateServer(s *grpc.Server, srv LocalStateServer) {
 	s.RegisterService(&_LocalState_serviceDesc, srv)
@@ -743,6 +847,78 @@ func _LocalState_GetTxBlockNumber_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LocalState_GetSyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalStateServer).GetSyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LocalState/GetSyncStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalStateServer).GetSyncStatus(ctx, req.(*SyncStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalState_GetPeerBans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerBansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalStateServer).GetPeerBans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LocalState/GetPeerBans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalStateServer).GetPeerBans(ctx, req.(*PeerBansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalState_GetPeerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalStateServer).GetPeerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LocalState/GetPeerInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalStateServer).GetPeerInfo(ctx, req.(*PeerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LocalState_GetTaskRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocalStateServer).GetTaskRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LocalState/GetTaskRuns",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocalStateServer).GetTaskRuns(ctx, req.(*TaskRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _LocalState_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.LocalState",
 	HandlerType: (*LocalStateServer)(nil),
@@ -803,6 +979,22 @@ var _LocalState_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetTxBlockNumber",
 			Handler:    _LocalState_GetTxBlockNumber_Handler,
 		},
+		{
+			MethodName: "GetSyncStatus",
+			Handler:    _LocalState_GetSyncStatus_Handler,
+		},
+		{
+			MethodName: "GetPeerBans",
+			Handler:    _LocalState_GetPeerBans_Handler,
+		},
+		{
+			MethodName: "GetPeerInfo",
+			Handler:    _LocalState_GetPeerInfo_Handler,
+		},
+		{
+			MethodName: "GetTaskRuns",
+			Handler:    _LocalState_GetTaskRuns_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "localstate.proto",
@@ -1394,32 +1394,1039 @@ func (x *RoundStateForValidatorResponse) GetRoundState() []byte {
 	return nil
 }
 
+type SyncStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SyncStatusRequest) Reset() {
+	*x = SyncStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatusRequest) ProtoMessage() {}
+
+func (x *SyncStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatusRequest.ProtoReflect.Descriptor instead.
+func (*SyncStatusRequest) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{28}
+}
+
+type SyncStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Active             bool                       `protobuf:"varint,1,opt,name=Active,proto3" json:"Active,omitempty"`
+	Resumed            bool                       `protobuf:"varint,2,opt,name=Resumed,proto3" json:"Resumed,omitempty"` // true if sync continued from persisted pending keys
+	SnapShotHeight     uint32                     `protobuf:"varint,3,opt,name=SnapShotHeight,proto3" json:"SnapShotHeight,omitempty"`
+	PendingHdrNodes    uint32                     `protobuf:"varint,4,opt,name=PendingHdrNodes,proto3" json:"PendingHdrNodes,omitempty"`
+	PendingHdrLeaves   uint32                     `protobuf:"varint,5,opt,name=PendingHdrLeaves,proto3" json:"PendingHdrLeaves,omitempty"`
+	PendingStateNodes  uint32                     `protobuf:"varint,6,opt,name=PendingStateNodes,proto3" json:"PendingStateNodes,omitempty"`
+	PendingStateLeaves uint32                     `protobuf:"varint,7,opt,name=PendingStateLeaves,proto3" json:"PendingStateLeaves,omitempty"`
+	StagedBlockHeaders uint32                     `protobuf:"varint,8,opt,name=StagedBlockHeaders,proto3" json:"StagedBlockHeaders,omitempty"`
+	Stored             uint64                     `protobuf:"varint,9,opt,name=Stored,proto3" json:"Stored,omitempty"` // nodes, leaves and headers stored since start
+	ItemsPerSecond     float64                    `protobuf:"fixed64,10,opt,name=ItemsPerSecond,proto3" json:"ItemsPerSecond,omitempty"`
+	ETASeconds         uint64                     `protobuf:"varint,11,opt,name=ETASeconds,proto3" json:"ETASeconds,omitempty"` // estimate from the currently pending keys
+	Peers              []*SyncStatusResponse_Peer `protobuf:"bytes,12,rep,name=Peers,proto3" json:"Peers,omitempty"`
+}
+
+func (x *SyncStatusResponse) Reset() {
+	*x = SyncStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatusResponse) ProtoMessage() {}
+
+func (x *SyncStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatusResponse.ProtoReflect.Descriptor instead.
+func (*SyncStatusResponse) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SyncStatusResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *SyncStatusResponse) GetResumed() bool {
+	if x != nil {
+		return x.Resumed
+	}
+	return false
+}
+
+func (x *SyncStatusResponse) GetSnapShotHeight() uint32 {
+	if x != nil {
+		return x.SnapShotHeight
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetPendingHdrNodes() uint32 {
+	if x != nil {
+		return x.PendingHdrNodes
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetPendingHdrLeaves() uint32 {
+	if x != nil {
+		return x.PendingHdrLeaves
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetPendingStateNodes() uint32 {
+	if x != nil {
+		return x.PendingStateNodes
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetPendingStateLeaves() uint32 {
+	if x != nil {
+		return x.PendingStateLeaves
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetStagedBlockHeaders() uint32 {
+	if x != nil {
+		return x.StagedBlockHeaders
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetStored() uint64 {
+	if x != nil {
+		return x.Stored
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetItemsPerSecond() float64 {
+	if x != nil {
+		return x.ItemsPerSecond
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetETASeconds() uint64 {
+	if x != nil {
+		return x.ETASeconds
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetPeers() []*SyncStatusResponse_Peer {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type PeerBansRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PeerBansRequest) Reset() {
+	*x = PeerBansRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerBansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerBansRequest) ProtoMessage() {}
+
+func (x *PeerBansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerBansRequest.ProtoReflect.Descriptor instead.
+func (*PeerBansRequest) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{30}
+}
+
+type PeerBansResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bans []*PeerBansResponse_Ban `protobuf:"bytes,1,rep,name=Bans,proto3" json:"Bans,omitempty"`
+}
+
+func (x *PeerBansResponse) Reset() {
+	*x = PeerBansResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerBansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerBansResponse) ProtoMessage() {}
+
+func (x *PeerBansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerBansResponse.ProtoReflect.Descriptor instead.
+func (*PeerBansResponse) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PeerBansResponse) GetBans() []*PeerBansResponse_Ban {
+	if x != nil {
+		return x.Bans
+	}
+	return nil
+}
+
+type PeerInfoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PeerInfoRequest) Reset() {
+	*x = PeerInfoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoRequest) ProtoMessage() {}
+
+func (x *PeerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoRequest.ProtoReflect.Descriptor instead.
+func (*PeerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{32}
+}
+
+type PeerInfoResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Actives   []*PeerInfoResponse_Active    `protobuf:"bytes,1,rep,name=Actives,proto3" json:"Actives,omitempty"`
+	Inactives []*PeerInfoResponse_Inactive  `protobuf:"bytes,2,rep,name=Inactives,proto3" json:"Inactives,omitempty"`
+	Totals    []*PeerInfoResponse_Bandwidth `protobuf:"bytes,3,rep,name=Totals,proto3" json:"Totals,omitempty"` // per protocol since start
+}
+
+func (x *PeerInfoResponse) Reset() {
+	*x = PeerInfoResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoResponse) ProtoMessage() {}
+
+func (x *PeerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoResponse.ProtoReflect.Descriptor instead.
+func (*PeerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *PeerInfoResponse) GetActives() []*PeerInfoResponse_Active {
+	if x != nil {
+		return x.Actives
+	}
+	return nil
+}
+
+func (x *PeerInfoResponse) GetInactives() []*PeerInfoResponse_Inactive {
+	if x != nil {
+		return x.Inactives
+	}
+	return nil
+}
+
+func (x *PeerInfoResponse) GetTotals() []*PeerInfoResponse_Bandwidth {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+type TaskRunsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key    string `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`       // only the run with this idempotency key if set
+	Status string `protobuf:"bytes,2,opt,name=Status,proto3" json:"Status,omitempty"` // only the runs in this status if set, e.g. DeadLetter
+}
+
+func (x *TaskRunsRequest) Reset() {
+	*x = TaskRunsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskRunsRequest) ProtoMessage() {}
+
+func (x *TaskRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskRunsRequest.ProtoReflect.Descriptor instead.
+func (*TaskRunsRequest) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TaskRunsRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TaskRunsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type TaskRunsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Runs []*TaskRunsResponse_Run `protobuf:"bytes,1,rep,name=Runs,proto3" json:"Runs,omitempty"`
+}
+
+func (x *TaskRunsResponse) Reset() {
+	*x = TaskRunsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskRunsResponse) ProtoMessage() {}
+
+func (x *TaskRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskRunsResponse.ProtoReflect.Descriptor instead.
+func (*TaskRunsResponse) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *TaskRunsResponse) GetRuns() []*TaskRunsResponse_Run {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
 type IterateNameSpaceResponse_Result struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	UTXOID string `protobuf:"bytes,1,opt,name=UTXOID,proto3" json:"UTXOID,omitempty"`
-	Index  string `protobuf:"bytes,2,opt,name=Index,proto3" json:"Index,omitempty"`
+	UTXOID string `protobuf:"bytes,1,opt,name=UTXOID,proto3" json:"UTXOID,omitempty"`
+	Index  string `protobuf:"bytes,2,opt,name=Index,proto3" json:"Index,omitempty"`
+}
+
+func (x *IterateNameSpaceResponse_Result) Reset() {
+	*x = IterateNameSpaceResponse_Result{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IterateNameSpaceResponse_Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IterateNameSpaceResponse_Result) ProtoMessage() {}
+
+func (x *IterateNameSpaceResponse_Result) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IterateNameSpaceResponse_Result.ProtoReflect.Descriptor instead.
+func (*IterateNameSpaceResponse_Result) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{21, 0}
+}
+
+func (x *IterateNameSpaceResponse_Result) GetUTXOID() string {
+	if x != nil {
+		return x.UTXOID
+	}
+	return ""
+}
+
+func (x *IterateNameSpaceResponse_Result) GetIndex() string {
+	if x != nil {
+		return x.Index
+	}
+	return ""
+}
+
+type SyncStatusResponse_Peer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Identity  string `protobuf:"bytes,1,opt,name=Identity,proto3" json:"Identity,omitempty"`
+	Addr      string `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	Responses uint64 `protobuf:"varint,3,opt,name=Responses,proto3" json:"Responses,omitempty"`
+	Failures  uint64 `protobuf:"varint,4,opt,name=Failures,proto3" json:"Failures,omitempty"`
+	Bytes     uint64 `protobuf:"varint,5,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+}
+
+func (x *SyncStatusResponse_Peer) Reset() {
+	*x = SyncStatusResponse_Peer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SyncStatusResponse_Peer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatusResponse_Peer) ProtoMessage() {}
+
+func (x *SyncStatusResponse_Peer) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatusResponse_Peer.ProtoReflect.Descriptor instead.
+func (*SyncStatusResponse_Peer) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{29, 0}
+}
+
+func (x *SyncStatusResponse_Peer) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *SyncStatusResponse_Peer) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *SyncStatusResponse_Peer) GetResponses() uint64 {
+	if x != nil {
+		return x.Responses
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse_Peer) GetFailures() uint64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse_Peer) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type PeerBansResponse_Ban struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Identity string `protobuf:"bytes,1,opt,name=Identity,proto3" json:"Identity,omitempty"`
+	Addr     string `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	Reason   string `protobuf:"bytes,3,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	Until    uint64 `protobuf:"varint,4,opt,name=Until,proto3" json:"Until,omitempty"` // unix time in seconds
+}
+
+func (x *PeerBansResponse_Ban) Reset() {
+	*x = PeerBansResponse_Ban{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerBansResponse_Ban) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerBansResponse_Ban) ProtoMessage() {}
+
+func (x *PeerBansResponse_Ban) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerBansResponse_Ban.ProtoReflect.Descriptor instead.
+func (*PeerBansResponse_Ban) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{31, 0}
+}
+
+func (x *PeerBansResponse_Ban) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *PeerBansResponse_Ban) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *PeerBansResponse_Ban) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PeerBansResponse_Ban) GetUntil() uint64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type PeerInfoResponse_Bandwidth struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Protocol      string `protobuf:"bytes,1,opt,name=Protocol,proto3" json:"Protocol,omitempty"`
+	BytesSent     uint64 `protobuf:"varint,2,opt,name=BytesSent,proto3" json:"BytesSent,omitempty"`
+	BytesReceived uint64 `protobuf:"varint,3,opt,name=BytesReceived,proto3" json:"BytesReceived,omitempty"`
+}
+
+func (x *PeerInfoResponse_Bandwidth) Reset() {
+	*x = PeerInfoResponse_Bandwidth{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerInfoResponse_Bandwidth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoResponse_Bandwidth) ProtoMessage() {}
+
+func (x *PeerInfoResponse_Bandwidth) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoResponse_Bandwidth.ProtoReflect.Descriptor instead.
+func (*PeerInfoResponse_Bandwidth) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{33, 0}
+}
+
+func (x *PeerInfoResponse_Bandwidth) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *PeerInfoResponse_Bandwidth) GetBytesSent() uint64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Bandwidth) GetBytesReceived() uint64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+type PeerInfoResponse_Traffic struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Method        string `protobuf:"bytes,1,opt,name=Method,proto3" json:"Method,omitempty"`
+	MsgsSent      uint64 `protobuf:"varint,2,opt,name=MsgsSent,proto3" json:"MsgsSent,omitempty"`
+	MsgsReceived  uint64 `protobuf:"varint,3,opt,name=MsgsReceived,proto3" json:"MsgsReceived,omitempty"`
+	BytesSent     uint64 `protobuf:"varint,4,opt,name=BytesSent,proto3" json:"BytesSent,omitempty"`
+	BytesReceived uint64 `protobuf:"varint,5,opt,name=BytesReceived,proto3" json:"BytesReceived,omitempty"`
+}
+
+func (x *PeerInfoResponse_Traffic) Reset() {
+	*x = PeerInfoResponse_Traffic{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerInfoResponse_Traffic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoResponse_Traffic) ProtoMessage() {}
+
+func (x *PeerInfoResponse_Traffic) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoResponse_Traffic.ProtoReflect.Descriptor instead.
+func (*PeerInfoResponse_Traffic) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{33, 1}
+}
+
+func (x *PeerInfoResponse_Traffic) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *PeerInfoResponse_Traffic) GetMsgsSent() uint64 {
+	if x != nil {
+		return x.MsgsSent
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Traffic) GetMsgsReceived() uint64 {
+	if x != nil {
+		return x.MsgsReceived
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Traffic) GetBytesSent() uint64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Traffic) GetBytesReceived() uint64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+type PeerInfoResponse_Active struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Identity         string                        `protobuf:"bytes,1,opt,name=Identity,proto3" json:"Identity,omitempty"`
+	Addr             string                        `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	Inbound          bool                          `protobuf:"varint,3,opt,name=Inbound,proto3" json:"Inbound,omitempty"`
+	ConnectedSeconds uint64                        `protobuf:"varint,4,opt,name=ConnectedSeconds,proto3" json:"ConnectedSeconds,omitempty"` // age of the connection
+	ProtoVersion     uint32                        `protobuf:"varint,5,opt,name=ProtoVersion,proto3" json:"ProtoVersion,omitempty"`
+	ChainID          uint32                        `protobuf:"varint,6,opt,name=ChainID,proto3" json:"ChainID,omitempty"`
+	LastGossip       uint64                        `protobuf:"varint,7,opt,name=LastGossip,proto3" json:"LastGossip,omitempty"` // unix time in seconds, zero if none received
+	Score            int64                         `protobuf:"varint,8,opt,name=Score,proto3" json:"Score,omitempty"`
+	Traffic          []*PeerInfoResponse_Traffic   `protobuf:"bytes,9,rep,name=Traffic,proto3" json:"Traffic,omitempty"`
+	Bandwidth        []*PeerInfoResponse_Bandwidth `protobuf:"bytes,10,rep,name=Bandwidth,proto3" json:"Bandwidth,omitempty"` // per protocol
+	ClientStream     *PeerInfoResponse_Bandwidth   `protobuf:"bytes,11,opt,name=ClientStream,proto3" json:"ClientStream,omitempty"`
+	ServerStream     *PeerInfoResponse_Bandwidth   `protobuf:"bytes,12,opt,name=ServerStream,proto3" json:"ServerStream,omitempty"`
+}
+
+func (x *PeerInfoResponse_Active) Reset() {
+	*x = PeerInfoResponse_Active{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerInfoResponse_Active) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoResponse_Active) ProtoMessage() {}
+
+func (x *PeerInfoResponse_Active) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoResponse_Active.ProtoReflect.Descriptor instead.
+func (*PeerInfoResponse_Active) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{33, 2}
+}
+
+func (x *PeerInfoResponse_Active) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *PeerInfoResponse_Active) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *PeerInfoResponse_Active) GetInbound() bool {
+	if x != nil {
+		return x.Inbound
+	}
+	return false
+}
+
+func (x *PeerInfoResponse_Active) GetConnectedSeconds() uint64 {
+	if x != nil {
+		return x.ConnectedSeconds
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Active) GetProtoVersion() uint32 {
+	if x != nil {
+		return x.ProtoVersion
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Active) GetChainID() uint32 {
+	if x != nil {
+		return x.ChainID
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Active) GetLastGossip() uint64 {
+	if x != nil {
+		return x.LastGossip
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Active) GetScore() int64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *PeerInfoResponse_Active) GetTraffic() []*PeerInfoResponse_Traffic {
+	if x != nil {
+		return x.Traffic
+	}
+	return nil
+}
+
+func (x *PeerInfoResponse_Active) GetBandwidth() []*PeerInfoResponse_Bandwidth {
+	if x != nil {
+		return x.Bandwidth
+	}
+	return nil
+}
+
+func (x *PeerInfoResponse_Active) GetClientStream() *PeerInfoResponse_Bandwidth {
+	if x != nil {
+		return x.ClientStream
+	}
+	return nil
+}
+
+func (x *PeerInfoResponse_Active) GetServerStream() *PeerInfoResponse_Bandwidth {
+	if x != nil {
+		return x.ServerStream
+	}
+	return nil
+}
+
+type PeerInfoResponse_Inactive struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Identity      string `protobuf:"bytes,1,opt,name=Identity,proto3" json:"Identity,omitempty"`
+	Addr          string `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	CooldownUntil uint64 `protobuf:"varint,3,opt,name=CooldownUntil,proto3" json:"CooldownUntil,omitempty"` // unix time in seconds, zero if not in cooldown
+}
+
+func (x *PeerInfoResponse_Inactive) Reset() {
+	*x = PeerInfoResponse_Inactive{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeerInfoResponse_Inactive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoResponse_Inactive) ProtoMessage() {}
+
+func (x *PeerInfoResponse_Inactive) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoResponse_Inactive.ProtoReflect.Descriptor instead.
+func (*PeerInfoResponse_Inactive) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{33, 3}
+}
+
+func (x *PeerInfoResponse_Inactive) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *PeerInfoResponse_Inactive) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *PeerInfoResponse_Inactive) GetCooldownUntil() uint64 {
+	if x != nil {
+		return x.CooldownUntil
+	}
+	return 0
+}
+
+type TaskRunsResponse_Attempt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Started uint64 `protobuf:"varint,1,opt,name=Started,proto3" json:"Started,omitempty"` // unix time in seconds
+	Block   uint64 `protobuf:"varint,2,opt,name=Block,proto3" json:"Block,omitempty"`     // zero if unknown
+	Err     string `protobuf:"bytes,3,opt,name=Err,proto3" json:"Err,omitempty"`          // empty if the attempt succeeded
 }
 
-func (x *IterateNameSpaceResponse_Result) Reset() {
-	*x = IterateNameSpaceResponse_Result{}
+func (x *TaskRunsResponse_Attempt) Reset() {
+	*x = TaskRunsResponse_Attempt{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_localstatetypes_proto_msgTypes[28]
+		mi := &file_localstatetypes_proto_msgTypes[43]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *IterateNameSpaceResponse_Result) String() string {
+func (x *TaskRunsResponse_Attempt) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IterateNameSpaceResponse_Result) ProtoMessage() {}
+func (*TaskRunsResponse_Attempt) ProtoMessage() {}
 
-func (x *IterateNameSpaceResponse_Result) ProtoReflect() protoreflect.Message {
-	mi := &file_localstatetypes_proto_msgTypes[28]
+func (x *TaskRunsResponse_Attempt) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[43]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1430,25 +2437,119 @@ func (x *IterateNameSpaceResponse_Result) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IterateNameSpaceResponse_Result.ProtoReflect.Descriptor instead.
-func (*IterateNameSpaceResponse_Result) Descriptor() ([]byte, []int) {
-	return file_localstatetypes_proto_rawDescGZIP(), []int{21, 0}
+// Deprecated: Use TaskRunsResponse_Attempt.ProtoReflect.Descriptor instead.
+func (*TaskRunsResponse_Attempt) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{35, 0}
 }
 
-func (x *IterateNameSpaceResponse_Result) GetUTXOID() string {
+func (x *TaskRunsResponse_Attempt) GetStarted() uint64 {
 	if x != nil {
-		return x.UTXOID
+		return x.Started
+	}
+	return 0
+}
+
+func (x *TaskRunsResponse_Attempt) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *TaskRunsResponse_Attempt) GetErr() string {
+	if x != nil {
+		return x.Err
 	}
 	return ""
 }
 
-func (x *IterateNameSpaceResponse_Result) GetIndex() string {
+type TaskRunsResponse_Run struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string                      `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`
+	Name     string                      `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	Status   string                      `protobuf:"bytes,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	Reason   string                      `protobuf:"bytes,4,opt,name=Reason,proto3" json:"Reason,omitempty"`      // why the run was dead lettered
+	Deadline uint64                      `protobuf:"varint,5,opt,name=Deadline,proto3" json:"Deadline,omitempty"` // zero if none
+	Attempts []*TaskRunsResponse_Attempt `protobuf:"bytes,6,rep,name=Attempts,proto3" json:"Attempts,omitempty"`
+}
+
+func (x *TaskRunsResponse_Run) Reset() {
+	*x = TaskRunsResponse_Run{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_localstatetypes_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskRunsResponse_Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskRunsResponse_Run) ProtoMessage() {}
+
+func (x *TaskRunsResponse_Run) ProtoReflect() protoreflect.Message {
+	mi := &file_localstatetypes_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskRunsResponse_Run.ProtoReflect.Descriptor instead.
+func (*TaskRunsResponse_Run) Descriptor() ([]byte, []int) {
+	return file_localstatetypes_proto_rawDescGZIP(), []int{35, 1}
+}
+
+func (x *TaskRunsResponse_Run) GetKey() string {
 	if x != nil {
-		return x.Index
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TaskRunsResponse_Run) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TaskRunsResponse_Run) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TaskRunsResponse_Run) GetReason() string {
+	if x != nil {
+		return x.Reason
 	}
 	return ""
 }
 
+func (x *TaskRunsResponse_Run) GetDeadline() uint64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *TaskRunsResponse_Run) GetAttempts() []*TaskRunsResponse_Attempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
 var File_localstatetypes_proto protoreflect.FileDescriptor
 
 var file_localstatetypes_proto_rawDesc = []byte{
@@ -1563,7 +2664,153 @@ var file_localstatetypes_proto_rawDesc = []byte{
 	0x61, 0x74, 0x65, 0x46, 0x6f, 0x72, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x52,
 	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x52, 0x6f, 0x75, 0x6e, 0x64,
 	0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x52, 0x6f, 0x75,
-	0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x22, 0x13, 0x0a, 0x11, 0x53, 0x79, 0x6e, 0x63, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xf1, 0x04, 0x0a,
+	0x12, 0x53, 0x79, 0x6e, 0x63, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x52,
+	0x65, 0x73, 0x75, 0x6d, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x52, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x53, 0x6e, 0x61, 0x70, 0x53, 0x68, 0x6f,
+	0x74, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x53,
+	0x6e, 0x61, 0x70, 0x53, 0x68, 0x6f, 0x74, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x28, 0x0a,
+	0x0f, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x64, 0x72, 0x4e, 0x6f, 0x64, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x48,
+	0x64, 0x72, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x50, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x48, 0x64, 0x72, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x10, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x48, 0x64, 0x72, 0x4c, 0x65, 0x61,
+	0x76, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11,
+	0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x12, 0x2e, 0x0a, 0x12, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x50,
+	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x4c, 0x65, 0x61, 0x76, 0x65,
+	0x73, 0x12, 0x2e, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x67, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x53,
+	0x74, 0x61, 0x67, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x49, 0x74, 0x65,
+	0x6d, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0e, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x45, 0x54, 0x41, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x45, 0x54, 0x41, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x12, 0x34, 0x0a, 0x05, 0x50, 0x65, 0x65, 0x72, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x50, 0x65, 0x65, 0x72,
+	0x52, 0x05, 0x50, 0x65, 0x65, 0x72, 0x73, 0x1a, 0x86, 0x01, 0x0a, 0x04, 0x50, 0x65, 0x65, 0x72,
+	0x12, 0x1a, 0x0a, 0x08, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x41, 0x64, 0x64, 0x72,
+	0x12, 0x1c, 0x0a, 0x09, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x09, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x22, 0x11, 0x0a, 0x0f, 0x50, 0x65, 0x65, 0x72, 0x42, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x22, 0xa8, 0x01, 0x0a, 0x10, 0x50, 0x65, 0x65, 0x72, 0x42, 0x61, 0x6e, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x04, 0x42, 0x61, 0x6e, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50,
+	0x65, 0x65, 0x72, 0x42, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x42, 0x61, 0x6e, 0x52, 0x04, 0x42, 0x61, 0x6e, 0x73, 0x1a, 0x63, 0x0a, 0x03, 0x42, 0x61, 0x6e,
+	0x12, 0x1a, 0x0a, 0x08, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x41, 0x64, 0x64, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x55, 0x6e, 0x74, 0x69,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x22, 0x11,
+	0x0a, 0x0f, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0xbd, 0x08, 0x0a, 0x10, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x07, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x52, 0x07, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x73,
+	0x12, 0x3e, 0x0a, 0x09, 0x49, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x49, 0x6e, 0x61,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x52, 0x09, 0x49, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x73,
+	0x12, 0x39, 0x0a, 0x06, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66,
+	0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69,
+	0x64, 0x74, 0x68, 0x52, 0x06, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x73, 0x1a, 0x6b, 0x0a, 0x09, 0x42,
+	0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x1c, 0x0a, 0x09, 0x42, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x42, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65,
+	0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x1a, 0xa5, 0x01, 0x0a, 0x07, 0x54, 0x72, 0x61,
+	0x66, 0x66, 0x69, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x4d, 0x73, 0x67, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
+	0x4d, 0x73, 0x67, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x4d, 0x73, 0x67, 0x73,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
+	0x4d, 0x73, 0x67, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x42, 0x79, 0x74, 0x65, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0d, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x1a, 0xfc, 0x03, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x41, 0x64, 0x64, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x41, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x49,
+	0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x49, 0x6e,
+	0x62, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x10, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x10, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x12, 0x22, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x12,
+	0x1e, 0x0a, 0x0a, 0x4c, 0x61, 0x73, 0x74, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0a, 0x4c, 0x61, 0x73, 0x74, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x12,
+	0x14, 0x0a, 0x05, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x39, 0x0a, 0x07, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63,
+	0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50,
+	0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x52, 0x07, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63,
+	0x12, 0x3f, 0x0a, 0x09, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x0a, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x6e,
+	0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x52, 0x09, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74,
+	0x68, 0x12, 0x45, 0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x2e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x52, 0x0c, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x45, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74,
+	0x68, 0x52, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x1a,
+	0x60, 0x0a, 0x08, 0x49, 0x6e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x41, 0x64, 0x64, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x41, 0x64, 0x64, 0x72, 0x12, 0x24, 0x0a, 0x0d, 0x43,
+	0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0d, 0x43, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x55, 0x6e, 0x74, 0x69,
+	0x6c, 0x22, 0x3b, 0x0a, 0x0f, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xc7,
+	0x02, 0x0a, 0x10, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x04, 0x52, 0x75, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x75,
+	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x52, 0x75, 0x6e, 0x52, 0x04,
+	0x52, 0x75, 0x6e, 0x73, 0x1a, 0x4b, 0x0a, 0x07, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12,
+	0x10, 0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x45, 0x72,
+	0x72, 0x1a, 0xb4, 0x01, 0x0a, 0x03, 0x52, 0x75, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x4b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x4b, 0x65, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x08, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x3b, 0x0a, 0x08, 0x41,
+	0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x52, 0x08,
+	0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1578,7 +2825,7 @@ func file_localstatetypes_proto_rawDescGZIP() []byte {
 	return file_localstatetypes_proto_rawDescData
 }
 
-var file_localstatetypes_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_localstatetypes_proto_msgTypes = make([]protoimpl.MessageInfo, 45)
 var file_localstatetypes_proto_goTypes = []interface{}{
 	(*GetDataRequest)(nil),                  // 0: proto.GetDataRequest
 	(*GetDataResponse)(nil),                 // 1: proto.GetDataResponse
@@ -1608,23 +2855,50 @@ var file_localstatetypes_proto_goTypes = []interface{}{
 	(*ValidatorSetResponse)(nil),            // 25: proto.ValidatorSetResponse
 	(*RoundStateForValidatorRequest)(nil),   // 26: proto.RoundStateForValidatorRequest
 	(*RoundStateForValidatorResponse)(nil),  // 27: proto.RoundStateForValidatorResponse
-	(*IterateNameSpaceResponse_Result)(nil), // 28: proto.IterateNameSpaceResponse.Result
-	(*Tx)(nil),                              // 29: proto.Tx
-	(*BlockHeader)(nil),                     // 30: proto.BlockHeader
-	(*TXOut)(nil),                           // 31: proto.TXOut
+	(*SyncStatusRequest)(nil),               // 28: proto.SyncStatusRequest
+	(*SyncStatusResponse)(nil),              // 29: proto.SyncStatusResponse
+	(*PeerBansRequest)(nil),                 // 30: proto.PeerBansRequest
+	(*PeerBansResponse)(nil),                // 31: proto.PeerBansResponse
+	(*PeerInfoRequest)(nil),                 // 32: proto.PeerInfoRequest
+	(*PeerInfoResponse)(nil),                // 33: proto.PeerInfoResponse
+	(*TaskRunsRequest)(nil),                 // 34: proto.TaskRunsRequest
+	(*TaskRunsResponse)(nil),                // 35: proto.TaskRunsResponse
+	(*IterateNameSpaceResponse_Result)(nil), // 36: proto.IterateNameSpaceResponse.Result
+	(*SyncStatusResponse_Peer)(nil),         // 37: proto.SyncStatusResponse.Peer
+	(*PeerBansResponse_Ban)(nil),            // 38: proto.PeerBansResponse.Ban
+	(*PeerInfoResponse_Bandwidth)(nil),      // 39: proto.PeerInfoResponse.Bandwidth
+	(*PeerInfoResponse_Traffic)(nil),        // 40: proto.PeerInfoResponse.Traffic
+	(*PeerInfoResponse_Active)(nil),         // 41: proto.PeerInfoResponse.Active
+	(*PeerInfoResponse_Inactive)(nil),       // 42: proto.PeerInfoResponse.Inactive
+	(*TaskRunsResponse_Attempt)(nil),        // 43: proto.TaskRunsResponse.Attempt
+	(*TaskRunsResponse_Run)(nil),            // 44: proto.TaskRunsResponse.Run
+	(*Tx)(nil),                              // 45: proto.Tx
+	(*BlockHeader)(nil),                     // 46: proto.BlockHeader
+	(*TXOut)(nil),                           // 47: proto.TXOut
 }
 var file_localstatetypes_proto_depIdxs = []int32{
-	29, // 0: proto.MinedTransactionResponse.Tx:type_name -> proto.Tx
-	30, // 1: proto.BlockHeaderResponse.BlockHeader:type_name -> proto.BlockHeader
-	31, // 2: proto.UTXOResponse.UTXOs:type_name -> proto.TXOut
-	29, // 3: proto.PendingTransactionResponse.Tx:type_name -> proto.Tx
-	29, // 4: proto.TransactionData.Tx:type_name -> proto.Tx
-	28, // 5: proto.IterateNameSpaceResponse.Results:type_name -> proto.IterateNameSpaceResponse.Result
-	6,  // [6:6] is the sub-list for method output_type
-	6,  // [6:6] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	45, // 0: proto.MinedTransactionResponse.Tx:type_name -> proto.Tx
+	46, // 1: proto.BlockHeaderResponse.BlockHeader:type_name -> proto.BlockHeader
+	47, // 2: proto.UTXOResponse.UTXOs:type_name -> proto.TXOut
+	45, // 3: proto.PendingTransactionResponse.Tx:type_name -> proto.Tx
+	45, // 4: proto.TransactionData.Tx:type_name -> proto.Tx
+	36, // 5: proto.IterateNameSpaceResponse.Results:type_name -> proto.IterateNameSpaceResponse.Result
+	37, // 6: proto.SyncStatusResponse.Peers:type_name -> proto.SyncStatusResponse.Peer
+	38, // 7: proto.PeerBansResponse.Bans:type_name -> proto.PeerBansResponse.Ban
+	41, // 8: proto.PeerInfoResponse.Actives:type_name -> proto.PeerInfoResponse.Active
+	42, // 9: proto.PeerInfoResponse.Inactives:type_name -> proto.PeerInfoResponse.Inactive
+	39, // 10: proto.PeerInfoResponse.Totals:type_name -> proto.PeerInfoResponse.Bandwidth
+	44, // 11: proto.TaskRunsResponse.Runs:type_name -> proto.TaskRunsResponse.Run
+	40, // 12: proto.PeerInfoResponse.Active.Traffic:type_name -> proto.PeerInfoResponse.Traffic
+	39, // 13: proto.PeerInfoResponse.Active.Bandwidth:type_name -> proto.PeerInfoResponse.Bandwidth
+	39, // 14: proto.PeerInfoResponse.Active.ClientStream:type_name -> proto.PeerInfoResponse.Bandwidth
+	39, // 15: proto.PeerInfoResponse.Active.ServerStream:type_name -> proto.PeerInfoResponse.Bandwidth
+	43, // 16: proto.TaskRunsResponse.Run.Attempts:type_name -> proto.TaskRunsResponse.Attempt
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_localstatetypes_proto_init() }
@@ -1972,6 +3246,102 @@ func file_localstatetypes_proto_init() {
 			}
 		}
 		file_localstatetypes_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerBansRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerBansResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerInfoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerInfoResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaskRunsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaskRunsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*IterateNameSpaceResponse_Result); i {
 			case 0:
 				return &v.state
@@ -1983,6 +3353,102 @@ func file_localstatetypes_proto_init() {
 				return nil
 			}
 		}
+		file_localstatetypes_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncStatusResponse_Peer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerBansResponse_Ban); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerInfoResponse_Bandwidth); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerInfoResponse_Traffic); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerInfoResponse_Active); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeerInfoResponse_Inactive); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaskRunsResponse_Attempt); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_localstatetypes_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaskRunsResponse_Run); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1990,7 +3456,7 @@ func file_localstatetypes_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_localstatetypes_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   29,
+			NumMessages:   45,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
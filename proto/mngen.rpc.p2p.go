@@ -152,12 +152,21 @@ type DiscoveryGetPeersHandler interface {
 	HandleDiscoveryGetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error)
 }
 
+// RateLimiter is consulted by the inboundRPCDispatch before every call is
+// dispatched to a handler. method is the name of the call as <Service><Name>.
+// A non nil error rejects the call and is returned to the caller.
+type RateLimiter interface {
+	Allow(ctx context.Context, method string) error
+}
+
 // inboundRPCDispatch allows handlers to be registered for all RPC methods
 // using the Register<Service><Name> methods.
 // After registration, the inboundRPCDispatch struct will dispatch calls
 // to an rpc method via the methods named as <Service><Name>(...)
 type inboundRPCDispatch struct {
 	sync.Mutex
+	// limiter is the optional rate limiter for all RPC methods
+	limiter RateLimiter
   //	handlerP2PStatus is the registered handler for the
 	//  Status RPC method of service P2P
 	handlerP2PStatus P2PStatusHandler
@@ -286,6 +295,25 @@ type inboundRPCDispatch struct {
 	waitChanDiscoveryGetPeers chan struct{}
 }
 
+// SetRateLimiter will register the object 'l' as the rate limiter for all
+// RPC methods
+func (d *inboundRPCDispatch) SetRateLimiter(l RateLimiter) {
+	d.Lock()
+	defer d.Unlock()
+	d.limiter = l
+}
+
+// allow returns the error of the rate limiter if one is registered
+func (d *inboundRPCDispatch) allow(ctx context.Context, method string) error {
+	d.Lock()
+	l := d.limiter
+	d.Unlock()
+	if l == nil {
+		return nil
+	}
+	return l.Allow(ctx, method)
+}
+
 // RegisterP2PStatus will register the object 't' as the service
 // handler for the RPC method Status from service P2P
 func (d *inboundRPCDispatch) RegisterP2PStatus(t P2PStatusHandler) {
@@ -304,6 +332,10 @@ func (d *inboundRPCDispatch) RegisterP2PStatus(t P2PStatusHandler) {
 // P2PStatus will invoke the handler for the RPC method
 // Status from service P2P
 func (d *inboundRPCDispatch) P2PStatus(ctx context.Context, r *StatusRequest) (*StatusResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PStatus"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -332,6 +364,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetBlockHeaders(t P2PGetBlockHeadersHand
 // P2PGetBlockHeaders will invoke the handler for the RPC method
 // GetBlockHeaders from service P2P
 func (d *inboundRPCDispatch) P2PGetBlockHeaders(ctx context.Context, r *GetBlockHeadersRequest) (*GetBlockHeadersResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetBlockHeaders"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -360,6 +396,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetMinedTxs(t P2PGetMinedTxsHandler) {
 // P2PGetMinedTxs will invoke the handler for the RPC method
 // GetMinedTxs from service P2P
 func (d *inboundRPCDispatch) P2PGetMinedTxs(ctx context.Context, r *GetMinedTxsRequest) (*GetMinedTxsResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetMinedTxs"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -388,6 +428,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetPendingTxs(t P2PGetPendingTxsHandler)
 // P2PGetPendingTxs will invoke the handler for the RPC method
 // GetPendingTxs from service P2P
 func (d *inboundRPCDispatch) P2PGetPendingTxs(ctx context.Context, r *GetPendingTxsRequest) (*GetPendingTxsResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetPendingTxs"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -416,6 +460,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetSnapShotNode(t P2PGetSnapShotNodeHand
 // P2PGetSnapShotNode will invoke the handler for the RPC method
 // GetSnapShotNode from service P2P
 func (d *inboundRPCDispatch) P2PGetSnapShotNode(ctx context.Context, r *GetSnapShotNodeRequest) (*GetSnapShotNodeResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetSnapShotNode"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -444,6 +492,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetSnapShotStateData(t P2PGetSnapShotSta
 // P2PGetSnapShotStateData will invoke the handler for the RPC method
 // GetSnapShotStateData from service P2P
 func (d *inboundRPCDispatch) P2PGetSnapShotStateData(ctx context.Context, r *GetSnapShotStateDataRequest) (*GetSnapShotStateDataResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetSnapShotStateData"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -472,6 +524,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetSnapShotHdrNode(t P2PGetSnapShotHdrNo
 // P2PGetSnapShotHdrNode will invoke the handler for the RPC method
 // GetSnapShotHdrNode from service P2P
 func (d *inboundRPCDispatch) P2PGetSnapShotHdrNode(ctx context.Context, r *GetSnapShotHdrNodeRequest) (*GetSnapShotHdrNodeResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetSnapShotHdrNode"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -500,6 +556,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipTransaction(t P2PGossipTransaction
 // P2PGossipTransaction will invoke the handler for the RPC method
 // GossipTransaction from service P2P
 func (d *inboundRPCDispatch) P2PGossipTransaction(ctx context.Context, r *GossipTransactionMessage) (*GossipTransactionAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipTransaction"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -528,6 +588,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipProposal(t P2PGossipProposalHandle
 // P2PGossipProposal will invoke the handler for the RPC method
 // GossipProposal from service P2P
 func (d *inboundRPCDispatch) P2PGossipProposal(ctx context.Context, r *GossipProposalMessage) (*GossipProposalAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipProposal"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -556,6 +620,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipPreVote(t P2PGossipPreVoteHandler)
 // P2PGossipPreVote will invoke the handler for the RPC method
 // GossipPreVote from service P2P
 func (d *inboundRPCDispatch) P2PGossipPreVote(ctx context.Context, r *GossipPreVoteMessage) (*GossipPreVoteAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipPreVote"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -584,6 +652,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipPreVoteNil(t P2PGossipPreVoteNilHa
 // P2PGossipPreVoteNil will invoke the handler for the RPC method
 // GossipPreVoteNil from service P2P
 func (d *inboundRPCDispatch) P2PGossipPreVoteNil(ctx context.Context, r *GossipPreVoteNilMessage) (*GossipPreVoteNilAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipPreVoteNil"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -612,6 +684,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipPreCommit(t P2PGossipPreCommitHand
 // P2PGossipPreCommit will invoke the handler for the RPC method
 // GossipPreCommit from service P2P
 func (d *inboundRPCDispatch) P2PGossipPreCommit(ctx context.Context, r *GossipPreCommitMessage) (*GossipPreCommitAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipPreCommit"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -640,6 +716,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipPreCommitNil(t P2PGossipPreCommitN
 // P2PGossipPreCommitNil will invoke the handler for the RPC method
 // GossipPreCommitNil from service P2P
 func (d *inboundRPCDispatch) P2PGossipPreCommitNil(ctx context.Context, r *GossipPreCommitNilMessage) (*GossipPreCommitNilAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipPreCommitNil"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -668,6 +748,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipNextRound(t P2PGossipNextRoundHand
 // P2PGossipNextRound will invoke the handler for the RPC method
 // GossipNextRound from service P2P
 func (d *inboundRPCDispatch) P2PGossipNextRound(ctx context.Context, r *GossipNextRoundMessage) (*GossipNextRoundAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipNextRound"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -696,6 +780,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipNextHeight(t P2PGossipNextHeightHa
 // P2PGossipNextHeight will invoke the handler for the RPC method
 // GossipNextHeight from service P2P
 func (d *inboundRPCDispatch) P2PGossipNextHeight(ctx context.Context, r *GossipNextHeightMessage) (*GossipNextHeightAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipNextHeight"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -724,6 +812,10 @@ func (d *inboundRPCDispatch) RegisterP2PGossipBlockHeader(t P2PGossipBlockHeader
 // P2PGossipBlockHeader will invoke the handler for the RPC method
 // GossipBlockHeader from service P2P
 func (d *inboundRPCDispatch) P2PGossipBlockHeader(ctx context.Context, r *GossipBlockHeaderMessage) (*GossipBlockHeaderAck, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGossipBlockHeader"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -752,6 +844,10 @@ func (d *inboundRPCDispatch) RegisterP2PGetPeers(t P2PGetPeersHandler) {
 // P2PGetPeers will invoke the handler for the RPC method
 // GetPeers from service P2P
 func (d *inboundRPCDispatch) P2PGetPeers(ctx context.Context, r *GetPeersRequest) (*GetPeersResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "P2PGetPeers"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
@@ -780,6 +876,10 @@ func (d *inboundRPCDispatch) RegisterDiscoveryGetPeers(t DiscoveryGetPeersHandle
 // DiscoveryGetPeers will invoke the handler for the RPC method
 // GetPeers from service Discovery
 func (d *inboundRPCDispatch) DiscoveryGetPeers(ctx context.Context, r *GetPeersRequest) (*GetPeersResponse, error) {
+	// reject the call if the caller is over its rate limit
+	if err := d.allow(ctx, "DiscoveryGetPeers"); err != nil {
+		return nil, err
+	}
 	// wait for registration to complete or context to be canceled
 	select {
 	case <-ctx.Done():
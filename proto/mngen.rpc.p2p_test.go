@@ -3,10 +3,20 @@ package proto
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
+type testRateLimiter struct {
+	method string
+}
+
+func (tl *testRateLimiter) Allow(ctx context.Context, method string) error {
+	tl.method = method
+	return errors.New("rate limited")
+}
+
 type testP2PStatusHandler struct{}
 
 func (th *testP2PStatusHandler) HandleP2PStatus(context.Context, *StatusRequest) (*StatusResponse, error) {
@@ -75,6 +85,29 @@ func TestP2PStatusCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PStatusRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PStatusHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PStatus(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.Status(context.Background(), &StatusRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PStatus", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetBlockHeadersHandler struct{}
 
 func (th *testP2PGetBlockHeadersHandler) HandleP2PGetBlockHeaders(context.Context, *GetBlockHeadersRequest) (*GetBlockHeadersResponse, error) {
@@ -143,6 +176,29 @@ func TestP2PGetBlockHeadersCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetBlockHeadersRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetBlockHeadersHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetBlockHeaders(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetBlockHeaders(context.Background(), &GetBlockHeadersRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetBlockHeaders", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetMinedTxsHandler struct{}
 
 func (th *testP2PGetMinedTxsHandler) HandleP2PGetMinedTxs(context.Context, *GetMinedTxsRequest) (*GetMinedTxsResponse, error) {
@@ -211,6 +267,29 @@ func TestP2PGetMinedTxsCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetMinedTxsRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetMinedTxsHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetMinedTxs(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetMinedTxs(context.Background(), &GetMinedTxsRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetMinedTxs", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetPendingTxsHandler struct{}
 
 func (th *testP2PGetPendingTxsHandler) HandleP2PGetPendingTxs(context.Context, *GetPendingTxsRequest) (*GetPendingTxsResponse, error) {
@@ -279,6 +358,29 @@ func TestP2PGetPendingTxsCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetPendingTxsRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetPendingTxsHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetPendingTxs(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetPendingTxs(context.Background(), &GetPendingTxsRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetPendingTxs", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetSnapShotNodeHandler struct{}
 
 func (th *testP2PGetSnapShotNodeHandler) HandleP2PGetSnapShotNode(context.Context, *GetSnapShotNodeRequest) (*GetSnapShotNodeResponse, error) {
@@ -347,6 +449,29 @@ func TestP2PGetSnapShotNodeCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetSnapShotNodeRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetSnapShotNodeHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetSnapShotNode(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetSnapShotNode(context.Background(), &GetSnapShotNodeRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetSnapShotNode", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetSnapShotStateDataHandler struct{}
 
 func (th *testP2PGetSnapShotStateDataHandler) HandleP2PGetSnapShotStateData(context.Context, *GetSnapShotStateDataRequest) (*GetSnapShotStateDataResponse, error) {
@@ -415,6 +540,29 @@ func TestP2PGetSnapShotStateDataCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetSnapShotStateDataRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetSnapShotStateDataHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetSnapShotStateData(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetSnapShotStateData(context.Background(), &GetSnapShotStateDataRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetSnapShotStateData", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetSnapShotHdrNodeHandler struct{}
 
 func (th *testP2PGetSnapShotHdrNodeHandler) HandleP2PGetSnapShotHdrNode(context.Context, *GetSnapShotHdrNodeRequest) (*GetSnapShotHdrNodeResponse, error) {
@@ -483,6 +631,29 @@ func TestP2PGetSnapShotHdrNodeCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetSnapShotHdrNodeRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetSnapShotHdrNodeHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetSnapShotHdrNode(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetSnapShotHdrNode(context.Background(), &GetSnapShotHdrNodeRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetSnapShotHdrNode", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipTransactionHandler struct{}
 
 func (th *testP2PGossipTransactionHandler) HandleP2PGossipTransaction(context.Context, *GossipTransactionMessage) (*GossipTransactionAck, error) {
@@ -551,6 +722,29 @@ func TestP2PGossipTransactionCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipTransactionRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipTransactionHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipTransaction(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipTransaction(context.Background(), &GossipTransactionMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipTransaction", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipProposalHandler struct{}
 
 func (th *testP2PGossipProposalHandler) HandleP2PGossipProposal(context.Context, *GossipProposalMessage) (*GossipProposalAck, error) {
@@ -619,6 +813,29 @@ func TestP2PGossipProposalCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipProposalRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipProposalHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipProposal(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipProposal(context.Background(), &GossipProposalMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipProposal", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipPreVoteHandler struct{}
 
 func (th *testP2PGossipPreVoteHandler) HandleP2PGossipPreVote(context.Context, *GossipPreVoteMessage) (*GossipPreVoteAck, error) {
@@ -687,6 +904,29 @@ func TestP2PGossipPreVoteCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipPreVoteRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipPreVoteHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipPreVote(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipPreVote(context.Background(), &GossipPreVoteMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipPreVote", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipPreVoteNilHandler struct{}
 
 func (th *testP2PGossipPreVoteNilHandler) HandleP2PGossipPreVoteNil(context.Context, *GossipPreVoteNilMessage) (*GossipPreVoteNilAck, error) {
@@ -755,6 +995,29 @@ func TestP2PGossipPreVoteNilCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipPreVoteNilRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipPreVoteNilHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipPreVoteNil(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipPreVoteNil(context.Background(), &GossipPreVoteNilMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipPreVoteNil", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipPreCommitHandler struct{}
 
 func (th *testP2PGossipPreCommitHandler) HandleP2PGossipPreCommit(context.Context, *GossipPreCommitMessage) (*GossipPreCommitAck, error) {
@@ -823,6 +1086,29 @@ func TestP2PGossipPreCommitCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipPreCommitRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipPreCommitHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipPreCommit(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipPreCommit(context.Background(), &GossipPreCommitMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipPreCommit", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipPreCommitNilHandler struct{}
 
 func (th *testP2PGossipPreCommitNilHandler) HandleP2PGossipPreCommitNil(context.Context, *GossipPreCommitNilMessage) (*GossipPreCommitNilAck, error) {
@@ -891,6 +1177,29 @@ func TestP2PGossipPreCommitNilCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipPreCommitNilRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipPreCommitNilHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipPreCommitNil(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipPreCommitNil(context.Background(), &GossipPreCommitNilMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipPreCommitNil", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipNextRoundHandler struct{}
 
 func (th *testP2PGossipNextRoundHandler) HandleP2PGossipNextRound(context.Context, *GossipNextRoundMessage) (*GossipNextRoundAck, error) {
@@ -959,6 +1268,29 @@ func TestP2PGossipNextRoundCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipNextRoundRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipNextRoundHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipNextRound(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipNextRound(context.Background(), &GossipNextRoundMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipNextRound", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipNextHeightHandler struct{}
 
 func (th *testP2PGossipNextHeightHandler) HandleP2PGossipNextHeight(context.Context, *GossipNextHeightMessage) (*GossipNextHeightAck, error) {
@@ -1027,6 +1359,29 @@ func TestP2PGossipNextHeightCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipNextHeightRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipNextHeightHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipNextHeight(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipNextHeight(context.Background(), &GossipNextHeightMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipNextHeight", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGossipBlockHeaderHandler struct{}
 
 func (th *testP2PGossipBlockHeaderHandler) HandleP2PGossipBlockHeader(context.Context, *GossipBlockHeaderMessage) (*GossipBlockHeaderAck, error) {
@@ -1095,6 +1450,29 @@ func TestP2PGossipBlockHeaderCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGossipBlockHeaderRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGossipBlockHeaderHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGossipBlockHeader(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GossipBlockHeader(context.Background(), &GossipBlockHeaderMessage{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGossipBlockHeader", l.method, "the rate limiter must be called with the method name")
+}
+
 type testP2PGetPeersHandler struct{}
 
 func (th *testP2PGetPeersHandler) HandleP2PGetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error) {
@@ -1163,6 +1541,29 @@ func TestP2PGetPeersCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestP2PGetPeersRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testP2PGetPeersHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterP2PGetPeers(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedP2PServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetPeers(context.Background(), &GetPeersRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "P2PGetPeers", l.method, "the rate limiter must be called with the method name")
+}
+
 type testDiscoveryGetPeersHandler struct{}
 
 func (th *testDiscoveryGetPeersHandler) HandleDiscoveryGetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error) {
@@ -1231,3 +1632,26 @@ func TestDiscoveryGetPeersCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+func TestDiscoveryGetPeersRateLimit(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewInboundRPCDispatch()
+
+	// Setup the handler for the TestService
+	h := &testDiscoveryGetPeersHandler{}
+
+	// Register the handler and a limiter that rejects every call
+	d.RegisterDiscoveryGetPeers(h)
+	l := &testRateLimiter{}
+	d.SetRateLimiter(l)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedDiscoveryServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetPeers(context.Background(), &GetPeersRequest{})
+	assert.EqualError(t, err, "rate limited", "the error of the rate limiter must be returned")
+	assert.Equal(t, "DiscoveryGetPeers", l.method, "the rate limiter must be called with the method name")
+}
+
@@ -121,6 +121,38 @@ type LocalStateGetTxBlockNumberHandler interface {
 	HandleLocalStateGetTxBlockNumber(context.Context, *TxBlockNumberRequest) (*TxBlockNumberResponse, error)
 }
 
+// LocalStateGetSyncStatusHandler is an interface class that only contains
+// the method HandleLocalStateGetSyncStatus
+// The class that implements this method MUST handle the RPC call for
+// the method GetSyncStatus of the RPC service LocalState
+type LocalStateGetSyncStatusHandler interface {
+	HandleLocalStateGetSyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusResponse, error)
+}
+
+// LocalStateGetPeerBansHandler is an interface class that only contains
+// the method HandleLocalStateGetPeerBans
+// The class that implements this method MUST handle the RPC call for
+// the method GetPeerBans of the RPC service LocalState
+type LocalStateGetPeerBansHandler interface {
+	HandleLocalStateGetPeerBans(context.Context, *PeerBansRequest) (*PeerBansResponse, error)
+}
+
+// LocalStateGetPeerInfoHandler is an interface class that only contains
+// the method HandleLocalStateGetPeerInfo
+// The class that implements this method MUST handle the RPC call for
+// the method GetPeerInfo of the RPC service LocalState
+type LocalStateGetPeerInfoHandler interface {
+	HandleLocalStateGetPeerInfo(context.Context, *PeerInfoRequest) (*PeerInfoResponse, error)
+}
+
+// LocalStateGetTaskRunsHandler is an interface class that only contains
+// the method HandleLocalStateGetTaskRuns
+// The class that implements this method MUST handle the RPC call for
+// the method GetTaskRuns of the RPC service LocalState
+type LocalStateGetTaskRunsHandler interface {
+	HandleLocalStateGetTaskRuns(context.Context, *TaskRunsRequest) (*TaskRunsResponse, error)
+}
+
 
 
 // LocalStateDispatch allows handlers to be registered for all RPC methods
@@ -227,6 +259,34 @@ type LocalStateDispatch struct {
 	// method GetTxBlockNumber on service LocalState to block until the
 	// method has been registered.
 	waitChanLocalStateGetTxBlockNumber chan struct{}
+  //	handlerLocalStateGetSyncStatus is the registered handler for the
+	//  GetSyncStatus RPC method of service LocalState
+	handlerLocalStateGetSyncStatus LocalStateGetSyncStatusHandler
+	// waitChanLocalStateGetSyncStatus will cause a caller of the RPC
+	// method GetSyncStatus on service LocalState to block until the
+	// method has been registered.
+	waitChanLocalStateGetSyncStatus chan struct{}
+  //	handlerLocalStateGetPeerBans is the registered handler for the
+	//  GetPeerBans RPC method of service LocalState
+	handlerLocalStateGetPeerBans LocalStateGetPeerBansHandler
+	// waitChanLocalStateGetPeerBans will cause a caller of the RPC
+	// method GetPeerBans on service LocalState to block until the
+	// method has been registered.
+	waitChanLocalStateGetPeerBans chan struct{}
+  //	handlerLocalStateGetPeerInfo is the registered handler for the
+	//  GetPeerInfo RPC method of service LocalState
+	handlerLocalStateGetPeerInfo LocalStateGetPeerInfoHandler
+	// waitChanLocalStateGetPeerInfo will cause a caller of the RPC
+	// method GetPeerInfo on service LocalState to block until the
+	// method has been registered.
+	waitChanLocalStateGetPeerInfo chan struct{}
+  //	handlerLocalStateGetTaskRuns is the registered handler for the
+	//  GetTaskRuns RPC method of service LocalState
+	handlerLocalStateGetTaskRuns LocalStateGetTaskRunsHandler
+	// waitChanLocalStateGetTaskRuns will cause a caller of the RPC
+	// method GetTaskRuns on service LocalState to block until the
+	// method has been registered.
+	waitChanLocalStateGetTaskRuns chan struct{}
 }
 
 
@@ -623,6 +683,118 @@ func (d *LocalStateDispatch) LocalStateGetTxBlockNumber(ctx context.Context, r *
 	}
 }
 
+// RegisterLocalStateGetSyncStatus will register the object 't' as the service
+// handler for the RPC method GetSyncStatus from service LocalState
+func (d *LocalStateDispatch) RegisterLocalStateGetSyncStatus(t LocalStateGetSyncStatusHandler) {
+	d.Lock()
+	defer d.Unlock()
+	// double registration is not allowed
+	if d.handlerLocalStateGetSyncStatus != nil {
+		panic("double registration of LocalStateGetSyncStatus")
+	}
+	// register the service handler
+	d.handlerLocalStateGetSyncStatus = t
+	// close the wait channel to signal that the method is ready to use
+	close(d.waitChanLocalStateGetSyncStatus)
+}
+
+// LocalStateGetSyncStatus will invoke the handler for the RPC method
+// GetSyncStatus from service LocalState
+func (d *LocalStateDispatch) LocalStateGetSyncStatus(ctx context.Context, r *SyncStatusRequest) (*SyncStatusResponse, error) {
+	// wait for registration to complete or context to be canceled
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("context canceled")
+	case <-d.waitChanLocalStateGetSyncStatus:
+		// return the invoked methods response
+		return d.handlerLocalStateGetSyncStatus.HandleLocalStateGetSyncStatus(ctx, r)
+	}
+}
+
+// RegisterLocalStateGetPeerBans will register the object 't' as the service
+// handler for the RPC method GetPeerBans from service LocalState
+func (d *LocalStateDispatch) RegisterLocalStateGetPeerBans(t LocalStateGetPeerBansHandler) {
+	d.Lock()
+	defer d.Unlock()
+	// double registration is not allowed
+	if d.handlerLocalStateGetPeerBans != nil {
+		panic("double registration of LocalStateGetPeerBans")
+	}
+	// register the service handler
+	d.handlerLocalStateGetPeerBans = t
+	// close the wait channel to signal that the method is ready to use
+	close(d.waitChanLocalStateGetPeerBans)
+}
+
+// LocalStateGetPeerBans will invoke the handler for the RPC method
+// GetPeerBans from service LocalState
+func (d *LocalStateDispatch) LocalStateGetPeerBans(ctx context.Context, r *PeerBansRequest) (*PeerBansResponse, error) {
+	// wait for registration to complete or context to be canceled
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("context canceled")
+	case <-d.waitChanLocalStateGetPeerBans:
+		// return the invoked methods response
+		return d.handlerLocalStateGetPeerBans.HandleLocalStateGetPeerBans(ctx, r)
+	}
+}
+
+// RegisterLocalStateGetPeerInfo will register the object 't' as the service
+// handler for the RPC method GetPeerInfo from service LocalState
+func (d *LocalStateDispatch) RegisterLocalStateGetPeerInfo(t LocalStateGetPeerInfoHandler) {
+	d.Lock()
+	defer d.Unlock()
+	// double registration is not allowed
+	if d.handlerLocalStateGetPeerInfo != nil {
+		panic("double registration of LocalStateGetPeerInfo")
+	}
+	// register the service handler
+	d.handlerLocalStateGetPeerInfo = t
+	// close the wait channel to signal that the method is ready to use
+	close(d.waitChanLocalStateGetPeerInfo)
+}
+
+// LocalStateGetPeerInfo will invoke the handler for the RPC method
+// GetPeerInfo from service LocalState
+func (d *LocalStateDispatch) LocalStateGetPeerInfo(ctx context.Context, r *PeerInfoRequest) (*PeerInfoResponse, error) {
+	// wait for registration to complete or context to be canceled
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("context canceled")
+	case <-d.waitChanLocalStateGetPeerInfo:
+		// return the invoked methods response
+		return d.handlerLocalStateGetPeerInfo.HandleLocalStateGetPeerInfo(ctx, r)
+	}
+}
+
+// RegisterLocalStateGetTaskRuns will register the object 't' as the service
+// handler for the RPC method GetTaskRuns from service LocalState
+func (d *LocalStateDispatch) RegisterLocalStateGetTaskRuns(t LocalStateGetTaskRunsHandler) {
+	d.Lock()
+	defer d.Unlock()
+	// double registration is not allowed
+	if d.handlerLocalStateGetTaskRuns != nil {
+		panic("double registration of LocalStateGetTaskRuns")
+	}
+	// register the service handler
+	d.handlerLocalStateGetTaskRuns = t
+	// close the wait channel to signal that the method is ready to use
+	close(d.waitChanLocalStateGetTaskRuns)
+}
+
+// LocalStateGetTaskRuns will invoke the handler for the RPC method
+// GetTaskRuns from service LocalState
+func (d *LocalStateDispatch) LocalStateGetTaskRuns(ctx context.Context, r *TaskRunsRequest) (*TaskRunsResponse, error) {
+	// wait for registration to complete or context to be canceled
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("context canceled")
+	case <-d.waitChanLocalStateGetTaskRuns:
+		// return the invoked methods response
+		return d.handlerLocalStateGetTaskRuns.HandleLocalStateGetTaskRuns(ctx, r)
+	}
+}
+
 
 
 // NewLocalStateDispatch will construct a new LocalStateDispatcher with all fields properly
@@ -657,6 +829,14 @@ func NewLocalStateDispatch() *LocalStateDispatch {
 		waitChanLocalStateGetEpochNumber: make(chan struct{}),
 		// initialize the wait channel for method GetTxBlockNumber on service LocalState
 		waitChanLocalStateGetTxBlockNumber: make(chan struct{}),
+		// initialize the wait channel for method GetSyncStatus on service LocalState
+		waitChanLocalStateGetSyncStatus: make(chan struct{}),
+		// initialize the wait channel for method GetPeerBans on service LocalState
+		waitChanLocalStateGetPeerBans: make(chan struct{}),
+		// initialize the wait channel for method GetPeerInfo on service LocalState
+		waitChanLocalStateGetPeerInfo: make(chan struct{}),
+		// initialize the wait channel for method GetTaskRuns on service LocalState
+		waitChanLocalStateGetTaskRuns: make(chan struct{}),
 	}
 }
 
@@ -765,6 +945,34 @@ func (s *GeneratedLocalStateServer) GetTxBlockNumber(ctx context.Context, r *TxB
 }
 
 
+// GetSyncStatus will invoke the method GetSyncStatus on the RPC service LocalState
+// using the LocalStateDispatch handler.
+func (s *GeneratedLocalStateServer) GetSyncStatus(ctx context.Context, r *SyncStatusRequest) (*SyncStatusResponse, error) {
+	return s.dispatch.LocalStateGetSyncStatus(ctx, r)
+}
+
+
+// GetPeerBans will invoke the method GetPeerBans on the RPC service LocalState
+// using the LocalStateDispatch handler.
+func (s *GeneratedLocalStateServer) GetPeerBans(ctx context.Context, r *PeerBansRequest) (*PeerBansResponse, error) {
+	return s.dispatch.LocalStateGetPeerBans(ctx, r)
+}
+
+
+// GetPeerInfo will invoke the method GetPeerInfo on the RPC service LocalState
+// using the LocalStateDispatch handler.
+func (s *GeneratedLocalStateServer) GetPeerInfo(ctx context.Context, r *PeerInfoRequest) (*PeerInfoResponse, error) {
+	return s.dispatch.LocalStateGetPeerInfo(ctx, r)
+}
+
+
+// GetTaskRuns will invoke the method GetTaskRuns on the RPC service LocalState
+// using the LocalStateDispatch handler.
+func (s *GeneratedLocalStateServer) GetTaskRuns(ctx context.Context, r *TaskRunsRequest) (*TaskRunsResponse, error) {
+	return s.dispatch.LocalStateGetTaskRuns(ctx, r)
+}
+
+
 
 // NewGeneratedLocalStateServer constructs a new server for the service.
 func NewGeneratedLocalStateServer(dispatch *LocalStateDispatch) *GeneratedLocalStateServer {
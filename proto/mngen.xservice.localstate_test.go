@@ -959,3 +959,275 @@ func TestLocalStateGetTxBlockNumberCancel(t *testing.T) {
 	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
 }
 
+type testLocalStateGetSyncStatusHandler struct{}
+
+func (th *testLocalStateGetSyncStatusHandler) HandleLocalStateGetSyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusResponse, error) {
+	return &SyncStatusResponse{}, nil
+}
+
+func TestLocalStateGetSyncStatus(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetSyncStatusHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetSyncStatus(h)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetSyncStatus(context.Background(), &SyncStatusRequest{})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDoubleregistrationLocalStateGetSyncStatus(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetSyncStatusHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetSyncStatus(h)
+
+	fn := func() {
+		d.RegisterLocalStateGetSyncStatus(h)
+	}
+	assert.Panics(t, fn, "double registration must panic")
+}
+
+func TestLocalStateGetSyncStatusCancel(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	errChan := make(chan error)
+	defer close(errChan)
+	ctx := context.Background()
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
+	fn := func() {
+		_, err := srvr.GetSyncStatus(cancelCtx, &SyncStatusRequest{})
+		errChan <- err
+	}
+	go fn()
+	cancelFunc()
+	cancelErr := <-errChan
+	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
+}
+
+type testLocalStateGetPeerBansHandler struct{}
+
+func (th *testLocalStateGetPeerBansHandler) HandleLocalStateGetPeerBans(context.Context, *PeerBansRequest) (*PeerBansResponse, error) {
+	return &PeerBansResponse{}, nil
+}
+
+func TestLocalStateGetPeerBans(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetPeerBansHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetPeerBans(h)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetPeerBans(context.Background(), &PeerBansRequest{})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDoubleregistrationLocalStateGetPeerBans(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetPeerBansHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetPeerBans(h)
+
+	fn := func() {
+		d.RegisterLocalStateGetPeerBans(h)
+	}
+	assert.Panics(t, fn, "double registration must panic")
+}
+
+func TestLocalStateGetPeerBansCancel(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	errChan := make(chan error)
+	defer close(errChan)
+	ctx := context.Background()
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
+	fn := func() {
+		_, err := srvr.GetPeerBans(cancelCtx, &PeerBansRequest{})
+		errChan <- err
+	}
+	go fn()
+	cancelFunc()
+	cancelErr := <-errChan
+	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
+}
+
+type testLocalStateGetPeerInfoHandler struct{}
+
+func (th *testLocalStateGetPeerInfoHandler) HandleLocalStateGetPeerInfo(context.Context, *PeerInfoRequest) (*PeerInfoResponse, error) {
+	return &PeerInfoResponse{}, nil
+}
+
+func TestLocalStateGetPeerInfo(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetPeerInfoHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetPeerInfo(h)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetPeerInfo(context.Background(), &PeerInfoRequest{})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDoubleregistrationLocalStateGetPeerInfo(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetPeerInfoHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetPeerInfo(h)
+
+	fn := func() {
+		d.RegisterLocalStateGetPeerInfo(h)
+	}
+	assert.Panics(t, fn, "double registration must panic")
+}
+
+func TestLocalStateGetPeerInfoCancel(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	errChan := make(chan error)
+	defer close(errChan)
+	ctx := context.Background()
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
+	fn := func() {
+		_, err := srvr.GetPeerInfo(cancelCtx, &PeerInfoRequest{})
+		errChan <- err
+	}
+	go fn()
+	cancelFunc()
+	cancelErr := <-errChan
+	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
+}
+
+type testLocalStateGetTaskRunsHandler struct{}
+
+func (th *testLocalStateGetTaskRunsHandler) HandleLocalStateGetTaskRuns(context.Context, *TaskRunsRequest) (*TaskRunsResponse, error) {
+	return &TaskRunsResponse{}, nil
+}
+
+func TestLocalStateGetTaskRuns(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetTaskRunsHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetTaskRuns(h)
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	_, err := srvr.GetTaskRuns(context.Background(), &TaskRunsRequest{})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDoubleregistrationLocalStateGetTaskRuns(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Setup the handler for the TestService
+	h := &testLocalStateGetTaskRunsHandler{}
+
+	// Register the handler with the dispatch class
+	d.RegisterLocalStateGetTaskRuns(h)
+
+	fn := func() {
+		d.RegisterLocalStateGetTaskRuns(h)
+	}
+	assert.Panics(t, fn, "double registration must panic")
+}
+
+func TestLocalStateGetTaskRunsCancel(t *testing.T) {
+	// Setup the dispatch handler
+	d := NewLocalStateDispatch()
+
+	// Create the server and pass in the dispatch class
+	srvr := GeneratedLocalStateServer{
+		dispatch: d,
+	}
+
+	// Test calling the method TestCall
+	errChan := make(chan error)
+	defer close(errChan)
+	ctx := context.Background()
+	cancelCtx, cancelFunc := context.WithCancel(ctx)
+	fn := func() {
+		_, err := srvr.GetTaskRuns(cancelCtx, &TaskRunsRequest{})
+		errChan <- err
+	}
+	go fn()
+	cancelFunc()
+	cancelErr := <-errChan
+	assert.EqualError(t, cancelErr, "context canceled", "the error returned must be a context canceled error")
+}
+
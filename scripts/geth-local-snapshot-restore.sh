@@ -1,5 +1,6 @@
 #!/bin/bash
 DATADIR=./local-geth/
+SNAPSHOT=$1
 
 set -e
 
@@ -15,3 +16,11 @@ mv ./validator1 ~/validator1
 mv ./validator2 ~/validator2
 mv ./validator3 ~/validator3
 rm -rf ~/validator4
+
+# snapshot.zip holds the Ethereum chain and the keys of the validators, the
+# state of the chain can be moved ahead with a snapshot file
+if [ -n "$SNAPSHOT" ]; then
+    for i in 0 1 2 3; do
+        ./madnet --config ./assets/config/validator$i.toml snapshot import $SNAPSHOT
+    done
+fi
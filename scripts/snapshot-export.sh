@@ -0,0 +1,2 @@
+#!/bin/sh
+./madnet --config ./assets/config/validator0.toml snapshot export ${1:-./local-snapshot.bin}
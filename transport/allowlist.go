@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Allowlist restricts the peers of a node to a set of transport identities
+// and the peers learned through discovery to a set of hosts. Hosts may be
+// given as IP addresses, CIDR ranges or hostnames. An empty identity or host
+// list allows every identity or host. A nil Allowlist allows everything.
+// The lists may be replaced at runtime with Reload.
+type Allowlist struct {
+	sync.RWMutex
+	identities map[string]bool
+	hosts      map[string]bool
+	nets       []*net.IPNet
+}
+
+// NewAllowlist creates an allowlist of the given identities and hosts
+func NewAllowlist(identities, hosts []string) (*Allowlist, error) {
+	a := &Allowlist{}
+	if err := a.Reload(identities, hosts); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reload replaces the identities and hosts of the allowlist. The allowlist
+// is not changed if any entry is invalid. Hostnames are resolved when the
+// lists are loaded; a hostname that does not resolve only matches itself.
+func (a *Allowlist) Reload(identities, hosts []string) error {
+	idents := make(map[string]bool)
+	for _, ident := range identities {
+		ident = strings.ToLower(ident)
+		b, err := hex.DecodeString(ident)
+		if err != nil {
+			return err
+		}
+		if len(b) != compressedPublicKeyHexStringLength/2 {
+			return ErrInvalidPubKeyLength
+		}
+		idents[ident] = true
+	}
+	names := make(map[string]bool)
+	nets := []*net.IPNet{}
+	for _, host := range hosts {
+		if _, n, err := net.ParseCIDR(host); err == nil {
+			nets = append(nets, n)
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			nets = append(nets, ipNet(ip))
+			continue
+		}
+		names[strings.ToLower(host)] = true
+		ips, err := net.LookupIP(host)
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			nets = append(nets, ipNet(ip))
+		}
+	}
+	a.Lock()
+	defer a.Unlock()
+	a.identities = idents
+	a.hosts = names
+	a.nets = nets
+	return nil
+}
+
+func ipNet(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// AllowIdentity returns true if a peer with the hex encoded transport public
+// key identity may connect
+func (a *Allowlist) AllowIdentity(identity string) bool {
+	if a == nil {
+		return true
+	}
+	a.RLock()
+	defer a.RUnlock()
+	if len(a.identities) == 0 {
+		return true
+	}
+	return a.identities[strings.ToLower(identity)]
+}
+
+// AllowHost returns true if a peer learned through discovery at host may be
+// used
+func (a *Allowlist) AllowHost(host string) bool {
+	if a == nil {
+		return true
+	}
+	a.RLock()
+	defer a.RUnlock()
+	if len(a.hosts) == 0 && len(a.nets) == 0 {
+		return true
+	}
+	if a.hosts[strings.ToLower(host)] {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range a.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
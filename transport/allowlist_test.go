@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"testing"
+)
+
+func TestAllowlist(t *testing.T) {
+	allowed, err := RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var none *Allowlist
+	if !none.AllowIdentity(other.Identity()) || !none.AllowHost("10.0.0.1") {
+		t.Fatal("nil allowlist rejected a peer")
+	}
+	a, err := NewAllowlist(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.AllowIdentity(other.Identity()) || !a.AllowHost("10.0.0.1") {
+		t.Fatal("empty allowlist rejected a peer")
+	}
+
+	a, err = NewAllowlist([]string{allowed.Identity()}, []string{"10.1.0.0/16", "192.168.1.7", "localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.AllowIdentity(allowed.Identity()) {
+		t.Fatal("allowed identity rejected")
+	}
+	if a.AllowIdentity(other.Identity()) {
+		t.Fatal("identity not on the allowlist allowed")
+	}
+	for _, host := range []string{"10.1.2.3", "192.168.1.7", "localhost", "127.0.0.1"} {
+		if !a.AllowHost(host) {
+			t.Fatalf("allowed host %s rejected", host)
+		}
+	}
+	for _, host := range []string{"10.2.0.1", "192.168.1.8", "example.com"} {
+		if a.AllowHost(host) {
+			t.Fatalf("host %s not on the allowlist allowed", host)
+		}
+	}
+
+	// an invalid reload keeps the current lists
+	if err := a.Reload([]string{"zz"}, nil); err == nil {
+		t.Fatal("invalid identity accepted")
+	}
+	if a.AllowIdentity(other.Identity()) || !a.AllowHost("10.1.2.3") {
+		t.Fatal("invalid reload changed the allowlist")
+	}
+	if err := a.Reload([]string{other.Identity()}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.AllowIdentity(allowed.Identity()) || !a.AllowIdentity(other.Identity()) || !a.AllowHost("10.2.0.1") {
+		t.Fatal("reload not applied")
+	}
+}
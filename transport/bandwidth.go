@@ -0,0 +1,222 @@
+package transport
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MadBase/MadNet/types"
+)
+
+// byteCounter counts the bytes that pass through a connection
+type byteCounter struct {
+	sent     uint64
+	received uint64
+}
+
+func (bc *byteCounter) bandwidth() types.Bandwidth {
+	return types.Bandwidth{
+		BytesSent:     atomic.LoadUint64(&bc.sent),
+		BytesReceived: atomic.LoadUint64(&bc.received),
+	}
+}
+
+// byteLimiter is a token bucket of bytes. The bucket holds at most one
+// second of traffic. Callers that take more bytes than the bucket holds go
+// into debt and are delayed until the debt is repaid, so the average rate
+// never exceeds the limit.
+type byteLimiter struct {
+	sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newByteLimiter returns a limiter of rate bytes per second or nil if the
+// rate is not positive
+func newByteLimiter(rate int) *byteLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &byteLimiter{
+		rate:   float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+// wait takes n bytes from the bucket and blocks until the bucket is out of
+// debt. A nil limiter never blocks.
+func (bl *byteLimiter) wait(n int) {
+	if bl == nil || n <= 0 {
+		return
+	}
+	bl.Lock()
+	now := time.Now()
+	bl.tokens += now.Sub(bl.last).Seconds() * bl.rate
+	if bl.tokens > bl.rate {
+		bl.tokens = bl.rate
+	}
+	bl.last = now
+	bl.tokens -= float64(n)
+	var delay time.Duration
+	if bl.tokens < 0 {
+		delay = time.Duration(-bl.tokens / bl.rate * float64(time.Second))
+	}
+	bl.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// meteredConn counts the bytes read from and written to a connection and
+// applies the bandwidth limits. The counters are the counter of the
+// connection itself followed by any aggregate counters it is a part of.
+type meteredConn struct {
+	net.Conn
+	counters []*byteCounter
+	limiters []*byteLimiter
+}
+
+func newMeteredConn(conn net.Conn, counters []*byteCounter, limiters []*byteLimiter) *meteredConn {
+	return &meteredConn{
+		Conn:     conn,
+		counters: append([]*byteCounter{{}}, counters...),
+		limiters: limiters,
+	}
+}
+
+// Read See docs for net.Conn
+func (mc *meteredConn) Read(b []byte) (int, error) {
+	n, err := mc.Conn.Read(b)
+	if n > 0 {
+		for _, c := range mc.counters {
+			atomic.AddUint64(&c.received, uint64(n))
+		}
+		for _, l := range mc.limiters {
+			l.wait(n)
+		}
+	}
+	return n, err
+}
+
+// Write See docs for net.Conn
+func (mc *meteredConn) Write(b []byte) (int, error) {
+	for _, l := range mc.limiters {
+		l.wait(len(b))
+	}
+	n, err := mc.Conn.Write(b)
+	if n > 0 {
+		for _, c := range mc.counters {
+			atomic.AddUint64(&c.sent, uint64(n))
+		}
+	}
+	return n, err
+}
+
+// bandwidth returns the bytes that passed through this connection
+func (mc *meteredConn) bandwidth() types.Bandwidth {
+	return mc.counters[0].bandwidth()
+}
+
+// peerBandwidth aggregates the connections to one peer
+type peerBandwidth struct {
+	conns     int
+	limiter   *byteLimiter
+	protocols map[types.Protocol]*byteCounter
+}
+
+// bandwidthTracker aggregates the traffic of all connections per peer and
+// per protocol and enforces the global and per peer bandwidth limits. The
+// totals are kept for the lifetime of the transport while the traffic of a
+// peer is dropped once its last connection closes.
+type bandwidthTracker struct {
+	sync.Mutex
+	limiter  *byteLimiter
+	peerRate int
+	totals   map[types.Protocol]*byteCounter
+	peers    map[string]*peerBandwidth
+}
+
+// newBandwidthTracker creates a tracker. limit is the global and peerLimit
+// the per peer limit in bytes per second. A limit that is not positive
+// disables the limit.
+func newBandwidthTracker(limit, peerLimit int) *bandwidthTracker {
+	return &bandwidthTracker{
+		limiter:  newByteLimiter(limit),
+		peerRate: peerLimit,
+		totals:   make(map[types.Protocol]*byteCounter),
+		peers:    make(map[string]*peerBandwidth),
+	}
+}
+
+// meter wraps the connection to the peer identity in a meteredConn. The
+// peer is released when closeChan closes.
+func (bt *bandwidthTracker) meter(conn net.Conn, identity string, protocol types.Protocol, closeChan <-chan struct{}) *meteredConn {
+	bt.Lock()
+	defer bt.Unlock()
+	total, ok := bt.totals[protocol]
+	if !ok {
+		total = &byteCounter{}
+		bt.totals[protocol] = total
+	}
+	pb, ok := bt.peers[identity]
+	if !ok {
+		pb = &peerBandwidth{
+			limiter:   newByteLimiter(bt.peerRate),
+			protocols: make(map[types.Protocol]*byteCounter),
+		}
+		bt.peers[identity] = pb
+	}
+	pb.conns++
+	pc, ok := pb.protocols[protocol]
+	if !ok {
+		pc = &byteCounter{}
+		pb.protocols[protocol] = pc
+	}
+	go func() {
+		<-closeChan
+		bt.release(identity)
+	}()
+	return newMeteredConn(conn, []*byteCounter{pc, total}, []*byteLimiter{pb.limiter, bt.limiter})
+}
+
+func (bt *bandwidthTracker) release(identity string) {
+	bt.Lock()
+	defer bt.Unlock()
+	pb, ok := bt.peers[identity]
+	if !ok {
+		return
+	}
+	pb.conns--
+	if pb.conns <= 0 {
+		delete(bt.peers, identity)
+	}
+}
+
+// peer returns the traffic of a peer per protocol
+func (bt *bandwidthTracker) peer(identity string) map[types.Protocol]types.Bandwidth {
+	bt.Lock()
+	defer bt.Unlock()
+	out := make(map[types.Protocol]types.Bandwidth)
+	pb, ok := bt.peers[identity]
+	if !ok {
+		return out
+	}
+	for p, c := range pb.protocols {
+		out[p] = c.bandwidth()
+	}
+	return out
+}
+
+// total returns the traffic of all peers per protocol
+func (bt *bandwidthTracker) total() map[types.Protocol]types.Bandwidth {
+	bt.Lock()
+	defer bt.Unlock()
+	out := make(map[types.Protocol]types.Bandwidth)
+	for p, c := range bt.totals {
+		out[p] = c.bandwidth()
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/types"
+)
+
+func TestBandwidthTracker(t *testing.T) {
+	bt := newBandwidthTracker(0, 0)
+	closeChan := make(chan struct{})
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+	mc := bt.meter(c1, "peer", types.P2PProtocol, closeChan)
+	go func() {
+		buf := make([]byte, 10)
+		_, _ = io.ReadFull(c2, buf)
+		_, _ = c2.Write([]byte("abc"))
+	}()
+	if _, err := mc.Write(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(mc, buf); err != nil {
+		t.Fatal(err)
+	}
+	want := types.Bandwidth{BytesSent: 10, BytesReceived: 3}
+	if mc.bandwidth() != want {
+		t.Fatalf("bad conn bandwidth: %+v", mc.bandwidth())
+	}
+	if bt.peer("peer")[types.P2PProtocol] != want {
+		t.Fatalf("bad peer bandwidth: %+v", bt.peer("peer"))
+	}
+	if bt.total()[types.P2PProtocol] != want {
+		t.Fatalf("bad total bandwidth: %+v", bt.total())
+	}
+	if _, ok := bt.total()[types.DiscProtocol]; ok {
+		t.Fatal("traffic recorded for unused protocol")
+	}
+
+	// the peer is dropped when its connection closes but the totals are kept
+	close(closeChan)
+	time.Sleep(10 * time.Millisecond)
+	if len(bt.peer("peer")) != 0 {
+		t.Fatal("closed peer not released")
+	}
+	if bt.total()[types.P2PProtocol] != want {
+		t.Fatal("totals not kept")
+	}
+}
+
+func TestByteLimiter(t *testing.T) {
+	if newByteLimiter(0) != nil {
+		t.Fatal("limiter created without a rate")
+	}
+	// a nil limiter never blocks
+	var bl *byteLimiter
+	bl.wait(1 << 30)
+
+	bl = newByteLimiter(1000)
+	start := time.Now()
+	// the first second of traffic is available at once
+	bl.wait(1000)
+	if time.Since(start) > 50*time.Millisecond {
+		t.Fatal("limiter blocked within burst")
+	}
+	// the debt of the next 100 bytes takes 100ms to repay
+	bl.wait(100)
+	if time.Since(start) < 80*time.Millisecond {
+		t.Fatal("limiter did not block over the limit")
+	}
+}
@@ -33,6 +33,8 @@ type Conn struct {
 	P2PPort  int
 	Protocol types.Protocol
 	Version  types.ProtoVersion
+	// Capabilities are the capabilities advertised by the remote peer
+	Capabilities *types.Capabilities
 
 	closeFn   func() error
 	closeChan chan struct{}
@@ -64,7 +66,7 @@ func (c *Conn) CloseChan() <-chan struct{} {
 // remote peer located at address which has remotePub as its long-term static
 // public key. In the case of a handshake failure, the connection is closed and
 // a non-nil error is returned.
-func Dial(localPriv *secp256k1.PrivateKey, protocol types.Protocol, protoVersion types.ProtoVersion, chainID types.ChainIdentifier, port int, netAddr *NetAddress, dialer func(string, string) (net.Conn, error)) (*Conn, error) {
+func Dial(localPriv *secp256k1.PrivateKey, protocol types.Protocol, protoVersion types.ProtoVersion, caps *types.Capabilities, chainID types.ChainIdentifier, port int, netAddr *NetAddress, dialer func(string, string) (net.Conn, error)) (*Conn, error) {
 	ipAddr := netAddr.Address.String()
 	var conn net.Conn
 	var err error
@@ -156,6 +158,19 @@ func Dial(localPriv *secp256k1.PrivateKey, protocol types.Protocol, protoVersion
 		return nil, err
 	}
 
+	remoteCaps := types.BaseCapabilities()
+	if negotiatesCapabilities(protoVersion, remoteVersion) {
+		if err := conn.SetReadDeadline(time.Now().Add(handshakeReadTimeout)); err != nil {
+			b.conn.Close()
+			return nil, err
+		}
+		remoteCaps, err = selfInitiatedCapabilityHandshake(b, caps)
+		if err != nil {
+			b.conn.Close()
+			return nil, err
+		}
+	}
+
 	if err := writeUint32(b, uint32(protocol)); err != nil {
 		b.conn.Close()
 		return nil, err
@@ -172,6 +187,7 @@ func Dial(localPriv *secp256k1.PrivateKey, protocol types.Protocol, protoVersion
 	b.P2PPort = remoteP2PPort
 	b.Version = types.ProtoVersion(remoteVersion)
 	b.Protocol = protocol
+	b.Capabilities = remoteCaps
 
 	return b, nil
 }
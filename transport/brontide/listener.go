@@ -1,6 +1,7 @@
 package brontide
 
 import (
+	"encoding/hex"
 	"errors"
 	"io"
 	"net"
@@ -23,6 +24,12 @@ var ErrReject = errors.New("unable to accept connection")
 // ErrBrontideClose is an error raised if a connection is closed
 var ErrBrontideClose = errors.New("brontide connection closed")
 
+// IdentityFilter decides if a remote peer may connect based on the hex
+// encoded compressed public key it authenticated with during the handshake.
+type IdentityFilter interface {
+	AllowIdentity(identity string) bool
+}
+
 type connCloseWrapper struct {
 	net.Conn
 	closeFN func() error
@@ -59,11 +66,17 @@ type Listener struct {
 	chainID      types.ChainIdentifier
 	port         int
 	protoVersion types.ProtoVersion
+	caps         *types.Capabilities
+
+	// filter rejects the peers that are not allowed to connect
+	filter IdentityFilter
 }
 
 // NewListener returns a new net.Listener which enforces the Brontide scheme
-// during both initial connection establishment and data transfer.
-func NewListener(localStatic *secp256k1.PrivateKey, host string, port int, protoVersion types.ProtoVersion, chainID types.ChainIdentifier, totalLimit int, pubkeyLimit int, originLimit int) (*Listener, error) {
+// during both initial connection establishment and data transfer. If filter
+// is not nil, connections from peers it does not allow are closed after the
+// handshake.
+func NewListener(localStatic *secp256k1.PrivateKey, host string, port int, protoVersion types.ProtoVersion, caps *types.Capabilities, chainID types.ChainIdentifier, totalLimit int, pubkeyLimit int, originLimit int, filter IdentityFilter) (*Listener, error) {
 	listenAddr := net.JoinHostPort(host, strconv.Itoa(port))
 
 	addr, err := net.ResolveTCPAddr("tcp", listenAddr)
@@ -89,7 +102,9 @@ func NewListener(localStatic *secp256k1.PrivateKey, host string, port int, proto
 		pubkeyLimit:            pubkeyLimit,
 		port:                   port,
 		protoVersion:           protoVersion,
+		caps:                   caps,
 		chainID:                chainID,
+		filter:                 filter,
 	}
 
 	go brontideListener.listen()
@@ -191,6 +206,16 @@ func (l *Listener) postHandshake(conn *Conn) {
 	// get pubkey for limit pubkey tracking
 	pubk := string(conn.RemotePub().SerializeCompressed())
 
+	// guard logic for the identity filter
+	if l.filter != nil && !l.filter.AllowIdentity(hex.EncodeToString([]byte(pubk))) {
+		l.logger.Debugf("Rejecting connection from identity %x that is not allowed", pubk)
+		err := conn.Close()
+		if err != nil {
+			utils.DebugTrace(l.logger, err)
+		}
+		return
+	}
+
 	// guard logic for pubkey limit tracking
 	if l.numConnectionsbyPubkey[pubk] >= l.pubkeyLimit {
 		err := conn.Close()
@@ -421,6 +446,29 @@ func (l *Listener) doHandshake(conn net.Conn) {
 		return
 	}
 
+	remoteCaps := types.BaseCapabilities()
+	if negotiatesCapabilities(l.protoVersion, remoteVersion) {
+		if err := conn.SetReadDeadline(time.Now().Add(handshakeReadTimeout)); err != nil {
+			utils.DebugTrace(l.logger, err)
+			err2 := brontideConn.Close()
+			if err2 != nil {
+				utils.DebugTrace(l.logger, err2)
+			}
+			l.rejectConn(rejectedConnErr(err, remoteAddr))
+			return
+		}
+		remoteCaps, err = peerInitiatedCapabilityHandshake(brontideConn, l.caps)
+		if err != nil {
+			utils.DebugTrace(l.logger, err)
+			err2 := brontideConn.Close()
+			if err2 != nil {
+				utils.DebugTrace(l.logger, err2)
+			}
+			l.rejectConn(rejectedConnErr(err, remoteAddr))
+			return
+		}
+	}
+
 	select {
 	case <-l.quit:
 		return
@@ -461,6 +509,7 @@ func (l *Listener) doHandshake(conn net.Conn) {
 	brontideConn.P2PPort = remoteP2PPort
 	brontideConn.Version = types.ProtoVersion(remoteVersion)
 	brontideConn.Protocol = types.Protocol(protocol)
+	brontideConn.Capabilities = remoteCaps
 
 	go l.postHandshake(brontideConn)
 }
@@ -7,9 +7,11 @@ import (
 	"io"
 	"math"
 	"net"
+	"reflect"
 	"strconv"
 	"testing"
 	"testing/iotest"
+	"time"
 
 	"github.com/MadBase/MadNet/crypto/secp256k1"
 	"github.com/MadBase/MadNet/types"
@@ -22,12 +24,21 @@ const (
 	testPortListener int                   = 9000
 )
 
+var (
+	testListenerCaps = &types.Capabilities{RPCs: []string{"Status"}, MaxMessageSize: 1024}
+	testDialerCaps   = &types.Capabilities{RPCs: []string{"Status", "GetPeers"}, Compression: []string{"snappy"}}
+)
+
 type maybeNetConn struct {
 	conn net.Conn
 	err  error
 }
 
 func makeListener() (*Listener, *NetAddress, error) {
+	return makeFilteredListener(nil)
+}
+
+func makeFilteredListener(filter IdentityFilter) (*Listener, *NetAddress, error) {
 	// First, generate the long-term private keys for the brontide listener.
 	localPriv, err := secp256k1.NewPrivateKey(secp256k1.S256())
 	if err != nil {
@@ -37,7 +48,7 @@ func makeListener() (*Listener, *NetAddress, error) {
 	addr := "localhost"
 
 	// Our listener will be local, and the connection remote.
-	listener, err := NewListener(localPriv, addr, testPortListener, testProtoVer, testChainID, 50, 1, 50)
+	listener, err := NewListener(localPriv, addr, testPortListener, testProtoVer, testListenerCaps, testChainID, 50, 1, 50, filter)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -68,7 +79,7 @@ func establishTestConnection(t *testing.T) (net.Conn, net.Conn, func(), error) {
 		if err != nil {
 			t.Error(err)
 		}
-		remoteConn, err := Dial(remotePriv, testProtocol, testProtoVer, testChainID, 9001, netAddr, net.Dial)
+		remoteConn, err := Dial(remotePriv, testProtocol, testProtoVer, testDialerCaps, testChainID, 9001, netAddr, net.Dial)
 		remoteConnChan <- maybeNetConn{remoteConn, err}
 	}()
 
@@ -112,6 +123,14 @@ func TestConnectionCorrectness(t *testing.T) {
 	}
 	defer cleanUp()
 
+	// Both ends learn the capabilities of the other end.
+	if !reflect.DeepEqual(localConn.(*Conn).Capabilities, testDialerCaps) {
+		t.Fatalf("bad dialer capabilities: %+v", localConn.(*Conn).Capabilities)
+	}
+	if !reflect.DeepEqual(remoteConn.(*Conn).Capabilities, testListenerCaps) {
+		t.Fatalf("bad listener capabilities: %+v", remoteConn.(*Conn).Capabilities)
+	}
+
 	// Test out some message full-message reads.
 	for i := 0; i < 10; i++ {
 		msg := []byte("hello" + strconv.Itoa(i))
@@ -152,6 +171,65 @@ func TestConnectionCorrectness(t *testing.T) {
 	}
 }
 
+// identitySet is an IdentityFilter that allows the identities in the set
+type identitySet map[string]bool
+
+func (s identitySet) AllowIdentity(identity string) bool {
+	return s[identity]
+}
+
+// TestListenerIdentityFilter verifies that the listener closes the
+// connections of identities the filter does not allow and accepts the
+// others.
+func TestListenerIdentityFilter(t *testing.T) {
+	allowedPriv, err := secp256k1.NewPrivateKey(secp256k1.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejectedPriv, err := secp256k1.NewPrivateKey(secp256k1.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := identitySet{hex.EncodeToString(allowedPriv.PubKey().SerializeCompressed()): true}
+	listener, netAddr, err := makeFilteredListener(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	rejected, err := Dial(rejectedPriv, testProtocol, testProtoVer, testDialerCaps, testChainID, 9001, netAddr, net.Dial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rejected.Close()
+	if err := rejected.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rejected.Read(make([]byte, 1)); err == nil {
+		t.Fatal("connection of rejected identity not closed")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatal("connection of rejected identity not closed")
+	}
+
+	go func() {
+		conn, err := Dial(allowedPriv, testProtocol, testProtoVer, testDialerCaps, testChainID, 9001, netAddr, net.Dial)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		time.Sleep(time.Second)
+	}()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if !bytes.Equal(conn.RemotePub().SerializeCompressed(), allowedPriv.PubKey().SerializeCompressed()) {
+		t.Fatal("accepted connection of wrong identity")
+	}
+}
+
 // TestConcurrentHandshakes verifies the listener's ability to not be blocked
 // by other pending handshakes. This is tested by opening multiple tcp
 // connections with the listener, without completing any of the brontide acts.
@@ -202,7 +280,7 @@ func TestConcurrentHandshakes(t *testing.T) {
 		if err != nil {
 			t.Fatalf("unable to generate private key: %v", err)
 		}
-		remoteConn, err := Dial(remotePriv, testProtocol, testProtoVer, testChainID, 9001, netAddr, net.Dial)
+		remoteConn, err := Dial(remotePriv, testProtocol, testProtoVer, testDialerCaps, testChainID, 9001, netAddr, net.Dial)
 		if err != nil {
 			t.Errorf("Error in concurrent dial: %v", err)
 		}
@@ -92,6 +92,75 @@ func peerInitiatedVersionHandshake(conn net.Conn, version types.ProtoVersion) (u
 	return remoteversion, nil
 }
 
+// maxCapabilitiesSize bounds the size of the capabilities a peer may send
+const maxCapabilitiesSize = 1 << 16
+
+// ErrCapabilitiesTooLarge occurs when a peer sends capabilities larger than
+// maxCapabilitiesSize.
+var ErrCapabilitiesTooLarge = errors.New("remote peer sent capabilities that are too large")
+
+// negotiatesCapabilities returns true if both peers exchange capabilities.
+// Peers running an older protocol version do not know about capabilities and
+// are assumed to support the base protocol only.
+func negotiatesCapabilities(localVersion types.ProtoVersion, remoteVersion uint32) bool {
+	return localVersion >= types.CapabilityVersion && types.ProtoVersion(remoteVersion) >= types.CapabilityVersion
+}
+
+func selfInitiatedCapabilityHandshake(conn net.Conn, caps *types.Capabilities) (*types.Capabilities, error) {
+	if err := writeCapabilities(conn, caps); err != nil {
+		return nil, err
+	}
+	return readCapabilities(conn)
+}
+
+func peerInitiatedCapabilityHandshake(conn net.Conn, caps *types.Capabilities) (*types.Capabilities, error) {
+	remoteCaps, err := readCapabilities(conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCapabilities(conn, caps); err != nil {
+		return nil, err
+	}
+	return remoteCaps, nil
+}
+
+func writeCapabilities(conn net.Conn, caps *types.Capabilities) error {
+	if caps == nil {
+		caps = types.BaseCapabilities()
+	}
+	capsBytes, err := caps.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if len(capsBytes) > maxCapabilitiesSize {
+		return ErrCapabilitiesTooLarge
+	}
+	if err := writeUint32(conn, uint32(len(capsBytes))); err != nil {
+		return err
+	}
+	_, err = conn.Write(capsBytes)
+	return err
+}
+
+func readCapabilities(conn net.Conn) (*types.Capabilities, error) {
+	size, err := readUint32(conn)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxCapabilitiesSize {
+		return nil, ErrCapabilitiesTooLarge
+	}
+	capsBytes := make([]byte, size)
+	if _, err := io.ReadFull(conn, capsBytes); err != nil {
+		return nil, err
+	}
+	caps := &types.Capabilities{}
+	if err := caps.UnmarshalBinary(capsBytes); err != nil {
+		return nil, err
+	}
+	return caps, nil
+}
+
 func writeUint32(conn net.Conn, local uint32) error {
 	localBytes := marshalUint32(local)
 	_, err := conn.Write(localBytes[:])
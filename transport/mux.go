@@ -99,22 +99,24 @@ func (pmx *P2PMux) serverMux(ctx context.Context, conn interfaces.P2PConn) (inte
 			return
 		}
 		clientp2pconn := &P2PConn{
-			Conn:         clientConn,
+			Conn:         newMeteredConn(clientConn, nil, nil),
 			logger:       mlog,
 			nodeAddr:     conn.NodeAddr(),
 			protocol:     conn.Protocol(),
 			protoVersion: conn.ProtoVersion(),
+			capabilities: conn.Capabilities(),
 			initiator:    conn.Initiator(),
 			session:      session,
 			closeChan:    conn.CloseChan(),
 			cleanupfn:    func() {},
 		}
 		serverp2pconn := &P2PConn{
-			Conn:         serverConn,
+			Conn:         newMeteredConn(serverConn, nil, nil),
 			logger:       mlog,
 			nodeAddr:     conn.NodeAddr(),
 			protocol:     conn.Protocol(),
 			protoVersion: conn.ProtoVersion(),
+			capabilities: conn.Capabilities(),
 			initiator:    conn.Initiator(),
 			session:      session,
 			closeChan:    conn.CloseChan(),
@@ -176,18 +178,20 @@ func (pmx *P2PMux) clientMux(ctx context.Context, conn interfaces.P2PConn) (inte
 			return
 		}
 		clientp2pconn := &P2PConn{
-			Conn:         clientConn,
+			Conn:         newMeteredConn(clientConn, nil, nil),
 			initiator:    conn.Initiator(),
 			logger:       mlog,
 			protoVersion: conn.ProtoVersion(),
+			capabilities: conn.Capabilities(),
 			nodeAddr:     conn.NodeAddr(),
 			session:      session,
 			closeChan:    conn.CloseChan(),
 			cleanupfn:    func() {},
 		}
 		serverp2pconn := &P2PConn{
-			Conn:         serverConn,
+			Conn:         newMeteredConn(serverConn, nil, nil),
 			protoVersion: conn.ProtoVersion(),
+			capabilities: conn.Capabilities(),
 			logger:       mlog,
 			initiator:    conn.Initiator(),
 			nodeAddr:     conn.NodeAddr(),
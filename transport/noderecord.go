@@ -0,0 +1,230 @@
+package transport
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/MadBase/MadNet/crypto"
+	"github.com/MadBase/MadNet/interfaces"
+	"github.com/MadBase/MadNet/types"
+	eth "github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrRecordExpired occurs when a node record is past its expiry.
+	ErrRecordExpired = errors.New("node record expired")
+
+	// ErrRecordIdentity occurs when a node record is not signed by the
+	// identity it claims or the identity differs from the connection.
+	ErrRecordIdentity = errors.New("node record identity mismatch")
+
+	// ErrRecordChainID occurs when a node record or one of its addresses is
+	// for another chain.
+	ErrRecordChainID = errors.New("node record for wrong chain")
+
+	// ErrInvalidRecord occurs when a node record can not be decoded.
+	ErrInvalidRecord = errors.New("invalid node record")
+)
+
+// maxRecordAddrs bounds the number of addresses in a node record
+const maxRecordAddrs = 8
+
+// NodeRecord is a statement signed with the transport key of a node that
+// lists the addresses the node can be reached at. The record is verified
+// against the identity authenticated by the brontide handshake, so a node
+// can not publish addresses for another identity.
+type NodeRecord struct {
+	ChainID   types.ChainIdentifier
+	Identity  string
+	Addrs     []string
+	Expiry    time.Time
+	Signature []byte
+}
+
+// NewNodeRecord creates a record for addrs signed with the transport private
+// key that expires after ttl.
+func NewNodeRecord(privateKeyHex string, chainID types.ChainIdentifier, addrs []interfaces.NodeAddr, ttl time.Duration) (*NodeRecord, error) {
+	privk, err := deserializeTransportPrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	r := &NodeRecord{
+		ChainID:  chainID,
+		Identity: pubkeyToIdent(publicKeyFromPrivateKey(privk)),
+		Expiry:   time.Unix(time.Now().Add(ttl).Unix(), 0),
+	}
+	for _, addr := range addrs {
+		r.Addrs = append(r.Addrs, addr.P2PAddr())
+	}
+	// the signer requires a 32 byte key
+	keyBytes := make([]byte, 32)
+	d := privk.Serialize()
+	copy(keyBytes[32-len(d):], d)
+	signer := &crypto.Secp256k1Signer{}
+	if err := signer.SetPrivk(keyBytes); err != nil {
+		return nil, err
+	}
+	r.Signature, err = signer.Sign(r.signedBytes())
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// signedBytes is the encoding of the record without the signature
+func (r *NodeRecord) signedBytes() []byte {
+	out := make([]byte, 12)
+	binary.BigEndian.PutUint32(out[0:4], uint32(r.ChainID))
+	binary.BigEndian.PutUint64(out[4:12], uint64(r.Expiry.Unix()))
+	out = appendBytes(out, []byte(r.Identity))
+	out = append(out, byte(len(r.Addrs)))
+	for _, addr := range r.Addrs {
+		out = appendBytes(out, []byte(addr))
+	}
+	return out
+}
+
+// MarshalBinary encodes the record
+func (r *NodeRecord) MarshalBinary() ([]byte, error) {
+	if len(r.Addrs) > maxRecordAddrs {
+		return nil, ErrInvalidRecord
+	}
+	return appendBytes(r.signedBytes(), r.Signature), nil
+}
+
+// UnmarshalBinary decodes a record encoded by MarshalBinary. The record is
+// not verified.
+func (r *NodeRecord) UnmarshalBinary(v []byte) error {
+	if len(v) < 12 {
+		return ErrInvalidRecord
+	}
+	chainID := types.ChainIdentifier(binary.BigEndian.Uint32(v[0:4]))
+	expiry := time.Unix(int64(binary.BigEndian.Uint64(v[4:12])), 0)
+	identity, rest, err := readBytes(v[12:])
+	if err != nil {
+		return err
+	}
+	if len(rest) < 1 || int(rest[0]) > maxRecordAddrs {
+		return ErrInvalidRecord
+	}
+	n := int(rest[0])
+	rest = rest[1:]
+	var addrs []string
+	for i := 0; i < n; i++ {
+		var addr []byte
+		addr, rest, err = readBytes(rest)
+		if err != nil {
+			return err
+		}
+		addrs = append(addrs, string(addr))
+	}
+	sig, rest, err := readBytes(rest)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return ErrInvalidRecord
+	}
+	r.ChainID = chainID
+	r.Expiry = expiry
+	r.Identity = string(identity)
+	r.Addrs = addrs
+	r.Signature = sig
+	return nil
+}
+
+// Verify checks that the record is signed by identity, is for chainID and
+// has not expired at now. Every address in the record must belong to the
+// same identity and chain.
+func (r *NodeRecord) Verify(identity string, chainID types.ChainIdentifier, now time.Time) error {
+	if r.Identity != identity {
+		return ErrRecordIdentity
+	}
+	if r.ChainID != chainID {
+		return ErrRecordChainID
+	}
+	if !now.Before(r.Expiry) {
+		return ErrRecordExpired
+	}
+	validator := &crypto.Secp256k1Validator{}
+	pubk, err := validator.Validate(r.signedBytes(), r.Signature)
+	if err != nil {
+		return err
+	}
+	ecpubk, err := eth.UnmarshalPubkey(pubk)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(eth.CompressPubkey(ecpubk)) != r.Identity {
+		return ErrRecordIdentity
+	}
+	addrs, err := r.NodeAddrs()
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if addr.Identity() != r.Identity {
+			return ErrRecordIdentity
+		}
+		if addr.ChainID() != r.ChainID {
+			return ErrRecordChainID
+		}
+	}
+	return nil
+}
+
+// NodeAddrs parses the addresses of the record
+func (r *NodeRecord) NodeAddrs() ([]interfaces.NodeAddr, error) {
+	out := []interfaces.NodeAddr{}
+	for _, a := range r.Addrs {
+		addr, err := NewNodeAddr(a)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrInvalidRecord, err)
+		}
+		out = append(out, addr)
+	}
+	return out, nil
+}
+
+// ResolveHost returns addr with the host replaced by host if the host of
+// addr is unspecified. Nodes that listen on all interfaces do not know the
+// address they are reached at, so the observer of the connection fills it in.
+func ResolveHost(addr interfaces.NodeAddr, host string) interfaces.NodeAddr {
+	ip := net.ParseIP(addr.Host())
+	if addr.Host() != "" && (ip == nil || !ip.IsUnspecified()) {
+		return addr
+	}
+	na, ok := addr.(*NodeAddr)
+	if !ok {
+		return addr
+	}
+	return &NodeAddr{
+		host:     host,
+		port:     na.port,
+		identity: na.identity,
+		chainID:  na.chainID,
+	}
+}
+
+func appendBytes(out []byte, b []byte) []byte {
+	l := make([]byte, 2)
+	binary.BigEndian.PutUint16(l, uint16(len(b)))
+	out = append(out, l...)
+	return append(out, b...)
+}
+
+func readBytes(v []byte) ([]byte, []byte, error) {
+	if len(v) < 2 {
+		return nil, nil, ErrInvalidRecord
+	}
+	l := int(binary.BigEndian.Uint16(v[0:2]))
+	v = v[2:]
+	if len(v) < l {
+		return nil, nil, ErrInvalidRecord
+	}
+	return v[:l], v[l:], nil
+}
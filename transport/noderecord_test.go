@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MadBase/MadNet/interfaces"
+)
+
+func TestNodeRecord(t *testing.T) {
+	privk, err := NewTransportPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := deserializeTransportPrivateKey(privk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := &NodeAddr{
+		host:     "0.0.0.0",
+		port:     4242,
+		identity: publicKeyFromPrivateKey(key),
+		chainID:  7,
+	}
+	r, err := NewNodeRecord(privk, 7, []interfaces.NodeAddr{addr}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2 := &NodeRecord{}
+	if err := r2.UnmarshalBinary(rb); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := r2.Verify(addr.Identity(), 7, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := r2.Verify(addr.Identity(), 8, now); err != ErrRecordChainID {
+		t.Fatalf("wrong chain accepted: %v", err)
+	}
+	if err := r2.Verify(addr.Identity(), 7, now.Add(2*time.Minute)); err != ErrRecordExpired {
+		t.Fatalf("expired record accepted: %v", err)
+	}
+	other, err := RandomNodeAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r2.Verify(other.Identity(), 7, now); err != ErrRecordIdentity {
+		t.Fatalf("record accepted for another identity: %v", err)
+	}
+
+	// a record relabelled with another identity fails the signature check
+	r2.Identity = other.Identity()
+	if err := r2.Verify(other.Identity(), 7, now); err != ErrRecordIdentity {
+		t.Fatalf("forged record accepted: %v", err)
+	}
+
+	addrs, err := r.NodeAddrs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved := ResolveHost(addrs[0], "10.0.0.1")
+	if resolved.Host() != "10.0.0.1" || resolved.Port() != 4242 || resolved.Identity() != addr.Identity() {
+		t.Fatalf("bad resolved address: %s", resolved.P2PAddr())
+	}
+	if ResolveHost(resolved, "10.0.0.2").Host() != "10.0.0.1" {
+		t.Fatal("specified host replaced")
+	}
+}
@@ -23,6 +23,7 @@ type P2PConn struct {
 	logger       *logrus.Logger
 	protocol     types.Protocol
 	protoVersion types.ProtoVersion
+	capabilities *types.Capabilities
 	initiator    types.P2PInitiator
 	nodeAddr     interfaces.NodeAddr
 	closeOnce    sync.Once
@@ -77,6 +78,14 @@ func (pc *P2PConn) Identity() string {
 	return pc.nodeAddr.Identity()
 }
 
+// Bandwidth returns the bytes sent and received on this connection.
+func (pc *P2PConn) Bandwidth() types.Bandwidth {
+	if mc, ok := pc.Conn.(*meteredConn); ok {
+		return mc.bandwidth()
+	}
+	return types.Bandwidth{}
+}
+
 // NodeAddr returns the address of the peer.
 func (pc *P2PConn) NodeAddr() interfaces.NodeAddr {
 	return pc.nodeAddr
@@ -92,3 +101,8 @@ func (pc *P2PConn) Protocol() types.Protocol {
 func (pc *P2PConn) ProtoVersion() types.ProtoVersion {
 	return pc.protoVersion
 }
+
+// Capabilities returns the capabilities advertised by the remote peer.
+func (pc *P2PConn) Capabilities() *types.Capabilities {
+	return pc.capabilities
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/MadBase/MadNet/config"
+	"github.com/MadBase/MadNet/constants"
 	"github.com/MadBase/MadNet/crypto/secp256k1"
 	"github.com/MadBase/MadNet/interfaces"
 	"github.com/MadBase/MadNet/transport/brontide"
@@ -19,7 +20,7 @@ var _ interfaces.P2PTransport = (*P2PTransport)(nil)
 // the design of brontide.
 const (
 	tcpNetwork   string             = "tcp"
-	protoVersion types.ProtoVersion = 1
+	protoVersion types.ProtoVersion = 2
 )
 
 // P2PTransport wraps the brontide library in native types.
@@ -41,6 +42,8 @@ type P2PTransport struct {
 	closeOnce sync.Once
 	// channel connections hold for acceptance
 	connSuccessChan chan *P2PConn
+	// bandwidth counts and limits the traffic of all connections
+	bandwidth *bandwidthTracker
 }
 
 // Close will close all loops in this object and any
@@ -78,6 +81,7 @@ func (pt *P2PTransport) Dial(addr interfaces.NodeAddr, protocol types.Protocol)
 	bconn, err := brontide.Dial(pt.localPrivateKey,
 		protocol,
 		protoVersion,
+		localCapabilities(),
 		pt.localNodeAddr.ChainID(),
 		pt.localNodeAddr.Port(),
 		btcAddr,
@@ -87,15 +91,16 @@ func (pt *P2PTransport) Dial(addr interfaces.NodeAddr, protocol types.Protocol)
 	if err != nil {
 		return nil, err
 	}
+	nodeAddr := &NodeAddr{
+		host:     addr.Host(),
+		port:     bconn.P2PPort,
+		chainID:  pt.localNodeAddr.ChainID(),
+		identity: bconn.RemotePub(),
+	}
 	// convert from brontide connection into P2PConn
 	return &P2PConn{
-		nodeAddr: &NodeAddr{
-			host:     addr.Host(),
-			port:     bconn.P2PPort,
-			chainID:  pt.localNodeAddr.ChainID(),
-			identity: bconn.RemotePub(),
-		},
-		Conn:         bconn,
+		nodeAddr:     nodeAddr,
+		Conn:         pt.bandwidth.meter(bconn, nodeAddr.Identity(), bconn.Protocol, bconn.CloseChan()),
 		logger:       pt.logger,
 		initiator:    types.SelfInitiatedConnection,
 		protocol:     bconn.Protocol,
@@ -116,15 +121,16 @@ func (pt *P2PTransport) handleConnection(bconn *brontide.Conn) interfaces.P2PCon
 		}
 		return nil
 	}
+	nodeAddr := &NodeAddr{
+		host:     host,
+		port:     bconn.P2PPort,
+		chainID:  pt.localNodeAddr.ChainID(),
+		identity: bconn.RemotePub(),
+	}
 	// turn the brontide conn into a p2PConn
 	return &P2PConn{
-		nodeAddr: &NodeAddr{
-			host:     host,
-			port:     bconn.P2PPort,
-			chainID:  pt.localNodeAddr.ChainID(),
-			identity: bconn.RemotePub(),
-		},
-		Conn:         bconn,
+		nodeAddr:     nodeAddr,
+		Conn:         pt.bandwidth.meter(bconn, nodeAddr.Identity(), bconn.Protocol, bconn.CloseChan()),
 		logger:       pt.logger,
 		initiator:    types.PeerInitiatedConnection,
 		protocol:     bconn.Protocol,
@@ -134,6 +140,29 @@ func (pt *P2PTransport) handleConnection(bconn *brontide.Conn) interfaces.P2PCon
 	}
 }
 
+// Bandwidth returns the traffic of all connections since the transport was
+// created per protocol.
+func (pt *P2PTransport) Bandwidth() map[types.Protocol]types.Bandwidth {
+	return pt.bandwidth.total()
+}
+
+// PeerBandwidth returns the traffic of the open connections to the peer
+// with the given identity per protocol.
+func (pt *P2PTransport) PeerBandwidth(identity string) map[types.Protocol]types.Bandwidth {
+	return pt.bandwidth.peer(identity)
+}
+
+// localCapabilities returns the capabilities advertised to remote peers
+// during the handshake.
+func localCapabilities() *types.Capabilities {
+	caps := types.BaseCapabilities()
+	caps.MaxMessageSize = constants.MaxMessageSize
+	if config.Configuration.Transport.Compression {
+		caps.Compression = []string{constants.CompressionSnappy}
+	}
+	return caps
+}
+
 // Accept method MUST be called after the transport is started.
 // This method will return connections that remote peers open
 // to this node.
@@ -167,8 +196,9 @@ func (pt *P2PTransport) Accept() (interfaces.P2PConn, error) {
 }
 
 // NewP2PTransport returns a transport object. This object is both a server
-// and a client.
-func NewP2PTransport(logger *logrus.Logger, cid types.ChainIdentifier, privateKeyHex string, port int, host string) (interfaces.P2PTransport, error) {
+// and a client. Inbound connections from identities that allowlist does not
+// allow are rejected; a nil allowlist allows every identity.
+func NewP2PTransport(logger *logrus.Logger, cid types.ChainIdentifier, privateKeyHex string, port int, host string, allowlist *Allowlist) (interfaces.P2PTransport, error) {
 	localPrivateKey, err := deserializeTransportPrivateKey(privateKeyHex)
 	if err != nil {
 		return nil, err
@@ -194,7 +224,7 @@ func NewP2PTransport(logger *logrus.Logger, cid types.ChainIdentifier, privateKe
 		mp = config.Configuration.Transport.PeerLimitMax
 	}
 
-	listener, err := brontide.NewListener(localPrivateKey, host, port, protoVersion, cid, mp, 1, mc)
+	listener, err := brontide.NewListener(localPrivateKey, host, port, protoVersion, localCapabilities(), cid, mp, 1, mc, allowlist)
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +235,7 @@ func NewP2PTransport(logger *logrus.Logger, cid types.ChainIdentifier, privateKe
 		localPrivateKey: localPrivateKey,
 		listener:        listener,
 		closeChan:       make(chan struct{}),
+		bandwidth:       newBandwidthTracker(config.Configuration.Transport.BandwidthLimit, config.Configuration.Transport.PeerBandwidthLimit),
 	}
 	return transport, nil
 }
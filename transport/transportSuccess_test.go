@@ -36,14 +36,14 @@ func TestTransportsuccess(t *testing.T) {
 	}
 	nodePrivKey2Hex := serializeTransportPrivateKey(nodePrivKey2)
 
-	transport1, err := NewP2PTransport(logger, testCID, nodePrivKey1Hex, t1Port, t1Host)
+	transport1, err := NewP2PTransport(logger, testCID, nodePrivKey1Hex, t1Port, t1Host, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer transport1.Close()
 	nodeAddr1 := transport1.NodeAddr()
 
-	transport2, err := NewP2PTransport(logger, testCID, nodePrivKey2Hex, t2Port, t2Host)
+	transport2, err := NewP2PTransport(logger, testCID, nodePrivKey2Hex, t2Port, t2Host, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,8 @@
+package types
+
+// Bandwidth is the number of bytes sent to and received from a peer over a
+// connection or a set of connections.
+type Bandwidth struct {
+	BytesSent     uint64
+	BytesReceived uint64
+}
@@ -0,0 +1,154 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// CapabilityVersion is the first protocol version that exchanges
+// capabilities during the handshake. Peers on an older version are assumed
+// to support the base protocol only.
+const CapabilityVersion ProtoVersion = 2
+
+// BaseRPCs are the P2P RPC methods served by every peer including the peers
+// that do not exchange capabilities. New methods must not be added here;
+// they are advertised through the capabilities instead.
+var BaseRPCs = []string{
+	"Status",
+	"GetBlockHeaders",
+	"GetMinedTxs",
+	"GetPendingTxs",
+	"GetSnapShotNode",
+	"GetSnapShotStateData",
+	"GetSnapShotHdrNode",
+	"GossipTransaction",
+	"GossipProposal",
+	"GossipPreVote",
+	"GossipPreVoteNil",
+	"GossipPreCommit",
+	"GossipPreCommitNil",
+	"GossipNextRound",
+	"GossipNextHeight",
+	"GossipBlockHeader",
+	"GetPeers",
+}
+
+// maxCapabilityEntries bounds the number of entries of each list so that a
+// peer can not make the local node allocate without limit
+const maxCapabilityEntries = 256
+
+// Capabilities is the set of optional protocol features a peer supports.
+// MaxMessageSize is the largest message in bytes the peer accepts and
+// Compression lists the payload compression algorithms the peer can decode.
+type Capabilities struct {
+	RPCs           []string
+	MaxMessageSize uint32
+	Compression    []string
+}
+
+// BaseCapabilities returns the capabilities of a peer that does not
+// exchange capabilities
+func BaseCapabilities() *Capabilities {
+	rpcs := make([]string, len(BaseRPCs))
+	copy(rpcs, BaseRPCs)
+	return &Capabilities{RPCs: rpcs}
+}
+
+// SupportsRPC returns true if the peer serves the RPC method name
+func (c *Capabilities) SupportsRPC(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, rpc := range c.RPCs {
+		if rpc == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsCompression returns true if the peer can decode payloads
+// compressed with algorithm name
+func (c *Capabilities) SupportsCompression(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, alg := range c.Compression {
+		if alg == name {
+			return true
+		}
+	}
+	return false
+}
+
+func marshalStrings(out []byte, list []string) []byte {
+	out = append(out, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(out[len(out)-4:], uint32(len(list)))
+	for _, s := range list {
+		out = append(out, make([]byte, 4)...)
+		binary.BigEndian.PutUint32(out[len(out)-4:], uint32(len(s)))
+		out = append(out, []byte(s)...)
+	}
+	return out
+}
+
+func unmarshalStrings(v []byte) ([]string, []byte, error) {
+	if len(v) < 4 {
+		return nil, nil, errors.New("invalid capabilities")
+	}
+	n := binary.BigEndian.Uint32(v[0:4])
+	v = v[4:]
+	if n > maxCapabilityEntries {
+		return nil, nil, errors.New("too many capabilities")
+	}
+	var list []string
+	for i := uint32(0); i < n; i++ {
+		if len(v) < 4 {
+			return nil, nil, errors.New("invalid capabilities")
+		}
+		l := binary.BigEndian.Uint32(v[0:4])
+		v = v[4:]
+		if uint32(len(v)) < l {
+			return nil, nil, errors.New("invalid capabilities")
+		}
+		list = append(list, string(v[:l]))
+		v = v[l:]
+	}
+	return list, v, nil
+}
+
+// MarshalBinary encodes the capabilities as the max message size followed by
+// the length prefixed lists of RPCs and compression algorithms
+func (c *Capabilities) MarshalBinary() ([]byte, error) {
+	if len(c.RPCs) > maxCapabilityEntries || len(c.Compression) > maxCapabilityEntries {
+		return nil, errors.New("too many capabilities")
+	}
+	out := make([]byte, 4)
+	binary.BigEndian.PutUint32(out, c.MaxMessageSize)
+	out = marshalStrings(out, c.RPCs)
+	out = marshalStrings(out, c.Compression)
+	return out, nil
+}
+
+// UnmarshalBinary decodes capabilities encoded by MarshalBinary
+func (c *Capabilities) UnmarshalBinary(v []byte) error {
+	if len(v) < 4 {
+		return errors.New("invalid capabilities")
+	}
+	maxMessageSize := binary.BigEndian.Uint32(v[0:4])
+	rpcs, rest, err := unmarshalStrings(v[4:])
+	if err != nil {
+		return err
+	}
+	compression, rest, err := unmarshalStrings(rest)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("invalid capabilities")
+	}
+	c.MaxMessageSize = maxMessageSize
+	c.RPCs = rpcs
+	c.Compression = compression
+	return nil
+}
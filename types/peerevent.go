@@ -0,0 +1,33 @@
+package types
+
+// PeerEvent classifies an interaction with a remote peer. Events are
+// reported to the peer manager and drive the reputation of the peer.
+type PeerEvent uint8
+
+// These types designate the events reported about a remote peer.
+// If this value is PeerUseful, the peer delivered valid data.
+// If this value is PeerTimeout, a request to the peer timed out.
+// If this value is PeerInvalidGossip, the peer gossiped an invalid message.
+// If this value is PeerInvalidResponse, the peer answered a request with
+// data that failed validation.
+const (
+	PeerUseful = PeerEvent(iota + 1)
+	PeerTimeout
+	PeerInvalidGossip
+	PeerInvalidResponse
+)
+
+func (pe PeerEvent) String() string {
+	switch pe {
+	case PeerUseful:
+		return "useful"
+	case PeerTimeout:
+		return "timeout"
+	case PeerInvalidGossip:
+		return "invalid gossip"
+	case PeerInvalidResponse:
+		return "invalid response"
+	default:
+		return "unknown"
+	}
+}
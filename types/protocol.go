@@ -15,3 +15,16 @@ const (
 	DiscProtocol
 	Bootnode
 )
+
+func (p Protocol) String() string {
+	switch p {
+	case P2PProtocol:
+		return "p2p"
+	case DiscProtocol:
+		return "discovery"
+	case Bootnode:
+		return "bootnode"
+	default:
+		return "unknown"
+	}
+}